@@ -0,0 +1,158 @@
+package initial
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/bootstrap"
+	"go-admin/internal/config"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+	"go-admin/internal/routers"
+)
+
+const commandUsage = `usage: admin [flags] <command>
+
+commands:
+  migrate up          apply all pending schema migrations
+  migrate down [n]    revert the last n applied migrations, default 1
+  migrate status      show the state of every migration
+  bootstrap           create the admin user, the default roles and the api rows if they do not exist,
+                      flags: -name, -password, -email, they default to the envs ADMIN_NAME, ADMIN_PASSWORD,
+                      ADMIN_EMAIL and the bootstrap settings of the configuration file`
+
+// HasCommand whether a subcommand is given after the flags, e.g. admin -c configs/admin.yml migrate up
+func HasCommand() bool {
+	return flag.NArg() > 0
+}
+
+// RunCommand run the subcommand given after the flags and exit, the exit code is 1 if it fails
+func RunCommand() {
+	args := flag.Args()
+	var err error
+	switch args[0] {
+	case "migrate":
+		err = runMigrate(args[1:])
+	case "bootstrap":
+		err = runBootstrap(args[1:])
+	default:
+		err = fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage)
+	}
+	_ = model.CloseDB()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate action\n\n%s", commandUsage)
+	}
+	db, driver := model.GetDB(), config.Get().Database.Driver
+
+	switch args[0] {
+	case "up":
+		done, err := migration.Up(db, driver)
+		for _, m := range done {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+			steps = n
+		}
+		done, err := migration.Down(db, driver, steps)
+		for _, m := range done {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("no applied migrations")
+		}
+		return err
+
+	case "status":
+		list, err := migration.GetStatus(db, driver)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range list {
+			state, appliedAt := "pending", ""
+			if s.Applied {
+				state, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+				if s.Modified {
+					state = "modified"
+				}
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	return fmt.Errorf("unknown migrate action %q\n\n%s", args[0], commandUsage)
+}
+
+func runBootstrap(args []string) error {
+	opts := bootstrap.Options{}
+	fs := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
+	fs.StringVar(&opts.AdminName, "name", "", "name of the admin user")
+	fs.StringVar(&opts.AdminPassword, "password", "", "password of the admin user, only required to create the user")
+	fs.StringVar(&opts.AdminEmail, "email", "", "email of the admin user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	gin.SetMode(gin.ReleaseMode) // the router is only built to list the routes
+	result, err := bootstrap.Run(context.Background(), model.GetDB(), routers.NewRouter().Routes(), bootstrapOptions(opts))
+	if err != nil {
+		return err
+	}
+	printBootstrapResult(result)
+	return nil
+}
+
+// bootstrapOptions the flags take precedence over the envs, the envs over the configuration file
+func bootstrapOptions(opts bootstrap.Options) bootstrap.Options {
+	opts = bootstrap.OptionsFromEnv(opts)
+	cfg := config.Get().Bootstrap
+	if opts.AdminName == "" {
+		opts.AdminName = cfg.AdminName
+	}
+	if opts.AdminEmail == "" {
+		opts.AdminEmail = cfg.AdminEmail
+	}
+	return opts
+}
+
+func printBootstrapResult(result *bootstrap.Result) {
+	if result.AdminCreated {
+		fmt.Println("created the admin user")
+	}
+	if result.AdminBound {
+		fmt.Println("granted the admin role to the admin user")
+	}
+	if len(result.Roles) > 0 {
+		fmt.Println("created roles: " + strings.Join(result.Roles, ", "))
+	}
+	fmt.Printf("created %d apis\n", result.Apis)
+}
@@ -1,8 +1,12 @@
 package initial
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
 
 	"github.com/zhufuyi/sponge/pkg/app"
 	"github.com/zhufuyi/sponge/pkg/logger"
@@ -11,7 +15,14 @@ import (
 	"github.com/zhufuyi/sponge/pkg/servicerd/registry/etcd"
 	"github.com/zhufuyi/sponge/pkg/servicerd/registry/nacos"
 
+	"go-admin/internal/apisync"
+	"go-admin/internal/bootstrap"
+	"go-admin/internal/cache"
 	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/elevation"
+	"go-admin/internal/handler"
+	"go-admin/internal/model"
 	"go-admin/internal/server"
 )
 
@@ -26,12 +37,59 @@ func CreateServices() []app.IServer {
 	httpServer := server.NewHTTPServer(httpAddr,
 		server.WithHTTPRegistry(httpRegistry, httpInstance),
 		server.WithHTTPIsProd(cfg.App.Env == "prod"),
+		server.WithHTTPRoutesHook(bootstrapOnStartup),
 	)
 	servers = append(servers, httpServer)
 
+	// expiring the temporary roles of the users
+	reaper := elevation.NewReaper(dao.NewRoleElevationDao(model.GetDB()), time.Duration(cfg.Elevation.ReapInterval)*time.Second,
+		elevation.WithOnExpire(endElevation(cache.NewRoleElevationCache(model.GetCacheType()), handler.NewKubeconfigSyncer())),
+	)
+	servers = append(servers, reaper)
+
 	return servers
 }
 
+// endElevation forget the cached grants of the user of an expired grant, and remove the bindings of the role
+// from the kubeconfig of the user
+func endElevation(elevations cache.RoleElevationCache, kubeconfig handler.KubeconfigSyncer) elevation.ExpireFunc {
+	return func(ctx context.Context, record *model.RoleElevation) {
+		if err := elevations.Del(ctx, record.UserID); err != nil {
+			logger.Warn("Del role elevations error", logger.Err(err), logger.Uint64("userID", record.UserID))
+		}
+		if err := kubeconfig.Sync(ctx, record.UserID); err != nil {
+			logger.Warn("Sync kubeconfig error", logger.Err(err), logger.Uint64("userID", record.UserID))
+		}
+	}
+}
+
+// bootstrapOnStartup create the admin user, the default roles and the api rows if bootstrap is enabled,
+// otherwise only sync the api rows with the routes if syncApis is enabled
+func bootstrapOnStartup(routes gin.RoutesInfo) {
+	cfg := config.Get()
+	if !cfg.Bootstrap.Enable {
+		if cfg.Bootstrap.SyncApis {
+			syncApis(routes)
+		}
+		return
+	}
+
+	result, err := bootstrap.Run(context.Background(), model.GetDB(), routes, bootstrapOptions(bootstrap.Options{}))
+	if err != nil {
+		panic("bootstrap error: " + err.Error())
+	}
+	logger.Info("bootstrap succeeded", logger.Bool("adminCreated", result.AdminCreated),
+		logger.Any("roles", result.Roles), logger.Int("apis", result.Apis))
+}
+
+func syncApis(routes gin.RoutesInfo) {
+	changes, err := apisync.Sync(context.Background(), model.GetDB(), routes, false)
+	if err != nil {
+		panic("sync apis error: " + err.Error())
+	}
+	logger.Info("sync apis succeeded", logger.Int("changes", len(changes)))
+}
+
 func registerService(scheme string, host string, port int) (registry.Registry, *registry.ServiceInstance) {
 	var (
 		instanceEndpoint = fmt.Sprintf("%s://%s:%d", scheme, host, port)
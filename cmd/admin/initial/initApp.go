@@ -18,6 +18,7 @@ import (
 
 	"go-admin/configs"
 	"go-admin/internal/config"
+	"go-admin/internal/migration"
 	"go-admin/internal/model"
 )
 
@@ -54,6 +55,13 @@ func InitApp() {
 	// initializing database
 	model.InitDB()
 	logger.Infof("init %s succeeded", cfg.Database.Driver)
+	if cfg.Database.AutoMigrate && !HasCommand() {
+		done, err := migration.Up(model.GetDB(), cfg.Database.Driver)
+		if err != nil {
+			panic("auto migrate error: " + err.Error())
+		}
+		logger.Info("auto migrate succeeded", logger.Int("applied", len(done)))
+	}
 	model.InitCache(cfg.App.CacheType)
 
 	// initializing tracing
@@ -15,9 +15,14 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Type Bearer your-jwt-token to Value
+// @description Type Bearer your-jwt-token or Bearer your-api-token (gat_...) to Value
 func main() {
 	initial.InitApp()
+	if initial.HasCommand() {
+		initial.RunCommand()
+		return
+	}
+
 	services := initial.CreateServices()
 	closes := initial.Close(services)
 
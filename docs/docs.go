@@ -133,6 +133,108 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/api/export": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "export the apis matching the query conditions to a csv, json or yaml file, the file can be imported again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api"
+                ],
+                "summary": "export apis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "query conditions",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ExportApisRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportApiRow"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create or update apis from a csv, json or yaml file in one transaction, the apis are matched by action and path.\nevery row is validated and reported, nothing is saved if a row is invalid or dryRun is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api"
+                ],
+                "summary": "import apis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate the rows without saving them",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "apis",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportApiRow"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportRespond"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/api/list": {
             "get": {
                 "security": [
@@ -261,49 +363,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/api/{id}": {
-            "get": {
+        "/api/v1/api/sync": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "get api detail by id",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "create the api rows of the new routes, update the handle and title from the handler names and swagger summaries, and mark the rows whose routes are gone as stale,\nonly for the admin roles",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "api"
                 ],
-                "summary": "get api detail",
+                "summary": "sync apis",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "default": false,
+                        "description": "only show the changes without saving them",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetApiByIDRespond"
+                            "$ref": "#/definitions/types.SyncApisRespond"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/api/trash/list": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "update api information by id",
+                "description": "list of the apis in the trash by paging and conditions, they can be restored or purged",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,22 +414,15 @@ const docTemplate = `{
                 "tags": [
                     "api"
                 ],
-                "summary": "update api",
+                "summary": "list of deleted apis",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "api information",
+                        "description": "query parameters",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateApiByIDRequest"
+                            "$ref": "#/definitions/types.Params"
                         }
                     }
                 ],
@@ -336,18 +430,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateApiByIDRespond"
+                            "$ref": "#/definitions/types.ListTrashApisRespond"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/api/trash/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "delete api by id",
+                "description": "permanently delete the api in the trash by id, the apis that are not deleted can not be purged",
                 "consumes": [
                     "application/json"
                 ],
@@ -357,7 +453,7 @@ const docTemplate = `{
                 "tags": [
                     "api"
                 ],
-                "summary": "delete api",
+                "summary": "purge api",
                 "parameters": [
                     {
                         "type": "string",
@@ -371,20 +467,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteApiByIDRespond"
+                            "$ref": "#/definitions/types.PurgeApiRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/login": {
+        "/api/v1/api/trash/{id}/restore": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Login information",
+                "description": "restore the deleted api by id, it fails if another api with the same action and path exists",
                 "consumes": [
                     "application/json"
                 ],
@@ -392,33 +488,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "api"
                 ],
-                "summary": "Login api",
+                "summary": "restore api",
                 "parameters": [
                     {
-                        "description": "user information",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.CreateUserRespond"
+                            "$ref": "#/definitions/types.RestoreApiRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/proxy/api": {
+        "/api/v1/api/{id}": {
             "get": {
-                "description": "代理K8s的所有接口",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get api detail by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -426,20 +525,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "代理K8s的所有接口"
+                    "api"
                 ],
-                "summary": "代理K8s的所有接口",
-                "responses": {}
-            }
-        },
-        "/api/v1/reg": {
-            "post": {
+                "summary": "get api detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by a previous get, the status is 304 if the api has not been modified",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetApiByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the api, send it in the If-Match header to update the api"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "submit information to create user",
+                "description": "update api information by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -447,38 +572,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "api"
                 ],
-                "summary": "Register api",
+                "summary": "update api",
                 "parameters": [
                     {
-                        "description": "user information",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "api information",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.CreateUserRequest"
+                            "$ref": "#/definitions/types.UpdateApiByIDRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.CreateUserRespond"
+                            "$ref": "#/definitions/types.UpdateApiByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated api"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/role": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "submit information to create role",
+                "description": "delete api by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -486,38 +628,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "api"
                 ],
-                "summary": "create role",
+                "summary": "delete api",
                 "parameters": [
                     {
-                        "description": "role information",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.CreateRoleRequest"
-                        }
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.CreateRoleRespond"
+                            "$ref": "#/definitions/types.DeleteApiByIDRespond"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/role/condition": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "get role by condition",
+                "description": "update the fields present in the JSON merge patch (RFC 7386) of the api, the absent fields are unchanged and null clears a field,\nthe path and the action can not be cleared, the values are validated by the rules of the update.",
                 "consumes": [
                     "application/json"
                 ],
@@ -525,38 +663,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "api"
                 ],
-                "summary": "get role by condition",
+                "summary": "patch api",
                 "parameters": [
                     {
-                        "description": "query condition",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the fields to change, the version is required unless the If-Match header is set",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.Conditions"
+                            "$ref": "#/definitions/types.UpdateApiByIDRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetRoleByConditionRespond"
+                            "$ref": "#/definitions/types.UpdateApiByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated api"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/v1/role/delete/ids": {
+        "/api/v1/department": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "delete roles by batch id",
+                "description": "submit information to create a department under its parent",
                 "consumes": [
                     "application/json"
                 ],
@@ -564,17 +721,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "department"
                 ],
-                "summary": "delete roles",
+                "summary": "create department",
                 "parameters": [
                     {
-                        "description": "id array",
+                        "description": "department information",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteRolesByIDsRequest"
+                            "$ref": "#/definitions/types.CreateDepartmentRequest"
                         }
                     }
                 ],
@@ -582,148 +739,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteRolesByIDsRespond"
+                            "$ref": "#/definitions/types.CreateDepartmentRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/role/list": {
+        "/api/v1/department/tree": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "list of roles by last id and limit",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "role"
-                ],
-                "summary": "list of roles by last id and limit",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "last id, default is MaxInt32",
-                        "name": "lastID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "size in each page",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "-id",
-                        "description": "sort by column name of table, and the ",
-                        "name": "sort",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/types.ListRolesRespond"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "list of roles by paging and conditions",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "role"
-                ],
-                "summary": "list of roles by query parameters",
-                "parameters": [
-                    {
-                        "description": "query parameters",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.Params"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/types.ListRolesRespond"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/role/list/ids": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "list of roles by batch id",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "get all the departments as a tree, the siblings are sorted by sort and id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
-                ],
-                "summary": "list of roles by batch id",
-                "parameters": [
-                    {
-                        "description": "id array",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.ListRolesByIDsRequest"
-                        }
-                    }
+                    "department"
                 ],
+                "summary": "get the department tree",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.ListRolesByIDsRespond"
+                            "$ref": "#/definitions/types.GetDepartmentTreeRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/role/{id}": {
+        "/api/v1/department/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "get role detail by id",
+                "description": "get department detail by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -731,9 +785,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "department"
                 ],
-                "summary": "get role detail",
+                "summary": "get department detail",
                 "parameters": [
                     {
                         "type": "string",
@@ -747,7 +801,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetRoleByIDRespond"
+                            "$ref": "#/definitions/types.GetDepartmentByIDRespond"
                         }
                     }
                 }
@@ -758,7 +812,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "update role information by id",
+                "description": "replace the information of a department, it can be moved under another parent but not under itself or its sub departments",
                 "consumes": [
                     "application/json"
                 ],
@@ -766,9 +820,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "department"
                 ],
-                "summary": "update role",
+                "summary": "update department",
                 "parameters": [
                     {
                         "type": "string",
@@ -778,12 +832,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "role information",
+                        "description": "department information",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateRoleByIDRequest"
+                            "$ref": "#/definitions/types.UpdateDepartmentByIDRequest"
                         }
                     }
                 ],
@@ -791,7 +845,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateRoleByIDRespond"
+                            "$ref": "#/definitions/types.UpdateDepartmentByIDRespond"
                         }
                     }
                 }
@@ -802,7 +856,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "delete role by id",
+                "description": "delete a department without sub departments, its members and its grants to the custom data scopes of the roles",
                 "consumes": [
                     "application/json"
                 ],
@@ -810,9 +864,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "department"
                 ],
-                "summary": "delete role",
+                "summary": "delete department",
                 "parameters": [
                     {
                         "type": "string",
@@ -826,59 +880,51 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteRoleByIDRespond"
+                            "$ref": "#/definitions/types.DeleteDepartmentByIDRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/condition": {
-            "post": {
+        "/api/v1/elevation": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "get user by condition",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list the role elevations of all the users for the approvers, the latest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "get user by condition",
+                "summary": "list role elevations",
                 "parameters": [
                     {
-                        "description": "query condition",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.Conditions"
-                        }
+                        "type": "string",
+                        "description": "pending, approved, rejected, cancelled, revoked or expired",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetUserByConditionRespond"
+                            "$ref": "#/definitions/types.ListElevationsRespond"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/user/delete/ids": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "delete users by batch id",
+                "description": "request to hold a role for duration seconds from the approval, with a justification, a member of the approver roles approves or rejects it",
                 "consumes": [
                     "application/json"
                 ],
@@ -886,17 +932,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "delete users",
+                "summary": "request role elevation",
                 "parameters": [
                     {
-                        "description": "id array",
+                        "description": "elevation information",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteUsersByIDsRequest"
+                            "$ref": "#/definitions/types.CreateElevationRequest"
                         }
                     }
                 ],
@@ -904,109 +950,79 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteUsersByIDsRespond"
+                            "$ref": "#/definitions/types.CreateElevationRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/list": {
+        "/api/v1/elevation/me": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "list of users by last id and limit",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list the role elevations requested by the current user, the latest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "list of users by last id and limit",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "last id, default is MaxInt32",
-                        "name": "lastID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "size in each page",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "-id",
-                        "description": "sort by column name of table, and the ",
-                        "name": "sort",
-                        "in": "query"
-                    }
+                    "elevation"
                 ],
+                "summary": "list my role elevations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.ListUsersRespond"
+                            "$ref": "#/definitions/types.ListElevationsRespond"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/elevation/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "list of users by paging and conditions",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "get a role elevation with all its steps, including the requests made while the role was held, for the requester and the approvers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "list of users by query parameters",
+                "summary": "get role elevation",
                 "parameters": [
                     {
-                        "description": "query parameters",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.Params"
-                        }
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.ListUsersRespond"
+                            "$ref": "#/definitions/types.GetElevationRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/list/ids": {
+        "/api/v1/elevation/{id}/approve": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "list of users by batch id",
+                "description": "approve a pending role elevation of another user, the role is held for its duration from now",
                 "consumes": [
                     "application/json"
                 ],
@@ -1014,17 +1030,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "list of users by batch id",
+                "summary": "approve role elevation",
                 "parameters": [
                     {
-                        "description": "id array",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "review comment",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.ListUsersByIDsRequest"
+                            "$ref": "#/definitions/types.ReviewElevationRequest"
                         }
                     }
                 ],
@@ -1032,30 +1055,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.ListUsersByIDsRespond"
+                            "$ref": "#/definitions/types.GetElevationRespond"
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/{id}": {
-            "get": {
+        "/api/v1/elevation/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "get user detail by id",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "withdraw a pending role elevation of the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "get user detail",
+                "summary": "cancel role elevation",
                 "parameters": [
                     {
                         "type": "string",
@@ -1069,18 +1089,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetUserByIDRespond"
+                            "$ref": "#/definitions/types.GetElevationRespond"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/elevation/{id}/reject": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "update user information by id",
+                "description": "reject a pending role elevation of another user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1088,9 +1110,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "update user",
+                "summary": "reject role elevation",
                 "parameters": [
                     {
                         "type": "string",
@@ -1100,12 +1122,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "user information",
+                        "description": "review comment",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateUserByIDRequest"
+                            "$ref": "#/definitions/types.ReviewElevationRequest"
                         }
                     }
                 ],
@@ -1113,18 +1135,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateUserByIDRespond"
+                            "$ref": "#/definitions/types.GetElevationRespond"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/elevation/{id}/revoke": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "delete user by id",
+                "description": "end an active role elevation before its expiry, by an approver or by the requester giving the role up, the bindings\nof the role are removed from the kubeconfig of the requester",
                 "consumes": [
                     "application/json"
                 ],
@@ -1132,9 +1156,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "elevation"
                 ],
-                "summary": "delete user",
+                "summary": "revoke role elevation",
                 "parameters": [
                     {
                         "type": "string",
@@ -1142,176 +1166,7716 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
+                    },
+                    {
+                        "description": "comment",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ReviewElevationRequest"
+                        }
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteUserByIDRespond"
+                            "$ref": "#/definitions/types.GetElevationRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/apis": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list all api groups and resources served by the cluster, including custom resources, the core group is named core",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "list of api resources",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListAPIResourcesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/apis/{group}/{version}/{resource}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of a resource served by a crd, rendered with the printer columns of the crd,\nthe namespaced resources are only listed in the namespaces of the data scope of the user,\nthe cluster-scoped resources only if the data scope has all the namespaces",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "list of custom resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "api group of the crd",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource, plural name",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace, if empty, all namespaces",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "size in each page, 0 means no limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "continue token of the previous page",
+                        "name": "continue",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListCustomResourcesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/apis/{group}/{version}/{resource}/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get a resource served by a crd by name, in a namespace of the data scope of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "get custom resource detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "api group of the crd",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource, plural name",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace, required for namespaced resources",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetCustomResourceRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace a resource served by a crd, metadata.resourceVersion is required, the object is validated against the openAPIV3Schema of the crd",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "update custom resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "api group of the crd",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource, plural name",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace, required for namespaced resources",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "description": "object",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CustomResourceObject"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetCustomResourceRespond"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete a resource served by a crd by name, in a namespace of the data scope of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "delete custom resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "api group of the crd",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource, plural name",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace, required for namespaced resources",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteCustomResourceRespond"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "types.ApiObjDetail": {
+        },
+        "/api/v1/k8s/crds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of customResourceDefinitions with served versions and printer columns",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customResource"
+                ],
+                "summary": "list of customResourceDefinitions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListCRDsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/kubeconfig": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create or reuse the service account of the user who sent the request, bind it to the cluster roles in the\nnamespaces permitted by the data scope of the roles of the user and by the departments of their data scope\nmodes, and return a kubeconfig file with a token of the service account that expires after expiresIn seconds.\nThe bindings no longer permitted are removed. The token expires no later than the temporary roles of the user,\nthe kubeconfig is refused if one of them ends before the minimum lifetime of a token.",
+                "produces": [
+                    "application/yaml"
+                ],
+                "tags": [
+                    "kubeconfig"
+                ],
+                "summary": "generate my kubeconfig",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lifetime of the token in seconds, 0 is the default lifetime",
+                        "name": "expiresIn",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "kubeconfig file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete the service account of the user who sent the request and its bindings, the tokens of all the\nkubeconfigs generated before are rejected by the api server at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kubeconfig"
+                ],
+                "summary": "revoke my kubeconfigs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevokeKubeconfigRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of the names of the namespaces of the cluster, only the namespaces permitted by the data scope of the\nroles of the user who sent the request and of their departments are listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "namespace"
+                ],
+                "summary": "list of namespaces",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListNamespacesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/configmaps": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of configMaps in namespace",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "configMap"
+                ],
+                "summary": "list of configMaps",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListConfigMapsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/configmaps/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get configMap detail by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "configMap"
+                ],
+                "summary": "get configMap detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetConfigMapRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/configmaps/{name}/keys/{key}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "add or update a key of configMap, base64 values are stored in binaryData, the other keys are kept as they are,\nonly for roles configured in k8s.writeRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "configMap"
+                ],
+                "summary": "set configMap key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "value",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetConfigMapKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetConfigMapKeyRespond"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete a key of configMap, the other keys are kept as they are, only for roles configured in k8s.writeRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "configMap"
+                ],
+                "summary": "delete configMap key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteConfigMapKeyRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/portforward": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "open a port-forward to a pod, or to a ready pod backing a service, the session is reachable through the http reverse-proxy path or the websocket tunnel path until it expires or is closed,\nonly for roles configured in k8s.portForwardRoles and the namespaces of the data scope of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portForward"
+                ],
+                "summary": "create port-forward",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "target",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreatePortForwardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreatePortForwardRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/pvcs/{name}/expand": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "request a larger size for persistentVolumeClaim, the storageClass must set allowVolumeExpansion,\nonly for roles configured in k8s.writeRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "expand persistentVolumeClaim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new size",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ExpandPVCRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ExpandPVCRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/secrets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of secrets in namespace, values are masked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secret"
+                ],
+                "summary": "list of secrets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListSecretsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/secrets/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get secret detail by name, values are masked, use the reveal api to get the values",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secret"
+                ],
+                "summary": "get secret detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetSecretRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/secrets/{name}/keys/{key}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "add or update a key of secret, the value is base64 encoded when stored, the other keys are kept as they are,\nonly for roles configured in k8s.writeRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secret"
+                ],
+                "summary": "set secret key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "value",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetSecretKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetSecretKeyRespond"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete a key of secret, the other keys are kept as they are, only for roles configured in k8s.writeRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secret"
+                ],
+                "summary": "delete secret key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteSecretKeyRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/secrets/{name}/reveal": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "reveal the decoded values of secret keys, only for roles configured in k8s.secretRevealRoles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secret"
+                ],
+                "summary": "reveal secret values",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "keys to reveal",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RevealSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetSecretRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the revisions of deployment (replicaSets), statefulSet or daemonSet (controllerRevisions), the newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workload"
+                ],
+                "summary": "list of workload revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "deployments, statefulsets or daemonsets",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListWorkloadRevisionsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "compare the pod templates of two revisions, to defaults to the current revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workload"
+                ],
+                "summary": "diff workload revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "deployments, statefulsets or daemonsets",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to compare to, default is the current revision",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DiffWorkloadRevisionsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "roll back the pod template of workload to a revision, revision 0 means the previous revision,\nonly for roles configured in k8s.writeRoles, a paused deployment is not rolled back",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workload"
+                ],
+                "summary": "roll back workload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "deployments, statefulsets or daemonsets",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "revision",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RollbackWorkloadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RollbackWorkloadRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/portforward/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of the running port-forward sessions opened by the caller",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portForward"
+                ],
+                "summary": "list of port-forwards",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListPortForwardsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/portforward/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "close a port-forward session, the open connections through it are closed as well",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portForward"
+                ],
+                "summary": "close port-forward",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ClosePortForwardRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/portforward/sessions/{id}/tunnel": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "upgrade to websocket, binary messages are written to the forwarded port and the bytes read from it are sent back as binary messages, browsers may pass the jwt in the token query parameter",
+                "tags": [
+                    "portForward"
+                ],
+                "summary": "port-forward websocket tunnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "jwt, used if the Authorization header is not set",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/storage/classes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of storageClasses",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "list of storageClasses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListStorageClassesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/storage/pvcs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of persistentVolumeClaims with bound volume, storageClass, capacity, access modes and consuming pods, Pending and Lost claims are flagged,\nonly the claims in the namespaces of the data scope of the user are listed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "list of persistentVolumeClaims",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace, if empty, all namespaces",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListPVCsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/k8s/storage/pvs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of persistentVolumes, Released volumes are flagged as orphaned,\nthe claims in the namespaces out of the data scope of the user are not shown",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "list of persistentVolumes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListPVsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/menu": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "submit information to create a menu under its parent, the parent must be a directory or a menu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "create menu",
+                "parameters": [
+                    {
+                        "description": "menu information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateMenuRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/menu/me/tree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the directories and menus granted to the roles of the user who sent the request, with their parents\nso that the tree stays connected, and the permission keys of the granted menus and buttons.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "get my menu tree",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetMyMenuTreeRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/menu/tree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get all the menus and buttons as a tree, the siblings are sorted by sort and id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "get the menu tree",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetMenuTreeRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/menu/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get menu detail by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "get menu detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetMenuByIDRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the information of a menu, it can be moved under another parent but not under itself or its descendants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "update menu",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "menu information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateMenuByIDRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateMenuByIDRespond"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete a menu without children and its grants to the roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "delete menu",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteMenuByIDRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/portforward/{session}/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "reverse-proxy any http request, including websocket upgrades, to the forwarded port, the Authorization header and the token query parameter are not passed to the pod,\nthe responses are sandboxed by their Content-Security-Policy so that the pages of the pod can not run script on the origin of the admin",
+                "tags": [
+                    "portForward"
+                ],
+                "summary": "port-forward http proxy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "path passed to the pod",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "jwt, used if the Authorization header is not set",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/v1/proxy/api": {
+            "get": {
+                "description": "代理K8s的所有接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "代理K8s的所有接口"
+                ],
+                "summary": "代理K8s的所有接口",
+                "responses": {}
+            }
+        },
+        "/api/v1/role": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "submit information to create role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "create role",
+                "parameters": [
+                    {
+                        "description": "role information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateRoleRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/condition": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get role by condition",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "get role by condition",
+                "parameters": [
+                    {
+                        "description": "query condition",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Conditions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRoleByConditionRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/delete/ids": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete roles by batch id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "delete roles",
+                "parameters": [
+                    {
+                        "description": "id array",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteRolesByIDsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteRolesByIDsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/export": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "export the roles matching the query conditions to a csv, json or yaml file, the file can be imported again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "export roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "query conditions",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ExportRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportRoleRow"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create or update roles from a csv, json or yaml file in one transaction, the roles are matched by role key.\nevery row is validated and reported, nothing is saved if a row is invalid or dryRun is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "import roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate the rows without saving them",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "roles",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportRoleRow"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of roles by last id and limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "list of roles by last id and limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "last id, default is MaxInt32",
+                        "name": "lastID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "size in each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "sort by column name of table, and the ",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListRolesRespond"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of roles by paging and conditions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "list of roles by query parameters",
+                "parameters": [
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListRolesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/list/ids": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of roles by batch id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "list of roles by batch id",
+                "parameters": [
+                    {
+                        "description": "id array",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ListRolesByIDsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListRolesByIDsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/trash/list": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of the roles in the trash by paging and conditions, they can be restored or purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "list of deleted roles",
+                "parameters": [
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListTrashRolesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/trash/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "permanently delete the role in the trash by id, the roles that are not deleted can not be purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "purge role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.PurgeRoleRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/trash/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore the deleted role by id, it fails if another role with the same role key exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "restore role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RestoreRoleRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get role detail by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "get role detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by a previous get, the status is 304 if the role has not been modified",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRoleByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the role, send it in the If-Match header to update the role"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update role information by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "update role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "role information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateRoleByIDRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateRoleByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated role"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete role by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "delete role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteRoleByIDRespond"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update the fields present in the JSON merge patch (RFC 7386) of the role, the absent fields are unchanged and null clears a field,\nthe roleName and the roleKey can not be cleared, the values are validated by the rules of the update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "patch role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the fields to change, the version is required unless the If-Match header is set",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateRoleByIDRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateRoleByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated role"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/{id}/apis": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the ids of the apis granted to the role directly, without the apis of its parents",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "get role apis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRoleApisRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the apis granted to the role, the roles inheriting the role are granted them too",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "set role apis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "api id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleApisRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleApisRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/{id}/departments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the ids of the departments of the custom data scope of the role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "get role departments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetDepartmentsRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the departments of the custom data scope of the role, they are only used when the data scope mode\nof the role is custom, the members see the users and the namespaces of these departments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "set role departments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "department id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetDepartmentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetDepartmentsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/{id}/menus": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the ids of the menus and buttons granted to the role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "get role menus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRoleMenusRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the menus and buttons granted to the role, the members see the granted menus in their menu tree",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "set role menus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "menu id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleMenusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleMenusRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/role/{id}/parents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the ids of the roles the role inherits directly",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "get role parents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRoleParentsRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the roles the role inherits, the role holds the apis and the namespaces of its parents and of their parents, a role can not inherit itself",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "set role parents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "parent role id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleParentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetRoleParentsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/condition": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get user by condition",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get user by condition",
+                "parameters": [
+                    {
+                        "description": "query condition",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Conditions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetUserByConditionRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/delete/ids": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete users by batch id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "delete users",
+                "parameters": [
+                    {
+                        "description": "id array",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteUsersByIDsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteUsersByIDsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/export": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "export the users matching the query conditions to a csv, json or yaml file, the file can be imported again.\nonly the users in the data scope of the roles of the user who sent the request are exported.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "export users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "query conditions",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ExportUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportUserRow"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create or update users from a csv, json or yaml file in one transaction, the users are matched by name, a new user requires a password.\nthe password of an existing user is only changed if resetPasswords is true.\nevery row is validated and reported, nothing is saved if a row is invalid or dryRun is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "import users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format of the file, csv, json or yaml, default is derived from the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate the rows without saving them",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "change the passwords of the existing users to the ones of the rows",
+                        "name": "resetPasswords",
+                        "in": "query"
+                    },
+                    {
+                        "description": "users",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ImportUserRow"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of users by last id and limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list of users by last id and limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "last id, default is MaxInt32",
+                        "name": "lastID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "size in each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "sort by column name of table, and the ",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListUsersRespond"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of users by paging and conditions, only the users in the data scope of the roles of the user\nwho sent the request are listed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list of users by query parameters",
+                "parameters": [
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListUsersRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/list/ids": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of users by batch id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list of users by batch id",
+                "parameters": [
+                    {
+                        "description": "id array",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ListUsersByIDsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListUsersByIDsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/login": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Login information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Login api",
+                "parameters": [
+                    {
+                        "description": "user information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.LoginRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/login/2fa": {
+            "post": {
+                "description": "the second step of a login that responded a challenge, the code is a TOTP code or an unused recovery code.\nif the challenge enrolls a secret, the TOTP code of that secret enables it and the recovery codes are returned once.\na challenge accepts a limited number of wrong codes, the password must then be sent again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "log in with the two-factor code",
+                "parameters": [
+                    {
+                        "description": "challenge and code",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.LoginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.LoginTwoFactorRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/login/2fa/qrcode": {
+            "get": {
+                "description": "get the png image of the QR code of the secret enrolled by the login challenge, it is scanned by the authenticator app.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get the QR code of a login challenge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "challenge returned by the login",
+                        "name": "challenge",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "png image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/login/history": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the login attempts by paging and conditions, e.g. the failed logins of a user or an ip",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list login history",
+                "parameters": [
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListLoginHistoryRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke the session of the jwt of the request, the jwt is rejected afterwards.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "logout",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.LogoutRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the profile of the user who sent the request, the password is not returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get my profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetMeRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the profile"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update the email, phone, age and gender of the user who sent the request, the empty fields are unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "update my profile",
+                "parameters": [
+                    {
+                        "description": "profile information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateMeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateMeRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated profile"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "upload a png, jpeg, gif or webp image of at most 4096x4096 pixels, it is cropped to a square, resized and stored as png,\nthe url of the stored image is saved in the avatar of the user who sent the request",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "upload my avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "avatar image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UploadAvatarRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "change the password of the user who sent the request, the current password must be given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "change my password",
+                "parameters": [
+                    {
+                        "description": "current and new password",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ChangePasswordRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the roles of the user who sent the request with the roles they inherit, and the apis and the kubernetes namespaces they grant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "get my permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetPermissionsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the active login sessions of the user who sent the request with their devices and addresses, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list my sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListSessionsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke a login session of the user who sent the request, e.g. of a lost device, its jwt is rejected at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "revoke my session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevokeSessionRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the api tokens of the user who sent the request, including the revoked and expired ones, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list my api tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListApiTokensRespond"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create a personal api token for the automations, the token is returned once and only its hash is stored.\nit is sent as the header \"Authorization: Bearer \u003ctoken\u003e\" and only calls the apis of apiIds as the user.\nthe requests authenticated by an api token can not create tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "create an api token",
+                "parameters": [
+                    {
+                        "description": "token information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateApiTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateApiTokenRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/tokens/{tokenID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke an api token of the user who sent the request, the requests sent with it are unauthorized at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "revoke my api token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token id",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevokeApiTokenRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/totp": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get whether the TOTP of the user who sent the request is enabled or required, and the number of unused recovery codes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get my two-factor status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetTwoFactorRespond"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create a secret for the authenticator app of the user who sent the request, a secret enrolled before and not enabled is replaced.\nthe secret is enabled by sending a code of the app to /api/v1/user/me/totp/enable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "enroll a TOTP secret",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.EnrollTwoFactorRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/totp/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "disable the TOTP and delete the recovery codes, a TOTP code or a recovery code is required.\nit can not be disabled if a role of the user requires it.\na limited number of codes is checked in the challenge timeout, the same as a login challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "disable my TOTP",
+                "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DisableTwoFactorRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/totp/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "enable the enrolled secret with a code of the authenticator app, the recovery codes are returned once.\nthe next logins require a code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "enable my TOTP",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RecoveryCodesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/totp/qrcode": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the png image of the QR code of the secret enrolled and not enabled yet, it is scanned by the authenticator app.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get the QR code of my TOTP secret",
+                "responses": {
+                    "200": {
+                        "description": "png image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/totp/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the recovery codes, the codes used or not are invalidated and the new codes are returned once.\na limited number of codes is checked in the challenge timeout, the same as a login challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "regenerate my recovery codes",
+                "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RecoveryCodesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/oidc/callback": {
+            "get": {
+                "description": "the provider redirects the browser here after the login, the user is linked or created and its roles are synced with its groups,\nthe token is returned as json, or in the fragment of the redirect to the success url if it is configured,\na user who must send a TOTP code gets the challenge of POST /api/v1/user/login/2fa the same way instead",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "single sign-on callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "state of the login request",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.LoginRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/oidc/login": {
+            "get": {
+                "description": "redirect the browser to the OpenID Connect provider, it redirects back to the callback after the login",
+                "tags": [
+                    "user"
+                ],
+                "summary": "single sign-on",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/user/reg": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "submit information to create user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Register api",
+                "parameters": [
+                    {
+                        "description": "user information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateUserRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/trash/list": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list of the users in the trash by paging and conditions, they can be restored or purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list of deleted users",
+                "parameters": [
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListTrashUsersRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/trash/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "permanently delete the user in the trash by id, the users that are not deleted can not be purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "purge user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.PurgeUserRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/trash/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore the deleted user by id, it fails if another user with the same name exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "restore user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RestoreUserRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get user detail by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get user detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by a previous get, the status is 304 if the user has not been modified",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetUserByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the user, send it in the If-Match header to update the user"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update user information by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "update user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user information",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateUserByIDRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateUserByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated user"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "delete user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteUserByIDRespond"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update the fields present in the JSON merge patch (RFC 7386) of the user, the absent fields are unchanged and null clears a field,\nthe name and the password can not be cleared, the values are validated by the rules of the update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "patch user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the fields to change, the version is required unless the If-Match header is set",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateUserByIDRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag returned by get, it takes precedence over the version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateUserByIDRespond"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the updated user"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/departments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the ids of the departments the user is a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "get user departments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetDepartmentsRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the departments the user is a member of, the members of the roles whose data scope covers\nthe departments see the user in their lists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "set user departments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "department id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetDepartmentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetDepartmentsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/login/history": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the login attempts of a user by paging and conditions, the users can list their own attempts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list login history of user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "query parameters",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListLoginHistoryRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the roles of the user with the roles they inherit, and the apis and the kubernetes namespaces they grant, each with the roles granting it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "get user permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetPermissionsRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/permissions/explain": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the roles granting the api of the method and the path, or the kubernetes namespace, to the user, with the chain of parents each role is held through",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "explain user permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "http method of the api, e.g. GET",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "route or request path of the api, e.g. /api/v1/user/:id or /api/v1/user/12",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "kubernetes namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ExplainPermissionRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the roles bound to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "get user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetUserRolesRespond"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace the roles bound to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "set user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "role id list",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetUserRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetUserRolesRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the active login sessions of a user with their devices and addresses, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list the sessions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListSessionsRespond"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke all the login sessions of a user, the jwt of the user are rejected at once. the api tokens are not revoked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "force the logout of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ForceLogoutRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list the api tokens of a user, including the revoked and expired ones, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "list the api tokens of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ListApiTokensRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/tokens/{tokenID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke an api token of a user, e.g. a leaked one, the requests sent with it are unauthorized at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "revoke an api token of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "token id",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevokeApiTokenRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/totp": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete the TOTP and the recovery codes of a user who lost the authenticator, the user enrolls again at the next login\nif a role requires it. the users resetting their own TOTP send a TOTP code or a recovery code,\na limited number of codes is checked in the challenge timeout, the same as a login challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "reset the TOTP of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "TOTP code or recovery code, required for the own TOTP",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/types.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ResetTwoFactorRespond"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "clear the failed logins and the temporary lock of the user, a blocked user is activated again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "unlock user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.UnlockUserRespond"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "types.APIResource": {
+            "type": "object",
+            "properties": {
+                "customResource": {
+                    "description": "true if the resource is defined by a customResourceDefinition",
+                    "type": "boolean"
+                },
+                "group": {
+                    "description": "api group, core for the core group",
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "namespaced": {
+                    "type": "boolean"
+                },
+                "resource": {
+                    "description": "plural name, e.g. deployments",
+                    "type": "string"
+                },
+                "verbs": {
+                    "description": "e.g. get, list, update",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.ApiObjDetail": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "createBy": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "only set for the records in the trash",
+                    "type": "string"
+                },
+                "deletedBy": {
+                    "description": "id of the user who deleted the record",
+                    "type": "integer"
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "stale": {
+                    "description": "true if no registered route matches the path and action any more",
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updateBy": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "version of the record, send it back to update the record",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ApiSyncChange": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "created, updated, stale or restored",
+                    "type": "string"
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "id of the api row, 0 for the rows to be created in a dry run",
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "oldTitle": {
+                    "description": "title before the update",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.ApiTokenObjDetail": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "neither revoked nor expired",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "unix time, 0 if it never expires",
+                    "type": "integer"
+                },
+                "hint": {
+                    "description": "start of the token, to recognize it",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "description": "unix time, 0 if it was never used",
+                    "type": "integer"
+                },
+                "lastUsedIp": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "description": "unix time, 0 if it is not revoked",
+                    "type": "integer"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ApiTokenScope"
+                    }
+                }
+            }
+        },
+        "types.ApiTokenScope": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "api id, convert to string id",
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.CRDObjDetail": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "e.g. crontabs.stable.example.com",
+                    "type": "string"
+                },
+                "printerColumns": {
+                    "description": "additionalPrinterColumns of the storage version",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PrinterColumn"
+                    }
+                },
+                "resource": {
+                    "description": "plural name",
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "Namespaced or Cluster",
+                    "type": "string"
+                },
+                "storageVersion": {
+                    "type": "string"
+                },
+                "versions": {
+                    "description": "served versions",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "types.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "currentPassword",
+                "newPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "description": "the password in use",
+                    "type": "string"
+                },
+                "newPassword": {
+                    "description": "the new password",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 6
+                }
+            }
+        },
+        "types.ChangePasswordRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ClosePortForwardRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.Column": {
+            "type": "object",
+            "properties": {
+                "exp": {
+                    "description": "expressions, which default to = when the value is null, have =, !=, \u003e, \u003e=, \u003c, \u003c=, like",
+                    "type": "string"
+                },
+                "logic": {
+                    "description": "logical type, defaults to and when value is null, only \u0026(and), ||(or)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "column name",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "column value"
+                }
+            }
+        },
+        "types.Conditions": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "columns info",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Column"
+                    }
+                }
+            }
+        },
+        "types.ConfigMapItem": {
+            "type": "object",
+            "properties": {
+                "base64": {
+                    "description": "true if the key is in binaryData",
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "size of the decoded value in bytes",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "value, base64 encoded if the key is in binaryData",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ConfigMapObjDetail": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ConfigMapItem"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "resourceVersion": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateApiRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "createBy": {
+                    "type": "integer"
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updateBy": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateApiRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "description": "id",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateApiTokenRequest": {
+            "type": "object",
+            "required": [
+                "apiIds",
+                "name"
+            ],
+            "properties": {
+                "apiIds": {
+                    "description": "ids of the apis the token may call",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "expiresIn": {
+                    "description": "lifetime in days, 0 is the default lifetime, -1 never expires if the tokens have no maximum lifetime",
+                    "type": "integer",
+                    "minimum": -1
+                },
+                "name": {
+                    "description": "e.g. the pipeline using the token",
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "types.CreateApiTokenRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apiToken": {
+                            "$ref": "#/definitions/types.ApiTokenObjDetail"
+                        },
+                        "token": {
+                            "description": "set it to the Authorization header as \"Bearer \u003ctoken\u003e\", it is not shown again",
+                            "type": "string"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateDepartmentRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "department name",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "namespaces": {
+                    "description": "kubernetes namespaces of the department, comma separated",
+                    "type": "string"
+                },
+                "parentId": {
+                    "description": "parent department id, 0 for a top level department",
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "sort": {
+                    "description": "the siblings are sorted in ascending order",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateDepartmentRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "description": "id",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateElevationRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "seconds the role is held from the approval",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "justification, e.g. the incident",
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "roleId": {
+                    "description": "id of the requested role",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateElevationRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "elevation": {
+                            "$ref": "#/definitions/types.ElevationObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateMenuRequest": {
+            "type": "object",
+            "required": [
+                "title",
+                "type"
+            ],
+            "properties": {
+                "component": {
+                    "description": "view component of the frontend",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "hidden": {
+                    "description": "routed but not shown in the navigation",
+                    "type": "boolean"
+                },
+                "icon": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "name": {
+                    "description": "route name of the frontend",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "parentId": {
+                    "description": "parent menu id, 0 for a top level menu",
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "route path of the frontend",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "permission": {
+                    "description": "permission key checked by the frontend, e.g. user:create",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "sort": {
+                    "description": "the siblings are sorted in ascending order",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "shown in the navigation",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "type": {
+                    "description": "the buttons only grant a permission key",
+                    "type": "string",
+                    "enum": [
+                        "directory",
+                        "menu",
+                        "button"
+                    ]
+                }
+            }
+        },
+        "types.CreateMenuRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "description": "id",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreatePortForwardRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "port"
+            ],
+            "properties": {
+                "kind": {
+                    "description": "pod or service, a service is forwarded to one of its ready pods",
+                    "type": "string",
+                    "enum": [
+                        "pod",
+                        "service"
+                    ]
+                },
+                "name": {
+                    "description": "name of pod or service",
+                    "type": "string"
+                },
+                "port": {
+                    "description": "port number or name, for a service it is a port of the service",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreatePortForwardRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "session": {
+                            "$ref": "#/definitions/types.PortForwardSession"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "type": "string"
+                },
+                "createBy": {
+                    "type": "integer"
+                },
+                "dataScope": {
+                    "type": "string"
+                },
+                "dataScopeMode": {
+                    "description": "the users visible to the members, all by default",
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "dept",
+                        "dept_and_children",
+                        "self",
+                        "custom"
+                    ]
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "requireTwoFactor": {
+                    "description": "the members must log in with a TOTP code",
+                    "type": "boolean"
+                },
+                "roleId": {
+                    "type": "integer"
+                },
+                "roleKey": {
+                    "type": "string"
+                },
+                "roleName": {
+                    "type": "string"
+                },
+                "roleSort": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updateBy": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateRoleRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "description": "id",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "age",
+                    "type": "integer"
+                },
+                "avatar": {
+                    "description": "avatar",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "email",
+                    "type": "string"
+                },
+                "gender": {
+                    "description": "gender, 1:Male, 2:Female, other values:unknown",
+                    "type": "integer"
+                },
+                "loginAt": {
+                    "description": "login timestamp",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "username",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "password",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "phone number",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "account status, 1:inactive, 2:activated, 3:blocked",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateUserRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "description": "id",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CustomResourceObject": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "types.CustomResourceRow": {
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "description": "values in the order of columns, null if the field is missing",
+                    "type": "array",
+                    "items": {}
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteApiByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteApisByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.DeleteApisByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteConfigMapKeyRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteCustomResourceRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteDepartmentByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteMenuByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteRoleByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteRolesByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.DeleteRolesByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteSecretKeyRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteUserByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DeleteUsersByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.DeleteUsersByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DepartmentObjDetail": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "description": "only set in the tree",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.DepartmentObjDetail"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespaces": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "description": "\"0\" for a top level department",
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "sort": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.DiffWorkloadRevisionsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "changes": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.RevisionChange"
+                            }
+                        },
+                        "from": {
+                            "$ref": "#/definitions/types.WorkloadRevision"
+                        },
+                        "to": {
+                            "$ref": "#/definitions/types.WorkloadRevision"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DisableTwoFactorRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ElevationEventDetail": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "request, approve, reject, cancel, revoke, expire or use",
+                    "type": "string"
+                },
+                "actorId": {
+                    "description": "id of the user who did the step, 0 for the expiry",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "the reason, the comment, or the method, the path and the status of a request",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ElevationObjDetail": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "the role is held now",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "seconds the role is held from the approval",
+                    "type": "integer"
+                },
+                "endedAt": {
+                    "description": "unix time of the revocation or the expiry, 0 otherwise",
+                    "type": "integer"
+                },
+                "endedBy": {
+                    "description": "id of the user who revoked the grant",
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ElevationEventDetail"
+                    }
+                },
+                "expiresAt": {
+                    "description": "unix time, 0 until the approval",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewComment": {
+                    "type": "string"
+                },
+                "reviewedAt": {
+                    "description": "unix time, 0 until the review",
+                    "type": "integer"
+                },
+                "reviewerId": {
+                    "type": "integer"
+                },
+                "roleId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, approved, rejected, cancelled, revoked or expired",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "id of the requester",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.EnrollTwoFactorRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "secret": {
+                            "description": "base32 secret, the manual entry in the authenticator app",
+                            "type": "string"
+                        },
+                        "uri": {
+                            "description": "otpauth:// uri, GET /api/v1/user/me/totp/qrcode returns its QR code",
+                            "type": "string"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ExpandPVCRequest": {
+            "type": "object",
+            "required": [
+                "size"
+            ],
+            "properties": {
+                "size": {
+                    "description": "new size, must be larger than the current request, e.g. 20Gi",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ExpandPVCRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ExplainPermissionRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "granted": {
+                            "type": "boolean"
+                        },
+                        "roles": {
+                            "description": "the roles granting the permission, empty if it is not granted",
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.GrantingRole"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ExportApisRequest": {
+            "type": "object"
+        },
+        "types.ExportRolesRequest": {
+            "type": "object"
+        },
+        "types.ExportUsersRequest": {
+            "type": "object"
+        },
+        "types.ForceLogoutRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetApiByConditionRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "api": {
+                            "$ref": "#/definitions/types.ApiObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetApiByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "api": {
+                            "$ref": "#/definitions/types.ApiObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetConfigMapRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "configMap": {
+                            "$ref": "#/definitions/types.ConfigMapObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetCustomResourceRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "object": {
+                            "$ref": "#/definitions/types.CustomResourceObject"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetDepartmentByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "department": {
+                            "$ref": "#/definitions/types.DepartmentObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetDepartmentTreeRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "departments": {
+                            "description": "the top level departments with their sub departments",
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.DepartmentObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetDepartmentsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "deptIds": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetElevationRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "elevation": {
+                            "description": "with its events",
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.ElevationObjDetail"
+                                }
+                            ]
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetMeRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "user": {
+                            "description": "the password is always empty",
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.UserObjDetail"
+                                }
+                            ]
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetMenuByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "menu": {
+                            "$ref": "#/definitions/types.MenuObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetMenuTreeRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "menus": {
+                            "description": "the top level menus with their children",
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.MenuObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetMyMenuTreeRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "menus": {
+                            "description": "the directories and menus granted to the roles, with their parents",
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.MenuObjDetail"
+                            }
+                        },
+                        "permissions": {
+                            "description": "the permission keys of the menus and buttons granted to the roles",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetPermissionsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "permissions": {
+                            "$ref": "#/definitions/types.PermissionsDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetRoleApisRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apiIds": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetRoleByConditionRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "role": {
+                            "$ref": "#/definitions/types.RoleObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetRoleByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "role": {
+                            "$ref": "#/definitions/types.RoleObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetRoleMenusRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "menuIds": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetRoleParentsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "parentIds": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetSecretRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "secret": {
+                            "$ref": "#/definitions/types.SecretObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetTwoFactorRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "enabled": {
+                            "description": "the login requires a TOTP code",
+                            "type": "boolean"
+                        },
+                        "pending": {
+                            "description": "a secret is enrolled but not enabled yet",
+                            "type": "boolean"
+                        },
+                        "recoveryCodesLeft": {
+                            "description": "number of unused recovery codes",
+                            "type": "integer"
+                        },
+                        "required": {
+                            "description": "a role of the user requires a TOTP code",
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetUserByConditionRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "user": {
+                            "$ref": "#/definitions/types.UserObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetUserByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "user": {
+                            "$ref": "#/definitions/types.UserObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GetUserRolesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "roles": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.RoleObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GrantingRole": {
+            "type": "object",
+            "properties": {
+                "elevated": {
+                    "description": "true if the first role of via is held through a temporary elevation",
+                    "type": "boolean"
+                },
+                "inherited": {
+                    "description": "true if the role is held through the parents of a role bound to the user",
+                    "type": "boolean"
+                },
+                "roleId": {
+                    "type": "string"
+                },
+                "roleKey": {
+                    "type": "string"
+                },
+                "roleName": {
+                    "type": "string"
+                },
+                "via": {
+                    "description": "keys of the roles from the role bound to the user to the role",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "types.ImportApiRow": {
+            "type": "object",
+            "required": [
+                "action",
+                "path"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST",
+                        "PUT",
+                        "PATCH",
+                        "DELETE",
+                        "HEAD",
+                        "OPTIONS",
+                        "ANY"
+                    ]
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.ImportRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ImportResult"
+                        }
+                    ]
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "number of rows created",
+                    "type": "integer"
+                },
+                "imported": {
+                    "description": "false if a row is invalid or it is a dry run, then nothing is saved",
+                    "type": "boolean"
+                },
+                "invalid": {
+                    "description": "number of invalid rows",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "result of every row",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ImportRowResult"
+                    }
+                },
+                "updated": {
+                    "description": "number of rows updated",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ImportRoleRow": {
+            "type": "object",
+            "required": [
+                "roleKey",
+                "roleName"
+            ],
+            "properties": {
+                "admin": {
+                    "type": "string"
+                },
+                "dataScope": {
+                    "type": "string"
+                },
+                "dataScopeMode": {
+                    "description": "the users visible to the members, all by default",
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "dept",
+                        "dept_and_children",
+                        "self",
+                        "custom"
+                    ]
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "requireTwoFactor": {
+                    "type": "boolean"
+                },
+                "roleKey": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "roleName": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "roleSort": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "created, updated or invalid",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "why the row is invalid",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "the unique key the row is matched by",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "row number in the file, starting from 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ImportUserRow": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "age": {
+                    "description": "age",
+                    "type": "integer",
+                    "maximum": 200,
+                    "minimum": 0
+                },
+                "avatar": {
+                    "description": "avatar",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "email": {
+                    "description": "email",
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "gender": {
+                    "description": "gender, 1:Male, 2:Female, other values:unknown",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "description": "username",
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "password": {
+                    "description": "password, only changes the password of an existing user if resetPasswords is set",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "phone": {
+                    "description": "phone number",
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "status": {
+                    "description": "account status, 1:inactive, 2:activated, 3:blocked",
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "types.ListAPIResourcesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "failedGroups": {
+                            "description": "group versions that could not be discovered",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "resources": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.APIResource"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListApiTokensRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apiTokens": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ApiTokenObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListApisByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.ListApisByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apis": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ApiObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListApisRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apis": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ApiObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListCRDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "crds": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.CRDObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListConfigMapsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "configMaps": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ConfigMapObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListCustomResourcesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "columns": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.PrinterColumn"
+                            }
+                        },
+                        "continue": {
+                            "description": "token of the next page, empty if there are no more pages",
+                            "type": "string"
+                        },
+                        "rows": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.CustomResourceRow"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListElevationsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "elevations": {
+                            "description": "the latest first",
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ElevationObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListLoginHistoryRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "loginHistory": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.LoginHistoryObjDetail"
+                            }
+                        },
+                        "total": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListNamespacesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "namespaces": {
+                            "description": "the namespaces of the cluster in the data scope of the user, sorted",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListPVCsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "pvcs": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.PVCObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListPVsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "pvs": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.PVObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListPortForwardsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "sessions": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.PortForwardSession"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListRolesByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.ListRolesByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "roles": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.RoleObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListRolesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "roles": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.RoleObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListSecretsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "secrets": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.SecretObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListSessionsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "sessions": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.SessionObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListStorageClassesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "storageClasses": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.StorageClassObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListTrashApisRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "apis": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ApiObjDetail"
+                            }
+                        },
+                        "total": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListTrashRolesRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "roles": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.RoleObjDetail"
+                            }
+                        },
+                        "total": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListTrashUsersRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "total": {
+                            "type": "integer"
+                        },
+                        "users": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.UserObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListUsersByIDsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "id list",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.ListUsersByIDsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "users": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.UserObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListUsersRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "users": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.UserObjDetail"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ListWorkloadRevisionsRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "revisions": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.WorkloadRevision"
+                            }
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.LoginHistoryObjDetail": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "time of the attempt",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "client ip",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "username of the attempt",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "success, failed, locked, throttled, blocked or challenge",
+                    "type": "string"
+                },
+                "userAgent": {
+                    "description": "user agent of the client",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "user id, 0 if no user has the name",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "username",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "password",
+                    "type": "string"
+                }
+            }
+        },
+        "types.LoginRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "challenge": {
+                            "description": "set instead of the token and the user if a TOTP code is required",
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/types.TwoFactorChallenge"
+                                }
+                            ]
+                        },
+                        "token": {
+                            "description": "jwt token, set it to the Authorization header as \"Bearer \u003ctoken\u003e\"",
+                            "type": "string"
+                        },
+                        "user": {
+                            "$ref": "#/definitions/types.UserObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.LoginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge",
+                "code"
+            ],
+            "properties": {
+                "challenge": {
+                    "description": "challenge returned by the login",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "TOTP code or recovery code",
+                    "type": "string"
+                }
+            }
+        },
+        "types.LoginTwoFactorRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "recoveryCodes": {
+                            "description": "only set if the TOTP was enrolled at the login, they are not shown again",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "token": {
+                            "description": "jwt token, set it to the Authorization header as \"Bearer \u003ctoken\u003e\"",
+                            "type": "string"
+                        },
+                        "user": {
+                            "$ref": "#/definitions/types.UserObjDetail"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.LogoutRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
+        "types.MenuObjDetail": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "description": "only set in the trees",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.MenuObjDetail"
+                    }
+                },
+                "component": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "hidden": {
+                    "type": "boolean"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "convert to string id",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "description": "\"0\" for a top level menu",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "sort": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.PVCObjDetail": {
             "type": "object",
             "properties": {
-                "action": {
-                    "type": "string"
+                "accessModes": {
+                    "description": "e.g. ReadWriteOnce",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "createBy": {
-                    "type": "integer"
+                "capacity": {
+                    "description": "actual size of the bound volume",
+                    "type": "string"
                 },
                 "createdAt": {
                     "type": "string"
                 },
-                "handle": {
+                "name": {
                     "type": "string"
                 },
-                "id": {
-                    "description": "convert to string id",
+                "namespace": {
                     "type": "string"
                 },
-                "path": {
+                "pods": {
+                    "description": "names of the pods that mount the claim",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "problem": {
+                    "description": "Pending or Lost if the claim needs attention, empty if it is healthy",
                     "type": "string"
                 },
-                "title": {
+                "requested": {
+                    "description": "requested size, e.g. 10Gi",
                     "type": "string"
                 },
-                "type": {
+                "status": {
+                    "description": "Pending, Bound or Lost",
                     "type": "string"
                 },
-                "updateBy": {
-                    "type": "integer"
+                "storageClass": {
+                    "description": "storageClass name",
+                    "type": "string"
                 },
-                "updatedAt": {
+                "volumeMode": {
+                    "description": "Filesystem or Block",
+                    "type": "string"
+                },
+                "volumeName": {
+                    "description": "bound persistentVolume",
                     "type": "string"
                 }
             }
         },
-        "types.Column": {
+        "types.PVObjDetail": {
             "type": "object",
             "properties": {
-                "exp": {
-                    "description": "expressions, which default to = when the value is null, have =, !=, \u003e, \u003e=, \u003c, \u003c=, like",
+                "accessModes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "capacity": {
                     "type": "string"
                 },
-                "logic": {
-                    "description": "logical type, defaults to and when value is null, only \u0026(and), ||(or)",
+                "claim": {
+                    "description": "namespace/name of the claim",
+                    "type": "string"
+                },
+                "createdAt": {
                     "type": "string"
                 },
                 "name": {
-                    "description": "column name",
                     "type": "string"
                 },
-                "value": {
-                    "description": "column value"
+                "orphaned": {
+                    "description": "true if the volume is Released, its claim has been deleted",
+                    "type": "boolean"
+                },
+                "reclaimPolicy": {
+                    "description": "Retain, Delete or Recycle",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Available, Bound, Released or Failed",
+                    "type": "string"
+                },
+                "storageClass": {
+                    "type": "string"
                 }
             }
         },
-        "types.Conditions": {
+        "types.Params": {
             "type": "object",
             "properties": {
                 "columns": {
-                    "description": "columns info",
+                    "description": "query conditions",
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/types.Column"
                     }
+                },
+                "page": {
+                    "description": "page number, starting from page 0",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "lines per page",
+                    "type": "integer"
+                },
+                "sort": {
+                    "description": "sorted fields, multi-column sorting separated by commas",
+                    "type": "string"
                 }
             }
         },
-        "types.CreateApiRequest": {
+        "types.PermissionApiDetail": {
             "type": "object",
             "properties": {
                 "action": {
                     "type": "string"
                 },
-                "createBy": {
-                    "type": "integer"
-                },
-                "handle": {
+                "id": {
                     "type": "string"
                 },
                 "path": {
                     "type": "string"
                 },
+                "roles": {
+                    "description": "the roles granting the api",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GrantingRole"
+                    }
+                },
                 "title": {
                     "type": "string"
-                },
-                "type": {
+                }
+            }
+        },
+        "types.PermissionNamespaceDetail": {
+            "type": "object",
+            "properties": {
+                "namespace": {
                     "type": "string"
                 },
-                "updateBy": {
-                    "type": "integer"
+                "roles": {
+                    "description": "the roles permitting the namespace",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GrantingRole"
+                    }
                 }
             }
         },
-        "types.CreateApiRespond": {
+        "types.PermissionsDetail": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "return code",
-                    "type": "integer"
+                "allNamespaces": {
+                    "description": "true if a role permits all the namespaces",
+                    "type": "boolean"
                 },
-                "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "id": {
-                            "description": "id",
-                            "type": "integer"
-                        }
+                "allNamespacesRoles": {
+                    "description": "the roles permitting all the namespaces",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GrantingRole"
                     }
                 },
-                "msg": {
-                    "description": "return information description",
-                    "type": "string"
+                "apis": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PermissionApiDetail"
+                    }
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PermissionNamespaceDetail"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GrantingRole"
+                    }
                 }
             }
         },
-        "types.CreateRoleRequest": {
+        "types.PortForwardSession": {
             "type": "object",
             "properties": {
-                "admin": {
+                "createdAt": {
                     "type": "string"
                 },
-                "createBy": {
-                    "type": "integer"
+                "expiresAt": {
+                    "description": "the session is closed automatically at this time",
+                    "type": "string"
                 },
-                "dataScope": {
+                "id": {
                     "type": "string"
                 },
-                "flag": {
+                "namespace": {
                     "type": "string"
                 },
-                "remark": {
+                "pod": {
                     "type": "string"
                 },
-                "roleId": {
+                "port": {
+                    "description": "port of pod",
                     "type": "integer"
                 },
-                "roleKey": {
+                "proxyPath": {
+                    "description": "http reverse-proxy path, e.g. /api/v1/portforward/{id}/",
                     "type": "string"
                 },
-                "roleName": {
+                "service": {
+                    "description": "empty if the session was opened to a pod",
                     "type": "string"
                 },
-                "roleSort": {
-                    "type": "integer"
+                "tunnelPath": {
+                    "description": "websocket tunnel path, raw tcp bytes are carried in binary messages",
+                    "type": "string"
+                }
+            }
+        },
+        "types.PrinterColumn": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
                 },
-                "status": {
+                "jsonPath": {
+                    "description": "e.g. .spec.replicas",
                     "type": "string"
                 },
-                "updateBy": {
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "0 is shown in the standard view, greater than 0 only in the wide view",
                     "type": "integer"
+                },
+                "type": {
+                    "description": "integer, number, string, boolean or date",
+                    "type": "string"
                 }
             }
         },
-        "types.CreateRoleRespond": {
+        "types.PurgeApiRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1319,14 +8883,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "id": {
-                            "description": "id",
-                            "type": "integer"
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1334,48 +8891,39 @@ const docTemplate = `{
                 }
             }
         },
-        "types.CreateUserRequest": {
+        "types.PurgeRoleRespond": {
             "type": "object",
             "properties": {
-                "age": {
-                    "description": "age",
+                "code": {
+                    "description": "return code",
                     "type": "integer"
                 },
-                "avatar": {
-                    "description": "avatar",
-                    "type": "string"
+                "data": {
+                    "description": "return data"
                 },
-                "email": {
-                    "description": "email",
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
-                },
-                "gender": {
-                    "description": "gender, 1:Male, 2:Female, other values:unknown",
-                    "type": "integer"
-                },
-                "loginAt": {
-                    "description": "login timestamp",
+                }
+            }
+        },
+        "types.PurgeUserRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
                     "type": "integer"
                 },
-                "name": {
-                    "description": "username",
-                    "type": "string"
-                },
-                "password": {
-                    "description": "password",
-                    "type": "string"
+                "data": {
+                    "description": "return data"
                 },
-                "phone": {
-                    "description": "phone number",
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
-                },
-                "status": {
-                    "description": "account status, 1:inactive, 2:activated, 3:blocked",
-                    "type": "integer"
                 }
             }
         },
-        "types.CreateUserRespond": {
+        "types.RecoveryCodesRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1386,9 +8934,12 @@ const docTemplate = `{
                     "description": "return data",
                     "type": "object",
                     "properties": {
-                        "id": {
-                            "description": "id",
-                            "type": "integer"
+                        "recoveryCodes": {
+                            "description": "one-time codes replacing the TOTP code, they are not shown again",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     }
                 },
@@ -1398,7 +8949,7 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteApiByIDRespond": {
+        "types.ResetTwoFactorRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1414,20 +8965,23 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteApisByIDsRequest": {
+        "types.RestoreApiRespond": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "description": "id list",
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "integer"
-                    }
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
                 }
             }
         },
-        "types.DeleteApisByIDsRespond": {
+        "types.RestoreRoleRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1443,7 +8997,7 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteRoleByIDRespond": {
+        "types.RestoreUserRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1459,20 +9013,48 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteRolesByIDsRequest": {
+        "types.RevealSecretRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "description": "id list",
+                "keys": {
+                    "description": "keys to reveal, if empty, reveal all keys",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
+                },
+                "reason": {
+                    "description": "why the values are needed, written to the audit log",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ReviewElevationRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "comment of the approver, recorded with the step",
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "types.RevisionChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "null if the field is added"
+                },
+                "path": {
+                    "description": "json path of field, e.g. spec.containers[0].image",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "null if the field is removed"
                 }
             }
         },
-        "types.DeleteRolesByIDsRespond": {
+        "types.RevokeApiTokenRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1488,7 +9070,7 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteUserByIDRespond": {
+        "types.RevokeKubeconfigRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1504,20 +9086,7 @@ const docTemplate = `{
                 }
             }
         },
-        "types.DeleteUsersByIDsRequest": {
-            "type": "object",
-            "properties": {
-                "ids": {
-                    "description": "id list",
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "integer"
-                    }
-                }
-            }
-        },
-        "types.DeleteUsersByIDsRespond": {
+        "types.RevokeSessionRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1533,29 +9102,79 @@ const docTemplate = `{
                 }
             }
         },
-        "types.GetApiByConditionRespond": {
+        "types.RoleObjDetail": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "return code",
+                "admin": {
+                    "type": "string"
+                },
+                "createBy": {
                     "type": "integer"
                 },
-                "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "api": {
-                            "$ref": "#/definitions/types.ApiObjDetail"
-                        }
-                    }
+                "createdAt": {
+                    "type": "string"
                 },
-                "msg": {
-                    "description": "return information description",
+                "dataScope": {
+                    "type": "string"
+                },
+                "dataScopeMode": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "only set for the records in the trash",
+                    "type": "string"
+                },
+                "deletedBy": {
+                    "description": "id of the user who deleted the record",
+                    "type": "integer"
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "requireTwoFactor": {
+                    "type": "boolean"
+                },
+                "roleId": {
+                    "type": "string"
+                },
+                "roleKey": {
+                    "type": "string"
+                },
+                "roleName": {
+                    "type": "string"
+                },
+                "roleSort": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updateBy": {
+                    "type": "integer"
+                },
+                "updatedAt": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "version of the record, send it back to update the record",
+                    "type": "integer"
                 }
             }
         },
-        "types.GetApiByIDRespond": {
+        "types.RollbackWorkloadRequest": {
+            "type": "object",
+            "properties": {
+                "revision": {
+                    "description": "revision to roll back to, 0 means the previous revision",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "types.RollbackWorkloadRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1566,8 +9185,13 @@ const docTemplate = `{
                     "description": "return data",
                     "type": "object",
                     "properties": {
-                        "api": {
-                            "$ref": "#/definitions/types.ApiObjDetail"
+                        "revision": {
+                            "description": "revision rolled back to",
+                            "type": "integer"
+                        },
+                        "skipped": {
+                            "description": "true if the revision is already in use",
+                            "type": "boolean"
                         }
                     }
                 },
@@ -1577,51 +9201,98 @@ const docTemplate = `{
                 }
             }
         },
-        "types.GetRoleByConditionRespond": {
+        "types.SecretItem": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "return code",
+                "base64": {
+                    "description": "true if the revealed value is base64 encoded because it is not utf-8 text",
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "size of the decoded value in bytes",
                     "type": "integer"
                 },
-                "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "role": {
-                            "$ref": "#/definitions/types.RoleObjDetail"
-                        }
+                "value": {
+                    "description": "masked value, or the decoded value when revealed",
+                    "type": "string"
+                }
+            }
+        },
+        "types.SecretObjDetail": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.SecretItem"
                     }
                 },
-                "msg": {
-                    "description": "return information description",
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "resourceVersion": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "types.GetRoleByIDRespond": {
+        "types.SessionObjDetail": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "return code",
+                "createdAt": {
+                    "description": "unix time of the login",
                     "type": "integer"
                 },
-                "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "role": {
-                            "$ref": "#/definitions/types.RoleObjDetail"
-                        }
-                    }
+                "current": {
+                    "description": "the session of the request",
+                    "type": "boolean"
                 },
-                "msg": {
-                    "description": "return information description",
+                "expiresAt": {
+                    "description": "unix time the jwt expires",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "client ip of the login",
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "description": "unix time, updated at most once a minute",
+                    "type": "integer"
+                },
+                "userAgent": {
+                    "description": "user agent of the login, it tells the device",
                     "type": "string"
                 }
             }
         },
-        "types.GetUserByConditionRespond": {
+        "types.SetConfigMapKeyRequest": {
+            "type": "object",
+            "properties": {
+                "base64": {
+                    "description": "true if value is base64 encoded, the key is stored in binaryData",
+                    "type": "boolean"
+                },
+                "value": {
+                    "description": "plain text value",
+                    "type": "string"
+                }
+            }
+        },
+        "types.SetConfigMapKeyRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1629,13 +9300,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "user": {
-                            "$ref": "#/definitions/types.UserObjDetail"
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1643,7 +9308,19 @@ const docTemplate = `{
                 }
             }
         },
-        "types.GetUserByIDRespond": {
+        "types.SetDepartmentsRequest": {
+            "type": "object",
+            "properties": {
+                "deptIds": {
+                    "description": "department id list, an empty list removes all departments",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.SetDepartmentsRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1651,13 +9328,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "user": {
-                            "$ref": "#/definitions/types.UserObjDetail"
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1665,20 +9336,19 @@ const docTemplate = `{
                 }
             }
         },
-        "types.ListApisByIDsRequest": {
+        "types.SetRoleApisRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "description": "id list",
+                "apiIds": {
+                    "description": "ids of the apis granted to the role, an empty list revokes all apis",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
                         "type": "integer"
                     }
                 }
             }
         },
-        "types.ListApisByIDsRespond": {
+        "types.SetRoleApisRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1686,16 +9356,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "apis": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/types.ApiObjDetail"
-                            }
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1703,7 +9364,19 @@ const docTemplate = `{
                 }
             }
         },
-        "types.ListApisRespond": {
+        "types.SetRoleMenusRequest": {
+            "type": "object",
+            "properties": {
+                "menuIds": {
+                    "description": "menu id list, an empty list removes all menus",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "types.SetRoleMenusRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1711,16 +9384,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "apis": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/types.ApiObjDetail"
-                            }
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1728,20 +9392,19 @@ const docTemplate = `{
                 }
             }
         },
-        "types.ListRolesByIDsRequest": {
+        "types.SetRoleParentsRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "description": "id list",
+                "parentIds": {
+                    "description": "ids of the roles the role inherits, an empty list removes all parents",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
                         "type": "integer"
                     }
                 }
             }
         },
-        "types.ListRolesByIDsRespond": {
+        "types.SetRoleParentsRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1749,16 +9412,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "roles": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/types.RoleObjDetail"
-                            }
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1766,45 +9420,48 @@ const docTemplate = `{
                 }
             }
         },
-        "types.ListRolesRespond": {
+        "types.SetSecretKeyRequest": {
+            "type": "object",
+            "properties": {
+                "base64": {
+                    "description": "true if value is already base64 encoded, use it for binary values",
+                    "type": "boolean"
+                },
+                "value": {
+                    "description": "plain text value, it is base64 encoded when stored",
+                    "type": "string"
+                }
+            }
+        },
+        "types.SetSecretKeyRespond": {
             "type": "object",
             "properties": {
                 "code": {
                     "description": "return code",
                     "type": "integer"
                 },
-                "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "roles": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/types.RoleObjDetail"
-                            }
-                        }
-                    }
-                },
+                "data": {
+                    "description": "return data"
+                },
                 "msg": {
                     "description": "return information description",
                     "type": "string"
                 }
             }
         },
-        "types.ListUsersByIDsRequest": {
+        "types.SetUserRolesRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "description": "id list",
+                "roleIds": {
+                    "description": "role id list, an empty list removes all roles",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
                         "type": "integer"
                     }
                 }
             }
         },
-        "types.ListUsersByIDsRespond": {
+        "types.SetUserRolesRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1812,16 +9469,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "description": "return data",
-                    "type": "object",
-                    "properties": {
-                        "users": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/types.UserObjDetail"
-                            }
-                        }
-                    }
+                    "description": "return data"
                 },
                 "msg": {
                     "description": "return information description",
@@ -1829,7 +9477,33 @@ const docTemplate = `{
                 }
             }
         },
-        "types.ListUsersRespond": {
+        "types.StorageClassObjDetail": {
+            "type": "object",
+            "properties": {
+                "allowVolumeExpansion": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "isDefault": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provisioner": {
+                    "type": "string"
+                },
+                "reclaimPolicy": {
+                    "type": "string"
+                },
+                "volumeBindingMode": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.SyncApisRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1840,11 +9514,14 @@ const docTemplate = `{
                     "description": "return data",
                     "type": "object",
                     "properties": {
-                        "users": {
+                        "changes": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/types.UserObjDetail"
+                                "$ref": "#/definitions/types.ApiSyncChange"
                             }
+                        },
+                        "dryRun": {
+                            "type": "boolean"
                         }
                     }
                 },
@@ -1854,118 +9531,263 @@ const docTemplate = `{
                 }
             }
         },
-        "types.LoginRequest": {
+        "types.TwoFactorChallenge": {
             "type": "object",
             "properties": {
-                "name": {
-                    "description": "username",
+                "challenge": {
+                    "description": "token of the login, valid for expiresIn seconds",
                     "type": "string"
                 },
-                "password": {
-                    "description": "password",
+                "enroll": {
+                    "description": "the roles of the user require a TOTP code but none is enrolled, the code of the new secret enables it",
+                    "type": "boolean"
+                },
+                "expiresIn": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "base32 secret to enroll, the manual entry in the authenticator app",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "otpauth:// uri of the secret to enroll, GET /api/v1/user/login/2fa/qrcode?challenge= returns its QR code",
                     "type": "string"
                 }
             }
         },
-        "types.Params": {
+        "types.TwoFactorCodeRequest": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "columns": {
-                    "description": "query conditions",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/types.Column"
-                    }
-                },
-                "page": {
-                    "description": "page number, starting from page 0",
+                "code": {
+                    "description": "TOTP code, or a recovery code if the TOTP is enabled",
+                    "type": "string"
+                }
+            }
+        },
+        "types.UnlockUserRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
                     "type": "integer"
                 },
-                "size": {
-                    "description": "lines per page",
-                    "type": "integer"
+                "data": {
+                    "description": "return data"
                 },
-                "sort": {
-                    "description": "sorted fields, multi-column sorting separated by commas",
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
                 }
             }
         },
-        "types.RoleObjDetail": {
+        "types.UpdateApiByIDRequest": {
             "type": "object",
             "properties": {
-                "admin": {
-                    "type": "string"
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST",
+                        "PUT",
+                        "PATCH",
+                        "DELETE",
+                        "HEAD",
+                        "OPTIONS",
+                        "ANY"
+                    ]
                 },
                 "createBy": {
                     "type": "integer"
                 },
-                "createdAt": {
+                "handle": {
                     "type": "string"
                 },
-                "dataScope": {
-                    "type": "string"
+                "id": {
+                    "description": "uint64 id",
+                    "type": "integer"
                 },
-                "flag": {
+                "path": {
                     "type": "string"
                 },
-                "remark": {
+                "title": {
                     "type": "string"
                 },
-                "roleId": {
+                "type": {
                     "type": "string"
                 },
-                "roleKey": {
+                "updateBy": {
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "version of the record read by get, required unless the If-Match header is set",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.UpdateApiByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
+                }
+            }
+        },
+        "types.UpdateDepartmentByIDRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
                 },
-                "roleName": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "namespaces": {
                     "type": "string"
                 },
-                "roleSort": {
+                "parentId": {
                     "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "remark": {
+                    "type": "string",
+                    "maxLength": 255
                 },
-                "updateBy": {
+                "sort": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.UpdateDepartmentByIDRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
                     "type": "integer"
                 },
-                "updatedAt": {
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
                 }
             }
         },
-        "types.UpdateApiByIDRequest": {
+        "types.UpdateMeRequest": {
             "type": "object",
             "properties": {
-                "action": {
-                    "type": "string"
+                "age": {
+                    "description": "age",
+                    "type": "integer",
+                    "maximum": 200,
+                    "minimum": 0
                 },
-                "createBy": {
+                "email": {
+                    "description": "email",
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "gender": {
+                    "description": "gender, 1:Male, 2:Female, other values:unknown",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "phone": {
+                    "description": "phone number",
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "version": {
+                    "description": "version of the profile read by get, not checked if it and the If-Match header are not set",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.UpdateMeRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
                     "type": "integer"
                 },
-                "handle": {
+                "data": {
+                    "description": "return data"
+                },
+                "msg": {
+                    "description": "return information description",
                     "type": "string"
+                }
+            }
+        },
+        "types.UpdateMenuByIDRequest": {
+            "type": "object",
+            "required": [
+                "title",
+                "type"
+            ],
+            "properties": {
+                "component": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "hidden": {
+                    "type": "boolean"
+                },
+                "icon": {
+                    "type": "string",
+                    "maxLength": 100
                 },
                 "id": {
-                    "description": "uint64 id",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "parentId": {
                     "type": "integer"
                 },
                 "path": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "permission": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "sort": {
+                    "type": "integer"
                 },
                 "title": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100
                 },
                 "type": {
-                    "type": "string"
-                },
-                "updateBy": {
-                    "type": "integer"
+                    "type": "string",
+                    "enum": [
+                        "directory",
+                        "menu",
+                        "button"
+                    ]
                 }
             }
         },
-        "types.UpdateApiByIDRespond": {
+        "types.UpdateMenuByIDRespond": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1993,29 +9815,51 @@ const docTemplate = `{
                 "dataScope": {
                     "type": "string"
                 },
+                "dataScopeMode": {
+                    "description": "the users visible to the members, all by default",
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "dept",
+                        "dept_and_children",
+                        "self",
+                        "custom"
+                    ]
+                },
                 "flag": {
                     "type": "string"
                 },
                 "remark": {
                     "type": "string"
                 },
+                "requireTwoFactor": {
+                    "description": "the members must log in with a TOTP code",
+                    "type": "boolean"
+                },
                 "roleId": {
                     "type": "integer"
                 },
                 "roleKey": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 128
                 },
                 "roleName": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 128
                 },
                 "roleSort": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "status": {
                     "type": "string"
                 },
                 "updateBy": {
                     "type": "integer"
+                },
+                "version": {
+                    "description": "version of the record read by get, required unless the If-Match header is set",
+                    "type": "integer"
                 }
             }
         },
@@ -2040,19 +9884,24 @@ const docTemplate = `{
             "properties": {
                 "age": {
                     "description": "age",
-                    "type": "integer"
+                    "type": "integer",
+                    "maximum": 200,
+                    "minimum": 0
                 },
                 "avatar": {
                     "description": "avatar",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 200
                 },
                 "email": {
                     "description": "email",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50
                 },
                 "gender": {
                     "description": "gender, 1:Male, 2:Female, other values:unknown",
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "id": {
                     "description": "uint64 id",
@@ -2064,18 +9913,30 @@ const docTemplate = `{
                 },
                 "name": {
                     "description": "username",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50
                 },
                 "password": {
                     "description": "password",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100
                 },
                 "phone": {
                     "description": "phone number",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 30
                 },
                 "status": {
                     "description": "account status, 1:inactive, 2:activated, 3:blocked",
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "version": {
+                    "description": "version of the record read by get, required unless the If-Match header is set",
                     "type": "integer"
                 }
             }
@@ -2096,6 +9957,29 @@ const docTemplate = `{
                 }
             }
         },
+        "types.UploadAvatarRespond": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "return code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "return data",
+                    "type": "object",
+                    "properties": {
+                        "avatar": {
+                            "description": "url of the uploaded avatar",
+                            "type": "string"
+                        }
+                    }
+                },
+                "msg": {
+                    "description": "return information description",
+                    "type": "string"
+                }
+            }
+        },
         "types.UserObjDetail": {
             "type": "object",
             "properties": {
@@ -2110,6 +9994,14 @@ const docTemplate = `{
                 "createdAt": {
                     "type": "string"
                 },
+                "deletedAt": {
+                    "description": "only set for the records in the trash",
+                    "type": "string"
+                },
+                "deletedBy": {
+                    "description": "id of the user who deleted the record",
+                    "type": "integer"
+                },
                 "email": {
                     "description": "email",
                     "type": "string"
@@ -2144,13 +10036,47 @@ const docTemplate = `{
                 },
                 "updatedAt": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "version of the record, send it back to update the record",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.WorkloadRevision": {
+            "type": "object",
+            "properties": {
+                "changeCause": {
+                    "description": "value of the kubernetes.io/change-cause annotation",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "true if it is the revision in use",
+                    "type": "boolean"
+                },
+                "images": {
+                    "description": "images of init containers and containers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "name of replicaSet or controllerRevision",
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
                 }
             }
         }
     },
     "securityDefinitions": {
         "BearerAuth": {
-            "description": "Type Bearer your-jwt-token to Value",
+            "description": "Type Bearer your-jwt-token or Bearer your-api-token (gat_...) to Value",
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
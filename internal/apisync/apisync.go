@@ -0,0 +1,184 @@
+// Package apisync keeps the api rows in line with the registered gin routes, rows are created for new routes,
+// their handle and title are updated from the handler names and the swagger summaries, and the rows
+// whose routes are gone are marked stale instead of being deleted, because roles may still refer to them.
+package apisync
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"go-admin/docs"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// Prefix only the routes under the prefix are synced
+const Prefix = "/api/v1/"
+
+// kinds of change
+const (
+	ChangeCreated  = "created"
+	ChangeUpdated  = "updated"
+	ChangeStale    = "stale"
+	ChangeRestored = "restored"
+)
+
+// Sync upsert an api row for every route under Prefix and mark the rows without route stale,
+// if dryRun is true the changes are computed but not saved.
+func Sync(ctx context.Context, db *gorm.DB, routes gin.RoutesInfo, dryRun bool) ([]types.ApiSyncChange, error) {
+	summaries := Summaries()
+	changes := []types.ApiSyncChange{}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var records []*model.Api
+		if err := tx.Find(&records).Error; err != nil {
+			return err
+		}
+		existing := map[string][]*model.Api{}
+		for _, record := range records {
+			key := routeKey(record.Action, record.Path)
+			existing[key] = append(existing[key], record)
+		}
+
+		registered := map[string]bool{}
+		for _, route := range routes {
+			if !strings.HasPrefix(route.Path, Prefix) {
+				continue
+			}
+			key := routeKey(route.Method, route.Path)
+			if registered[key] {
+				continue
+			}
+			registered[key] = true
+
+			title := summaries[routeKey(route.Method, swaggerPath(route.Path))]
+			if title == "" {
+				title = HandlerTitle(route.Handler)
+			}
+			change := types.ApiSyncChange{Method: route.Method, Path: route.Path, Handle: route.Handler, Title: title}
+
+			rows := existing[key]
+			if len(rows) == 0 {
+				change.Change = ChangeCreated
+				if !dryRun {
+					record := &model.Api{Handle: route.Handler, Title: title, Path: route.Path, Action: route.Method, Type: "BUS"}
+					if err := tx.Create(record).Error; err != nil {
+						return err
+					}
+					change.ID = record.ID
+				}
+				changes = append(changes, change)
+				continue
+			}
+
+			for _, row := range rows {
+				if row.Handle == route.Handler && row.Title == title && !row.Stale {
+					continue
+				}
+				change.ID = row.ID
+				change.Change = ChangeUpdated
+				if row.Stale {
+					change.Change = ChangeRestored
+				}
+				if row.Title != title {
+					change.OldTitle = row.Title
+				}
+				changes = append(changes, change)
+				if !dryRun {
+					update := map[string]interface{}{"handle": route.Handler, "title": title, "stale": false, "version": gorm.Expr("version + 1")}
+					err := tx.Model(row).Updates(update).Error
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for _, record := range records {
+			if record.Stale || registered[routeKey(record.Action, record.Path)] {
+				continue
+			}
+			changes = append(changes, types.ApiSyncChange{
+				ID:     record.ID,
+				Change: ChangeStale,
+				Method: record.Action,
+				Path:   record.Path,
+				Handle: record.Handle,
+				Title:  record.Title,
+			})
+			if !dryRun {
+				update := map[string]interface{}{"stale": true, "version": gorm.Expr("version + 1")}
+				if err := tx.Model(record).Updates(update).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Method < changes[j].Method
+	})
+	return changes, nil
+}
+
+// Summaries the swagger summaries of the operations, the key is the upper case method and the swagger path,
+// e.g. GET /api/v1/user/{id}
+func Summaries() map[string]string {
+	doc := struct {
+		Paths map[string]map[string]struct {
+			Summary string `json:"summary"`
+		} `json:"paths"`
+	}{}
+	summaries := map[string]string{}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc); err != nil {
+		return summaries
+	}
+
+	for p, operations := range doc.Paths {
+		for method, operation := range operations {
+			if operation.Summary != "" {
+				summaries[routeKey(method, p)] = operation.Summary
+			}
+		}
+	}
+	return summaries
+}
+
+// HandlerTitle short name of a gin handler, e.g. go-admin/internal/handler.(*userHandler).Login-fm is userHandler.Login
+func HandlerTitle(handler string) string {
+	name := strings.TrimSuffix(path.Base(handler), "-fm")
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.NewReplacer("(", "", ")", "", "*", "").Replace(name)
+	return name
+}
+
+func routeKey(method string, p string) string {
+	return strings.ToUpper(method) + " " + p
+}
+
+// swaggerPath convert the gin parameters of path to swagger parameters, e.g. /user/:id is /user/{id}
+func swaggerPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
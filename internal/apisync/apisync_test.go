@@ -0,0 +1,96 @@
+package apisync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	if _, err = migration.Up(db, ggorm.DBDriverSqlite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSync(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	routes := gin.RoutesInfo{
+		{Method: "POST", Path: "/api/v1/user/login", Handler: "go-admin/internal/handler.(*userHandler).Login-fm"},
+		{Method: "GET", Path: "/api/v1/user/:id", Handler: "go-admin/internal/handler.(*userHandler).GetByID-fm"},
+		{Method: "GET", Path: "/health", Handler: "github.com/zhufuyi/sponge/pkg/gin/handlerfunc.CheckHealth"},
+	}
+
+	// dry run does not save anything
+	changes, err := Sync(ctx, db, routes, true)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	var count int64
+	db.Model(&model.Api{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	changes, err = Sync(ctx, db, routes, false)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	for _, change := range changes {
+		assert.Equal(t, ChangeCreated, change.Change)
+		assert.NotZero(t, change.ID)
+	}
+	api := &model.Api{}
+	assert.NoError(t, db.Where("path = ?", "/api/v1/user/:id").First(api).Error)
+	assert.Equal(t, Summaries()["GET /api/v1/user/{id}"], api.Title)
+
+	// nothing changed
+	changes, err = Sync(ctx, db, routes, false)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// the title edited by hand is restored, the removed route is marked stale
+	assert.NoError(t, db.Model(&model.Api{}).Where("path = ?", "/api/v1/user/login").Update("title", "foo").Error)
+	changes, err = Sync(ctx, db, routes[:1], false)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, ChangeStale, changes[0].Change)
+		assert.Equal(t, "/api/v1/user/:id", changes[0].Path)
+		assert.Equal(t, ChangeUpdated, changes[1].Change)
+		assert.Equal(t, "foo", changes[1].OldTitle)
+	}
+	api = &model.Api{}
+	assert.NoError(t, db.Where("path = ?", "/api/v1/user/:id").First(api).Error)
+	assert.True(t, api.Stale)
+
+	// the route is back
+	changes, err = Sync(ctx, db, routes, false)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, ChangeRestored, changes[0].Change)
+	}
+	api = &model.Api{}
+	assert.NoError(t, db.Where("path = ?", "/api/v1/user/:id").First(api).Error)
+	assert.False(t, api.Stale)
+}
+
+func TestHandlerTitle(t *testing.T) {
+	assert.Equal(t, "userHandler.Login", HandlerTitle("go-admin/internal/handler.(*userHandler).Login-fm"))
+	assert.Equal(t, "CheckHealth", HandlerTitle("github.com/zhufuyi/sponge/pkg/gin/handlerfunc.CheckHealth"))
+}
+
+func Test_swaggerPath(t *testing.T) {
+	assert.Equal(t, "/api/v1/user/{id}", swaggerPath("/api/v1/user/:id"))
+	assert.Equal(t, "/portforward/{session}/{path}", swaggerPath("/portforward/:session/*path"))
+}
@@ -0,0 +1,49 @@
+// Package apitoken is the personal api tokens the users create for the automations, e.g. the CI pipelines.
+// A token is sent like a jwt in the header "Authorization: Bearer gat_...", it authenticates as its user
+// and only calls the apis of its scopes. Only the sha256 of a token is stored, the token is shown once.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix the start of the tokens, it tells them from the jwt and lets the secret scanners find them
+const Prefix = "gat_"
+
+// ContextKey key of the id of the token in the gin context of the requests authenticated by a token
+const ContextKey = "apiTokenID"
+
+// length of the hex random part of a token
+const randomLength = 40
+
+// Generate create a random token, it returns the token and the hash to store
+func Generate() (string, string, error) {
+	b := make([]byte, randomLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := Prefix + hex.EncodeToString(b)
+	return token, Hash(token), nil
+}
+
+// Hash the sha256 of a token, a token has enough entropy to need no salt
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Is whether the credential looks like a token, otherwise it is a jwt
+func Is(credential string) bool {
+	return strings.HasPrefix(credential, Prefix) && len(credential) == len(Prefix)+randomLength
+}
+
+// Hint the start of the token shown in the lists, it is enough to recognize the token
+func Hint(token string) string {
+	if len(token) < len(Prefix)+8 {
+		return token
+	}
+	return token[:len(Prefix)+8]
+}
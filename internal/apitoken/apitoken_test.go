@@ -0,0 +1,129 @@
+package apitoken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+func TestGenerate(t *testing.T) {
+	token, hash, err := Generate()
+	require.NoError(t, err)
+	assert.True(t, Is(token))
+	assert.Equal(t, Hash(token), hash)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, token[:12], Hint(token))
+
+	other, _, err := Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	assert.False(t, Is("gat_short"))
+	assert.False(t, Is("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJ1aWQiOiIxIn0.signature"))
+	assert.Equal(t, "gat_", Hint("gat_"))
+}
+
+func TestInScopes(t *testing.T) {
+	scopes := []*model.Api{
+		{Path: "/api/v1/user/:id", Action: "GET"},
+		{Path: "/api/v1/user/list", Action: "post"},
+	}
+	assert.True(t, InScopes(scopes, http.MethodGet, "/api/v1/user/:id"))
+	assert.True(t, InScopes(scopes, http.MethodPost, "/api/v1/user/list"))
+	assert.False(t, InScopes(scopes, http.MethodDelete, "/api/v1/user/:id"))
+	assert.False(t, InScopes(scopes, http.MethodGet, "/api/v1/role/:id"))
+	assert.False(t, InScopes(nil, http.MethodGet, "/api/v1/user/:id"))
+}
+
+func TestAuth(t *testing.T) {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	_, err = migration.Up(db, ggorm.DBDriverSqlite)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	userDao := dao.NewUserDao(db, nil)
+	require.NoError(t, userDao.Create(ctx, &model.User{Name: "foo", Status: model.UserStatusActivated}))
+	require.NoError(t, userDao.Create(ctx, &model.User{Name: "bar", Status: model.UserStatusBlocked}))
+	getUser := &model.Api{Path: "/api/v1/user/:id", Action: http.MethodGet}
+	require.NoError(t, dao.NewApiDao(db, nil).Create(ctx, getUser))
+
+	tokenDao := dao.NewApiTokenDao(db)
+	now := time.Now().Unix()
+	newToken := func(userID uint64, expiresAt int64) (string, uint64) {
+		token, hash, err := Generate()
+		require.NoError(t, err)
+		record := &model.ApiToken{UserID: userID, Name: "ci", Hint: Hint(token), TokenHash: hash, ExpiresAt: expiresAt}
+		require.NoError(t, tokenDao.Create(ctx, record, []uint64{getUser.ID}))
+		return token, record.ID
+	}
+	valid, validID := newToken(1, now+3600)
+	expired, _ := newToken(1, now-1)
+	revoked, revokedID := newToken(1, 0)
+	_, err = tokenDao.Revoke(ctx, revokedID, 1, now)
+	require.NoError(t, err)
+	blocked, _ := newToken(2, 0)
+	unknown, _, _ := Generate()
+
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	onAuth := func(c *gin.Context) { c.Set("authenticated", c.GetString("uid")) }
+	group := r.Group("/api/v1", Auth(tokenDao, userDao, middleware.Auth(), onAuth))
+	reply := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"uid": c.GetString("uid"), "name": c.GetString("name"), "tokenID": c.GetUint64(ContextKey),
+			"authenticated": c.GetString("authenticated")})
+	}
+	group.GET("/user/:id", reply)
+	group.POST("/user/list", reply)
+	do := func(method string, path string, credential string) string {
+		req := httptest.NewRequest(method, path, nil)
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := do(http.MethodGet, "/api/v1/user/1", valid)
+	assert.Contains(t, body, `"uid":"1"`)
+	assert.Contains(t, body, `"name":"foo"`)
+	assert.Contains(t, body, `"tokenID":`)
+	assert.Contains(t, body, `"authenticated":"1"`) // onAuth is called before the handler
+	record, err := tokenDao.GetByID(ctx, validID)
+	require.NoError(t, err)
+	assert.NotZero(t, record.LastUsedAt)
+	assert.NotEmpty(t, record.LastUsedIP)
+
+	// the routes that are not in the scopes are forbidden
+	assert.Contains(t, do(http.MethodPost, "/api/v1/user/list", valid), ecode.Forbidden.Msg())
+
+	for _, token := range []string{expired, revoked, blocked, unknown, ""} {
+		assert.Contains(t, do(http.MethodGet, "/api/v1/user/1", token), ecode.Unauthorized.Msg())
+	}
+
+	// the jwt are still accepted for all the routes
+	token, err := jwt.GenerateToken("1", "foo")
+	require.NoError(t, err)
+	body = do(http.MethodPost, "/api/v1/user/list", token)
+	assert.Contains(t, body, `"uid":"1"`)
+	assert.Contains(t, body, `"tokenID":0`)
+}
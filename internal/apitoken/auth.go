@@ -0,0 +1,108 @@
+package apitoken
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+)
+
+// Auth authenticate the requests with a personal api token, the other credentials are passed to jwtAuth.
+// A token sets the uid and the name of its user, and is forbidden to call the routes that are not in its scopes.
+// The revoked and expired tokens and the tokens of the blocked or deleted users are unauthorized.
+// onAuth, if not nil, is called after the user of a token is set and before the handler.
+func Auth(tokenDao dao.ApiTokenDao, userDao dao.UserDao, jwtAuth gin.HandlerFunc, onAuth func(c *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader(middleware.HeaderAuthorizationKey), "Bearer ")
+		if !Is(token) {
+			jwtAuth(c)
+			return
+		}
+
+		ctx := middleware.WrapCtx(c)
+		record, err := tokenDao.GetByHash(ctx, Hash(token))
+		if err != nil {
+			abortLookup(c, "GetByHash error", err)
+			return
+		}
+		now := time.Now().Unix()
+		if !record.Active(now) {
+			logger.Warn("api token is revoked or expired", logger.Any("tokenID", record.ID), middleware.GCtxRequestIDField(c))
+			abortUnauthorized(c)
+			return
+		}
+		user, err := userDao.GetByID(ctx, record.UserID)
+		if err != nil {
+			abortLookup(c, "GetByID error", err)
+			return
+		}
+		if user.Status == model.UserStatusBlocked {
+			logger.Warn("api token of a blocked user", logger.Any("tokenID", record.ID), middleware.GCtxRequestIDField(c))
+			abortUnauthorized(c)
+			return
+		}
+
+		scopes, err := tokenDao.GetScopes(ctx, []uint64{record.ID})
+		if err != nil {
+			logger.Error("GetScopes error", logger.Err(err), logger.Any("tokenID", record.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			c.Abort()
+			return
+		}
+		if !InScopes(scopes[record.ID], c.Request.Method, c.FullPath()) {
+			logger.Warn("api token is not allowed to call the route", logger.Any("tokenID", record.ID),
+				logger.String("method", c.Request.Method), logger.String("path", c.FullPath()), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Forbidden)
+			c.Abort()
+			return
+		}
+
+		if err = tokenDao.Touch(ctx, record.ID, c.ClientIP(), now); err != nil {
+			logger.Warn("Touch error", logger.Err(err), logger.Any("tokenID", record.ID), middleware.GCtxRequestIDField(c))
+		}
+
+		c.Set("uid", utils.Uint64ToStr(user.ID))
+		c.Set("name", user.Name)
+		c.Set(ContextKey, record.ID)
+		if onAuth != nil {
+			onAuth(c)
+		}
+		c.Next()
+	}
+}
+
+// InScopes whether an api of the scopes is the route, the path is the route pattern e.g. /api/v1/user/:id
+func InScopes(scopes []*model.Api, method string, path string) bool {
+	for _, api := range scopes {
+		if api.Path == path && strings.EqualFold(api.Action, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func abortLookup(c *gin.Context, msg string, err error) {
+	if errors.Is(err, model.ErrRecordNotFound) {
+		logger.Warn("unknown api token", middleware.GCtxRequestIDField(c))
+		abortUnauthorized(c)
+		return
+	}
+	logger.Error(msg, logger.Err(err), middleware.GCtxRequestIDField(c))
+	response.Output(c, ecode.InternalServerError.ToHTTPCode())
+	c.Abort()
+}
+
+func abortUnauthorized(c *gin.Context) {
+	response.Error(c, ecode.Unauthorized)
+	c.Abort()
+}
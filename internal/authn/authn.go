@@ -0,0 +1,73 @@
+// Package authn authenticates the users by their name and password with a chain of providers,
+// the local users of the database and the directories like LDAP or Active Directory.
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-admin/internal/model"
+)
+
+// the types of the providers
+const (
+	TypeLocal = "local"
+	TypeLDAP  = "ldap"
+)
+
+// ErrInvalidCredentials the provider does not know the user or the password is wrong
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Account the account authenticated by a provider
+type Account struct {
+	Provider string      // name of the provider
+	Subject  string      // id of the account at the provider, e.g. the dn of an ldap entry
+	Username string      // name of the account at the provider
+	Email    string      // email of the account at the provider
+	User     *model.User // the local user, only set by the local provider
+
+	ManagedRoleKeys []string // the role keys mapped from the groups of the provider
+	RoleKeys        []string // the role keys mapped from the groups of the account
+}
+
+// Authenticator checks the name and password of a user
+type Authenticator interface {
+	// Name of the provider
+	Name() string
+	// Authenticate return ErrInvalidCredentials if the user is unknown or the password is wrong
+	Authenticate(ctx context.Context, username string, password string) (*Account, error)
+}
+
+// Chain tries the authenticators in order, the first one accepting the credentials authenticates the user
+type Chain []Authenticator
+
+// Authenticate the user with the first authenticator accepting the credentials. If none accepts them the error
+// is ErrInvalidCredentials, joined with the errors of the authenticators that failed, e.g. an unreachable server;
+// it is not ErrInvalidCredentials if all of them failed.
+func (c Chain) Authenticate(ctx context.Context, username string, password string) (*Account, error) {
+	var errs []error
+	rejected := false
+	for _, a := range c {
+		acc, err := a.Authenticate(ctx, username, password)
+		if err == nil {
+			return acc, nil
+		}
+		if errors.Is(err, ErrInvalidCredentials) {
+			rejected = true
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", a.Name(), err))
+	}
+
+	if rejected {
+		errs = append([]error{ErrInvalidCredentials}, errs...)
+	}
+	if len(errs) == 0 { // no authenticator
+		return nil, ErrInvalidCredentials
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, errors.Join(errs...)
+}
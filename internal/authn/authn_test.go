@@ -0,0 +1,84 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+type fakeAuthenticator struct {
+	name string
+	acc  *Account
+	err  error
+}
+
+func (f *fakeAuthenticator) Name() string {
+	return f.name
+}
+
+func (f *fakeAuthenticator) Authenticate(_ context.Context, _ string, _ string) (*Account, error) {
+	return f.acc, f.err
+}
+
+func TestChain_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("connection refused")
+	rejecting := &fakeAuthenticator{name: "a", err: ErrInvalidCredentials}
+	failing := &fakeAuthenticator{name: "b", err: down}
+	accepting := &fakeAuthenticator{name: "c", acc: &Account{Provider: "c"}}
+
+	acc, err := Chain{rejecting, failing, accepting}.Authenticate(ctx, "foo", "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "c", acc.Provider)
+
+	_, err = Chain{rejecting}.Authenticate(ctx, "foo", "bar")
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	_, err = Chain{}.Authenticate(ctx, "foo", "bar")
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	// a rejection with an unreachable provider is still a rejection
+	_, err = Chain{failing, rejecting}.Authenticate(ctx, "foo", "bar")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.ErrorIs(t, err, down)
+
+	_, err = Chain{failing}.Authenticate(ctx, "foo", "bar")
+	assert.ErrorIs(t, err, down)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestLocal_Authenticate(t *testing.T) {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	_, err = migration.Up(db, ggorm.DBDriverSqlite)
+	require.NoError(t, err)
+
+	userDao := dao.NewUserDao(db, nil)
+	ctx := context.Background()
+	require.NoError(t, userDao.Create(ctx, &model.User{Name: "foo", Password: "123456", Email: "foo@example.com", Status: 2}))
+
+	p := NewLocal(TypeLocal, userDao)
+	assert.Equal(t, TypeLocal, p.Name())
+	acc, err := p.Authenticate(ctx, "foo", "123456")
+	require.NoError(t, err)
+	assert.Equal(t, TypeLocal, acc.Provider)
+	assert.Equal(t, "1", acc.Subject)
+	assert.Equal(t, "foo", acc.User.Name)
+	assert.Equal(t, "foo@example.com", acc.Email)
+
+	_, err = p.Authenticate(ctx, "foo", "654321")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = p.Authenticate(ctx, "bar", "123456")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
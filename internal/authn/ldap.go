@@ -0,0 +1,257 @@
+package authn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// the defaults of the ldap provider
+const (
+	DefaultLDAPUserFilter     = "(uid=%s)"
+	DefaultLDAPNameAttribute  = "uid"
+	DefaultLDAPEmailAttribute = "mail"
+	DefaultLDAPGroupAttribute = "memberOf"
+	DefaultLDAPTimeout        = 10 * time.Second
+)
+
+// GroupRole binds the members of a group to a role
+type GroupRole struct {
+	Group   string // dn or common name of the group, case insensitive
+	RoleKey string // key of the role
+}
+
+// LDAPOptions the settings of an LDAP or Active Directory server
+type LDAPOptions struct {
+	URL                string // ldap://host:389 or ldaps://host:636
+	StartTLS           bool   // upgrade the ldap:// connection to TLS
+	CAFile             string // pem file of the certificate authorities of the server, default is the system pool
+	InsecureSkipVerify bool   // do not verify the certificate of the server, for the tests only
+
+	BindDN       string // service account that searches the users, anonymous if it is empty
+	BindPassword string
+
+	BaseDN         string // base of the user search
+	UserFilter     string // filter of the user search, %s is the escaped username, e.g. (sAMAccountName=%s)
+	NameAttribute  string // attribute of the username
+	EmailAttribute string // attribute of the email
+	GroupAttribute string // attribute of the user entry listing the dn of its groups
+	GroupBaseDN    string // base of the group search, the groups are not searched if it or GroupFilter is empty
+	GroupFilter    string // filter of the group search, %s is the escaped dn of the user, e.g. (member=%s)
+
+	GroupRoles []GroupRole
+	Timeout    time.Duration
+}
+
+// NewLDAP create the provider of the users of a directory
+func NewLDAP(name string, opts *LDAPOptions) (Authenticator, error) {
+	o := *opts
+	if o.URL == "" || o.BaseDN == "" {
+		return nil, errors.New("the url and the base dn of the ldap server are required")
+	}
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return nil, err
+	}
+	if o.UserFilter == "" {
+		o.UserFilter = DefaultLDAPUserFilter
+	}
+	if strings.Count(o.UserFilter, "%s") != 1 {
+		return nil, fmt.Errorf("the user filter %q must contain one %%s", o.UserFilter)
+	}
+	if o.NameAttribute == "" {
+		o.NameAttribute = DefaultLDAPNameAttribute
+	}
+	if o.EmailAttribute == "" {
+		o.EmailAttribute = DefaultLDAPEmailAttribute
+	}
+	if o.GroupAttribute == "" {
+		o.GroupAttribute = DefaultLDAPGroupAttribute
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultLDAPTimeout
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: o.InsecureSkipVerify, //nolint
+		MinVersion:         tls.VersionTLS12,
+	}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in %s", o.CAFile)
+		}
+	}
+
+	return &ldapProvider{name: name, opts: &o, tlsConfig: tlsConfig}, nil
+}
+
+type ldapProvider struct {
+	name      string
+	opts      *LDAPOptions
+	tlsConfig *tls.Config
+}
+
+func (p *ldapProvider) Name() string {
+	return p.name
+}
+
+func (p *ldapProvider) Authenticate(_ context.Context, username string, password string) (*Account, error) {
+	// a bind with an empty password is an unauthenticated bind, it succeeds on many servers
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close() //nolint
+
+	if err = p.bindService(conn); err != nil {
+		return nil, err
+	}
+	entry, err := p.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	groups := entry.GetAttributeValues(p.opts.GroupAttribute)
+	if p.opts.GroupBaseDN != "" && p.opts.GroupFilter != "" {
+		// the user may not read the groups, they are searched by the service account
+		if err = p.bindService(conn); err != nil {
+			return nil, err
+		}
+		found, err := p.searchGroups(conn, entry.DN)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, found...)
+	}
+
+	acc := &Account{
+		Provider: p.name,
+		Subject:  entry.DN,
+		Username: entry.GetAttributeValue(p.opts.NameAttribute),
+		Email:    entry.GetAttributeValue(p.opts.EmailAttribute),
+	}
+	if acc.Username == "" {
+		acc.Username = username
+	}
+	acc.ManagedRoleKeys, acc.RoleKeys = mapGroupRoles(p.opts.GroupRoles, groups)
+	return acc, nil
+}
+
+func (p *ldapProvider) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(p.opts.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: p.opts.Timeout}),
+		ldap.DialWithTLSConfig(p.tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(p.opts.Timeout)
+	if p.opts.StartTLS {
+		if err = conn.StartTLS(p.tlsConfig); err != nil {
+			conn.Close() //nolint
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (p *ldapProvider) bindService(conn *ldap.Conn) error {
+	if p.opts.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(p.opts.BindDN, p.opts.BindPassword); err != nil {
+		return fmt.Errorf("bind %s: %w", p.opts.BindDN, err)
+	}
+	return nil
+}
+
+// searchUser the entry of the username, ErrInvalidCredentials if there is none
+func (p *ldapProvider) searchUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(p.opts.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(p.opts.Timeout.Seconds()), false,
+		fmt.Sprintf(p.opts.UserFilter, ldap.EscapeFilter(username)),
+		[]string{p.opts.NameAttribute, p.opts.EmailAttribute, p.opts.GroupAttribute}, nil)
+	res, err := conn.Search(req)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, ErrInvalidCredentials
+		}
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("several entries match the user %s", username)
+		}
+		return nil, err
+	}
+	switch len(res.Entries) {
+	case 0:
+		return nil, ErrInvalidCredentials
+	case 1:
+		return res.Entries[0], nil
+	default:
+		return nil, fmt.Errorf("several entries match the user %s", username)
+	}
+}
+
+func (p *ldapProvider) searchGroups(conn *ldap.Conn, userDN string) ([]string, error) {
+	req := ldap.NewSearchRequest(p.opts.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(p.opts.Timeout.Seconds()), false,
+		fmt.Sprintf(p.opts.GroupFilter, ldap.EscapeFilter(userDN)),
+		[]string{"dn"}, nil)
+	res, err := conn.Search(req)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	groups := make([]string, 0, len(res.Entries))
+	for _, entry := range res.Entries {
+		groups = append(groups, entry.DN)
+	}
+	return groups, nil
+}
+
+// mapGroupRoles the role keys of all the mappings and the role keys of the groups of the user,
+// a mapping matches a group by its dn or by the value of its first attribute, e.g. the cn
+func mapGroupRoles(groupRoles []GroupRole, groups []string) ([]string, []string) {
+	names := map[string]bool{}
+	for _, g := range groups {
+		names[strings.ToLower(g)] = true
+		if dn, err := ldap.ParseDN(g); err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+			names[strings.ToLower(dn.RDNs[0].Attributes[0].Value)] = true
+		}
+	}
+
+	var managed, granted []string
+	for _, gr := range groupRoles {
+		managed = append(managed, gr.RoleKey)
+		if names[strings.ToLower(gr.Group)] {
+			granted = append(granted, gr.RoleKey)
+		}
+	}
+	return managed, granted
+}
@@ -0,0 +1,141 @@
+package authn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDirectory start an in-process directory with the users alice (admins), bob (groups searched) and svc,
+// their password is "password"
+func startDirectory(t *testing.T, opt ...testdirectory.Option) *testdirectory.Directory {
+	td := testdirectory.Start(t, opt...)
+	users := testdirectory.NewUsers(t, []string{"alice"}, testdirectory.WithMembersOf(t, testdirectory.NewMemberOf(t, []string{"admins", "staff"})...))
+	users = append(users, testdirectory.NewUsers(t, []string{"bob", "svc"})...)
+	td.SetUsers(users...)
+	td.SetGroups(testdirectory.NewGroup(t, "operators", []string{"bob"}))
+	return td
+}
+
+func writeCA(t *testing.T, td *testdirectory.Directory) string {
+	name := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(name, []byte(td.Cert()), 0o600))
+	return name
+}
+
+func newTestLDAPOptions(url string, caFile string) *LDAPOptions {
+	return &LDAPOptions{
+		URL:            url,
+		CAFile:         caFile,
+		BindDN:         "cn=svc," + testdirectory.DefaultUserDN,
+		BindPassword:   "password",
+		BaseDN:         testdirectory.DefaultUserDN,
+		UserFilter:     "(cn=%s)",
+		NameAttribute:  "name",
+		EmailAttribute: "email",
+		GroupBaseDN:    testdirectory.DefaultGroupDN,
+		GroupFilter:    "(member=%s)",
+		GroupRoles: []GroupRole{
+			{Group: "cn=admins," + testdirectory.DefaultGroupDN, RoleKey: "admin"},
+			{Group: "Operators", RoleKey: "operator"},
+			{Group: "viewers", RoleKey: "viewer"},
+		},
+	}
+}
+
+func TestLDAP_LDAPS(t *testing.T) {
+	td := startDirectory(t)
+	url := fmt.Sprintf("ldaps://%s:%d", td.Host(), td.Port())
+	p, err := NewLDAP("corp", newTestLDAPOptions(url, writeCA(t, td)))
+	require.NoError(t, err)
+	assert.Equal(t, "corp", p.Name())
+	ctx := context.Background()
+
+	acc, err := p.Authenticate(ctx, "alice", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "corp", acc.Provider)
+	assert.Equal(t, "cn=alice,"+testdirectory.DefaultUserDN, acc.Subject)
+	assert.Equal(t, "alice", acc.Username)
+	assert.Equal(t, "alice@example.com", acc.Email)
+	assert.Nil(t, acc.User)
+	assert.Equal(t, []string{"admin", "operator", "viewer"}, acc.ManagedRoleKeys)
+	assert.Equal(t, []string{"admin"}, acc.RoleKeys)
+
+	// the groups found by the group search
+	acc, err = p.Authenticate(ctx, "bob", "password")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"operator"}, acc.RoleKeys)
+
+	_, err = p.Authenticate(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = p.Authenticate(ctx, "alice", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = p.Authenticate(ctx, "nobody", "password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// the service account can not bind
+	opts := newTestLDAPOptions(url, writeCA(t, td))
+	opts.BindPassword = "wrong"
+	p, err = NewLDAP("corp", opts)
+	require.NoError(t, err)
+	_, err = p.Authenticate(ctx, "alice", "password")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+
+	// the certificate of the server is not trusted
+	p, err = NewLDAP("corp", newTestLDAPOptions(url, ""))
+	require.NoError(t, err)
+	_, err = p.Authenticate(ctx, "alice", "password")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestLDAP_StartTLS(t *testing.T) {
+	td := startDirectory(t, testdirectory.WithNoTLS(t))
+	url := fmt.Sprintf("ldap://%s:%d", td.Host(), td.Port())
+	ctx := context.Background()
+
+	opts := newTestLDAPOptions(url, "")
+	opts.StartTLS = true
+	opts.InsecureSkipVerify = true
+	p, err := NewLDAP("corp", opts)
+	require.NoError(t, err)
+	acc, err := p.Authenticate(ctx, "alice", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", acc.Username)
+
+	// plain ldap
+	p, err = NewLDAP("corp", newTestLDAPOptions(url, ""))
+	require.NoError(t, err)
+	_, err = p.Authenticate(ctx, "bob", "password")
+	assert.NoError(t, err)
+}
+
+func TestNewLDAP(t *testing.T) {
+	_, err := NewLDAP("corp", &LDAPOptions{BaseDN: "dc=example,dc=org"})
+	assert.Error(t, err)
+	_, err = NewLDAP("corp", &LDAPOptions{URL: "ldap://localhost", BaseDN: "dc=example,dc=org", UserFilter: "(uid=foo)"})
+	assert.Error(t, err)
+	_, err = NewLDAP("corp", &LDAPOptions{URL: "ldap://localhost", BaseDN: "dc=example,dc=org", CAFile: "not-exist.pem"})
+	assert.Error(t, err)
+	_, err = NewLDAP("corp", &LDAPOptions{URL: "ldap://localhost", BaseDN: "dc=example,dc=org"})
+	assert.NoError(t, err)
+}
+
+func Test_mapGroupRoles(t *testing.T) {
+	managed, granted := mapGroupRoles([]GroupRole{
+		{Group: "Admins", RoleKey: "admin"},
+		{Group: "cn=devs,ou=groups,dc=example,dc=org", RoleKey: "operator"},
+	}, []string{"CN=admins,OU=groups,DC=example,DC=org", "cn=devs,ou=groups,dc=example,dc=org", "plain"})
+	assert.Equal(t, []string{"admin", "operator"}, managed)
+	assert.Equal(t, []string{"admin", "operator"}, granted)
+
+	_, granted = mapGroupRoles([]GroupRole{{Group: "admins", RoleKey: "admin"}}, nil)
+	assert.Empty(t, granted)
+}
@@ -0,0 +1,42 @@
+package authn
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+)
+
+// NewLocal create the provider of the users in the database
+func NewLocal(name string, userDao dao.UserDao) Authenticator {
+	return &local{name: name, userDao: userDao}
+}
+
+type local struct {
+	name    string
+	userDao dao.UserDao
+}
+
+func (l *local) Name() string {
+	return l.name
+}
+
+func (l *local) Authenticate(ctx context.Context, username string, password string) (*Account, error) {
+	user, err := l.userDao.GetByName(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Account{
+		Provider: l.name,
+		Subject:  utils.Uint64ToStr(user.ID),
+		Username: user.Name,
+		Email:    user.Email,
+		User:     user,
+	}, nil
+}
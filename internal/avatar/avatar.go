@@ -0,0 +1,94 @@
+// Package avatar validates and resizes the avatar images uploaded by the users,
+// and stores them on the local disk or an S3-compatible object storage.
+package avatar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the gif decoder
+	_ "image/jpeg" // register the jpeg decoder
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the webp decoder
+)
+
+// ContentType the content type of the stored avatars, they are always encoded as png
+const ContentType = "image/png"
+
+// MaxPixels the maximum width x height of an uploaded image, a small compressed file can declare a huge image
+// whose decoding would exhaust the memory
+const MaxPixels = 4096 * 4096
+
+// ErrUnsupportedImage the uploaded file is not an image of an allowed content type
+var ErrUnsupportedImage = errors.New("the avatar must be a png, jpeg, gif or webp image")
+
+// ErrImageTooLarge the uploaded image has more than MaxPixels pixels
+var ErrImageTooLarge = errors.New("the avatar image is too large")
+
+var allowedContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Store saves the avatar files
+type Store interface {
+	// Put save the file with the key and return the url it can be downloaded from
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete the file with the url returned by Put, the urls that were not returned by the store are ignored
+	Delete(ctx context.Context, url string) error
+}
+
+// Resize check the content type of the image by its content, not by the name or the header of the upload,
+// and its size is at most MaxPixels, then crop it to a centered square and scale it to size x size pixels, the result is encoded as png.
+func Resize(r io.Reader, size int) ([]byte, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	if !allowedContentTypes[http.DetectContentType(head)] {
+		return nil, ErrUnsupportedImage
+	}
+
+	// the size declared by the header is checked before the pixels are decoded
+	header := &bytes.Buffer{}
+	cfg, _, err := image.DecodeConfig(io.TeeReader(br, header))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrUnsupportedImage)
+	}
+	if cfg.Width > MaxPixels/cfg.Height {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(io.MultiReader(header, br))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	buf := &bytes.Buffer{}
+	if err = png.Encode(buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
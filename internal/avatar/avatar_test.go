@@ -0,0 +1,106 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 200; y++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, jpeg.Encode(buf, src, nil))
+
+	data, err := Resize(buf, 64)
+	assert.NoError(t, err)
+	img, format, err := image.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+
+	// the content is checked, not the name of the file
+	_, err = Resize(strings.NewReader("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), 64)
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+	_, err = Resize(bytes.NewReader(append([]byte("\x89PNG\r\n\x1a\n"), 0, 1, 2)), 64)
+	assert.ErrorIs(t, err, ErrUnsupportedImage) // truncated png
+
+	// the size is checked before the decoding, the large images are rejected
+	buf.Reset()
+	assert.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 5000, 4000))))
+	_, err = Resize(buf, 64)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStore(dir, "/avatars/")
+	ctx := context.Background()
+
+	url, err := s.Put(ctx, "1/foo.png", []byte("foo"), ContentType)
+	assert.NoError(t, err)
+	assert.Equal(t, "/avatars/1/foo.png", url)
+	data, err := os.ReadFile(filepath.Join(dir, "1", "foo.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(data))
+
+	_, err = s.Put(ctx, "../foo.png", []byte("foo"), ContentType)
+	assert.Error(t, err)
+
+	assert.NoError(t, s.Delete(ctx, "https://example.com/foo.png")) // not stored by s, ignored
+	assert.NoError(t, s.Delete(ctx, url))
+	_, err = os.Stat(filepath.Join(dir, "1", "foo.png"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, s.Delete(ctx, url))
+}
+
+func TestS3Store(t *testing.T) {
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewS3Store(&S3Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "avatars",
+		AccessKey: "foo",
+		SecretKey: "bar",
+	})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	img := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(img, image.NewRGBA(image.Rect(0, 0, 1, 1))))
+	url, err := s.Put(ctx, "1/foo.png", img.Bytes(), ContentType)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/avatars/1/foo.png", url)
+	assert.True(t, bytes.Contains(objects["/avatars/1/foo.png"], img.Bytes())) // the body is signed in chunks over http
+
+	assert.NoError(t, s.Delete(ctx, url))
+	assert.Empty(t, objects)
+}
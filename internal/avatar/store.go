@@ -0,0 +1,121 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// the defaults of the local store
+const (
+	DefaultDir       = "./data/avatars"
+	DefaultURLPrefix = "/avatars"
+)
+
+// NewLocalStore create a store that saves the files in dir, they are served at the url prefix
+func NewLocalStore(dir string, urlPrefix string) Store {
+	return &localStore{dir: dir, urlPrefix: strings.TrimSuffix(urlPrefix, "/")}
+}
+
+type localStore struct {
+	dir       string
+	urlPrefix string
+}
+
+func (s *localStore) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(name, data, 0o644); err != nil {
+		return "", err
+	}
+	return s.urlPrefix + "/" + key, nil
+}
+
+func (s *localStore) Delete(_ context.Context, url string) error {
+	key, ok := strings.CutPrefix(url, s.urlPrefix+"/")
+	if !ok {
+		return nil
+	}
+	name, err := s.path(key)
+	if err != nil {
+		return nil
+	}
+	err = os.Remove(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path the file path of the key, the keys escaping the directory are rejected
+func (s *localStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") || path.IsAbs(key) {
+		return "", errors.New("invalid key " + key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// S3Options the settings of an S3-compatible object storage
+type S3Options struct {
+	Endpoint  string // host:port of the service
+	UseSSL    bool
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // url prefix the objects are downloaded from, default is the endpoint url with the bucket
+}
+
+// NewS3Store create a store that saves the files in a bucket of an S3-compatible object storage,
+// the bucket must allow the anonymous download of the objects to show the avatars
+func NewS3Store(opts *S3Options) (Store, error) {
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicURL := opts.PublicURL
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + opts.Bucket
+	}
+	return &s3Store{client: client, bucket: opts.Bucket, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+type s3Store struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", err
+	}
+	return s.publicURL + "/" + key, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, url string) error {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok {
+		return nil
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,141 @@
+// Package bootstrap seeds a fresh database with the first admin user, the default roles and the api rows,
+// it is idempotent, the existing users and roles are never changed, so it is safe to run at every startup.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"go-admin/internal/apisync"
+	"go-admin/internal/model"
+)
+
+// AdminRoleKey key of the role granted to the superuser
+const AdminRoleKey = "admin"
+
+// DefaultRoles the roles created by bootstrap, ordered by privilege
+var DefaultRoles = []*model.Role{
+	{RoleName: "Administrator", RoleKey: AdminRoleKey, RoleSort: 1, Status: "2", Admin: "1", Remark: "full access"},
+	{RoleName: "Operator", RoleKey: "operator", RoleSort: 2, Status: "2", Admin: "0", Remark: "operates cluster resources"},
+	{RoleName: "Viewer", RoleKey: "viewer", RoleSort: 3, Status: "2", Admin: "0", Remark: "read only access"},
+}
+
+// Options superuser settings
+type Options struct {
+	AdminName     string
+	AdminPassword string // required only if the superuser does not exist yet
+	AdminEmail    string
+}
+
+// OptionsFromEnv fill the empty fields of opts from the environment variables ADMIN_NAME, ADMIN_PASSWORD and ADMIN_EMAIL
+func OptionsFromEnv(opts Options) Options {
+	if v := os.Getenv("ADMIN_NAME"); v != "" && opts.AdminName == "" {
+		opts.AdminName = v
+	}
+	if v := os.Getenv("ADMIN_PASSWORD"); v != "" && opts.AdminPassword == "" {
+		opts.AdminPassword = v
+	}
+	if v := os.Getenv("ADMIN_EMAIL"); v != "" && opts.AdminEmail == "" {
+		opts.AdminEmail = v
+	}
+	return opts
+}
+
+// Result what bootstrap created
+type Result struct {
+	AdminCreated bool
+	AdminBound   bool // the superuser was granted the admin role
+	Roles        []string
+	Apis         int
+}
+
+// Run create the rows that do not exist yet, all in one transaction
+func Run(ctx context.Context, db *gorm.DB, routes gin.RoutesInfo, opts Options) (*Result, error) {
+	if opts.AdminName == "" {
+		return nil, errors.New("admin name is empty")
+	}
+
+	result := &Result{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, role := range DefaultRoles {
+			created, err := createRole(tx, role)
+			if err != nil {
+				return err
+			}
+			if created {
+				result.Roles = append(result.Roles, role.RoleKey)
+			}
+		}
+
+		var err error
+		result.AdminCreated, result.AdminBound, err = createAdmin(tx, opts)
+		if err != nil {
+			return err
+		}
+
+		changes, err := apisync.Sync(ctx, tx, routes, false)
+		for _, change := range changes {
+			if change.Change == apisync.ChangeCreated {
+				result.Apis++
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func createRole(tx *gorm.DB, role *model.Role) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Role{}).Where("role_key = ?", role.RoleKey).Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	record := *role
+	return true, tx.Create(&record).Error
+}
+
+func createAdmin(tx *gorm.DB, opts Options) (created bool, bound bool, err error) {
+	user := &model.User{}
+	err = tx.Where("name = ?", opts.AdminName).Limit(1).Find(user).Error
+	if err != nil {
+		return false, false, err
+	}
+
+	if user.ID == 0 {
+		if opts.AdminPassword == "" {
+			return false, false, errors.New("admin password is empty, it is required to create the admin user")
+		}
+		user = &model.User{
+			Name:     opts.AdminName,
+			Password: opts.AdminPassword,
+			Email:    opts.AdminEmail,
+			Status:   model.UserStatusActivated,
+		}
+		if err = tx.Create(user).Error; err != nil {
+			return false, false, err
+		}
+		created = true
+	}
+
+	role := &model.Role{}
+	err = tx.Where("role_key = ?", AdminRoleKey).First(role).Error
+	if err != nil {
+		return created, false, err
+	}
+	var count int64
+	err = tx.Model(&model.UserRole{}).Where("user_id = ? AND role_id = ?", user.ID, role.ID).Count(&count).Error
+	if err != nil || count > 0 {
+		return created, false, err
+	}
+	err = tx.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID}).Error
+	return created, err == nil, err
+}
@@ -0,0 +1,72 @@
+package bootstrap
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/apisync"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	if _, err = migration.Up(db, ggorm.DBDriverSqlite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRun(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	routes := gin.RoutesInfo{
+		{Method: "POST", Path: "/api/v1/user/login", Handler: "go-admin/internal/handler.(*userHandler).Login-fm"},
+		{Method: "GET", Path: "/api/v1/user/:id", Handler: "go-admin/internal/handler.(*userHandler).GetByID-fm"},
+		{Method: "GET", Path: "/health", Handler: "github.com/zhufuyi/sponge/pkg/gin/handlerfunc.CheckHealth"},
+	}
+
+	// the password is required to create the admin user
+	_, err := Run(ctx, db, routes, Options{AdminName: "admin"})
+	assert.Error(t, err)
+	var count int64
+	db.Model(&model.Role{}).Count(&count)
+	assert.Equal(t, int64(0), count) // rolled back
+
+	result, err := Run(ctx, db, routes, Options{AdminName: "admin", AdminPassword: "123456"})
+	assert.NoError(t, err)
+	assert.True(t, result.AdminCreated)
+	assert.True(t, result.AdminBound)
+	assert.Equal(t, []string{"admin", "operator", "viewer"}, result.Roles)
+	assert.Equal(t, 2, result.Apis)
+
+	api := &model.Api{}
+	assert.NoError(t, db.Where("path = ?", "/api/v1/user/login").First(api).Error)
+	assert.Equal(t, apisync.Summaries()["POST /api/v1/user/login"], api.Title)
+	assert.Equal(t, "POST", api.Action)
+
+	// idempotent, the password is not needed once the user exists
+	result, err = Run(ctx, db, routes, Options{AdminName: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Result{}, result)
+	db.Model(&model.UserRole{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("ADMIN_NAME", "root")
+	t.Setenv("ADMIN_PASSWORD", "secret")
+	opts := OptionsFromEnv(Options{AdminName: "admin"})
+	assert.Equal(t, Options{AdminName: "admin", AdminPassword: "secret"}, opts)
+}
@@ -0,0 +1,306 @@
+// Package bulk decodes the rows of an import and encodes the rows of an export in csv, json or yaml,
+// the columns of csv and the keys of json and yaml are the json names of the row struct fields.
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// supported formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatJSON: "application/json; charset=utf-8",
+	FormatYAML: "application/yaml; charset=utf-8",
+}
+
+// Format get the format by name, if name is empty it is derived from the content type, the default is json
+func Format(name string, contentType string) (string, error) {
+	if name == "" {
+		switch {
+		case strings.Contains(contentType, "csv"):
+			return FormatCSV, nil
+		case strings.Contains(contentType, "yaml"):
+			return FormatYAML, nil
+		}
+		return FormatJSON, nil
+	}
+
+	name = strings.ToLower(name)
+	if name == "yml" {
+		name = FormatYAML
+	}
+	if _, ok := contentTypes[name]; !ok {
+		return "", fmt.Errorf("unsupported format %s, it must be csv, json or yaml", name)
+	}
+	return name, nil
+}
+
+// ContentType the content type of the format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Decode the rows in the format, rows must be a pointer to a slice of struct pointers,
+// the unknown columns or keys are rejected
+func Decode(format string, r io.Reader, rows interface{}) error {
+	switch format {
+	case FormatJSON:
+		return decodeJSON(r, rows)
+	case FormatYAML:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		data, err = yaml.YAMLToJSON(data)
+		if err != nil {
+			return err
+		}
+		return decodeJSON(bytes.NewReader(data), rows)
+	case FormatCSV:
+		return decodeCSV(r, rows)
+	}
+	return fmt.Errorf("unsupported format %s", format)
+}
+
+func decodeJSON(r io.Reader, rows interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(rows)
+}
+
+func decodeCSV(r io.Reader, rows interface{}) error {
+	slice := reflect.ValueOf(rows)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.New("rows must be a pointer to a slice")
+	}
+	slice = slice.Elem()
+	rowType := slice.Type().Elem().Elem()
+	fields := fieldIndexes(rowType)
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	indexes := make([]int, len(header))
+	for i, name := range header {
+		index, ok := fields[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown column %s", name)
+		}
+		indexes[i] = index
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := reflect.New(rowType)
+		for i, value := range record {
+			field := rowType.Field(indexes[i])
+			if err = setField(row.Elem().Field(indexes[i]), value); err != nil {
+				return fmt.Errorf("line %d column %s: %v", line, jsonName(field), err)
+			}
+		}
+		slice.Set(reflect.Append(slice, row))
+	}
+}
+
+func setField(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil // zero value
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Encoder write rows one by one, an export does not have to hold all the rows in memory
+type Encoder interface {
+	Encode(row interface{}) error
+	// Close write the end of the rows, it must be called after the last row
+	Close() error
+}
+
+// NewEncoder create an encoder of the format, rowType is a value of the row struct, it defines the csv header
+func NewEncoder(format string, w io.Writer, rowType interface{}) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatYAML:
+		return &yamlEncoder{w: w}, nil
+	case FormatCSV:
+		t := reflect.Indirect(reflect.ValueOf(rowType)).Type()
+		return &csvEncoder{w: csv.NewWriter(w), t: t}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %s", format)
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) Encode(row interface{}) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n"
+	if e.count == 0 {
+		prefix = "[\n"
+	}
+	e.count++
+	_, err = e.w.Write(append([]byte(prefix), data...))
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+type yamlEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *yamlEncoder) Encode(row interface{}) error {
+	data, err := yaml.Marshal([]interface{}{row})
+	if err != nil {
+		return err
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *yamlEncoder) Close() error {
+	if e.count == 0 {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	t      reflect.Type
+	header bool
+}
+
+func (e *csvEncoder) Encode(row interface{}) error {
+	if !e.header {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(row))
+	record := make([]string, 0, e.t.NumField())
+	for i := 0; i < e.t.NumField(); i++ {
+		if !e.t.Field(i).IsExported() {
+			continue
+		}
+		record = append(record, fmt.Sprint(v.Field(i).Interface()))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	if !e.header {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	e.header = true
+	header := make([]string, 0, e.t.NumField())
+	for i := 0; i < e.t.NumField(); i++ {
+		if f := e.t.Field(i); f.IsExported() {
+			header = append(header, jsonName(f))
+		}
+	}
+	return e.w.Write(header)
+}
+
+// fieldIndexes the index of the exported fields by json name
+func fieldIndexes(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() {
+			fields[jsonName(f)] = i
+		}
+	}
+	return fields
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
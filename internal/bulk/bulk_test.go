@@ -0,0 +1,90 @@
+package bulk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type row struct {
+	Name   string `json:"name"`
+	Age    int    `json:"age"`
+	Active bool   `json:"active,omitempty"`
+}
+
+func TestFormat(t *testing.T) {
+	for _, c := range []struct{ name, contentType, want string }{
+		{"", "", FormatJSON},
+		{"", "text/csv", FormatCSV},
+		{"", "application/x-yaml", FormatYAML},
+		{"YML", "application/json", FormatYAML},
+		{"csv", "", FormatCSV},
+	} {
+		format, err := Format(c.name, c.contentType)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, format)
+	}
+
+	_, err := Format("xml", "")
+	assert.Error(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	inputs := map[string]string{
+		FormatJSON: `[{"name":"foo","age":20,"active":true},{"name":"bar"}]`,
+		FormatYAML: "- name: foo\n  age: 20\n  active: true\n- name: bar\n",
+		FormatCSV:  "name,age,active\nfoo,20,true\nbar,,\n",
+	}
+	for format, input := range inputs {
+		rows := []*row{}
+		err := Decode(format, strings.NewReader(input), &rows)
+		assert.NoError(t, err, format)
+		assert.Equal(t, []*row{{Name: "foo", Age: 20, Active: true}, {Name: "bar"}}, rows, format)
+	}
+
+	for format, input := range map[string]string{
+		FormatJSON: `[{"name":"foo","email":"foo@example.com"}]`,
+		FormatYAML: "- name: foo\n  email: foo@example.com\n",
+		FormatCSV:  "name,email\nfoo,foo@example.com\n",
+	} {
+		rows := []*row{}
+		err := Decode(format, strings.NewReader(input), &rows)
+		assert.Error(t, err, format) // unknown column
+	}
+
+	rows := []*row{}
+	err := Decode(FormatCSV, strings.NewReader("name,age\nfoo,bar\n"), &rows)
+	assert.EqualError(t, err, `line 2 column age: strconv.ParseInt: parsing "bar": invalid syntax`)
+}
+
+func TestEncoder(t *testing.T) {
+	wants := map[string]string{
+		FormatJSON: "[\n{\"name\":\"foo\",\"age\":20,\"active\":true},\n{\"name\":\"bar\",\"age\":0}\n]\n",
+		FormatYAML: "- active: true\n  age: 20\n  name: foo\n- age: 0\n  name: bar\n",
+		FormatCSV:  "name,age,active\nfoo,20,true\nbar,0,false\n",
+	}
+	for format, want := range wants {
+		buf := &bytes.Buffer{}
+		e, err := NewEncoder(format, buf, &row{})
+		assert.NoError(t, err)
+		assert.NoError(t, e.Encode(&row{Name: "foo", Age: 20, Active: true}))
+		assert.NoError(t, e.Encode(&row{Name: "bar"}))
+		assert.NoError(t, e.Close())
+		assert.Equal(t, want, buf.String(), format)
+
+		// the exported rows can be imported
+		rows := []*row{}
+		assert.NoError(t, Decode(format, buf, &rows))
+		assert.Len(t, rows, 2)
+	}
+
+	// no rows
+	for format, want := range map[string]string{FormatJSON: "[]\n", FormatYAML: "[]\n", FormatCSV: "name,age,active\n"} {
+		buf := &bytes.Buffer{}
+		e, _ := NewEncoder(format, buf, row{})
+		assert.NoError(t, e.Close())
+		assert.Equal(t, want, buf.String(), format)
+	}
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	loginFailuresCachePrefixKey = "login_failures:"
+	loginLockCachePrefixKey     = "login_lock:"
+)
+
+var _ LoginAttemptCache = (*loginAttemptRedisCache)(nil)
+var _ LoginAttemptCache = (*loginAttemptMemoryCache)(nil)
+
+// LoginAttemptCache counts the failed logins and holds the temporary locks, the key is an account or an ip
+type LoginAttemptCache interface {
+	// Incr add a failure of the key and return the number of failures since the first one in the window
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+	// Reset delete the failures and the lock of the key
+	Reset(ctx context.Context, key string) error
+	// Lock the key for the duration
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	// LockedFor the remaining duration of the lock of the key, 0 if the key is not locked
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+}
+
+// NewLoginAttemptCache new a cache, the counters are kept in memory if the cache type is not redis,
+// the failures are then counted per instance of the service
+func NewLoginAttemptCache(cacheType *model.CacheType) LoginAttemptCache {
+	if strings.ToLower(cacheType.CType) == "redis" {
+		return &loginAttemptRedisCache{rdb: cacheType.Rdb}
+	}
+	return &loginAttemptMemoryCache{items: map[string]*loginAttemptItem{}}
+}
+
+type loginAttemptRedisCache struct {
+	rdb *redis.Client
+}
+
+// incrScript set the expiration with the first failure only, the later failures do not extend the window
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+func (c *loginAttemptRedisCache) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return incrScript.Run(ctx, c.rdb, []string{loginFailuresCachePrefixKey + key}, window.Milliseconds()).Int64()
+}
+
+func (c *loginAttemptRedisCache) Reset(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, loginFailuresCachePrefixKey+key, loginLockCachePrefixKey+key).Err()
+}
+
+func (c *loginAttemptRedisCache) Lock(ctx context.Context, key string, duration time.Duration) error {
+	return c.rdb.Set(ctx, loginLockCachePrefixKey+key, 1, duration).Err()
+}
+
+func (c *loginAttemptRedisCache) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.rdb.PTTL(ctx, loginLockCachePrefixKey+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 { // -2 if the key does not exist
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+type loginAttemptItem struct {
+	failures    int64
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+type loginAttemptMemoryCache struct {
+	mu    sync.Mutex
+	items map[string]*loginAttemptItem
+}
+
+func (c *loginAttemptMemoryCache) Incr(_ context.Context, key string, window time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evict(now)
+	item, ok := c.items[key]
+	if !ok {
+		item = &loginAttemptItem{}
+		c.items[key] = item
+	}
+	if item.windowEnd.Before(now) {
+		item.failures = 0
+		item.windowEnd = now.Add(window)
+	}
+	item.failures++
+	return item.failures, nil
+}
+
+func (c *loginAttemptMemoryCache) Reset(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+	return nil
+}
+
+func (c *loginAttemptMemoryCache) Lock(_ context.Context, key string, duration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		item = &loginAttemptItem{}
+		c.items[key] = item
+	}
+	item.lockedUntil = time.Now().Add(duration)
+	return nil
+}
+
+func (c *loginAttemptMemoryCache) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return 0, nil
+	}
+	if d := time.Until(item.lockedUntil); d > 0 {
+		return d, nil
+	}
+	return 0, nil
+}
+
+// evict the items whose window and lock have both ended, so that the guessed names do not fill the memory
+func (c *loginAttemptMemoryCache) evict(now time.Time) {
+	for key, item := range c.items {
+		if item.windowEnd.Before(now) && item.lockedUntil.Before(now) {
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zhufuyi/sponge/pkg/gotest"
+
+	"go-admin/internal/model"
+)
+
+func testLoginAttemptCache(t *testing.T, c LoginAttemptCache) {
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		n, err := c.Incr(ctx, "account:foo", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	n, err := c.Incr(ctx, "ip:127.0.0.1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	d, err := c.LockedFor(ctx, "account:foo")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+	assert.NoError(t, c.Lock(ctx, "account:foo", time.Minute))
+	d, err = c.LockedFor(ctx, "account:foo")
+	assert.NoError(t, err)
+	assert.True(t, d > 50*time.Second && d <= time.Minute)
+
+	// reset deletes the failures and the lock
+	assert.NoError(t, c.Reset(ctx, "account:foo"))
+	d, err = c.LockedFor(ctx, "account:foo")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+	n, err = c.Incr(ctx, "account:foo", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func Test_loginAttemptCache_Redis(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	testLoginAttemptCache(t, NewLoginAttemptCache(&model.CacheType{CType: "redis", Rdb: c.RedisClient}))
+}
+
+func Test_loginAttemptCache_Memory(t *testing.T) {
+	c := NewLoginAttemptCache(&model.CacheType{CType: "memory"})
+	testLoginAttemptCache(t, c)
+
+	// the window is not extended by the later failures
+	ctx := context.Background()
+	_, _ = c.Incr(ctx, "ip:127.0.0.2", 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	n, err := c.Incr(ctx, "ip:127.0.0.2", 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	loginChallengeCachePrefixKey = "login_challenge:"
+)
+
+var _ LoginChallengeCache = (*loginChallengeRedisCache)(nil)
+var _ LoginChallengeCache = (*loginChallengeMemoryCache)(nil)
+
+// ErrLoginChallengeNotFound the challenge is unknown, expired or already completed
+var ErrLoginChallengeNotFound = errors.New("login challenge not found")
+
+// LoginChallenge a login whose password is verified, waiting for the TOTP code
+type LoginChallenge struct {
+	UserID uint64 `json:"userId"`
+	Secret string `json:"secret"` // the secret enrolled at the login, empty if the TOTP of the user is enabled
+}
+
+// LoginChallengeCache keeps the logins waiting for the second factor
+type LoginChallengeCache interface {
+	Set(ctx context.Context, token string, data *LoginChallenge, duration time.Duration) error
+	// Get the data of the challenge, ErrLoginChallengeNotFound if it does not exist
+	Get(ctx context.Context, token string) (*LoginChallenge, error)
+	Del(ctx context.Context, token string) error
+}
+
+// NewLoginChallengeCache new a cache, the challenges are kept in memory if the cache type is not redis,
+// the code must then reach the instance of the service that verified the password
+func NewLoginChallengeCache(cacheType *model.CacheType) LoginChallengeCache {
+	if strings.ToLower(cacheType.CType) == "redis" {
+		return &loginChallengeRedisCache{rdb: cacheType.Rdb}
+	}
+	return &loginChallengeMemoryCache{items: map[string]*loginChallengeItem{}}
+}
+
+type loginChallengeRedisCache struct {
+	rdb *redis.Client
+}
+
+func (c *loginChallengeRedisCache) Set(ctx context.Context, token string, data *LoginChallenge, duration time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, loginChallengeCachePrefixKey+token, b, duration).Err()
+}
+
+func (c *loginChallengeRedisCache) Get(ctx context.Context, token string) (*LoginChallenge, error) {
+	b, err := c.rdb.Get(ctx, loginChallengeCachePrefixKey+token).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrLoginChallengeNotFound
+		}
+		return nil, err
+	}
+	data := &LoginChallenge{}
+	err = json.Unmarshal(b, data)
+	return data, err
+}
+
+func (c *loginChallengeRedisCache) Del(ctx context.Context, token string) error {
+	return c.rdb.Del(ctx, loginChallengeCachePrefixKey+token).Err()
+}
+
+type loginChallengeItem struct {
+	data      *LoginChallenge
+	expiredAt time.Time
+}
+
+type loginChallengeMemoryCache struct {
+	mu    sync.Mutex
+	items map[string]*loginChallengeItem
+}
+
+func (c *loginChallengeMemoryCache) Set(_ context.Context, token string, data *LoginChallenge, duration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range c.items { // evict the abandoned logins
+		if item.expiredAt.Before(now) {
+			delete(c.items, key)
+		}
+	}
+	c.items[token] = &loginChallengeItem{data: data, expiredAt: now.Add(duration)}
+	return nil
+}
+
+func (c *loginChallengeMemoryCache) Get(_ context.Context, token string) (*LoginChallenge, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[token]
+	if !ok || item.expiredAt.Before(time.Now()) {
+		return nil, ErrLoginChallengeNotFound
+	}
+	return item.data, nil
+}
+
+func (c *loginChallengeMemoryCache) Del(_ context.Context, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, token)
+	return nil
+}
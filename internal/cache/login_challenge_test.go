@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zhufuyi/sponge/pkg/gotest"
+
+	"go-admin/internal/model"
+)
+
+func testLoginChallengeCache(t *testing.T, c LoginChallengeCache) {
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrLoginChallengeNotFound)
+
+	assert.NoError(t, c.Set(ctx, "foo", &LoginChallenge{UserID: 1, Secret: "s"}, time.Minute))
+	data, err := c.Get(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, &LoginChallenge{UserID: 1, Secret: "s"}, data)
+	// the challenge is kept until it is deleted
+	_, err = c.Get(ctx, "foo")
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Del(ctx, "foo"))
+	_, err = c.Get(ctx, "foo")
+	assert.ErrorIs(t, err, ErrLoginChallengeNotFound)
+}
+
+func Test_loginChallengeCache_Redis(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	testLoginChallengeCache(t, NewLoginChallengeCache(&model.CacheType{CType: "redis", Rdb: c.RedisClient}))
+}
+
+func Test_loginChallengeCache_Memory(t *testing.T) {
+	c := NewLoginChallengeCache(&model.CacheType{CType: "memory"})
+	testLoginChallengeCache(t, c)
+
+	ctx := context.Background()
+	assert.NoError(t, c.Set(ctx, "bar", &LoginChallenge{}, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, err := c.Get(ctx, "bar")
+	assert.ErrorIs(t, err, ErrLoginChallengeNotFound)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"go-admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	oidcStateCachePrefixKey = "oidc_state:"
+)
+
+var _ OIDCStateCache = (*oidcStateRedisCache)(nil)
+var _ OIDCStateCache = (*oidcStateMemoryCache)(nil)
+
+// ErrOIDCStateNotFound the state is unknown, expired or already used
+var ErrOIDCStateNotFound = errors.New("oidc state not found")
+
+// OIDCState the secrets of a single sign-on request, kept until the callback with the state
+type OIDCState struct {
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// OIDCStateCache keeps the single sign-on requests, a state can be taken once
+type OIDCStateCache interface {
+	Set(ctx context.Context, state string, data *OIDCState, duration time.Duration) error
+	// Take get and delete the data of the state, ErrOIDCStateNotFound if it does not exist
+	Take(ctx context.Context, state string) (*OIDCState, error)
+}
+
+// NewOIDCStateCache new a cache, the states are kept in memory if the cache type is not redis,
+// the callback must then reach the instance of the service that started the login
+func NewOIDCStateCache(cacheType *model.CacheType) OIDCStateCache {
+	if strings.ToLower(cacheType.CType) == "redis" {
+		return &oidcStateRedisCache{rdb: cacheType.Rdb}
+	}
+	return &oidcStateMemoryCache{items: map[string]*oidcStateItem{}}
+}
+
+type oidcStateRedisCache struct {
+	rdb *redis.Client
+}
+
+func (c *oidcStateRedisCache) Set(ctx context.Context, state string, data *OIDCState, duration time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, oidcStateCachePrefixKey+state, b, duration).Err()
+}
+
+func (c *oidcStateRedisCache) Take(ctx context.Context, state string) (*OIDCState, error) {
+	b, err := c.rdb.GetDel(ctx, oidcStateCachePrefixKey+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrOIDCStateNotFound
+		}
+		return nil, err
+	}
+	data := &OIDCState{}
+	err = json.Unmarshal(b, data)
+	return data, err
+}
+
+type oidcStateItem struct {
+	data      *OIDCState
+	expiredAt time.Time
+}
+
+type oidcStateMemoryCache struct {
+	mu    sync.Mutex
+	items map[string]*oidcStateItem
+}
+
+func (c *oidcStateMemoryCache) Set(_ context.Context, state string, data *OIDCState, duration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range c.items { // evict the abandoned requests
+		if item.expiredAt.Before(now) {
+			delete(c.items, key)
+		}
+	}
+	c.items[state] = &oidcStateItem{data: data, expiredAt: now.Add(duration)}
+	return nil
+}
+
+func (c *oidcStateMemoryCache) Take(_ context.Context, state string) (*OIDCState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[state]
+	if !ok {
+		return nil, ErrOIDCStateNotFound
+	}
+	delete(c.items, state)
+	if item.expiredAt.Before(time.Now()) {
+		return nil, ErrOIDCStateNotFound
+	}
+	return item.data, nil
+}
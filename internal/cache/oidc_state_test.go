@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zhufuyi/sponge/pkg/gotest"
+
+	"go-admin/internal/model"
+)
+
+func testOIDCStateCache(t *testing.T, c OIDCStateCache) {
+	ctx := context.Background()
+
+	_, err := c.Take(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrOIDCStateNotFound)
+
+	assert.NoError(t, c.Set(ctx, "foo", &OIDCState{Nonce: "n", Verifier: "v"}, time.Minute))
+	data, err := c.Take(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, &OIDCState{Nonce: "n", Verifier: "v"}, data)
+
+	// a state is taken once
+	_, err = c.Take(ctx, "foo")
+	assert.ErrorIs(t, err, ErrOIDCStateNotFound)
+}
+
+func Test_oidcStateCache_Redis(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	testOIDCStateCache(t, NewOIDCStateCache(&model.CacheType{CType: "redis", Rdb: c.RedisClient}))
+}
+
+func Test_oidcStateCache_Memory(t *testing.T) {
+	c := NewOIDCStateCache(&model.CacheType{CType: "memory"})
+	testOIDCStateCache(t, c)
+
+	ctx := context.Background()
+	assert.NoError(t, c.Set(ctx, "bar", &OIDCState{}, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, err := c.Take(ctx, "bar")
+	assert.ErrorIs(t, err, ErrOIDCStateNotFound)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	roleElevationCachePrefixKey = "role_elevation:"
+)
+
+var _ RoleElevationCache = (*roleElevationRedisCache)(nil)
+var _ RoleElevationCache = (*roleElevationMemoryCache)(nil)
+
+// ErrRoleElevationNotFound the active grants of the user are not cached
+var ErrRoleElevationNotFound = errors.New("role elevations not found")
+
+// RoleElevationCache keeps the active grants of the users, an empty list is cached for the users without grant
+// so that their requests do not query the database
+type RoleElevationCache interface {
+	Set(ctx context.Context, userID uint64, elevations []*model.RoleElevation, duration time.Duration) error
+	// Get the active grants of the user, ErrRoleElevationNotFound if they are not cached
+	Get(ctx context.Context, userID uint64) ([]*model.RoleElevation, error)
+	Del(ctx context.Context, userID uint64) error
+}
+
+// memoryRoleElevations the grants kept in memory, shared by the middleware reading them and the handlers and
+// the reaper changing them
+var memoryRoleElevations = &roleElevationMemoryCache{items: map[uint64]*roleElevationItem{}}
+
+// NewRoleElevationCache new a cache, the grants are kept in the memory of the instance if the cache type is not redis
+func NewRoleElevationCache(cacheType *model.CacheType) RoleElevationCache {
+	if strings.ToLower(cacheType.CType) == "redis" {
+		return &roleElevationRedisCache{rdb: cacheType.Rdb}
+	}
+	return memoryRoleElevations
+}
+
+type roleElevationRedisCache struct {
+	rdb *redis.Client
+}
+
+func (c *roleElevationRedisCache) Set(ctx context.Context, userID uint64, elevations []*model.RoleElevation, duration time.Duration) error {
+	b, err := json.Marshal(elevations)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, roleElevationCachePrefixKey+utils.Uint64ToStr(userID), b, duration).Err()
+}
+
+func (c *roleElevationRedisCache) Get(ctx context.Context, userID uint64) ([]*model.RoleElevation, error) {
+	b, err := c.rdb.Get(ctx, roleElevationCachePrefixKey+utils.Uint64ToStr(userID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrRoleElevationNotFound
+		}
+		return nil, err
+	}
+	elevations := []*model.RoleElevation{}
+	err = json.Unmarshal(b, &elevations)
+	return elevations, err
+}
+
+func (c *roleElevationRedisCache) Del(ctx context.Context, userID uint64) error {
+	return c.rdb.Del(ctx, roleElevationCachePrefixKey+utils.Uint64ToStr(userID)).Err()
+}
+
+type roleElevationItem struct {
+	elevations []*model.RoleElevation
+	expiredAt  time.Time
+}
+
+type roleElevationMemoryCache struct {
+	mu    sync.Mutex
+	items map[uint64]*roleElevationItem
+}
+
+func (c *roleElevationMemoryCache) Set(_ context.Context, userID uint64, elevations []*model.RoleElevation, duration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range c.items { // evict the users no longer seen
+		if item.expiredAt.Before(now) {
+			delete(c.items, key)
+		}
+	}
+	c.items[userID] = &roleElevationItem{elevations: elevations, expiredAt: now.Add(duration)}
+	return nil
+}
+
+func (c *roleElevationMemoryCache) Get(_ context.Context, userID uint64) ([]*model.RoleElevation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[userID]
+	if !ok || item.expiredAt.Before(time.Now()) {
+		return nil, ErrRoleElevationNotFound
+	}
+	return item.elevations, nil
+}
+
+func (c *roleElevationMemoryCache) Del(_ context.Context, userID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, userID)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zhufuyi/sponge/pkg/gotest"
+
+	"go-admin/internal/model"
+)
+
+func testRoleElevationCache(t *testing.T, c RoleElevationCache) {
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, 1)
+	assert.ErrorIs(t, err, ErrRoleElevationNotFound)
+
+	// no grant is cached as an empty list
+	assert.NoError(t, c.Set(ctx, 1, []*model.RoleElevation{}, time.Minute))
+	elevations, err := c.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, elevations)
+
+	assert.NoError(t, c.Set(ctx, 1, []*model.RoleElevation{{UserID: 1, RoleID: 2, ExpiresAt: 100}}, time.Minute))
+	elevations, err = c.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, elevations, 1)
+	assert.Equal(t, uint64(2), elevations[0].RoleID)
+	assert.Equal(t, int64(100), elevations[0].ExpiresAt)
+
+	assert.NoError(t, c.Del(ctx, 1))
+	_, err = c.Get(ctx, 1)
+	assert.ErrorIs(t, err, ErrRoleElevationNotFound)
+}
+
+func Test_roleElevationCache_Redis(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	testRoleElevationCache(t, NewRoleElevationCache(&model.CacheType{CType: "redis", Rdb: c.RedisClient}))
+}
+
+func Test_roleElevationCache_Memory(t *testing.T) {
+	c := NewRoleElevationCache(&model.CacheType{CType: "memory"})
+	testRoleElevationCache(t, c)
+	// the handlers and the middleware share the grants
+	assert.Equal(t, c, NewRoleElevationCache(&model.CacheType{}))
+
+	ctx := context.Background()
+	assert.NoError(t, c.Set(ctx, 2, nil, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, err := c.Get(ctx, 2)
+	assert.ErrorIs(t, err, ErrRoleElevationNotFound)
+}
@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	sessionCachePrefixKey      = "session:"
+	userSessionsCachePrefixKey = "user_sessions:"
+)
+
+var _ SessionCache = (*sessionRedisCache)(nil)
+var _ SessionCache = (*sessionMemoryCache)(nil)
+
+// ErrSessionNotFound the session is unknown, expired or revoked
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session a jwt issued by a login, the jwt is only accepted while its session exists
+type Session struct {
+	ID         string `json:"id"` // derived from the jwt, the jwt itself is not stored
+	UserID     uint64 `json:"userId"`
+	IP         string `json:"ip"`        // client ip of the login
+	UserAgent  string `json:"userAgent"` // user agent of the login, it tells the device
+	CreatedAt  int64  `json:"createdAt"` // unix time of the login
+	LastSeenAt int64  `json:"lastSeenAt"`
+	ExpiresAt  int64  `json:"expiresAt"` // unix time the jwt expires, the session is deleted then
+}
+
+// SessionCache keeps the sessions of the users until their jwt expire
+type SessionCache interface {
+	// Set save the session until it expires
+	Set(ctx context.Context, session *Session) error
+	// Get the session of the user, ErrSessionNotFound if it does not exist
+	Get(ctx context.Context, userID uint64, id string) (*Session, error)
+	// List the sessions of the user, the latest first
+	List(ctx context.Context, userID uint64) ([]*Session, error)
+	Del(ctx context.Context, userID uint64, id string) error
+	// DelAll delete all the sessions of the user, it logs the user out everywhere
+	DelAll(ctx context.Context, userID uint64) error
+}
+
+// memorySessions the sessions kept in memory, shared by the handlers issuing the jwt and the middleware checking them
+var memorySessions = &sessionMemoryCache{items: map[uint64]map[string]*Session{}}
+
+// NewSessionCache new a cache, the sessions are kept in the memory of the instance if the cache type is not redis,
+// a jwt is then only accepted by the instance of the service that issued it
+func NewSessionCache(cacheType *model.CacheType) SessionCache {
+	if strings.ToLower(cacheType.CType) == "redis" {
+		return &sessionRedisCache{rdb: cacheType.Rdb}
+	}
+	return memorySessions
+}
+
+func sortSessions(sessions []*Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].CreatedAt != sessions[j].CreatedAt {
+			return sessions[i].CreatedAt > sessions[j].CreatedAt
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+}
+
+type sessionRedisCache struct {
+	rdb *redis.Client
+}
+
+func sessionKey(userID uint64, id string) string {
+	return sessionCachePrefixKey + utils.Uint64ToStr(userID) + ":" + id
+}
+
+func userSessionsKey(userID uint64) string {
+	return userSessionsCachePrefixKey + utils.Uint64ToStr(userID)
+}
+
+// Set save the session and add its id to the set of the sessions of the user, the set expires with the last session
+func (c *sessionRedisCache) Set(ctx context.Context, session *Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Unix(session.ExpiresAt, 0)
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	indexKey := userSessionsKey(session.UserID)
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, sessionKey(session.UserID, session.ID), b, ttl)
+	pipe.SAdd(ctx, indexKey, session.ID)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	indexTTL, err := c.rdb.TTL(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
+	if indexTTL < ttl {
+		return c.rdb.ExpireAt(ctx, indexKey, expiresAt).Err()
+	}
+	return nil
+}
+
+func (c *sessionRedisCache) Get(ctx context.Context, userID uint64, id string) (*Session, error) {
+	b, err := c.rdb.Get(ctx, sessionKey(userID, id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	session := &Session{}
+	err = json.Unmarshal(b, session)
+	return session, err
+}
+
+// List get the sessions of the set of the user, the expired ids are removed from the set
+func (c *sessionRedisCache) List(ctx context.Context, userID uint64) ([]*Session, error) {
+	indexKey := userSessionsKey(userID)
+	ids, err := c.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions := []*Session{}
+	if len(ids) == 0 {
+		return sessions, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, sessionKey(userID, id))
+	}
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []interface{}{}
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		session := &Session{}
+		if err = json.Unmarshal([]byte(s), session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if len(expired) > 0 {
+		if err = c.rdb.SRem(ctx, indexKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+func (c *sessionRedisCache) Del(ctx context.Context, userID uint64, id string) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.Del(ctx, sessionKey(userID, id))
+	pipe.SRem(ctx, userSessionsKey(userID), id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *sessionRedisCache) DelAll(ctx context.Context, userID uint64) error {
+	indexKey := userSessionsKey(userID)
+	ids, err := c.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{indexKey}
+	for _, id := range ids {
+		keys = append(keys, sessionKey(userID, id))
+	}
+	return c.rdb.Del(ctx, keys...).Err()
+}
+
+type sessionMemoryCache struct {
+	mu    sync.Mutex
+	items map[uint64]map[string]*Session // user id -> session id -> session
+}
+
+func (c *sessionMemoryCache) Set(_ context.Context, session *Session) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().Unix()
+	for userID, sessions := range c.items { // evict the expired sessions
+		for id, s := range sessions {
+			if s.ExpiresAt <= now {
+				delete(sessions, id)
+			}
+		}
+		if len(sessions) == 0 {
+			delete(c.items, userID)
+		}
+	}
+	if session.ExpiresAt <= now {
+		return nil
+	}
+	if c.items[session.UserID] == nil {
+		c.items[session.UserID] = map[string]*Session{}
+	}
+	copied := *session
+	c.items[session.UserID][session.ID] = &copied
+	return nil
+}
+
+func (c *sessionMemoryCache) Get(_ context.Context, userID uint64, id string) (*Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, ok := c.items[userID][id]
+	if !ok || session.ExpiresAt <= time.Now().Unix() {
+		return nil, ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (c *sessionMemoryCache) List(_ context.Context, userID uint64) ([]*Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().Unix()
+	sessions := []*Session{}
+	for _, session := range c.items[userID] {
+		if session.ExpiresAt > now {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+func (c *sessionMemoryCache) Del(_ context.Context, userID uint64, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items[userID], id)
+	return nil
+}
+
+func (c *sessionMemoryCache) DelAll(_ context.Context, userID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, userID)
+	return nil
+}
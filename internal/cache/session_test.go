@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/gotest"
+
+	"go-admin/internal/model"
+)
+
+func testSessionCache(t *testing.T, c SessionCache, userID uint64) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+	require.NoError(t, c.DelAll(ctx, userID))
+
+	_, err := c.Get(ctx, userID, "unknown")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	first := &Session{ID: "a", UserID: userID, IP: "127.0.0.1", UserAgent: "curl", CreatedAt: now - 10, ExpiresAt: now + 3600}
+	second := &Session{ID: "b", UserID: userID, CreatedAt: now, ExpiresAt: now + 60}
+	assert.NoError(t, c.Set(ctx, first))
+	assert.NoError(t, c.Set(ctx, second))
+	assert.NoError(t, c.Set(ctx, &Session{ID: "c", UserID: userID, ExpiresAt: now - 1})) // already expired
+	assert.NoError(t, c.Set(ctx, &Session{ID: "a", UserID: userID + 1, ExpiresAt: now + 60}))
+
+	session, err := c.Get(ctx, userID, "a")
+	require.NoError(t, err)
+	assert.Equal(t, first, session)
+	_, err = c.Get(ctx, userID, "c")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	sessions, err := c.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []*Session{second, first}, sessions)
+
+	assert.NoError(t, c.Del(ctx, userID, "b"))
+	sessions, err = c.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []*Session{first}, sessions)
+
+	// the sessions of the other users are kept
+	assert.NoError(t, c.DelAll(ctx, userID))
+	sessions, err = c.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	_, err = c.Get(ctx, userID+1, "a")
+	assert.NoError(t, err)
+}
+
+func Test_sessionCache_Redis(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	testSessionCache(t, NewSessionCache(&model.CacheType{CType: "redis", Rdb: c.RedisClient}), 1)
+}
+
+func Test_sessionCache_Memory(t *testing.T) {
+	c := NewSessionCache(&model.CacheType{CType: "memory"})
+	testSessionCache(t, c, 1001)
+	// the memory sessions are shared by the caches of the instance
+	assert.Equal(t, c, NewSessionCache(&model.CacheType{CType: "memory"}))
+}
@@ -29,17 +29,26 @@ func Set(conf *Config) {
 }
 
 type Config struct {
+	ApiToken   ApiToken     `yaml:"apiToken" json:"apiToken"`
 	App        App          `yaml:"app" json:"app"`
+	Auth       Auth         `yaml:"auth" json:"auth"`
+	Avatar     Avatar       `yaml:"avatar" json:"avatar"`
+	Bootstrap  Bootstrap    `yaml:"bootstrap" json:"bootstrap"`
 	Consul     Consul       `yaml:"consul" json:"consul"`
 	Database   Database     `yaml:"database" json:"database"`
+	Elevation  Elevation    `yaml:"elevation" json:"elevation"`
 	Etcd       Etcd         `yaml:"etcd" json:"etcd"`
 	Grpc       Grpc         `yaml:"grpc" json:"grpc"`
 	GrpcClient []GrpcClient `yaml:"grpcClient" json:"grpcClient"`
 	HTTP       HTTP         `yaml:"http" json:"http"`
 	Jaeger     Jaeger       `yaml:"jaeger" json:"jaeger"`
+	K8s        K8s          `yaml:"k8s" json:"k8s"`
 	Logger     Logger       `yaml:"logger" json:"logger"`
+	Login      Login        `yaml:"login" json:"login"`
 	NacosRd    NacosRd      `yaml:"nacosRd" json:"nacosRd"`
+	OIDC       OIDC         `yaml:"oidc" json:"oidc"`
 	Redis      Redis        `yaml:"redis" json:"redis"`
+	TwoFactor  TwoFactor    `yaml:"twoFactor" json:"twoFactor"`
 }
 
 type Consul struct {
@@ -76,6 +85,87 @@ type ServerSecure struct {
 	Type     string `yaml:"type" json:"type"`
 }
 
+type Bootstrap struct {
+	AdminEmail string `yaml:"adminEmail" json:"adminEmail"`
+	AdminName  string `yaml:"adminName" json:"adminName"`
+	Enable     bool   `yaml:"enable" json:"enable"`
+	SyncApis   bool   `yaml:"syncApis" json:"syncApis"`
+}
+
+type Avatar struct {
+	Local   AvatarLocal `yaml:"local" json:"local"`
+	MaxSize int         `yaml:"maxSize" json:"maxSize"`
+	S3      AvatarS3    `yaml:"s3" json:"s3"`
+	Size    int         `yaml:"size" json:"size"`
+	Storage string      `yaml:"storage" json:"storage"`
+}
+
+type OIDC struct {
+	AutoCreate   bool        `yaml:"autoCreate" json:"autoCreate"`
+	ClientID     string      `yaml:"clientID" json:"clientID"`
+	ClientSecret string      `yaml:"clientSecret" json:"clientSecret"`
+	Enable       bool        `yaml:"enable" json:"enable"`
+	GroupRoles   []GroupRole `yaml:"groupRoles" json:"groupRoles"`
+	GroupsClaim  string      `yaml:"groupsClaim" json:"groupsClaim"`
+	Issuer       string      `yaml:"issuer" json:"issuer"`
+	LinkByEmail  bool        `yaml:"linkByEmail" json:"linkByEmail"`
+	RedirectURL  string      `yaml:"redirectURL" json:"redirectURL"`
+	Scopes       []string    `yaml:"scopes" json:"scopes"`
+	SuccessURL   string      `yaml:"successURL" json:"successURL"`
+}
+
+type Auth struct {
+	AdminRoles []string       `yaml:"adminRoles" json:"adminRoles"`
+	Providers  []AuthProvider `yaml:"providers" json:"providers"`
+}
+
+type AuthProvider struct {
+	LDAP LDAP   `yaml:"ldap" json:"ldap"`
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+}
+
+type LDAP struct {
+	AutoCreate         bool        `yaml:"autoCreate" json:"autoCreate"`
+	BaseDN             string      `yaml:"baseDN" json:"baseDN"`
+	BindDN             string      `yaml:"bindDN" json:"bindDN"`
+	BindPassword       string      `yaml:"bindPassword" json:"bindPassword"`
+	CaFile             string      `yaml:"caFile" json:"caFile"`
+	EmailAttribute     string      `yaml:"emailAttribute" json:"emailAttribute"`
+	GroupAttribute     string      `yaml:"groupAttribute" json:"groupAttribute"`
+	GroupBaseDN        string      `yaml:"groupBaseDN" json:"groupBaseDN"`
+	GroupFilter        string      `yaml:"groupFilter" json:"groupFilter"`
+	GroupRoles         []GroupRole `yaml:"groupRoles" json:"groupRoles"`
+	InsecureSkipVerify bool        `yaml:"insecureSkipVerify" json:"insecureSkipVerify"`
+	LinkByEmail        bool        `yaml:"linkByEmail" json:"linkByEmail"`
+	LinkByName         bool        `yaml:"linkByName" json:"linkByName"`
+	NameAttribute      string      `yaml:"nameAttribute" json:"nameAttribute"`
+	StartTLS           bool        `yaml:"startTLS" json:"startTLS"`
+	Timeout            int         `yaml:"timeout" json:"timeout"`
+	URL                string      `yaml:"url" json:"url"`
+	UserFilter         string      `yaml:"userFilter" json:"userFilter"`
+}
+
+type GroupRole struct {
+	Group   string `yaml:"group" json:"group"`
+	RoleKey string `yaml:"roleKey" json:"roleKey"`
+}
+
+type AvatarLocal struct {
+	Dir       string `yaml:"dir" json:"dir"`
+	URLPrefix string `yaml:"urlPrefix" json:"urlPrefix"`
+}
+
+type AvatarS3 struct {
+	AccessKey string `yaml:"accessKey" json:"accessKey"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	PublicURL string `yaml:"publicURL" json:"publicURL"`
+	Region    string `yaml:"region" json:"region"`
+	SecretKey string `yaml:"secretKey" json:"secretKey"`
+	UseSSL    bool   `yaml:"useSSL" json:"useSSL"`
+}
+
 type App struct {
 	CacheType             string  `yaml:"cacheType" json:"cacheType"`
 	EnableCircuitBreaker  bool    `yaml:"enableCircuitBreaker" json:"enableCircuitBreaker"`
@@ -137,11 +227,12 @@ type Redis struct {
 }
 
 type Database struct {
-	Driver     string  `yaml:"driver" json:"driver"`
-	Mongodb    Mongodb `yaml:"mongodb" json:"mongodb"`
-	Mysql      Mysql   `yaml:"mysql" json:"mysql"`
-	Postgresql Mysql   `yaml:"postgresql" json:"postgresql"`
-	Sqlite     Sqlite  `yaml:"sqlite" json:"sqlite"`
+	AutoMigrate bool       `yaml:"autoMigrate" json:"autoMigrate"`
+	Driver      string     `yaml:"driver" json:"driver"`
+	Mongodb     Mongodb    `yaml:"mongodb" json:"mongodb"`
+	Mysql       Mysql      `yaml:"mysql" json:"mysql"`
+	Postgresql  Postgresql `yaml:"postgresql" json:"postgresql"`
+	Sqlite      Sqlite     `yaml:"sqlite" json:"sqlite"`
 }
 
 type Mongodb struct {
@@ -167,7 +258,62 @@ type NacosRd struct {
 	Port        int    `yaml:"port" json:"port"`
 }
 
+type Login struct {
+	BlockFailures      int `yaml:"blockFailures" json:"blockFailures"`
+	BlockWindow        int `yaml:"blockWindow" json:"blockWindow"`
+	FailureWindow      int `yaml:"failureWindow" json:"failureWindow"`
+	LockDuration       int `yaml:"lockDuration" json:"lockDuration"`
+	MaxAccountFailures int `yaml:"maxAccountFailures" json:"maxAccountFailures"`
+	MaxIPFailures      int `yaml:"maxIPFailures" json:"maxIPFailures"`
+}
+
+type TwoFactor struct {
+	ChallengeTimeout int    `yaml:"challengeTimeout" json:"challengeTimeout"`
+	Issuer           string `yaml:"issuer" json:"issuer"`
+	MaxAttempts      int    `yaml:"maxAttempts" json:"maxAttempts"`
+	QRCodeSize       int    `yaml:"qrCodeSize" json:"qrCodeSize"`
+	RecoveryCodes    int    `yaml:"recoveryCodes" json:"recoveryCodes"`
+}
+
+type ApiToken struct {
+	DefaultLifetime int `yaml:"defaultLifetime" json:"defaultLifetime"`
+	MaxLifetime     int `yaml:"maxLifetime" json:"maxLifetime"`
+	MaxPerUser      int `yaml:"maxPerUser" json:"maxPerUser"`
+}
+
+type Elevation struct {
+	ApproverRoles []string `yaml:"approverRoles" json:"approverRoles"`
+	MaxDuration   int      `yaml:"maxDuration" json:"maxDuration"`
+	ReapInterval  int      `yaml:"reapInterval" json:"reapInterval"`
+	Roles         []string `yaml:"roles" json:"roles"`
+}
+
+type K8s struct {
+	Kubeconfig         Kubeconfig `yaml:"kubeconfig" json:"kubeconfig"`
+	MaxPortForwards    int        `yaml:"maxPortForwards" json:"maxPortForwards"`
+	PortForwardRoles   []string   `yaml:"portForwardRoles" json:"portForwardRoles"`
+	PortForwardTimeout int        `yaml:"portForwardTimeout" json:"portForwardTimeout"`
+	SecretRevealRoles  []string   `yaml:"secretRevealRoles" json:"secretRevealRoles"`
+	WriteRoles         []string   `yaml:"writeRoles" json:"writeRoles"`
+}
+
+type Kubeconfig struct {
+	ClusterName      string            `yaml:"clusterName" json:"clusterName"`
+	ClusterRole      string            `yaml:"clusterRole" json:"clusterRole"`
+	MaxTokenTTL      int               `yaml:"maxTokenTTL" json:"maxTokenTTL"`
+	Namespace        string            `yaml:"namespace" json:"namespace"`
+	RoleClusterRoles []RoleClusterRole `yaml:"roleClusterRoles" json:"roleClusterRoles"`
+	Server           string            `yaml:"server" json:"server"`
+	TokenTTL         int               `yaml:"tokenTTL" json:"tokenTTL"`
+}
+
+type RoleClusterRole struct {
+	ClusterRole string `yaml:"clusterRole" json:"clusterRole"`
+	RoleKey     string `yaml:"roleKey" json:"roleKey"`
+}
+
 type HTTP struct {
-	Port    int `yaml:"port" json:"port"`
-	Timeout int `yaml:"timeout" json:"timeout"`
+	Port           int      `yaml:"port" json:"port"`
+	Timeout        int      `yaml:"timeout" json:"timeout"`
+	TrustedProxies []string `yaml:"trustedProxies" json:"trustedProxies"`
 }
@@ -0,0 +1,145 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+// the last use of a token is saved at most once per interval, not at every request, unit(second)
+const apiTokenTouchInterval = 60
+
+var _ ApiTokenDao = (*apiTokenDao)(nil)
+
+// ApiTokenDao defining the dao interface of the personal api tokens and their scopes
+type ApiTokenDao interface {
+	Create(ctx context.Context, table *model.ApiToken, apiIDs []uint64) error
+	GetByID(ctx context.Context, id uint64) (*model.ApiToken, error)
+	GetByHash(ctx context.Context, hash string) (*model.ApiToken, error)
+	ListByUserID(ctx context.Context, userID uint64) ([]*model.ApiToken, error)
+	CountActive(ctx context.Context, userID uint64, now int64) (int64, error)
+	GetScopes(ctx context.Context, tokenIDs []uint64) (map[uint64][]*model.Api, error)
+	Revoke(ctx context.Context, id uint64, revokedBy uint64, now int64) (bool, error)
+	Touch(ctx context.Context, id uint64, ip string, now int64) error
+}
+
+type apiTokenDao struct {
+	db *gorm.DB
+}
+
+// NewApiTokenDao creating the dao interface
+func NewApiTokenDao(db *gorm.DB) ApiTokenDao {
+	return &apiTokenDao{db: db}
+}
+
+// Create create a token with the apis it may call
+func (d *apiTokenDao) Create(ctx context.Context, table *model.ApiToken, apiIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(table).Error; err != nil {
+			return err
+		}
+		scopes := make([]*model.ApiTokenScope, 0, len(apiIDs))
+		for _, apiID := range apiIDs {
+			scopes = append(scopes, &model.ApiTokenScope{TokenID: table.ID, ApiID: apiID})
+		}
+		if len(scopes) == 0 {
+			return nil
+		}
+		return tx.Create(scopes).Error
+	})
+}
+
+// GetByID get a token by id
+func (d *apiTokenDao) GetByID(ctx context.Context, id uint64) (*model.ApiToken, error) {
+	table := &model.ApiToken{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// GetByHash get a token by the hash of the token, it may be revoked or expired
+func (d *apiTokenDao) GetByHash(ctx context.Context, hash string) (*model.ApiToken, error) {
+	table := &model.ApiToken{}
+	err := d.db.WithContext(ctx).Where("token_hash = ?", hash).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// ListByUserID list all the tokens of the user, the latest first
+func (d *apiTokenDao) ListByUserID(ctx context.Context, userID uint64) ([]*model.ApiToken, error) {
+	records := []*model.ApiToken{}
+	err := d.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CountActive count the tokens of the user that are neither revoked nor expired at the unix time
+func (d *apiTokenDao) CountActive(ctx context.Context, userID uint64, now int64) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("user_id = ? AND revoked_at = ? AND (expires_at = ? OR expires_at > ?)", userID, 0, 0, now).
+		Count(&count).Error
+	return count, err
+}
+
+// GetScopes get the apis the tokens may call, the key is the token id, the deleted apis are left out
+func (d *apiTokenDao) GetScopes(ctx context.Context, tokenIDs []uint64) (map[uint64][]*model.Api, error) {
+	scopes := map[uint64][]*model.Api{}
+	if len(tokenIDs) == 0 {
+		return scopes, nil
+	}
+
+	var rows []*model.ApiTokenScope
+	err := d.db.WithContext(ctx).Where("token_id IN (?)", tokenIDs).Order("id ASC").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	apiIDs := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		apiIDs = append(apiIDs, row.ApiID)
+	}
+	var apis []*model.Api
+	if len(apiIDs) > 0 {
+		err = d.db.WithContext(ctx).Where("id IN (?)", apiIDs).Find(&apis).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	apiMap := make(map[uint64]*model.Api, len(apis))
+	for _, api := range apis {
+		apiMap[api.ID] = api
+	}
+
+	for _, row := range rows {
+		if api, ok := apiMap[row.ApiID]; ok {
+			scopes[row.TokenID] = append(scopes[row.TokenID], api)
+		}
+	}
+	return scopes, nil
+}
+
+// Revoke revoke the token, false if it is already revoked
+func (d *apiTokenDao) Revoke(ctx context.Context, id uint64, revokedBy uint64, now int64) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("id = ? AND revoked_at = ?", id, 0).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_by": revokedBy})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Touch save the last use of the token, it is skipped if it was saved within apiTokenTouchInterval
+func (d *apiTokenDao) Touch(ctx context.Context, id uint64, ip string, now int64) error {
+	return d.db.WithContext(ctx).Model(&model.ApiToken{}).
+		Where("id = ? AND last_used_at <= ?", id, now-apiTokenTouchInterval).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
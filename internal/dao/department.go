@@ -0,0 +1,243 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/datascope"
+	"go-admin/internal/model"
+)
+
+var _ DepartmentDao = (*departmentDao)(nil)
+
+// DepartmentDao defining the dao interface of the department tree, the memberships of the users
+// and the departments of the custom data scopes of the roles
+type DepartmentDao interface {
+	Create(ctx context.Context, table *model.Department) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.Department) error
+	GetByID(ctx context.Context, id uint64) (*model.Department, error)
+	GetAll(ctx context.Context) ([]*model.Department, error)
+	CountByIDs(ctx context.Context, ids []uint64) (int64, error)
+	GetDeptIDsByUserID(ctx context.Context, userID uint64) ([]uint64, error)
+	SetUserDepartments(ctx context.Context, userID uint64, deptIDs []uint64) error
+	GetDeptIDsByRoleID(ctx context.Context, roleID uint64) ([]uint64, error)
+	SetRoleDepartments(ctx context.Context, roleID uint64, deptIDs []uint64) error
+	GetDataScope(ctx context.Context, userID uint64) (*datascope.Scope, error)
+}
+
+type departmentDao struct {
+	db *gorm.DB
+}
+
+// NewDepartmentDao creating the dao interface
+func NewDepartmentDao(db *gorm.DB) DepartmentDao {
+	return &departmentDao{db: db}
+}
+
+// Create a department under its parent, model.ErrDepartmentParent if the parent does not exist
+func (d *departmentDao) Create(ctx context.Context, table *model.Department) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkDepartmentParent(tx, 0, table.ParentID); err != nil {
+			return err
+		}
+		return tx.Create(table).Error
+	})
+}
+
+// DeleteByID delete a department with its members and its grants to the roles,
+// model.ErrDepartmentHasChildren if it has sub departments
+func (d *departmentDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		err := tx.Model(&model.Department{}).Where("parent_id = ?", id).Count(&children).Error
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return model.ErrDepartmentHasChildren
+		}
+		err = tx.Unscoped().Where("dept_id = ?", id).Delete(&model.UserDepartment{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Where("dept_id = ?", id).Delete(&model.RoleDepartment{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", id).Delete(&model.Department{}).Error
+	})
+}
+
+// UpdateByID replace all the fields of a department, the department can be moved under another parent but not under itself
+func (d *departmentDao) UpdateByID(ctx context.Context, table *model.Department) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkDepartmentParent(tx, table.ID, table.ParentID); err != nil {
+			return err
+		}
+		result := tx.Model(&model.Department{}).Where("id = ?", table.ID).
+			Select("parent_id", "name", "sort", "namespaces", "remark").
+			Updates(table)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// checkDepartmentParent walk up from the parent to the top level, the department id must not be met on the way
+func checkDepartmentParent(tx *gorm.DB, id uint64, parentID uint64) error {
+	seen := map[uint64]bool{}
+	for current := parentID; current != 0; {
+		if current == id || seen[current] {
+			return model.ErrDepartmentParent
+		}
+		seen[current] = true
+		parent := &model.Department{}
+		err := tx.Select("id", "parent_id").Where("id = ?", current).First(parent).Error
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				return model.ErrDepartmentParent
+			}
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+// GetByID get a department by id
+func (d *departmentDao) GetByID(ctx context.Context, id uint64) (*model.Department, error) {
+	table := &model.Department{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// GetAll get all the departments, sorted by sort and id
+func (d *departmentDao) GetAll(ctx context.Context) ([]*model.Department, error) {
+	records := []*model.Department{}
+	err := d.db.WithContext(ctx).Order("sort ASC, id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CountByIDs count the existing departments of the ids
+func (d *departmentDao) CountByIDs(ctx context.Context, ids []uint64) (int64, error) {
+	var count int64
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := d.db.WithContext(ctx).Model(&model.Department{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+// GetDeptIDsByUserID get the ids of the departments the user is a member of
+func (d *departmentDao) GetDeptIDsByUserID(ctx context.Context, userID uint64) ([]uint64, error) {
+	ids := []uint64{}
+	err := d.db.WithContext(ctx).Model(&model.UserDepartment{}).Where("user_id = ?", userID).Order("dept_id ASC").Pluck("dept_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetUserDepartments replace the departments the user is a member of
+func (d *departmentDao) SetUserDepartments(ctx context.Context, userID uint64, deptIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserDepartment{}).Error
+		if err != nil {
+			return err
+		}
+		members := make([]*model.UserDepartment, 0, len(deptIDs))
+		for _, deptID := range deptIDs {
+			members = append(members, &model.UserDepartment{UserID: userID, DeptID: deptID})
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return tx.Create(members).Error
+	})
+}
+
+// GetDeptIDsByRoleID get the ids of the departments of the custom data scope of the role
+func (d *departmentDao) GetDeptIDsByRoleID(ctx context.Context, roleID uint64) ([]uint64, error) {
+	ids := []uint64{}
+	err := d.db.WithContext(ctx).Model(&model.RoleDepartment{}).Where("role_id = ?", roleID).Order("dept_id ASC").Pluck("dept_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetRoleDepartments replace the departments of the custom data scope of the role
+func (d *departmentDao) SetRoleDepartments(ctx context.Context, roleID uint64, deptIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&model.RoleDepartment{}).Error
+		if err != nil {
+			return err
+		}
+		grants := make([]*model.RoleDepartment, 0, len(deptIDs))
+		for _, deptID := range deptIDs {
+			grants = append(grants, &model.RoleDepartment{RoleID: roleID, DeptID: deptID})
+		}
+		if len(grants) == 0 {
+			return nil
+		}
+		return tx.Create(grants).Error
+	})
+}
+
+// GetDataScope compute the data scope of the user from its roles and its departments
+func (d *departmentDao) GetDataScope(ctx context.Context, userID uint64) (*datascope.Scope, error) {
+	db := d.db.WithContext(ctx)
+	grants, err := getInheritedRolesByTx(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]*model.Role, 0, len(grants))
+	for _, grant := range grants {
+		roles = append(roles, grant.Role)
+	}
+
+	userDeptIDs, err := d.GetDeptIDsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleDeptIDs := map[uint64][]uint64{}
+	var customRoleIDs []uint64
+	for _, role := range roles {
+		if role.DataScopeMode == model.DataScopeModeCustom {
+			customRoleIDs = append(customRoleIDs, role.ID)
+		}
+	}
+	if len(customRoleIDs) > 0 {
+		grants := []*model.RoleDepartment{}
+		err = db.Where("role_id IN ?", customRoleIDs).Find(&grants).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, grant := range grants {
+			roleDeptIDs[grant.RoleID] = append(roleDeptIDs[grant.RoleID], grant.DeptID)
+		}
+	}
+
+	depts, err := d.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datascope.New(userID, roles, userDeptIDs, roleDeptIDs, depts), nil
+}
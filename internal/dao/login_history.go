@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
+
+	"go-admin/internal/model"
+)
+
+var _ LoginHistoryDao = (*loginHistoryDao)(nil)
+
+// LoginHistoryDao defining the dao interface
+type LoginHistoryDao interface {
+	Create(ctx context.Context, table *model.LoginHistory) error
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoginHistory, int64, error)
+	GetByUserID(ctx context.Context, userID uint64, params *query.Params) ([]*model.LoginHistory, int64, error)
+}
+
+type loginHistoryDao struct {
+	db *gorm.DB
+}
+
+// NewLoginHistoryDao creating the dao interface
+func NewLoginHistoryDao(db *gorm.DB) LoginHistoryDao {
+	return &loginHistoryDao{db: db}
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *loginHistoryDao) Create(ctx context.Context, table *model.LoginHistory) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// GetByColumns get paging records by column information, the params are the same as userDao.GetByColumns
+func (d *loginHistoryDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoginHistory, int64, error) {
+	return d.getByColumns(d.db.WithContext(ctx), params)
+}
+
+// GetByUserID get paging records of the user by column information, the columns cannot select other users
+func (d *loginHistoryDao) GetByUserID(ctx context.Context, userID uint64, params *query.Params) ([]*model.LoginHistory, int64, error) {
+	return d.getByColumns(d.db.WithContext(ctx).Where("user_id = ?", userID), params)
+}
+
+func (d *loginHistoryDao) getByColumns(db *gorm.DB, params *query.Params) ([]*model.LoginHistory, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions()
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = db.Session(&gorm.Session{}).Model(&model.LoginHistory{}).Select([]string{"id"}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.LoginHistory{}
+	order, limit, offset := params.ConvertToPage()
+	err = db.Session(&gorm.Session{}).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+var _ MenuDao = (*menuDao)(nil)
+
+// MenuDao defining the dao interface of the menu tree and the menus granted to the roles
+type MenuDao interface {
+	Create(ctx context.Context, table *model.Menu) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.Menu) error
+	GetByID(ctx context.Context, id uint64) (*model.Menu, error)
+	GetAll(ctx context.Context) ([]*model.Menu, error)
+	GetByRoleIDs(ctx context.Context, roleIDs []uint64) ([]*model.Menu, error)
+	GetMenuIDsByRoleID(ctx context.Context, roleID uint64) ([]uint64, error)
+	SetRoleMenus(ctx context.Context, roleID uint64, menuIDs []uint64) error
+}
+
+type menuDao struct {
+	db *gorm.DB
+}
+
+// NewMenuDao creating the dao interface
+func NewMenuDao(db *gorm.DB) MenuDao {
+	return &menuDao{db: db}
+}
+
+// Create a menu under its parent, model.ErrMenuParent if the parent is not a directory or a menu
+func (d *menuDao) Create(ctx context.Context, table *model.Menu) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkMenuParent(tx, 0, table.ParentID); err != nil {
+			return err
+		}
+		return tx.Create(table).Error
+	})
+}
+
+// DeleteByID delete a menu and its grants to the roles, model.ErrMenuHasChildren if it has children
+func (d *menuDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		err := tx.Model(&model.Menu{}).Where("parent_id = ?", id).Count(&children).Error
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return model.ErrMenuHasChildren
+		}
+		err = tx.Unscoped().Where("menu_id = ?", id).Delete(&model.RoleMenu{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", id).Delete(&model.Menu{}).Error
+	})
+}
+
+// UpdateByID replace all the fields of a menu, the menu can be moved under another parent but not under itself
+func (d *menuDao) UpdateByID(ctx context.Context, table *model.Menu) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkMenuParent(tx, table.ID, table.ParentID); err != nil {
+			return err
+		}
+		result := tx.Model(&model.Menu{}).Where("id = ?", table.ID).
+			Select("parent_id", "type", "title", "name", "path", "component", "icon", "permission", "sort", "hidden").
+			Updates(table)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// checkMenuParent walk up from the parent to the top level, the menu id must not be met on the way
+func checkMenuParent(tx *gorm.DB, id uint64, parentID uint64) error {
+	seen := map[uint64]bool{}
+	for current := parentID; current != 0; {
+		if current == id || seen[current] {
+			return model.ErrMenuParent
+		}
+		seen[current] = true
+		parent := &model.Menu{}
+		err := tx.Select("id", "parent_id", "type").Where("id = ?", current).First(parent).Error
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) {
+				return model.ErrMenuParent
+			}
+			return err
+		}
+		if current == parentID && parent.Type == model.MenuTypeButton {
+			return model.ErrMenuParent
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+// GetByID get a menu by id
+func (d *menuDao) GetByID(ctx context.Context, id uint64) (*model.Menu, error) {
+	table := &model.Menu{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// GetAll get all the menus, sorted by sort and id
+func (d *menuDao) GetAll(ctx context.Context) ([]*model.Menu, error) {
+	records := []*model.Menu{}
+	err := d.db.WithContext(ctx).Order("sort ASC, id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetByRoleIDs get the menus granted to any of the roles, sorted by sort and id
+func (d *menuDao) GetByRoleIDs(ctx context.Context, roleIDs []uint64) ([]*model.Menu, error) {
+	records := []*model.Menu{}
+	if len(roleIDs) == 0 {
+		return records, nil
+	}
+	err := d.db.WithContext(ctx).
+		Where("id IN (?)", d.db.Model(&model.RoleMenu{}).Select("menu_id").Where("role_id IN ?", roleIDs)).
+		Order("sort ASC, id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetMenuIDsByRoleID get the ids of the menus granted to the role
+func (d *menuDao) GetMenuIDsByRoleID(ctx context.Context, roleID uint64) ([]uint64, error) {
+	ids := []uint64{}
+	err := d.db.WithContext(ctx).Model(&model.RoleMenu{}).Where("role_id = ?", roleID).Order("menu_id ASC").Pluck("menu_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetRoleMenus replace the menus granted to the role
+func (d *menuDao) SetRoleMenus(ctx context.Context, roleID uint64, menuIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&model.RoleMenu{}).Error
+		if err != nil {
+			return err
+		}
+		grants := make([]*model.RoleMenu, 0, len(menuIDs))
+		for _, menuID := range menuIDs {
+			grants = append(grants, &model.RoleMenu{RoleID: roleID, MenuID: menuID})
+		}
+		if len(grants) == 0 {
+			return nil
+		}
+		return tx.Create(grants).Error
+	})
+}
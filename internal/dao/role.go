@@ -22,18 +22,23 @@ var _ RoleDao = (*roleDao)(nil)
 // RoleDao defining the dao interface
 type RoleDao interface {
 	Create(ctx context.Context, table *model.Role) error
-	DeleteByID(ctx context.Context, id uint64) error
-	DeleteByIDs(ctx context.Context, ids []uint64) error
+	DeleteByID(ctx context.Context, id uint64, deletedBy uint64) error
+	DeleteByIDs(ctx context.Context, ids []uint64, deletedBy uint64) error
 	UpdateByID(ctx context.Context, table *model.Role) error
+	PatchByID(ctx context.Context, id uint64, version uint64, columns map[string]interface{}) error
 	GetByID(ctx context.Context, id uint64) (*model.Role, error)
 	GetByCondition(ctx context.Context, condition *query.Conditions) (*model.Role, error)
 	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Role, error)
 	GetByLastID(ctx context.Context, lastID uint64, limit int, sort string) ([]*model.Role, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Role, int64, error)
+	GetTrash(ctx context.Context, params *query.Params) ([]*model.Role, int64, error)
+	Restore(ctx context.Context, id uint64) error
+	Purge(ctx context.Context, id uint64) error
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Role) (uint64, error)
-	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64, deletedBy uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Role) error
+	GetByConditionByTx(ctx context.Context, tx *gorm.DB, condition *query.Conditions) (*model.Role, error)
 }
 
 type roleDao struct {
@@ -66,9 +71,13 @@ func (d *roleDao) Create(ctx context.Context, table *model.Role) error {
 	return d.db.WithContext(ctx).Create(table).Error
 }
 
-// DeleteByID delete a record by id
-func (d *roleDao) DeleteByID(ctx context.Context, id uint64) error {
-	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Role{}).Error
+// DeleteByID soft delete a record by id, the record stays in the trash until it is restored or purged
+func (d *roleDao) DeleteByID(ctx context.Context, id uint64, deletedBy uint64) error {
+	update := map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}
+	err := d.db.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).Updates(update).Error
 	if err != nil {
 		return err
 	}
@@ -79,9 +88,13 @@ func (d *roleDao) DeleteByID(ctx context.Context, id uint64) error {
 	return nil
 }
 
-// DeleteByIDs delete records by batch id
-func (d *roleDao) DeleteByIDs(ctx context.Context, ids []uint64) error {
-	err := d.db.WithContext(ctx).Where("id IN (?)", ids).Delete(&model.Role{}).Error
+// DeleteByIDs soft delete records by batch id
+func (d *roleDao) DeleteByIDs(ctx context.Context, ids []uint64, deletedBy uint64) error {
+	update := map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}
+	err := d.db.WithContext(ctx).Model(&model.Role{}).Where("id IN (?)", ids).Updates(update).Error
 	if err != nil {
 		return err
 	}
@@ -104,6 +117,24 @@ func (d *roleDao) UpdateByID(ctx context.Context, table *model.Role) error {
 	return err
 }
 
+// PatchByID update the columns of a record by id, unlike UpdateByID the zero values are written too,
+// the version is checked as in UpdateByID
+func (d *roleDao) PatchByID(ctx context.Context, id uint64, version uint64, columns map[string]interface{}) error {
+	if id < 1 {
+		return errors.New("id cannot be 0")
+	}
+	if len(columns) == 0 {
+		return errors.New("no columns to update")
+	}
+
+	err := d.updateColumns(ctx, d.db, id, version, columns)
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return err
+}
+
 func (d *roleDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.Role) error {
 	if table.ID < 1 {
 		return errors.New("id cannot be 0")
@@ -138,6 +169,12 @@ func (d *roleDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 	if table.DataScope != "" {
 		update["data_scope"] = table.DataScope
 	}
+	if table.DataScopeMode != "" {
+		update["data_scope_mode"] = table.DataScopeMode
+	}
+	if table.RequireTwoFactor { // it is turned off by a patch
+		update["require_two_factor"] = table.RequireTwoFactor
+	}
 	if table.CreateBy != 0 {
 		update["create_by"] = table.CreateBy
 	}
@@ -145,7 +182,35 @@ func (d *roleDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 		update["update_by"] = table.UpdateBy
 	}
 
-	return db.WithContext(ctx).Model(table).Updates(update).Error
+	return d.updateColumns(ctx, db, table.ID, table.Version, update)
+}
+
+// updateColumns update the columns of a record, every update increments the version,
+// if version is not 0 the record is only updated if it still has that version
+func (d *roleDao) updateColumns(ctx context.Context, db *gorm.DB, id uint64, version uint64, update map[string]interface{}) error {
+	update["version"] = gorm.Expr("version + 1")
+	if version == 0 {
+		return db.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).Updates(update).Error
+	}
+
+	result := db.WithContext(ctx).Model(&model.Role{}).Where("id = ? AND version = ?", id, version).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// either the record does not exist or its version has changed
+		var count int64
+		err := db.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return model.ErrRecordNotFound
+		}
+		return model.ErrVersionConflict
+	}
+
+	return nil
 }
 
 // GetByID get a record by id
@@ -379,16 +444,108 @@ func (d *roleDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	return records, total, err
 }
 
+// GetTrash get paging soft deleted records by column information, the params are the same as GetByColumns
+func (d *roleDao) GetTrash(ctx context.Context, params *query.Params) ([]*model.Role, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions()
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	db := d.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if queryStr != "" {
+		db = db.Where(queryStr, args...)
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = db.Session(&gorm.Session{}).Model(&model.Role{}).Select([]string{"id"}).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.Role{}
+	order, limit, offset := params.ConvertToPage()
+	err = db.Order(order).Limit(limit).Offset(offset).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// Restore a soft deleted record, it returns model.ErrRecordConflict if an undeleted role has the same role key
+func (d *roleDao) Restore(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		record := &model.Role{}
+		err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&model.Role{}).Where("role_key = ?", record.RoleKey).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return model.ErrRecordConflict
+		}
+
+		update := map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": 0,
+		}
+		return tx.Unscoped().Model(record).Updates(update).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache, it may hold the not found placeholder
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// Purge permanently delete a soft deleted record, the records that are not in the trash can not be purged
+func (d *roleDao) Purge(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Role{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrRecordNotFound
+		}
+
+		// unbind the role from the users
+		return tx.Unscoped().Where("role_id = ?", id).Delete(&model.UserRole{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *roleDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Role) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
 	return table.ID, err
 }
 
-// DeleteByTx delete a record by id in the database using the provided transaction
-func (d *roleDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+// DeleteByTx soft delete a record by id in the database using the provided transaction
+func (d *roleDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64, deletedBy uint64) error {
 	update := map[string]interface{}{
 		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
 	}
 	err := tx.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).Updates(update).Error
 	if err != nil {
@@ -410,3 +567,20 @@ func (d *roleDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Role
 
 	return err
 }
+
+// GetByConditionByTx get a record by condition in the database using the provided transaction,
+// the query conditions are the same as GetByCondition
+func (d *roleDao) GetByConditionByTx(ctx context.Context, tx *gorm.DB, c *query.Conditions) (*model.Role, error) {
+	queryStr, args, err := c.ConvertToGorm()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &model.Role{}
+	err = tx.WithContext(ctx).Where(queryStr, args...).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
@@ -0,0 +1,264 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+var _ RoleElevationDao = (*roleElevationDao)(nil)
+
+// RoleElevationDao defining the dao interface of the temporary roles requested by the users,
+// every step is recorded as an event in the same transaction
+type RoleElevationDao interface {
+	Create(ctx context.Context, table *model.RoleElevation, now int64) error
+	GetByID(ctx context.Context, id uint64) (*model.RoleElevation, error)
+	List(ctx context.Context, userID uint64, status string) ([]*model.RoleElevation, error)
+	GetEvents(ctx context.Context, id uint64) ([]*model.RoleElevationEvent, error)
+	GetActiveByUserID(ctx context.Context, userID uint64, now int64) ([]*model.RoleElevation, error)
+	Approve(ctx context.Context, id uint64, reviewerID uint64, comment string, now int64) (*model.RoleElevation, error)
+	Reject(ctx context.Context, id uint64, reviewerID uint64, comment string, now int64) (*model.RoleElevation, error)
+	Cancel(ctx context.Context, id uint64, userID uint64) (*model.RoleElevation, error)
+	Revoke(ctx context.Context, id uint64, actorID uint64, comment string, now int64) (*model.RoleElevation, error)
+	ExpireDue(ctx context.Context, now int64) ([]*model.RoleElevation, error)
+	RecordUse(ctx context.Context, elevations []*model.RoleElevation, userID uint64, detail string) error
+}
+
+type roleElevationDao struct {
+	db *gorm.DB
+}
+
+// NewRoleElevationDao creating the dao interface
+func NewRoleElevationDao(db *gorm.DB) RoleElevationDao {
+	return &roleElevationDao{db: db}
+}
+
+// Create a pending request with its request event, model.ErrRecordConflict if the user already has a pending
+// request or an active grant of the role
+func (d *roleElevationDao) Create(ctx context.Context, table *model.RoleElevation, now int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&model.RoleElevation{}).
+			Where("user_id = ? AND role_id = ?", table.UserID, table.RoleID).
+			Where("status = ? OR (status = ? AND expires_at > ?)", model.ElevationStatusPending, model.ElevationStatusApproved, now).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return model.ErrRecordConflict
+		}
+
+		table.Status = model.ElevationStatusPending
+		err = tx.Create(table).Error
+		if err != nil {
+			return err
+		}
+		return recordElevationEvent(tx, table.ID, table.UserID, model.ElevationActionRequest, table.Reason)
+	})
+}
+
+// GetByID get a request
+func (d *roleElevationDao) GetByID(ctx context.Context, id uint64) (*model.RoleElevation, error) {
+	record := &model.RoleElevation{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List the requests of the user, or of all the users if userID is 0, with the status if it is not empty,
+// the latest first
+func (d *roleElevationDao) List(ctx context.Context, userID uint64, status string) ([]*model.RoleElevation, error) {
+	db := d.db.WithContext(ctx)
+	if userID != 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	records := []*model.RoleElevation{}
+	err := db.Order("id DESC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetEvents get the steps of a request in order
+func (d *roleElevationDao) GetEvents(ctx context.Context, id uint64) ([]*model.RoleElevationEvent, error) {
+	records := []*model.RoleElevationEvent{}
+	err := d.db.WithContext(ctx).Where("elevation_id = ?", id).Order("id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetActiveByUserID get the grants of the user active at the unix time
+func (d *roleElevationDao) GetActiveByUserID(ctx context.Context, userID uint64, now int64) ([]*model.RoleElevation, error) {
+	records := []*model.RoleElevation{}
+	err := d.db.WithContext(ctx).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, model.ElevationStatusApproved, now).
+		Order("id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Approve a pending request, the role is held for its duration from now
+func (d *roleElevationDao) Approve(ctx context.Context, id uint64, reviewerID uint64, comment string, now int64) (*model.RoleElevation, error) {
+	return d.transition(ctx, id, model.ElevationStatusPending, func(record *model.RoleElevation) map[string]interface{} {
+		return map[string]interface{}{
+			"status":         model.ElevationStatusApproved,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    now,
+			"expires_at":     now + int64(record.Duration),
+		}
+	}, reviewerID, model.ElevationActionApprove, comment)
+}
+
+// Reject a pending request
+func (d *roleElevationDao) Reject(ctx context.Context, id uint64, reviewerID uint64, comment string, now int64) (*model.RoleElevation, error) {
+	return d.transition(ctx, id, model.ElevationStatusPending, func(*model.RoleElevation) map[string]interface{} {
+		return map[string]interface{}{
+			"status":         model.ElevationStatusRejected,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    now,
+		}
+	}, reviewerID, model.ElevationActionReject, comment)
+}
+
+// Cancel a pending request of the user
+func (d *roleElevationDao) Cancel(ctx context.Context, id uint64, userID uint64) (*model.RoleElevation, error) {
+	return d.transition(ctx, id, model.ElevationStatusPending, func(*model.RoleElevation) map[string]interface{} {
+		return map[string]interface{}{"status": model.ElevationStatusCancelled}
+	}, userID, model.ElevationActionCancel, "")
+}
+
+// Revoke an active grant before its expiry
+func (d *roleElevationDao) Revoke(ctx context.Context, id uint64, actorID uint64, comment string, now int64) (*model.RoleElevation, error) {
+	return d.transition(ctx, id, model.ElevationStatusApproved, func(record *model.RoleElevation) map[string]interface{} {
+		if record.ExpiresAt <= now { // left to the expiry
+			return nil
+		}
+		return map[string]interface{}{
+			"status":   model.ElevationStatusRevoked,
+			"ended_at": now,
+			"ended_by": actorID,
+		}
+	}, actorID, model.ElevationActionRevoke, comment)
+}
+
+// ExpireDue end the grants whose expiry is past at the unix time, they are no longer held since their expiry,
+// the expired grants are returned
+func (d *roleElevationDao) ExpireDue(ctx context.Context, now int64) ([]*model.RoleElevation, error) {
+	due := []*model.RoleElevation{}
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", model.ElevationStatusApproved, now).
+		Order("id ASC").Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]*model.RoleElevation, 0, len(due))
+	for _, record := range due {
+		updated, err := d.transition(ctx, record.ID, model.ElevationStatusApproved, func(record *model.RoleElevation) map[string]interface{} {
+			return map[string]interface{}{
+				"status":   model.ElevationStatusExpired,
+				"ended_at": record.ExpiresAt,
+			}
+		}, 0, model.ElevationActionExpire, "")
+		if errors.Is(err, model.ErrElevationStatus) { // revoked meanwhile
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired = append(expired, updated)
+	}
+	return expired, nil
+}
+
+// RecordUse record a request made by the user while holding the roles of the grants
+func (d *roleElevationDao) RecordUse(ctx context.Context, elevations []*model.RoleElevation, userID uint64, detail string) error {
+	records := make([]*model.RoleElevationEvent, 0, len(elevations))
+	for _, elevation := range elevations {
+		records = append(records, &model.RoleElevationEvent{
+			ElevationID: elevation.ID,
+			ActorID:     userID,
+			Action:      model.ElevationActionUse,
+			Detail:      detail,
+		})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return d.db.WithContext(ctx).Create(records).Error
+}
+
+// transition change the status of a request from the status with the updates and record the step,
+// model.ErrElevationStatus if the request is not in the status or if updates returns nil
+func (d *roleElevationDao) transition(ctx context.Context, id uint64, from string, updates func(*model.RoleElevation) map[string]interface{},
+	actorID uint64, action string, detail string) (*model.RoleElevation, error) {
+	record := &model.RoleElevation{}
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", id).First(record).Error
+		if err != nil {
+			return err
+		}
+		if record.Status != from {
+			return model.ErrElevationStatus
+		}
+		columns := updates(record)
+		if columns == nil {
+			return model.ErrElevationStatus
+		}
+
+		// the status condition keeps a concurrent step from being applied twice
+		result := tx.Model(&model.RoleElevation{}).Where("id = ? AND status = ?", id, from).Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrElevationStatus
+		}
+		err = recordElevationEvent(tx, id, actorID, action, detail)
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func recordElevationEvent(tx *gorm.DB, elevationID uint64, actorID uint64, action string, detail string) error {
+	return tx.Create(&model.RoleElevationEvent{
+		ElevationID: elevationID,
+		ActorID:     actorID,
+		Action:      action,
+		Detail:      detail,
+	}).Error
+}
+
+// getElevatedRoleIDsByTx the ids of the roles the user holds through the grants active at the unix time
+func getElevatedRoleIDsByTx(tx *gorm.DB, userID uint64, now int64) ([]uint64, error) {
+	ids := []uint64{}
+	err := tx.Model(&model.RoleElevation{}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, model.ElevationStatusApproved, now).
+		Pluck("role_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,230 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+	"go-admin/internal/permission"
+)
+
+var _ RolePermissionDao = (*rolePermissionDao)(nil)
+
+// RolePermissionDao defining the dao interface of the parents the roles inherit, the apis granted to the roles
+// and the effective permissions of the users
+type RolePermissionDao interface {
+	GetParentIDs(ctx context.Context, roleID uint64) ([]uint64, error)
+	SetParents(ctx context.Context, roleID uint64, parentIDs []uint64) error
+	GetApiIDs(ctx context.Context, roleID uint64) ([]uint64, error)
+	SetApis(ctx context.Context, roleID uint64, apiIDs []uint64) error
+	GetPermissions(ctx context.Context, userID uint64) (*permission.Set, error)
+}
+
+type rolePermissionDao struct {
+	db *gorm.DB
+}
+
+// NewRolePermissionDao creating the dao interface
+func NewRolePermissionDao(db *gorm.DB) RolePermissionDao {
+	return &rolePermissionDao{db: db}
+}
+
+// GetParentIDs get the ids of the roles the role inherits directly
+func (d *rolePermissionDao) GetParentIDs(ctx context.Context, roleID uint64) ([]uint64, error) {
+	ids := []uint64{}
+	err := d.db.WithContext(ctx).Model(&model.RoleParent{}).Where("role_id = ?", roleID).Order("id ASC").Pluck("parent_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetParents replace the parents of the role, model.ErrRecordNotFound if a parent does not exist and
+// model.ErrRoleCycle if the role would inherit itself
+func (d *rolePermissionDao) SetParents(ctx context.Context, roleID uint64, parentIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(parentIDs) > 0 {
+			var count int64
+			err := tx.Model(&model.Role{}).Where("id IN ?", parentIDs).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count != int64(len(parentIDs)) {
+				return model.ErrRecordNotFound
+			}
+		}
+
+		parents, err := getRoleParentsByTx(tx)
+		if err != nil {
+			return err
+		}
+		for _, parentID := range parentIDs {
+			if permission.Ancestors(parentID, parents)[roleID] {
+				return model.ErrRoleCycle
+			}
+		}
+
+		err = tx.Unscoped().Where("role_id = ?", roleID).Delete(&model.RoleParent{}).Error
+		if err != nil {
+			return err
+		}
+		records := make([]*model.RoleParent, 0, len(parentIDs))
+		for _, parentID := range parentIDs {
+			records = append(records, &model.RoleParent{RoleID: roleID, ParentID: parentID})
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(records).Error
+	})
+}
+
+// GetApiIDs get the ids of the apis granted to the role directly
+func (d *rolePermissionDao) GetApiIDs(ctx context.Context, roleID uint64) ([]uint64, error) {
+	ids := []uint64{}
+	err := d.db.WithContext(ctx).Model(&model.RoleApi{}).Where("role_id = ?", roleID).Order("api_id ASC").Pluck("api_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetApis replace the apis granted to the role, model.ErrRecordNotFound if an api does not exist
+func (d *rolePermissionDao) SetApis(ctx context.Context, roleID uint64, apiIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(apiIDs) > 0 {
+			var count int64
+			err := tx.Model(&model.Api{}).Where("id IN ?", apiIDs).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count != int64(len(apiIDs)) {
+				return model.ErrRecordNotFound
+			}
+		}
+
+		err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&model.RoleApi{}).Error
+		if err != nil {
+			return err
+		}
+		records := make([]*model.RoleApi, 0, len(apiIDs))
+		for _, apiID := range apiIDs {
+			records = append(records, &model.RoleApi{RoleID: roleID, ApiID: apiID})
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(records).Error
+	})
+}
+
+// GetPermissions get the roles of the user with the roles they inherit, and the apis and the namespaces they grant
+func (d *rolePermissionDao) GetPermissions(ctx context.Context, userID uint64) (*permission.Set, error) {
+	db := d.db.WithContext(ctx)
+	grants, err := getInheritedRolesByTx(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uint64, 0, len(grants))
+	for _, grant := range grants {
+		roleIDs = append(roleIDs, grant.Role.ID)
+	}
+	apis := map[uint64][]*model.Api{}
+	if len(roleIDs) > 0 {
+		records := []*model.RoleApi{}
+		err = db.Where("role_id IN ?", roleIDs).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		apiIDs := make([]uint64, 0, len(records))
+		for _, record := range records {
+			apiIDs = append(apiIDs, record.ApiID)
+		}
+		byID := map[uint64]*model.Api{}
+		if len(apiIDs) > 0 {
+			list := []*model.Api{}
+			err = db.Where("id IN ?", apiIDs).Find(&list).Error
+			if err != nil {
+				return nil, err
+			}
+			for _, api := range list {
+				byID[api.ID] = api
+			}
+		}
+		for _, record := range records {
+			if api, ok := byID[record.ApiID]; ok { // the deleted apis are not granted
+				apis[record.RoleID] = append(apis[record.RoleID], api)
+			}
+		}
+	}
+
+	return permission.New(grants, apis), nil
+}
+
+// getRoleParentsByTx the parents of all the roles
+func getRoleParentsByTx(tx *gorm.DB) (map[uint64][]uint64, error) {
+	records := []*model.RoleParent{}
+	err := tx.Order("id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	parents := map[uint64][]uint64{}
+	for _, record := range records {
+		parents[record.RoleID] = append(parents[record.RoleID], record.ParentID)
+	}
+	return parents, nil
+}
+
+// getInheritedRolesByTx the roles bound to the user and the roles of its active elevations, followed by the roles
+// they inherit, the deleted roles are skipped with the roles they inherit
+func getInheritedRolesByTx(tx *gorm.DB, userID uint64) ([]*permission.RoleGrant, error) {
+	boundIDs := []uint64{}
+	err := tx.Model(&model.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &boundIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	elevatedIDs, err := getElevatedRoleIDsByTx(tx, userID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if len(boundIDs)+len(elevatedIDs) == 0 {
+		return []*permission.RoleGrant{}, nil
+	}
+	direct := []*model.Role{}
+	err = tx.Where("id IN ?", append(boundIDs, elevatedIDs...)).Order("id ASC").Find(&direct).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var grants []*permission.RoleGrant
+	parents, err := getRoleParentsByTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) == 0 {
+		grants = permission.Inherit(direct, nil, nil)
+	} else {
+		list := []*model.Role{}
+		err = tx.Find(&list).Error
+		if err != nil {
+			return nil, err
+		}
+		roles := make(map[uint64]*model.Role, len(list))
+		for _, role := range list {
+			roles[role.ID] = role
+		}
+		grants = permission.Inherit(direct, roles, parents)
+	}
+
+	bound := make(map[uint64]bool, len(boundIDs))
+	for _, id := range boundIDs {
+		bound[id] = true
+	}
+	for _, grant := range grants {
+		grant.Elevated = !bound[grant.Via[0].ID]
+	}
+	return grants, nil
+}
@@ -67,17 +67,17 @@ func Test_roleDao_DeleteByID(t *testing.T) {
 
 	d.SQLMock.ExpectBegin()
 	d.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(d.AnyTime, testData.ID).
+		WithArgs(d.AnyTime, 1, d.AnyTime, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	d.SQLMock.ExpectCommit()
 
-	err := d.IDao.(RoleDao).DeleteByID(d.Ctx, testData.ID)
+	err := d.IDao.(RoleDao).DeleteByID(d.Ctx, testData.ID, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// zero id error
-	err = d.IDao.(RoleDao).DeleteByID(d.Ctx, 0)
+	err = d.IDao.(RoleDao).DeleteByID(d.Ctx, 0, 1)
 	assert.Error(t, err)
 }
 
@@ -93,17 +93,17 @@ func Test_roleDao_DeleteByIDs(t *testing.T) {
 
 	d.SQLMock.ExpectBegin()
 	d.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(d.AnyTime, testData.ID).
+		WithArgs(d.AnyTime, 1, d.AnyTime, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	d.SQLMock.ExpectCommit()
 
-	err := d.IDao.(RoleDao).DeleteByID(d.Ctx, testData.ID)
+	err := d.IDao.(RoleDao).DeleteByID(d.Ctx, testData.ID, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// zero id error
-	err = d.IDao.(RoleDao).DeleteByIDs(d.Ctx, []uint64{0})
+	err = d.IDao.(RoleDao).DeleteByIDs(d.Ctx, []uint64{0}, 1)
 	assert.Error(t, err)
 }
 
@@ -129,6 +129,27 @@ func Test_roleDao_UpdateByID(t *testing.T) {
 
 }
 
+func Test_roleDao_PatchByID(t *testing.T) {
+	d := newRoleDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Role)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(0, d.AnyTime, testData.ID, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(RoleDao).PatchByID(d.Ctx, testData.ID, 1, map[string]interface{}{"create_by": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(RoleDao).PatchByID(d.Ctx, 0, 1, map[string]interface{}{"create_by": 0})
+	assert.Error(t, err)
+}
+
 func Test_roleDao_GetByID(t *testing.T) {
 	d := newRoleDao()
 	defer d.Close()
@@ -333,11 +354,11 @@ func Test_roleDao_DeleteByTx(t *testing.T) {
 
 	d.SQLMock.ExpectBegin()
 	d.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(d.AnyTime, d.AnyTime, testData.ID).
+		WithArgs(d.AnyTime, 1, d.AnyTime, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	d.SQLMock.ExpectCommit()
 
-	err := d.IDao.(RoleDao).DeleteByTx(d.Ctx, d.DB, testData.ID)
+	err := d.IDao.(RoleDao).DeleteByTx(d.Ctx, d.DB, testData.ID, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -359,3 +380,38 @@ func Test_roleDao_UpdateByTx(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_roleDao_GetByConditionByTx(t *testing.T) {
+	d := newRoleDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Role)
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+		AddRow(testData.ID, testData.CreatedAt, testData.UpdatedAt)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID).
+		WillReturnRows(rows)
+
+	record, err := d.IDao.(RoleDao).GetByConditionByTx(d.Ctx, d.DB, &query.Conditions{
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Value: testData.ID,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testData.ID, record.ID)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	_, err = d.IDao.(RoleDao).GetByConditionByTx(d.Ctx, d.DB, &query.Conditions{})
+	assert.Error(t, err)
+}
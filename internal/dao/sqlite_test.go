@@ -0,0 +1,280 @@
+package dao
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
+
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+// newSQLiteDB create a database in a temporary sqlite file with the embedded migrations applied,
+// the dao sql runs for real instead of against sqlmock
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+
+	_, err = migration.Up(db, ggorm.DBDriverSqlite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func Test_userDao_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewUserDao(db, nil)
+	ctx := context.Background()
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		err := d.Create(ctx, &model.User{Name: name, Email: name + "@example.com", Status: 2})
+		assert.NoError(t, err)
+	}
+
+	user, err := d.GetByName(ctx, "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), user.ID)
+
+	err = d.UpdateByID(ctx, &model.User{Model: ggorm.Model{ID: user.ID}, Phone: "123", Status: 3})
+	assert.NoError(t, err)
+	user, err = d.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", user.Phone)
+	assert.Equal(t, 3, user.Status)
+
+	users, total, err := d.GetByColumns(ctx, &query.Params{
+		Page: 0,
+		Size: 10,
+		Sort: "-id",
+		Columns: []query.Column{
+			{Name: "status", Value: 2},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "baz", users[0].Name)
+
+	err = d.DeleteByID(ctx, 1, 1)
+	assert.NoError(t, err)
+	_, err = d.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, model.ErrRecordNotFound)
+	records, err := d.GetByIDs(ctx, []uint64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+}
+
+func Test_roleDao_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewRoleDao(db, nil)
+	ctx := context.Background()
+
+	err := d.Create(ctx, &model.Role{RoleName: "admin", RoleKey: "admin", RoleSort: 1})
+	assert.NoError(t, err)
+	err = d.Create(ctx, &model.Role{RoleName: "viewer", RoleKey: "viewer", RoleSort: 2})
+	assert.NoError(t, err)
+
+	role, err := d.GetByCondition(ctx, &query.Conditions{Columns: []query.Column{{Name: "role_key", Value: "viewer"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "viewer", role.RoleName)
+
+	roles, err := d.GetByLastID(ctx, 3, 10, "-id")
+	assert.NoError(t, err)
+	assert.Len(t, roles, 2)
+}
+
+func Test_userRoleDao_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	roleDao := NewRoleDao(db, nil)
+	for _, key := range []string{"admin", "operator", "viewer"} {
+		assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: key, RoleKey: key}))
+	}
+
+	d := NewUserRoleDao(db)
+	err := d.SetUserRoles(ctx, 1, []uint64{1, 2})
+	assert.NoError(t, err)
+	err = d.SetUserRoles(ctx, 1, []uint64{2, 3}) // replaces the previous roles
+	assert.NoError(t, err)
+
+	roles, err := d.GetRolesByUserID(ctx, 1)
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, role := range roles {
+		keys = append(keys, role.RoleKey)
+	}
+	assert.ElementsMatch(t, []string{"operator", "viewer"}, keys)
+
+	roles, err = d.GetRolesByUserID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func Test_userRoleDao_SyncRolesByKeys_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	roleDao := NewRoleDao(db, nil)
+	for _, key := range []string{"admin", "operator", "viewer", "auditor"} {
+		assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: key, RoleKey: key}))
+	}
+
+	d := NewUserRoleDao(db)
+	assert.NoError(t, d.SetUserRoles(ctx, 1, []uint64{1, 4})) // admin and auditor
+	managed := []string{"admin", "operator", "viewer"}
+	getKeys := func() []string {
+		roles, err := d.GetRolesByUserID(ctx, 1)
+		assert.NoError(t, err)
+		keys := []string{}
+		for _, role := range roles {
+			keys = append(keys, role.RoleKey)
+		}
+		return keys
+	}
+
+	// the auditor is not managed, it is kept
+	assert.NoError(t, d.SyncRolesByKeysByTx(ctx, db, 1, managed, []string{"operator", "unknown"}))
+	assert.ElementsMatch(t, []string{"operator", "auditor"}, getKeys())
+	assert.NoError(t, d.SyncRolesByKeysByTx(ctx, db, 1, managed, []string{"operator", "viewer"}))
+	assert.ElementsMatch(t, []string{"operator", "viewer", "auditor"}, getKeys())
+	assert.NoError(t, d.SyncRolesByKeysByTx(ctx, db, 1, managed, nil))
+	assert.ElementsMatch(t, []string{"auditor"}, getKeys())
+}
+
+func Test_userIdentityDao_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	d := NewUserIdentityDao(db)
+
+	_, err := d.GetBySubjectByTx(ctx, db, "https://idp", "u-1")
+	assert.ErrorIs(t, err, model.ErrRecordNotFound)
+
+	id, err := d.CreateByTx(ctx, db, &model.UserIdentity{UserID: 1, Issuer: "https://idp", Subject: "u-1", Email: "foo@example.com"})
+	assert.NoError(t, err)
+	_, err = d.CreateByTx(ctx, db, &model.UserIdentity{UserID: 2, Issuer: "https://idp", Subject: "u-1"})
+	assert.Error(t, err) // unique subject at the issuer
+	assert.NoError(t, d.UpdateEmailByTx(ctx, db, id, "bar@example.com"))
+
+	identity, err := d.GetBySubjectByTx(ctx, db, "https://idp", "u-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), identity.UserID)
+	assert.Equal(t, "bar@example.com", identity.Email)
+	_, err = d.GetBySubjectByTx(ctx, db, "https://other", "u-1")
+	assert.ErrorIs(t, err, model.ErrRecordNotFound)
+}
+
+func Test_userDao_Trash_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewUserDao(db, nil)
+	ctx := context.Background()
+
+	assert.NoError(t, d.Create(ctx, &model.User{Name: "foo", Status: 2}))
+	assert.NoError(t, d.Create(ctx, &model.User{Name: "bar", Status: 2}))
+	assert.Error(t, d.Create(ctx, &model.User{Name: "foo"})) // unique name
+	assert.NoError(t, NewUserRoleDao(db).SetUserRoles(ctx, 1, []uint64{1}))
+
+	// only the deleted records are in the trash
+	assert.NoError(t, d.DeleteByID(ctx, 1, 2))
+	users, total, err := d.GetTrash(ctx, &query.Params{Page: 0, Size: 10, Sort: "-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "foo", users[0].Name)
+		assert.Equal(t, uint64(2), users[0].DeletedBy)
+		assert.True(t, users[0].DeletedAt.Valid)
+	}
+	_, total, err = d.GetTrash(ctx, &query.Params{Page: 0, Size: 10, Columns: []query.Column{{Name: "name", Value: "bar"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+
+	// the deleted name can be used again, then the deleted record can not be restored
+	assert.NoError(t, d.Create(ctx, &model.User{Name: "foo", Status: 2}))
+	assert.ErrorIs(t, d.Restore(ctx, 1), model.ErrRecordConflict)
+	assert.NoError(t, d.DeleteByID(ctx, 3, 2))
+	assert.NoError(t, d.Restore(ctx, 1))
+	user, err := d.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), user.DeletedBy)
+	assert.ErrorIs(t, d.Restore(ctx, 1), model.ErrRecordNotFound) // not in the trash
+
+	// only the records in the trash can be purged, the role bindings are purged too
+	assert.ErrorIs(t, d.Purge(ctx, 1), model.ErrRecordNotFound)
+	assert.NoError(t, d.DeleteByIDs(ctx, []uint64{1}, 2))
+	assert.NoError(t, d.Purge(ctx, 1))
+	var count int64
+	db.Unscoped().Model(&model.User{}).Where("id = ?", 1).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Unscoped().Model(&model.UserRole{}).Where("user_id = ?", 1).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func Test_roleDao_Trash_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewRoleDao(db, nil)
+	ctx := context.Background()
+
+	assert.NoError(t, d.Create(ctx, &model.Role{RoleName: "viewer", RoleKey: "viewer"}))
+	assert.NoError(t, d.DeleteByTx(ctx, db, 1, 1))
+	assert.NoError(t, d.Create(ctx, &model.Role{RoleName: "viewer", RoleKey: "viewer"}))
+	assert.Error(t, d.Create(ctx, &model.Role{RoleName: "viewer", RoleKey: "viewer"})) // unique role key
+	assert.ErrorIs(t, d.Restore(ctx, 1), model.ErrRecordConflict)
+	assert.NoError(t, d.Purge(ctx, 1))
+	_, total, err := d.GetTrash(ctx, &query.Params{Page: 0, Size: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func Test_roleDao_Version_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewRoleDao(db, nil)
+	ctx := context.Background()
+
+	role := &model.Role{RoleName: "viewer", RoleKey: "viewer"}
+	assert.NoError(t, d.Create(ctx, role))
+	assert.Equal(t, uint64(1), role.Version)
+
+	// two updates read version 1, the second one is rejected
+	assert.NoError(t, d.UpdateByID(ctx, &model.Role{Model: ggorm.Model{ID: 1}, Remark: "foo", Version: 1}))
+	err := d.UpdateByID(ctx, &model.Role{Model: ggorm.Model{ID: 1}, Remark: "bar", Version: 1})
+	assert.ErrorIs(t, err, model.ErrVersionConflict)
+	err = d.UpdateByID(ctx, &model.Role{Model: ggorm.Model{ID: 2}, Remark: "bar", Version: 1})
+	assert.ErrorIs(t, err, model.ErrRecordNotFound)
+
+	// the updates without version are not checked but still increment the version
+	assert.NoError(t, d.UpdateByID(ctx, &model.Role{Model: ggorm.Model{ID: 1}, Remark: "baz"}))
+	role, err = d.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", role.Remark)
+	assert.Equal(t, uint64(3), role.Version)
+}
+
+func Test_userDao_PatchByID_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	d := NewUserDao(db, nil)
+	ctx := context.Background()
+
+	assert.NoError(t, d.Create(ctx, &model.User{Name: "foo", Avatar: "foo.png", Age: 20, Status: 2}))
+
+	// the zero values are written, the absent columns are unchanged
+	err := d.PatchByID(ctx, 1, 1, map[string]interface{}{"avatar": "", "status": 0})
+	assert.NoError(t, err)
+	user, err := d.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "", user.Avatar)
+	assert.Equal(t, 0, user.Status)
+	assert.Equal(t, 20, user.Age)
+	assert.Equal(t, uint64(2), user.Version)
+
+	err = d.PatchByID(ctx, 1, 1, map[string]interface{}{"age": 0})
+	assert.ErrorIs(t, err, model.ErrVersionConflict)
+	err = d.PatchByID(ctx, 1, 2, map[string]interface{}{})
+	assert.Error(t, err)
+}
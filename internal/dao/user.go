@@ -14,6 +14,7 @@ import (
 	"github.com/zhufuyi/sponge/pkg/utils"
 
 	"go-admin/internal/cache"
+	"go-admin/internal/datascope"
 	"go-admin/internal/model"
 )
 
@@ -22,19 +23,24 @@ var _ UserDao = (*userDao)(nil)
 // UserDao defining the dao interface
 type UserDao interface {
 	Create(ctx context.Context, table *model.User) error
-	DeleteByID(ctx context.Context, id uint64) error
-	DeleteByIDs(ctx context.Context, ids []uint64) error
+	DeleteByID(ctx context.Context, id uint64, deletedBy uint64) error
+	DeleteByIDs(ctx context.Context, ids []uint64, deletedBy uint64) error
 	UpdateByID(ctx context.Context, table *model.User) error
+	PatchByID(ctx context.Context, id uint64, version uint64, columns map[string]interface{}) error
 	GetByID(ctx context.Context, id uint64) (*model.User, error)
 	GetByName(ctx context.Context, name string) (*model.User, error)
 	GetByCondition(ctx context.Context, condition *query.Conditions) (*model.User, error)
 	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.User, error)
 	GetByLastID(ctx context.Context, lastID uint64, limit int, sort string) ([]*model.User, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.User, int64, error)
+	GetTrash(ctx context.Context, params *query.Params) ([]*model.User, int64, error)
+	Restore(ctx context.Context, id uint64) error
+	Purge(ctx context.Context, id uint64) error
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.User) (uint64, error)
-	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64, deletedBy uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.User) error
+	GetByConditionByTx(ctx context.Context, tx *gorm.DB, condition *query.Conditions) (*model.User, error)
 }
 
 type userDao struct {
@@ -67,9 +73,13 @@ func (d *userDao) Create(ctx context.Context, table *model.User) error {
 	return d.db.WithContext(ctx).Create(table).Error
 }
 
-// DeleteByID delete a record by id
-func (d *userDao) DeleteByID(ctx context.Context, id uint64) error {
-	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.User{}).Error
+// DeleteByID soft delete a record by id, the record stays in the trash until it is restored or purged
+func (d *userDao) DeleteByID(ctx context.Context, id uint64, deletedBy uint64) error {
+	update := map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}
+	err := d.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(update).Error
 	if err != nil {
 		return err
 	}
@@ -80,9 +90,13 @@ func (d *userDao) DeleteByID(ctx context.Context, id uint64) error {
 	return nil
 }
 
-// DeleteByIDs delete records by batch id
-func (d *userDao) DeleteByIDs(ctx context.Context, ids []uint64) error {
-	err := d.db.WithContext(ctx).Where("id IN (?)", ids).Delete(&model.User{}).Error
+// DeleteByIDs soft delete records by batch id
+func (d *userDao) DeleteByIDs(ctx context.Context, ids []uint64, deletedBy uint64) error {
+	update := map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}
+	err := d.db.WithContext(ctx).Model(&model.User{}).Where("id IN (?)", ids).Updates(update).Error
 	if err != nil {
 		return err
 	}
@@ -105,6 +119,24 @@ func (d *userDao) UpdateByID(ctx context.Context, table *model.User) error {
 	return err
 }
 
+// PatchByID update the columns of a record by id, unlike UpdateByID the zero values are written too,
+// the version is checked as in UpdateByID
+func (d *userDao) PatchByID(ctx context.Context, id uint64, version uint64, columns map[string]interface{}) error {
+	if id < 1 {
+		return errors.New("id cannot be 0")
+	}
+	if len(columns) == 0 {
+		return errors.New("no columns to update")
+	}
+
+	err := d.updateColumns(ctx, d.db, id, version, columns)
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return err
+}
+
 func (d *userDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.User) error {
 	if table.ID < 1 {
 		return errors.New("id cannot be 0")
@@ -140,7 +172,35 @@ func (d *userDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 		update["login_at"] = table.LoginAt
 	}
 
-	return db.WithContext(ctx).Model(table).Updates(update).Error
+	return d.updateColumns(ctx, db, table.ID, table.Version, update)
+}
+
+// updateColumns update the columns of a record, every update increments the version,
+// if version is not 0 the record is only updated if it still has that version
+func (d *userDao) updateColumns(ctx context.Context, db *gorm.DB, id uint64, version uint64, update map[string]interface{}) error {
+	update["version"] = gorm.Expr("version + 1")
+	if version == 0 {
+		return db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(update).Error
+	}
+
+	result := db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND version = ?", id, version).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// either the record does not exist or its version has changed
+		var count int64
+		err := db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return model.ErrRecordNotFound
+		}
+		return model.ErrVersionConflict
+	}
+
+	return nil
 }
 
 // GetByID get a record by id
@@ -363,9 +423,14 @@ func (d *userDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
 
+	inScope, err := d.inDataScope(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var total int64
 	if params.Sort != "ignore count" { // determine if count is required
-		err = d.db.WithContext(ctx).Model(&model.User{}).Select([]string{"id"}).Where(queryStr, args...).Count(&total).Error
+		err = d.db.WithContext(ctx).Model(&model.User{}).Select([]string{"id"}).Where(queryStr, args...).Scopes(inScope).Count(&total).Error
 		if err != nil {
 			return nil, 0, err
 		}
@@ -376,7 +441,7 @@ func (d *userDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 
 	records := []*model.User{}
 	order, limit, offset := params.ConvertToPage()
-	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Scopes(inScope).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -384,16 +449,127 @@ func (d *userDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	return records, total, err
 }
 
+// inDataScope the condition restricting the users to the data scope of the context, the user of the scope
+// and the members of its departments, the users are not restricted if the context has no scope
+func (d *userDao) inDataScope(ctx context.Context) (func(*gorm.DB) *gorm.DB, error) {
+	scope, err := datascope.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if scope == nil || scope.AllUsers {
+			return db
+		}
+		if len(scope.DeptIDs) == 0 {
+			return db.Where("id = ?", scope.UserID)
+		}
+		return db.Where("id = ? OR id IN (?)", scope.UserID,
+			d.db.Model(&model.UserDepartment{}).Select("user_id").Where("dept_id IN ?", scope.DeptIDs))
+	}, nil
+}
+
+// GetTrash get paging soft deleted records by column information, the params are the same as GetByColumns
+func (d *userDao) GetTrash(ctx context.Context, params *query.Params) ([]*model.User, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions()
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	db := d.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if queryStr != "" {
+		db = db.Where(queryStr, args...)
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = db.Session(&gorm.Session{}).Model(&model.User{}).Select([]string{"id"}).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.User{}
+	order, limit, offset := params.ConvertToPage()
+	err = db.Order(order).Limit(limit).Offset(offset).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// Restore a soft deleted record, it returns model.ErrRecordConflict if an undeleted user has the same name
+func (d *userDao) Restore(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		record := &model.User{}
+		err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&model.User{}).Where("name = ?", record.Name).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return model.ErrRecordConflict
+		}
+
+		update := map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": 0,
+		}
+		return tx.Unscoped().Model(record).Updates(update).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache, it may hold the not found placeholder
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// Purge permanently delete a soft deleted record, the records that are not in the trash can not be purged
+func (d *userDao) Purge(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrRecordNotFound
+		}
+
+		// the role bindings of the user are useless without the user
+		return tx.Unscoped().Where("user_id = ?", id).Delete(&model.UserRole{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *userDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.User) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
 	return table.ID, err
 }
 
-// DeleteByTx delete a record by id in the database using the provided transaction
-func (d *userDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+// DeleteByTx soft delete a record by id in the database using the provided transaction
+func (d *userDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64, deletedBy uint64) error {
 	update := map[string]interface{}{
 		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
 	}
 	err := tx.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(update).Error
 	if err != nil {
@@ -415,3 +591,20 @@ func (d *userDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.User
 
 	return err
 }
+
+// GetByConditionByTx get a record by condition in the database using the provided transaction,
+// the query conditions are the same as GetByCondition
+func (d *userDao) GetByConditionByTx(ctx context.Context, tx *gorm.DB, c *query.Conditions) (*model.User, error) {
+	queryStr, args, err := c.ConvertToGorm()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &model.User{}
+	err = tx.WithContext(ctx).Where(queryStr, args...).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
@@ -0,0 +1,48 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+var _ UserIdentityDao = (*userIdentityDao)(nil)
+
+// UserIdentityDao defining the dao interface
+type UserIdentityDao interface {
+	GetBySubjectByTx(ctx context.Context, tx *gorm.DB, issuer string, subject string) (*model.UserIdentity, error)
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.UserIdentity) (uint64, error)
+	UpdateEmailByTx(ctx context.Context, tx *gorm.DB, id uint64, email string) error
+}
+
+type userIdentityDao struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityDao creating the dao interface
+func NewUserIdentityDao(db *gorm.DB) UserIdentityDao {
+	return &userIdentityDao{db: db}
+}
+
+// GetBySubjectByTx get the identity of the account at the issuer, model.ErrRecordNotFound if it is not linked to a user
+func (d *userIdentityDao) GetBySubjectByTx(ctx context.Context, tx *gorm.DB, issuer string, subject string) (*model.UserIdentity, error) {
+	table := &model.UserIdentity{}
+	err := tx.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *userIdentityDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.UserIdentity) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// UpdateEmailByTx update the email claimed by the issuer using the provided transaction
+func (d *userIdentityDao) UpdateEmailByTx(ctx context.Context, tx *gorm.DB, id uint64, email string) error {
+	return tx.WithContext(ctx).Model(&model.UserIdentity{}).Where("id = ?", id).Update("email", email).Error
+}
@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+var _ UserRoleDao = (*userRoleDao)(nil)
+
+// UserRoleDao defining the dao interface
+type UserRoleDao interface {
+	GetRolesByUserID(ctx context.Context, userID uint64) ([]*model.Role, error)
+	GetInheritedRolesByUserID(ctx context.Context, userID uint64) ([]*model.Role, error)
+	SetUserRoles(ctx context.Context, userID uint64, roleIDs []uint64) error
+	SyncRolesByKeysByTx(ctx context.Context, tx *gorm.DB, userID uint64, managedKeys []string, grantedKeys []string) error
+}
+
+type userRoleDao struct {
+	db *gorm.DB
+}
+
+// NewUserRoleDao creating the dao interface
+func NewUserRoleDao(db *gorm.DB) UserRoleDao {
+	return &userRoleDao{db: db}
+}
+
+// GetRolesByUserID get the roles bound to the user
+func (d *userRoleDao) GetRolesByUserID(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	records := []*model.Role{}
+	err := d.db.WithContext(ctx).
+		Where("id IN (?)", d.db.Model(&model.UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetInheritedRolesByUserID get the roles bound to the user followed by the roles they inherit
+func (d *userRoleDao) GetInheritedRolesByUserID(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	grants, err := getInheritedRolesByTx(d.db.WithContext(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*model.Role, 0, len(grants))
+	for _, grant := range grants {
+		records = append(records, grant.Role)
+	}
+	return records, nil
+}
+
+// SetUserRoles replace the roles bound to the user
+func (d *userRoleDao) SetUserRoles(ctx context.Context, userID uint64, roleIDs []uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserRole{}).Error
+		if err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			err = tx.Create(&model.UserRole{UserID: userID, RoleID: roleID}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SyncRolesByKeysByTx bind the user to the roles of the granted keys and unbind it from the other roles of
+// the managed keys, the roles of the keys that are not managed are unchanged, the unknown keys are ignored
+func (d *userRoleDao) SyncRolesByKeysByTx(ctx context.Context, tx *gorm.DB, userID uint64, managedKeys []string, grantedKeys []string) error {
+	tx = tx.WithContext(ctx)
+
+	if len(managedKeys) > 0 {
+		managed := tx.Model(&model.Role{}).Select("id").Where("role_key IN ?", managedKeys)
+		if len(grantedKeys) > 0 {
+			managed = managed.Where("role_key NOT IN ?", grantedKeys)
+		}
+		err := tx.Unscoped().Where("user_id = ? AND role_id IN (?)", userID, managed).Delete(&model.UserRole{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(grantedKeys) == 0 {
+		return nil
+	}
+
+	roleIDs := []uint64{}
+	err := tx.Model(&model.Role{}).
+		Where("role_key IN ?", grantedKeys).
+		Where("id NOT IN (?)", tx.Model(&model.UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Pluck("id", &roleIDs).Error
+	if err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		err = tx.Create(&model.UserRole{UserID: userID, RoleID: roleID}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-admin/internal/model"
+)
+
+var _ UserTOTPDao = (*userTOTPDao)(nil)
+
+// UserTOTPDao defining the dao interface of the TOTP and the recovery codes of the users
+type UserTOTPDao interface {
+	GetByUserID(ctx context.Context, userID uint64) (*model.UserTOTP, error)
+	SavePending(ctx context.Context, userID uint64, secret string) error
+	Enable(ctx context.Context, userID uint64, secret string, counter uint64, codeHashes []string) error
+	UseCounter(ctx context.Context, id uint64, counter uint64) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uint64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error)
+}
+
+type userTOTPDao struct {
+	db *gorm.DB
+}
+
+// NewUserTOTPDao creating the dao interface
+func NewUserTOTPDao(db *gorm.DB) UserTOTPDao {
+	return &userTOTPDao{db: db}
+}
+
+// GetByUserID get the TOTP of the user, model.ErrRecordNotFound if it has none
+func (d *userTOTPDao) GetByUserID(ctx context.Context, userID uint64) (*model.UserTOTP, error) {
+	table := &model.UserTOTP{}
+	err := d.db.WithContext(ctx).Where("user_id = ?", userID).First(table).Error
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// SavePending replace the secret that is not enabled yet, model.ErrTOTPEnabled if the TOTP of the user is enabled
+func (d *userTOTPDao) SavePending(ctx context.Context, userID uint64, secret string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deletePendingTOTP(tx, userID); err != nil {
+			return err
+		}
+		return tx.Create(&model.UserTOTP{UserID: userID, Secret: secret}).Error
+	})
+}
+
+// Enable enable the secret whose code of the time step counter is verified and replace the recovery codes,
+// a pending secret of the user is replaced, model.ErrTOTPEnabled if the TOTP of the user is enabled
+func (d *userTOTPDao) Enable(ctx context.Context, userID uint64, secret string, counter uint64, codeHashes []string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deletePendingTOTP(tx, userID); err != nil {
+			return err
+		}
+		err := tx.Create(&model.UserTOTP{UserID: userID, Secret: secret, Enabled: true, LastCounter: counter}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// deletePendingTOTP delete the TOTP of the user that is not enabled, model.ErrTOTPEnabled if it is enabled
+func deletePendingTOTP(tx *gorm.DB, userID uint64) error {
+	var count int64
+	err := tx.Model(&model.UserTOTP{}).Where("user_id = ? AND enabled = ?", userID, true).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return model.ErrTOTPEnabled
+	}
+	// deleted for real, the user id is unique
+	return tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserTOTP{}).Error
+}
+
+// UseCounter save the time step of an accepted code, false if a code of the same or a later step was accepted,
+// the code is then replayed
+func (d *userTOTPDao) UseCounter(ctx context.Context, id uint64, counter uint64) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&model.UserTOTP{}).
+		Where("id = ? AND last_counter < ?", id, counter).
+		Update("last_counter", counter)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserID delete the TOTP and the recovery codes of the user
+func (d *userTOTPDao) DeleteByUserID(ctx context.Context, userID uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserTOTP{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes replace all the recovery codes of the user
+func (d *userTOTPDao) ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes []string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint64, codeHashes []string) error {
+	err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error
+	if err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	codes := make([]*model.UserRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, &model.UserRecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(codes).Error
+}
+
+// UseRecoveryCode mark the unused recovery code as used, false if the user has no such unused code
+func (d *userTOTPDao) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at = ?", userID, codeHash, 0).
+		Update("used_at", time.Now().Unix())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes count the unused recovery codes of the user
+func (d *userTOTPDao) CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&model.UserRecoveryCode{}).Where("user_id = ? AND used_at = ?", userID, 0).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,177 @@
+// Package datascope computes the records visible to a user from the data scope modes of the roles,
+// and carries the scope of the request in its context so that the daos filter their lists.
+package datascope
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go-admin/internal/model"
+)
+
+// RoleScope the scope granted by a role
+type RoleScope struct {
+	RoleID        uint64
+	RoleKey       string
+	Mode          string
+	DeptIDs       []uint64 // the departments whose users are visible, empty for the modes all and self
+	Namespaces    []string // the kubernetes namespaces of the data scope and of the departments of the mode
+	AllNamespaces bool     // the data scope of the role is model.DataScopeAll
+}
+
+// Scope the records visible to a user, the union of the scopes of its roles
+type Scope struct {
+	UserID        uint64
+	AllUsers      bool     // a role has the mode all
+	DeptIDs       []uint64 // the users of these departments are visible, the user is always visible
+	AllNamespaces bool     // a role has the data scope model.DataScopeAll
+	Namespaces    []string // the visible kubernetes namespaces, sorted
+	Roles         []*RoleScope
+}
+
+// New compute the scope of the user from its roles, the departments it is a member of, the departments of
+// the custom scope of each role and the department tree, a role without a mode has the mode all
+func New(userID uint64, roles []*model.Role, userDeptIDs []uint64, roleDeptIDs map[uint64][]uint64, depts []*model.Department) *Scope {
+	byID := make(map[uint64]*model.Department, len(depts))
+	children := map[uint64][]uint64{}
+	for _, dept := range depts {
+		byID[dept.ID] = dept
+		children[dept.ParentID] = append(children[dept.ParentID], dept.ID)
+	}
+
+	scope := &Scope{UserID: userID}
+	allDepts := map[uint64]bool{}
+	allNamespaces := map[string]bool{}
+	for _, role := range roles {
+		rs := &RoleScope{RoleID: role.ID, RoleKey: role.RoleKey, Mode: role.DataScopeMode}
+		if rs.Mode == "" {
+			rs.Mode = model.DataScopeModeAll
+		}
+
+		var deptIDs []uint64
+		switch rs.Mode {
+		case model.DataScopeModeAll: // the namespaces of all the departments are not granted, only the ones of the data scope
+			scope.AllUsers = true
+		case model.DataScopeModeDept:
+			deptIDs = userDeptIDs
+		case model.DataScopeModeDeptAndChildren:
+			deptIDs = descendants(userDeptIDs, children)
+		case model.DataScopeModeCustom:
+			deptIDs = roleDeptIDs[role.ID]
+		}
+
+		namespaces := map[string]bool{}
+		roleNamespaces, all := role.Namespaces()
+		rs.AllNamespaces = all
+		for _, ns := range roleNamespaces {
+			namespaces[ns] = true
+		}
+		seen := map[uint64]bool{}
+		for _, id := range deptIDs {
+			dept, ok := byID[id]
+			if !ok || seen[id] { // deleted departments are ignored
+				continue
+			}
+			seen[id] = true
+			rs.DeptIDs = append(rs.DeptIDs, id)
+			for _, ns := range dept.NamespaceList() {
+				namespaces[ns] = true
+			}
+		}
+		sortIDs(rs.DeptIDs)
+		rs.Namespaces = sortedKeys(namespaces)
+
+		for _, id := range rs.DeptIDs {
+			allDepts[id] = true
+		}
+		for ns := range namespaces {
+			allNamespaces[ns] = true
+		}
+		scope.AllNamespaces = scope.AllNamespaces || rs.AllNamespaces
+		scope.Roles = append(scope.Roles, rs)
+	}
+
+	for id := range allDepts {
+		scope.DeptIDs = append(scope.DeptIDs, id)
+	}
+	sortIDs(scope.DeptIDs)
+	if !scope.AllNamespaces {
+		scope.Namespaces = sortedKeys(allNamespaces)
+	}
+	return scope
+}
+
+// CanSeeNamespace report whether the kubernetes namespace is visible, all the namespaces are visible with a nil scope
+func (s *Scope) CanSeeNamespace(namespace string) bool {
+	if s == nil || s.AllNamespaces {
+		return true
+	}
+	i := sort.SearchStrings(s.Namespaces, namespace)
+	return i < len(s.Namespaces) && s.Namespaces[i] == namespace
+}
+
+// descendants the departments and all their sub departments
+func descendants(ids []uint64, children map[uint64][]uint64) []uint64 {
+	seen := map[uint64]bool{}
+	queue := append([]uint64{}, ids...)
+	var result []uint64
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result
+}
+
+func sortIDs(ids []uint64) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Resolver compute the scope of a request, a nil scope means the request is not filtered
+type Resolver func(ctx context.Context) (*Scope, error)
+
+type contextKey struct{}
+
+type lazyScope struct {
+	once    sync.Once
+	resolve Resolver
+	scope   *Scope
+	err     error
+}
+
+// WithResolver return a context whose scope is computed by the resolver the first time it is needed
+func WithResolver(ctx context.Context, resolve Resolver) context.Context {
+	return context.WithValue(ctx, contextKey{}, &lazyScope{resolve: resolve})
+}
+
+// WithScope return a context with the scope
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return WithResolver(ctx, func(context.Context) (*Scope, error) { return scope, nil })
+}
+
+// FromContext get the scope of the context, nil if the context has no scope and its lists are not filtered
+func FromContext(ctx context.Context) (*Scope, error) {
+	lazy, ok := ctx.Value(contextKey{}).(*lazyScope)
+	if !ok {
+		return nil, nil
+	}
+	lazy.once.Do(func() {
+		lazy.scope, lazy.err = lazy.resolve(ctx)
+	})
+	return lazy.scope, lazy.err
+}
@@ -0,0 +1,97 @@
+package datascope
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-admin/internal/model"
+)
+
+// the department tree: 1 company(prod) > 2 dev(dev) > 3 backend(backend), 1 company > 4 ops(ops, monitoring)
+func testDepartments() []*model.Department {
+	newDept := func(id uint64, parentID uint64, namespaces string) *model.Department {
+		dept := &model.Department{ParentID: parentID, Namespaces: namespaces}
+		dept.ID = id
+		return dept
+	}
+	return []*model.Department{
+		newDept(1, 0, "prod"),
+		newDept(2, 1, "dev"),
+		newDept(3, 2, "backend"),
+		newDept(4, 1, "ops, monitoring,ops"),
+	}
+}
+
+func newRole(id uint64, mode string, dataScope string) *model.Role {
+	role := &model.Role{DataScopeMode: mode, DataScope: dataScope}
+	role.ID = id
+	return role
+}
+
+func TestNew(t *testing.T) {
+	depts := testDepartments()
+	tests := []struct {
+		name          string
+		roles         []*model.Role
+		allUsers      bool
+		deptIDs       []uint64
+		namespaces    []string
+		allNamespaces bool
+	}{
+		{"no role", nil, false, nil, []string{}, false},
+		{"self", []*model.Role{newRole(1, model.DataScopeModeSelf, "sandbox")}, false, nil, []string{"sandbox"}, false},
+		{"dept", []*model.Role{newRole(1, model.DataScopeModeDept, "")}, false, []uint64{2}, []string{"dev"}, false},
+		{"dept and children", []*model.Role{newRole(1, model.DataScopeModeDeptAndChildren, "")}, false, []uint64{2, 3}, []string{"backend", "dev"}, false},
+		{"custom", []*model.Role{newRole(1, model.DataScopeModeCustom, "")}, false, []uint64{4}, []string{"monitoring", "ops"}, false},
+		{"all", []*model.Role{newRole(1, "", "prod")}, true, nil, []string{"prod"}, false},
+		{"union", []*model.Role{newRole(1, model.DataScopeModeDept, ""), newRole(2, model.DataScopeModeCustom, "*")}, false, []uint64{2, 4}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := New(9, tt.roles, []uint64{2}, map[uint64][]uint64{2: {4, 99}, 1: {4}}, depts)
+			assert.Equal(t, uint64(9), scope.UserID)
+			assert.Equal(t, tt.allUsers, scope.AllUsers)
+			assert.Equal(t, tt.deptIDs, scope.DeptIDs)
+			assert.Equal(t, tt.allNamespaces, scope.AllNamespaces)
+			if !tt.allNamespaces {
+				assert.Equal(t, tt.namespaces, scope.Namespaces)
+			}
+			assert.Len(t, scope.Roles, len(tt.roles))
+		})
+	}
+
+	scope := New(9, []*model.Role{newRole(1, model.DataScopeModeDept, "sandbox"), newRole(2, model.DataScopeModeSelf, "")}, []uint64{2}, nil, depts)
+	assert.Equal(t, []string{"dev", "sandbox"}, scope.Roles[0].Namespaces)
+	assert.Empty(t, scope.Roles[1].Namespaces)
+	assert.True(t, scope.CanSeeNamespace("sandbox"))
+	assert.False(t, scope.CanSeeNamespace("prod"))
+}
+
+func TestFromContext(t *testing.T) {
+	scope, err := FromContext(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, scope)
+
+	calls := 0
+	ctx := WithResolver(context.Background(), func(context.Context) (*Scope, error) {
+		calls++
+		return &Scope{UserID: 1}, nil
+	})
+	assert.Equal(t, 0, calls)
+	for i := 0; i < 2; i++ {
+		scope, err = FromContext(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), scope.UserID)
+	}
+	assert.Equal(t, 1, calls)
+
+	ctx = WithResolver(context.Background(), func(context.Context) (*Scope, error) { return nil, errors.New("failed") })
+	_, err = FromContext(ctx)
+	assert.Error(t, err)
+
+	scope, _ = FromContext(WithScope(context.Background(), &Scope{UserID: 2}))
+	assert.Equal(t, uint64(2), scope.UserID)
+}
@@ -11,14 +11,22 @@ var (
 	apiName     = "api"
 	apiBaseCode = errcode.HCode(apiNO)
 
-	ErrCreateApi         = errcode.NewError(apiBaseCode+1, "failed to create "+apiName)
-	ErrDeleteByIDApi     = errcode.NewError(apiBaseCode+2, "failed to delete "+apiName)
-	ErrDeleteByIDsApi    = errcode.NewError(apiBaseCode+3, "failed to delete by batch ids "+apiName)
-	ErrUpdateByIDApi     = errcode.NewError(apiBaseCode+4, "failed to update "+apiName)
-	ErrGetByIDApi        = errcode.NewError(apiBaseCode+5, "failed to get "+apiName+" details")
-	ErrGetByConditionApi = errcode.NewError(apiBaseCode+6, "failed to get "+apiName+" details by conditions")
-	ErrListByIDsApi      = errcode.NewError(apiBaseCode+7, "failed to list by batch ids "+apiName)
-	ErrListByLastIDApi   = errcode.NewError(apiBaseCode+8, "failed to list by last id "+apiName)
-	ErrListApi           = errcode.NewError(apiBaseCode+9, "failed to list of "+apiName)
+	ErrCreateApi          = errcode.NewError(apiBaseCode+1, "failed to create "+apiName)
+	ErrDeleteByIDApi      = errcode.NewError(apiBaseCode+2, "failed to delete "+apiName)
+	ErrDeleteByIDsApi     = errcode.NewError(apiBaseCode+3, "failed to delete by batch ids "+apiName)
+	ErrUpdateByIDApi      = errcode.NewError(apiBaseCode+4, "failed to update "+apiName)
+	ErrGetByIDApi         = errcode.NewError(apiBaseCode+5, "failed to get "+apiName+" details")
+	ErrGetByConditionApi  = errcode.NewError(apiBaseCode+6, "failed to get "+apiName+" details by conditions")
+	ErrListByIDsApi       = errcode.NewError(apiBaseCode+7, "failed to list by batch ids "+apiName)
+	ErrListByLastIDApi    = errcode.NewError(apiBaseCode+8, "failed to list by last id "+apiName)
+	ErrListApi            = errcode.NewError(apiBaseCode+9, "failed to list of "+apiName)
+	ErrSyncApi            = errcode.NewError(apiBaseCode+10, "failed to sync "+apiName+" with the registered routes")
+	ErrListTrashApi       = errcode.NewError(apiBaseCode+11, "failed to list deleted "+apiName)
+	ErrRestoreApi         = errcode.NewError(apiBaseCode+12, "failed to restore "+apiName)
+	ErrPurgeApi           = errcode.NewError(apiBaseCode+13, "failed to purge "+apiName)
+	ErrApiConflict        = errcode.NewError(apiBaseCode+14, "an "+apiName+" with the same action and path already exists")
+	ErrApiVersionConflict = errcode.NewError(apiBaseCode+15, "the "+apiName+" has been modified by someone else, get it again and retry")
+	ErrImportApi          = errcode.NewError(apiBaseCode+16, "failed to import "+apiName+"s")
+	ErrExportApi          = errcode.NewError(apiBaseCode+17, "failed to export "+apiName+"s")
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// configMap business-level http error codes.
+// the configMapNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	configMapNO       = 12
+	configMapName     = "configMap"
+	configMapBaseCode = errcode.HCode(configMapNO)
+
+	ErrListConfigMap      = errcode.NewError(configMapBaseCode+1, "failed to list of "+configMapName)
+	ErrGetConfigMap       = errcode.NewError(configMapBaseCode+2, "failed to get "+configMapName+" details")
+	ErrSetConfigMapKey    = errcode.NewError(configMapBaseCode+3, "failed to set "+configMapName+" key")
+	ErrDeleteConfigMapKey = errcode.NewError(configMapBaseCode+4, "failed to delete "+configMapName+" key")
+	ErrConfigMapValue     = errcode.NewError(configMapBaseCode+5, "invalid base64 "+configMapName+" value")
+	// error codes are globally unique, adding 1 to the previous error code
+)
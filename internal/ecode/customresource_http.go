@@ -0,0 +1,23 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// customResource business-level http error codes.
+// the customResourceNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	customResourceNO       = 15
+	customResourceName     = "customResource"
+	customResourceBaseCode = errcode.HCode(customResourceNO)
+
+	ErrListAPIResources      = errcode.NewError(customResourceBaseCode+1, "failed to list of api resources")
+	ErrListCRDs              = errcode.NewError(customResourceBaseCode+2, "failed to list of customResourceDefinitions")
+	ErrListCustomResources   = errcode.NewError(customResourceBaseCode+3, "failed to list of "+customResourceName)
+	ErrGetCustomResource     = errcode.NewError(customResourceBaseCode+4, "failed to get "+customResourceName+" details")
+	ErrUpdateCustomResource  = errcode.NewError(customResourceBaseCode+5, "failed to update "+customResourceName)
+	ErrDeleteCustomResource  = errcode.NewError(customResourceBaseCode+6, "failed to delete "+customResourceName)
+	ErrInvalidCustomResource = errcode.NewError(customResourceBaseCode+7, customResourceName+" does not match the schema of crd")
+	ErrNotCustomResource     = errcode.NewError(customResourceBaseCode+8, "the resource is not served by a customResourceDefinition")
+	// error codes are globally unique, adding 1 to the previous error code
+)
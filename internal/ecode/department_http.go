@@ -0,0 +1,24 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// department business-level http error codes.
+// the departmentNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	departmentNO       = 19
+	departmentName     = "department"
+	departmentBaseCode = errcode.HCode(departmentNO)
+
+	ErrCreateDepartment      = errcode.NewError(departmentBaseCode+1, "failed to create "+departmentName)
+	ErrDeleteByIDDepartment  = errcode.NewError(departmentBaseCode+2, "failed to delete "+departmentName)
+	ErrUpdateByIDDepartment  = errcode.NewError(departmentBaseCode+3, "failed to update "+departmentName)
+	ErrGetByIDDepartment     = errcode.NewError(departmentBaseCode+4, "failed to get "+departmentName+" details")
+	ErrDepartmentParent      = errcode.NewError(departmentBaseCode+5, "the parent must be an existing "+departmentName+" outside of the "+departmentName)
+	ErrDepartmentHasChildren = errcode.NewError(departmentBaseCode+6, "the "+departmentName+" has sub "+departmentName+"s, delete or move them first")
+	ErrSetDepartments        = errcode.NewError(departmentBaseCode+7, "failed to set the "+departmentName+"s")
+	ErrDepartmentIDs         = errcode.NewError(departmentBaseCode+8, "the "+departmentName+"s must exist")
+	ErrDataScope             = errcode.NewError(departmentBaseCode+9, "failed to get the data scope")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,26 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// elevation business-level http error codes.
+// the elevationNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	elevationNO       = 22
+	elevationName     = "role elevation"
+	elevationBaseCode = errcode.HCode(elevationNO)
+
+	ErrCreateElevation     = errcode.NewError(elevationBaseCode+1, "failed to request the "+elevationName)
+	ErrElevationRole       = errcode.NewError(elevationBaseCode+2, "the role does not exist or can not be requested")
+	ErrElevationDuration   = errcode.NewError(elevationBaseCode+3, "the duration of the "+elevationName+" exceeds the maximum")
+	ErrElevationHeld       = errcode.NewError(elevationBaseCode+4, "the role is already held")
+	ErrElevationExists     = errcode.NewError(elevationBaseCode+5, "the role is already requested, wait for the review or cancel the request")
+	ErrElevationNotFound   = errcode.NewError(elevationBaseCode+6, elevationName+" not found")
+	ErrElevationForbidden  = errcode.NewError(elevationBaseCode+7, "only the approvers can review the "+elevationName+"s of the other users")
+	ErrElevationSelfReview = errcode.NewError(elevationBaseCode+8, "a user can not review its own "+elevationName)
+	ErrElevationStatus     = errcode.NewError(elevationBaseCode+9, "the "+elevationName+" is not in a status allowing this step")
+	ErrReviewElevation     = errcode.NewError(elevationBaseCode+10, "failed to update the "+elevationName)
+	ErrElevationApiToken   = errcode.NewError(elevationBaseCode+11, "api tokens can not request or review "+elevationName+"s, log in with a password")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// kubeconfig business-level http error codes.
+// the kubeconfigNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	kubeconfigNO       = 17
+	kubeconfigName     = "kubeconfig"
+	kubeconfigBaseCode = errcode.HCode(kubeconfigNO)
+
+	ErrGenerateKubeconfig        = errcode.NewError(kubeconfigBaseCode+1, "failed to generate "+kubeconfigName)
+	ErrRevokeKubeconfig          = errcode.NewError(kubeconfigBaseCode+2, "failed to revoke "+kubeconfigName)
+	ErrKubeconfigNoNamespace     = errcode.NewError(kubeconfigBaseCode+3, "no namespace is permitted by the data scope of the roles")
+	ErrKubeconfigExpiresIn       = errcode.NewError(kubeconfigBaseCode+4, "the lifetime of the "+kubeconfigName+" exceeds the maximum")
+	ErrKubeconfigElevationEnding = errcode.NewError(kubeconfigBaseCode+5, "a temporary role ends before the minimum lifetime of the "+kubeconfigName)
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,23 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// menu business-level http error codes.
+// the menuNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	menuNO       = 18
+	menuName     = "menu"
+	menuBaseCode = errcode.HCode(menuNO)
+
+	ErrCreateMenu      = errcode.NewError(menuBaseCode+1, "failed to create "+menuName)
+	ErrDeleteByIDMenu  = errcode.NewError(menuBaseCode+2, "failed to delete "+menuName)
+	ErrUpdateByIDMenu  = errcode.NewError(menuBaseCode+3, "failed to update "+menuName)
+	ErrGetByIDMenu     = errcode.NewError(menuBaseCode+4, "failed to get "+menuName+" details")
+	ErrMenuParent      = errcode.NewError(menuBaseCode+5, "the parent must be an existing directory or "+menuName+" outside of the "+menuName)
+	ErrMenuHasChildren = errcode.NewError(menuBaseCode+6, "the "+menuName+" has children, delete or move them first")
+	ErrSetRoleMenus    = errcode.NewError(menuBaseCode+7, "failed to set the "+menuName+"s of the role")
+	ErrRoleMenuIDs     = errcode.NewError(menuBaseCode+8, "the "+menuName+"s granted to a role must exist")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,16 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// namespace business-level http error codes.
+// the namespaceNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	namespaceNO       = 20
+	namespaceName     = "namespace"
+	namespaceBaseCode = errcode.HCode(namespaceNO)
+
+	ErrListNamespaces = errcode.NewError(namespaceBaseCode+1, "failed to list of "+namespaceName+"s")
+	// error codes are globally unique, adding 1 to the previous error code
+)
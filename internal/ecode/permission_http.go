@@ -0,0 +1,22 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// permission business-level http error codes.
+// the permissionNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	permissionNO       = 21
+	permissionName     = "permission"
+	permissionBaseCode = errcode.HCode(permissionNO)
+
+	ErrRoleCycle         = errcode.NewError(permissionBaseCode+1, "a role can not inherit itself, directly or through its parents")
+	ErrRoleParentIDs     = errcode.NewError(permissionBaseCode+2, "the parent roles must exist")
+	ErrSetRoleParents    = errcode.NewError(permissionBaseCode+3, "failed to set the parent roles")
+	ErrRoleApiIDs        = errcode.NewError(permissionBaseCode+4, "the apis must exist")
+	ErrSetRoleApis       = errcode.NewError(permissionBaseCode+5, "failed to set the apis of the role")
+	ErrGetPermissions    = errcode.NewError(permissionBaseCode+6, "failed to get the "+permissionName+"s")
+	ErrExplainPermission = errcode.NewError(permissionBaseCode+7, "either the method and the path of an api or a namespace is required")
+	// error codes are globally unique, adding 1 to the previous error code
+)
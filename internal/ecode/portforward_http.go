@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// portForward business-level http error codes.
+// the portForwardNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	portForwardNO       = 16
+	portForwardName     = "portForward"
+	portForwardBaseCode = errcode.HCode(portForwardNO)
+
+	ErrCreatePortForward     = errcode.NewError(portForwardBaseCode+1, "failed to create "+portForwardName)
+	ErrPortForwardTarget     = errcode.NewError(portForwardBaseCode+2, "no ready pod or port to forward to")
+	ErrPortForwardLimit      = errcode.NewError(portForwardBaseCode+3, "too many "+portForwardName+" sessions, close one first")
+	ErrPortForwardConnection = errcode.NewError(portForwardBaseCode+4, "failed to connect to the forwarded port")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -11,14 +11,21 @@ var (
 	roleName     = "role"
 	roleBaseCode = errcode.HCode(roleNO)
 
-	ErrCreateRole         = errcode.NewError(roleBaseCode+1, "failed to create "+roleName)
-	ErrDeleteByIDRole     = errcode.NewError(roleBaseCode+2, "failed to delete "+roleName)
-	ErrDeleteByIDsRole    = errcode.NewError(roleBaseCode+3, "failed to delete by batch ids "+roleName)
-	ErrUpdateByIDRole     = errcode.NewError(roleBaseCode+4, "failed to update "+roleName)
-	ErrGetByIDRole        = errcode.NewError(roleBaseCode+5, "failed to get "+roleName+" details")
-	ErrGetByConditionRole = errcode.NewError(roleBaseCode+6, "failed to get "+roleName+" details by conditions")
-	ErrListByIDsRole      = errcode.NewError(roleBaseCode+7, "failed to list by batch ids "+roleName)
-	ErrListByLastIDRole   = errcode.NewError(roleBaseCode+8, "failed to list by last id "+roleName)
-	ErrListRole           = errcode.NewError(roleBaseCode+9, "failed to list of "+roleName)
+	ErrCreateRole          = errcode.NewError(roleBaseCode+1, "failed to create "+roleName)
+	ErrDeleteByIDRole      = errcode.NewError(roleBaseCode+2, "failed to delete "+roleName)
+	ErrDeleteByIDsRole     = errcode.NewError(roleBaseCode+3, "failed to delete by batch ids "+roleName)
+	ErrUpdateByIDRole      = errcode.NewError(roleBaseCode+4, "failed to update "+roleName)
+	ErrGetByIDRole         = errcode.NewError(roleBaseCode+5, "failed to get "+roleName+" details")
+	ErrGetByConditionRole  = errcode.NewError(roleBaseCode+6, "failed to get "+roleName+" details by conditions")
+	ErrListByIDsRole       = errcode.NewError(roleBaseCode+7, "failed to list by batch ids "+roleName)
+	ErrListByLastIDRole    = errcode.NewError(roleBaseCode+8, "failed to list by last id "+roleName)
+	ErrListRole            = errcode.NewError(roleBaseCode+9, "failed to list of "+roleName)
+	ErrListTrashRole       = errcode.NewError(roleBaseCode+10, "failed to list deleted "+roleName)
+	ErrRestoreRole         = errcode.NewError(roleBaseCode+11, "failed to restore "+roleName)
+	ErrPurgeRole           = errcode.NewError(roleBaseCode+12, "failed to purge "+roleName)
+	ErrRoleConflict        = errcode.NewError(roleBaseCode+13, "a "+roleName+" with the same role key already exists")
+	ErrRoleVersionConflict = errcode.NewError(roleBaseCode+14, "the "+roleName+" has been modified by someone else, get it again and retry")
+	ErrImportRole          = errcode.NewError(roleBaseCode+15, "failed to import "+roleName+"s")
+	ErrExportRole          = errcode.NewError(roleBaseCode+16, "failed to export "+roleName+"s")
 	// error codes are globally unique, adding 1 to the previous error code
 )
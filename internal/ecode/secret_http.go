@@ -0,0 +1,21 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// secret business-level http error codes.
+// the secretNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	secretNO       = 11
+	secretName     = "secret"
+	secretBaseCode = errcode.HCode(secretNO)
+
+	ErrListSecret      = errcode.NewError(secretBaseCode+1, "failed to list of "+secretName)
+	ErrGetSecret       = errcode.NewError(secretBaseCode+2, "failed to get "+secretName+" details")
+	ErrRevealSecret    = errcode.NewError(secretBaseCode+3, "failed to reveal "+secretName)
+	ErrSetSecretKey    = errcode.NewError(secretBaseCode+4, "failed to set "+secretName+" key")
+	ErrDeleteSecretKey = errcode.NewError(secretBaseCode+5, "failed to delete "+secretName+" key")
+	ErrSecretValue     = errcode.NewError(secretBaseCode+6, "invalid base64 "+secretName+" value")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// storage business-level http error codes.
+// the storageNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	storageNO       = 14
+	storageName     = "storage"
+	storageBaseCode = errcode.HCode(storageNO)
+
+	ErrListPVCs                  = errcode.NewError(storageBaseCode+1, "failed to list of persistentVolumeClaims")
+	ErrListPVs                   = errcode.NewError(storageBaseCode+2, "failed to list of persistentVolumes")
+	ErrListStorageClasses        = errcode.NewError(storageBaseCode+3, "failed to list of storageClasses")
+	ErrExpandPVC                 = errcode.NewError(storageBaseCode+4, "failed to expand persistentVolumeClaim")
+	ErrVolumeExpansionNotAllowed = errcode.NewError(storageBaseCode+5, storageName+" volume expansion is not allowed")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -21,6 +21,42 @@ var (
 	ErrListByLastIDUser   = errcode.NewError(userBaseCode+8, "failed to list by last id "+userName)
 	ErrListUser           = errcode.NewError(userBaseCode+9, "failed to list of "+userName)
 
-	ErrLogin = errcode.NewError(userBaseCode+10, "username or passwd error ")
+	ErrLogin                = errcode.NewError(userBaseCode+10, "username or passwd error ")
+	ErrGetUserRoles         = errcode.NewError(userBaseCode+11, "failed to get "+userName+" roles")
+	ErrSetUserRoles         = errcode.NewError(userBaseCode+12, "failed to set "+userName+" roles")
+	ErrListTrashUser        = errcode.NewError(userBaseCode+13, "failed to list deleted "+userName)
+	ErrRestoreUser          = errcode.NewError(userBaseCode+14, "failed to restore "+userName)
+	ErrPurgeUser            = errcode.NewError(userBaseCode+15, "failed to purge "+userName)
+	ErrUserConflict         = errcode.NewError(userBaseCode+16, "a "+userName+" with the same name already exists")
+	ErrUserVersionConflict  = errcode.NewError(userBaseCode+17, "the "+userName+" has been modified by someone else, get it again and retry")
+	ErrImportUser           = errcode.NewError(userBaseCode+18, "failed to import "+userName+"s")
+	ErrExportUser           = errcode.NewError(userBaseCode+19, "failed to export "+userName+"s")
+	ErrChangePassword       = errcode.NewError(userBaseCode+20, "failed to change password")
+	ErrWrongPassword        = errcode.NewError(userBaseCode+21, "the current password is wrong")
+	ErrUploadAvatar         = errcode.NewError(userBaseCode+22, "failed to upload avatar")
+	ErrAvatarImage          = errcode.NewError(userBaseCode+23, "the avatar must be a png, jpeg, gif or webp image")
+	ErrLoginLocked          = errcode.NewError(userBaseCode+24, "too many failed logins, the account is locked temporarily")
+	ErrLoginThrottled       = errcode.NewError(userBaseCode+25, "too many failed logins from this address, retry later")
+	ErrUserBlocked          = errcode.NewError(userBaseCode+26, "the "+userName+" is blocked, contact an admin")
+	ErrUnlockUser           = errcode.NewError(userBaseCode+27, "failed to unlock "+userName)
+	ErrListLoginHistory     = errcode.NewError(userBaseCode+28, "failed to list login history")
+	ErrOIDCDisabled         = errcode.NewError(userBaseCode+29, "single sign-on is not enabled")
+	ErrOIDCLogin            = errcode.NewError(userBaseCode+30, "single sign-on failed, retry the login")
+	ErrUserNotLinked        = errcode.NewError(userBaseCode+31, "no "+userName+" is linked to the account of the identity provider")
+	ErrTwoFactorChallenge   = errcode.NewError(userBaseCode+32, "the two-factor login is expired or invalid, log in again")
+	ErrTwoFactorCode        = errcode.NewError(userBaseCode+33, "invalid two-factor code")
+	ErrTwoFactorEnabled     = errcode.NewError(userBaseCode+34, "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errcode.NewError(userBaseCode+35, "two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errcode.NewError(userBaseCode+36, "no two-factor secret is enrolled")
+	ErrTwoFactorRequired    = errcode.NewError(userBaseCode+37, "two-factor authentication is required by the roles of the "+userName)
+	ErrCreateApiToken       = errcode.NewError(userBaseCode+38, "failed to create api token")
+	ErrApiTokenScope        = errcode.NewError(userBaseCode+39, "the scopes of an api token must be existing apis")
+	ErrApiTokenLifetime     = errcode.NewError(userBaseCode+40, "the lifetime of the api token exceeds the maximum")
+	ErrApiTokenLimit        = errcode.NewError(userBaseCode+41, "too many active api tokens, revoke one first")
+	ErrApiTokenNotFound     = errcode.NewError(userBaseCode+42, "api token not found")
+	ErrApiTokenForbidden    = errcode.NewError(userBaseCode+43, "api tokens can not manage api tokens, log in with a password")
+	ErrSessionNotFound      = errcode.NewError(userBaseCode+44, "session not found")
+	ErrTwoFactorLocked      = errcode.NewError(userBaseCode+45, "too many invalid two-factor codes, try again later")
+	ErrAvatarTooLarge       = errcode.NewError(userBaseCode+46, "the avatar image is too large")
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/zhufuyi/sponge/pkg/errcode"
+)
+
+// workload business-level http error codes.
+// the workloadNO value range is 1~100, if the same number appears, it will cause a failure to start the service.
+var (
+	workloadNO       = 13
+	workloadName     = "workload"
+	workloadBaseCode = errcode.HCode(workloadNO)
+
+	ErrListWorkloadRevisions    = errcode.NewError(workloadBaseCode+1, "failed to list of "+workloadName+" revisions")
+	ErrDiffWorkloadRevisions    = errcode.NewError(workloadBaseCode+2, "failed to diff "+workloadName+" revisions")
+	ErrRollbackWorkload         = errcode.NewError(workloadBaseCode+3, "failed to roll back "+workloadName)
+	ErrWorkloadRevisionNotFound = errcode.NewError(workloadBaseCode+4, workloadName+" revision not found")
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,103 @@
+// Package elevation ends the temporary roles of the users at their expiry. A role is no longer held once its
+// expiry is past whether it was reaped or not, the reaper records the end of the grants for the audit.
+package elevation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zhufuyi/sponge/pkg/app"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/model"
+)
+
+var _ app.IServer = (*Reaper)(nil)
+
+// Expirer ends the grants whose expiry is past at the unix time
+type Expirer interface {
+	ExpireDue(ctx context.Context, now int64) ([]*model.RoleElevation, error)
+}
+
+// ExpireFunc is called for each grant expired by the reaper, e.g. to remove what the role permitted out of the app
+type ExpireFunc func(ctx context.Context, record *model.RoleElevation)
+
+// ReaperOption set the options of the reaper
+type ReaperOption func(*Reaper)
+
+// WithOnExpire call fn for each expired grant
+func WithOnExpire(fn ExpireFunc) ReaperOption {
+	return func(r *Reaper) {
+		r.onExpire = fn
+	}
+}
+
+// Reaper expires the grants at an interval, it runs as a service of the app
+type Reaper struct {
+	expirer  Expirer
+	interval time.Duration
+	now      func() time.Time
+	onExpire ExpireFunc
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReaper creating a reaper, the interval defaults to a minute
+func NewReaper(expirer Expirer, interval time.Duration, opts ...ReaperOption) *Reaper {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	r := &Reaper{
+		expirer:  expirer,
+		interval: interval,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start expire the grants at every interval until Stop is called
+func (r *Reaper) Start() error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.Reap(context.Background())
+		select {
+		case <-r.stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop the reaper
+func (r *Reaper) Stop() error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	return nil
+}
+
+// String comment
+func (r *Reaper) String() string {
+	return "role elevation reaper, interval " + r.interval.String()
+}
+
+// Reap expire the grants whose expiry is past once, the number of expired grants is returned
+func (r *Reaper) Reap(ctx context.Context) int {
+	expired, err := r.expirer.ExpireDue(ctx, r.now().Unix())
+	for _, record := range expired {
+		logger.Info("role elevation expired", logger.Uint64("id", record.ID), logger.Uint64("userID", record.UserID),
+			logger.Uint64("roleID", record.RoleID), logger.Int64("expiresAt", record.ExpiresAt))
+		if r.onExpire != nil {
+			r.onExpire(ctx, record)
+		}
+	}
+	if err != nil {
+		logger.Error("ExpireDue error", logger.Err(err))
+	}
+	return len(expired)
+}
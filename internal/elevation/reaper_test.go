@@ -0,0 +1,68 @@
+package elevation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-admin/internal/model"
+)
+
+type mockExpirer struct {
+	mu    sync.Mutex
+	calls []int64
+	err   error
+}
+
+func (m *mockExpirer) ExpireDue(_ context.Context, now int64) ([]*model.RoleElevation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, now)
+	return []*model.RoleElevation{{UserID: 1, RoleID: 2, ExpiresAt: now}}, m.err
+}
+
+func (m *mockExpirer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+func TestReaper_Reap(t *testing.T) {
+	expirer := &mockExpirer{}
+	var expired []*model.RoleElevation
+	r := NewReaper(expirer, 0, WithOnExpire(func(_ context.Context, record *model.RoleElevation) {
+		expired = append(expired, record)
+	}))
+	assert.Equal(t, time.Minute, r.interval)
+	r.now = func() time.Time { return time.Unix(100, 0) }
+	assert.Equal(t, 1, r.Reap(context.Background()))
+	assert.Equal(t, []int64{100}, expirer.calls)
+	assert.Equal(t, []*model.RoleElevation{{UserID: 1, RoleID: 2, ExpiresAt: 100}}, expired)
+
+	// the grants expired before an error are still counted and passed to the hook
+	expirer.err = errors.New("failed")
+	assert.Equal(t, 1, r.Reap(context.Background()))
+	assert.Len(t, expired, 2)
+}
+
+func TestReaper_StartStop(t *testing.T) {
+	expirer := &mockExpirer{}
+	r := NewReaper(expirer, 10*time.Millisecond)
+	done := make(chan error)
+	go func() { done <- r.Start() }()
+
+	assert.Eventually(t, func() bool { return expirer.count() >= 2 }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, r.Stop())
+	assert.NoError(t, r.Stop())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the reaper did not stop")
+	}
+	assert.Contains(t, r.String(), "10ms")
+}
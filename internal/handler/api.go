@@ -3,10 +3,13 @@ package handler
 import (
 	"errors"
 	"math"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
 
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
 	"github.com/zhufuyi/sponge/pkg/gin/middleware"
 	"github.com/zhufuyi/sponge/pkg/gin/response"
 	"github.com/zhufuyi/sponge/pkg/logger"
@@ -27,20 +30,28 @@ type ApiHandler interface {
 	DeleteByID(c *gin.Context)
 	DeleteByIDs(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	GetByCondition(c *gin.Context)
 	ListByIDs(c *gin.Context)
 	ListByLastID(c *gin.Context)
 	List(c *gin.Context)
+	ListTrash(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
 }
 
 type apiHandler struct {
+	db   *gorm.DB
 	iDao dao.ApiDao
 }
 
 // NewApiHandler creating the handler interface
 func NewApiHandler() ApiHandler {
 	return &apiHandler{
+		db: model.GetDB(),
 		iDao: dao.NewApiDao(
 			model.GetDB(),
 			cache.NewApiCache(model.GetCacheType()),
@@ -102,8 +113,13 @@ func (h *apiHandler) DeleteByID(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
+	err := h.iDao.DeleteByID(ctx, id, deletedBy)
 	if err != nil {
 		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -132,8 +148,13 @@ func (h *apiHandler) DeleteByIDs(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err = h.iDao.DeleteByIDs(ctx, form.IDs)
+	err = h.iDao.DeleteByIDs(ctx, form.IDs, deletedBy)
 	if err != nil {
 		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -151,7 +172,9 @@ func (h *apiHandler) DeleteByIDs(c *gin.Context) {
 // @Produce json
 // @Param id path string true "id"
 // @Param data body types.UpdateApiByIDRequest true "api information"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
 // @Success 200 {object} types.UpdateApiByIDRespond{}
+// @Header 200 {string} ETag "version of the updated api"
 // @Router /api/v1/api/{id} [put]
 // @Security BearerAuth
 func (h *apiHandler) UpdateByID(c *gin.Context) {
@@ -169,6 +192,20 @@ func (h *apiHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		form.Version = ifMatch
+	}
+	if form.Version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
 
 	api := &model.Api{}
 	err = copier.Copy(api, form)
@@ -180,11 +217,88 @@ func (h *apiHandler) UpdateByID(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, api)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("UpdateByID version conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrApiVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
+	setETag(c, form.Version+1)
+	response.Success(c)
+}
+
+// PatchByID partially update a record by id
+// @Summary patch api
+// @Description update the fields present in the JSON merge patch (RFC 7386) of the api, the absent fields are unchanged and null clears a field,
+// @Description the path and the action can not be cleared, the values are validated by the rules of the update.
+// @Tags api
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateApiByIDRequest true "the fields to change, the version is required unless the If-Match header is set"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
+// @Success 200 {object} types.UpdateApiByIDRespond{}
+// @Header 200 {string} ETag "version of the updated api"
+// @Router /api/v1/api/{id} [patch]
+// @Security BearerAuth
+func (h *apiHandler) PatchByID(c *gin.Context) {
+	_, id, isAbort := getApiIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		logger.Warn("GetRawData error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	columns, version, err := parseMergePatch(data, &model.Api{}, &types.UpdateApiByIDRequest{}, "path", "action")
+	if err != nil {
+		logger.Warn("parseMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		version = ifMatch
+	}
+	if version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.PatchByID(ctx, id, version, columns)
+	if err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("PatchByID version conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrApiVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("PatchByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("id", id), logger.Any("columns", columns), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, version+1)
 	response.Success(c)
 }
 
@@ -195,7 +309,9 @@ func (h *apiHandler) UpdateByID(c *gin.Context) {
 // @Param id path string true "id"
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag returned by a previous get, the status is 304 if the api has not been modified"
 // @Success 200 {object} types.GetApiByIDRespond{}
+// @Header 200 {string} ETag "version of the api, send it in the If-Match header to update the api"
 // @Router /api/v1/api/{id} [get]
 // @Security BearerAuth
 func (h *apiHandler) GetByID(c *gin.Context) {
@@ -217,6 +333,9 @@ func (h *apiHandler) GetByID(c *gin.Context) {
 		}
 		return
 	}
+	if isNotModified(c, api.Version) {
+		return
+	}
 
 	data := &types.ApiObjDetail{}
 	err = copier.Copy(data, api)
@@ -226,6 +345,7 @@ func (h *apiHandler) GetByID(c *gin.Context) {
 	}
 	data.ID = idStr
 
+	setETag(c, api.Version)
 	response.Success(c, gin.H{"api": data})
 }
 
@@ -403,6 +523,226 @@ func (h *apiHandler) List(c *gin.Context) {
 	})
 }
 
+// ListTrash list of the soft deleted records by query parameters
+// @Summary list of deleted apis
+// @Description list of the apis in the trash by paging and conditions, they can be restored or purged
+// @Tags api
+// @accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListTrashApisRespond{}
+// @Router /api/v1/api/trash/list [post]
+// @Security BearerAuth
+func (h *apiHandler) ListTrash(c *gin.Context) {
+	form := &types.ListTrashApisRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	apis, total, err := h.iDao.GetTrash(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetTrash error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertApis(apis)
+	if err != nil {
+		response.Error(c, ecode.ErrListTrashApi)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"apis":  data,
+		"total": total,
+	})
+}
+
+// Restore a soft deleted record by id
+// @Summary restore api
+// @Description restore the deleted api by id, it fails if another api with the same action and path exists
+// @Tags api
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreApiRespond{}
+// @Router /api/v1/api/trash/{id}/restore [post]
+// @Security BearerAuth
+func (h *apiHandler) Restore(c *gin.Context) {
+	_, id, isAbort := getApiIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Restore not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else if errors.Is(err, model.ErrRecordConflict) {
+			logger.Warn("Restore conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrApiConflict)
+		} else {
+			logger.Error("Restore error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRestoreApi)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Purge permanently delete a soft deleted record by id
+// @Summary purge api
+// @Description permanently delete the api in the trash by id, the apis that are not deleted can not be purged
+// @Tags api
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.PurgeApiRespond{}
+// @Router /api/v1/api/trash/{id} [delete]
+// @Security BearerAuth
+func (h *apiHandler) Purge(c *gin.Context) {
+	_, id, isAbort := getApiIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Purge(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Purge not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("Purge error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrPurgeApi)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Import create or update records from a file
+// @Summary import apis
+// @Description create or update apis from a csv, json or yaml file in one transaction, the apis are matched by action and path.
+// @Description every row is validated and reported, nothing is saved if a row is invalid or dryRun is true.
+// @Tags api
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Content-Type"
+// @Param dryRun query bool false "validate the rows without saving them"
+// @Param data body []types.ImportApiRow true "apis"
+// @Success 200 {object} types.ImportRespond{}
+// @Router /api/v1/api/import [post]
+// @Security BearerAuth
+func (h *apiHandler) Import(c *gin.Context) {
+	form := []*types.ImportApiRow{}
+	err := decodeImport(c, &form)
+	if err != nil {
+		logger.Warn("decodeImport error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if len(form) == 0 {
+		response.Error(c, ecode.InvalidParams.WithDetails("no rows to import"))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	rows := make([]importRow, 0, len(form))
+	for _, row := range form {
+		rows = append(rows, importRow{key: row.Action + " " + row.Path, value: row})
+	}
+
+	ctx := middleware.WrapCtx(c)
+	result, err := runImport(h.db.WithContext(ctx), rows, dryRun, func(tx *gorm.DB, value interface{}) (string, error) {
+		row := value.(*types.ImportApiRow)
+		api := &model.Api{}
+		if err := copier.Copy(api, row); err != nil {
+			return "", err
+		}
+
+		record, err := h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "action", Value: row.Action}, {Name: "path", Value: row.Path}}})
+		if errors.Is(err, model.ErrRecordNotFound) {
+			_, err = h.iDao.CreateByTx(ctx, tx, api)
+			return importCreated, err
+		}
+		if err != nil {
+			return "", err
+		}
+		api.ID = record.ID // the version is not checked, the import overwrites the non-empty fields
+		return importUpdated, h.iDao.UpdateByTx(ctx, tx, api)
+	})
+	if err != nil {
+		logger.Error("Import error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrImportApi)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Export write the records matching the query conditions to a file
+// @Summary export apis
+// @Description export the apis matching the query conditions to a csv, json or yaml file, the file can be imported again.
+// @Tags api
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Accept header"
+// @Param data body types.ExportApisRequest true "query conditions"
+// @Success 200 {array} types.ImportApiRow
+// @Router /api/v1/api/export [post]
+// @Security BearerAuth
+func (h *apiHandler) Export(c *gin.Context) {
+	format, err := getExportFormat(c)
+	if err != nil {
+		logger.Warn("getExportFormat error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	form := &types.ExportApisRequest{}
+	err = c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = exportRecords(c, format, "apis", &types.ImportApiRow{}, &query.Params{Sort: form.Sort, Columns: form.Columns}, func(params *query.Params) ([]interface{}, error) {
+		records, _, err := h.iDao.GetByColumns(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]interface{}, 0, len(records))
+		for _, record := range records {
+			row := &types.ImportApiRow{}
+			if err = copier.Copy(row, record); err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	})
+	if err != nil {
+		logger.Error("Export error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() {
+			response.Error(c, ecode.ErrExportApi)
+		}
+		return
+	}
+}
+
 func getApiIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -421,6 +761,9 @@ func convertApi(api *model.Api) (*types.ApiObjDetail, error) {
 		return nil, err
 	}
 	data.ID = utils.Uint64ToStr(api.ID)
+	if api.DeletedAt.Valid {
+		data.DeletedAt = &api.DeletedAt.Time
+	}
 	return data, nil
 }
 
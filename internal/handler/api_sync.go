@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/apisync"
+	"go-admin/internal/cache"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+)
+
+var _ ApiSyncHandler = (*apiSyncHandler)(nil)
+
+// ApiSyncHandler defining the handler interface
+type ApiSyncHandler interface {
+	Sync(c *gin.Context)
+}
+
+type apiSyncHandler struct {
+	db     *gorm.DB
+	iCache cache.ApiCache
+	routes func() gin.RoutesInfo
+}
+
+// NewApiSyncHandler creating the handler interface, routes returns the registered routes of the server
+func NewApiSyncHandler(routes func() gin.RoutesInfo) ApiSyncHandler {
+	return &apiSyncHandler{
+		db:     model.GetDB(),
+		iCache: cache.NewApiCache(model.GetCacheType()),
+		routes: routes,
+	}
+}
+
+// Sync the api rows with the registered routes
+// @Summary sync apis
+// @Description create the api rows of the new routes, update the handle and title from the handler names and swagger summaries, and mark the rows whose routes are gone as stale,
+// @Description only for the admin roles
+// @Tags api
+// @Produce json
+// @Param dryRun query bool false "only show the changes without saving them" default(false)
+// @Success 200 {object} types.SyncApisRespond{}
+// @Router /api/v1/api/sync [post]
+// @Security BearerAuth
+func (h *apiSyncHandler) Sync(c *gin.Context) {
+	dryRun := c.Query("dryRun") == "true"
+
+	ctx := middleware.WrapCtx(c)
+	changes, err := apisync.Sync(ctx, h.db, h.routes(), dryRun)
+	if err != nil {
+		logger.Error("Sync error", logger.Err(err), logger.Bool("dryRun", dryRun), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSyncApi)
+		return
+	}
+
+	if !dryRun {
+		for _, change := range changes {
+			if change.ID == 0 || change.Change == apisync.ChangeCreated {
+				continue
+			}
+			if err = h.iCache.Del(ctx, change.ID); err != nil {
+				logger.Warn("Del api cache error", logger.Err(err), logger.Uint64("id", change.ID), middleware.GCtxRequestIDField(c))
+			}
+		}
+	}
+
+	response.Success(c, gin.H{
+		"dryRun":  dryRun,
+		"changes": changes,
+	})
+}
@@ -16,6 +16,7 @@ import (
 
 	"go-admin/internal/cache"
 	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
 	"go-admin/internal/model"
 	"go-admin/internal/types"
 )
@@ -54,13 +55,13 @@ func newApiHandler() *gotest.Handler {
 			FuncName:    "DeleteByID",
 			Method:      http.MethodDelete,
 			Path:        "/api/:id",
-			HandlerFunc: iHandler.DeleteByID,
+			HandlerFunc: withCaller("1", iHandler.DeleteByID),
 		},
 		{
 			FuncName:    "DeleteByIDs",
 			Method:      http.MethodPost,
 			Path:        "/api/delete/ids",
-			HandlerFunc: iHandler.DeleteByIDs,
+			HandlerFunc: withCaller("1", iHandler.DeleteByIDs),
 		},
 		{
 			FuncName:    "UpdateByID",
@@ -68,6 +69,12 @@ func newApiHandler() *gotest.Handler {
 			Path:        "/api/:id",
 			HandlerFunc: iHandler.UpdateByID,
 		},
+		{
+			FuncName:    "PatchByID",
+			Method:      http.MethodPatch,
+			Path:        "/api/:id",
+			HandlerFunc: iHandler.PatchByID,
+		},
 		{
 			FuncName:    "GetByID",
 			Method:      http.MethodGet,
@@ -98,6 +105,24 @@ func newApiHandler() *gotest.Handler {
 			Path:        "/api/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "ListTrash",
+			Method:      http.MethodPost,
+			Path:        "/api/trash/list",
+			HandlerFunc: iHandler.ListTrash,
+		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/api/trash/:id/restore",
+			HandlerFunc: iHandler.Restore,
+		},
+		{
+			FuncName:    "Purge",
+			Method:      http.MethodDelete,
+			Path:        "/api/trash/:id",
+			HandlerFunc: iHandler.Purge,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -136,7 +161,7 @@ func Test_apiHandler_DeleteByID(t *testing.T) {
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, 1, h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -165,7 +190,7 @@ func Test_apiHandler_DeleteByIDs(t *testing.T) {
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, 1, h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -192,12 +217,21 @@ func Test_apiHandler_UpdateByID(t *testing.T) {
 	defer h.Close()
 	testData := &types.UpdateApiByIDRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Api))
+	testData.Version = 1
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, testData.ID, testData.Version). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
+	// the version has been changed by another update
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(h.MockDao.AnyTime, testData.ID, testData.Version).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
 	result := &gohttp.StdResult{}
 	err := gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
@@ -208,6 +242,17 @@ func Test_apiHandler_UpdateByID(t *testing.T) {
 		t.Fatalf("%+v", result)
 	}
 
+	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrApiVersionConflict.Code(), result.Code)
+
+	// missing version error test
+	testData.Version = 0
+	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	testData.Version = 1
+
 	// zero id error test
 	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
 	assert.NoError(t, err)
@@ -217,6 +262,45 @@ func Test_apiHandler_UpdateByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_apiHandler_PatchByID(t *testing.T) {
+	h := newApiHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Api)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs("", h.MockDao.AnyTime, testData.ID, 1). // the zero values are updated
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"title": "", "version": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// unknown field error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"foo": 1, "version": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// missing version error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"createBy": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// zero id error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", 0), map[string]interface{}{"title": "", "version": 1})
+	assert.NoError(t, err)
+
+	// update error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", 111), map[string]interface{}{"title": "", "version": 1})
+	assert.Error(t, err)
+}
+
 func Test_apiHandler_GetByID(t *testing.T) {
 	h := newApiHandler()
 	defer h.Close()
@@ -259,7 +343,7 @@ func Test_apiHandler_GetByCondition(t *testing.T) {
 
 	result := &gohttp.StdResult{}
 	err := gohttp.Post(result, h.GetRequestURL("GetByCondition"), &types.GetApiByConditionRequest{
-		query.Conditions{
+		Conditions: query.Conditions{
 			Columns: []query.Column{
 				{
 					Name:  "id",
@@ -281,7 +365,7 @@ func Test_apiHandler_GetByCondition(t *testing.T) {
 
 	// get error test
 	err = gohttp.Post(result, h.GetRequestURL("GetByCondition"), &types.GetApiByConditionRequest{
-		query.Conditions{
+		Conditions: query.Conditions{
 			Columns: []query.Column{
 				{
 					Name:  "id",
@@ -355,7 +439,7 @@ func Test_apiHandler_List(t *testing.T) {
 	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 
 	result := &gohttp.StdResult{}
-	err := gohttp.Post(result, h.GetRequestURL("List"), &types.ListApisRequest{query.Params{
+	err := gohttp.Post(result, h.GetRequestURL("List"), &types.ListApisRequest{Params: query.Params{
 		Page: 0,
 		Size: 10,
 		Sort: "ignore count", // ignore test count
@@ -372,7 +456,43 @@ func Test_apiHandler_List(t *testing.T) {
 	assert.NoError(t, err)
 
 	// get error test
-	err = gohttp.Post(result, h.GetRequestURL("List"), &types.ListApisRequest{query.Params{
+	err = gohttp.Post(result, h.GetRequestURL("List"), &types.ListApisRequest{Params: query.Params{
+		Page: 0,
+		Size: 10,
+		Sort: "unknown-column",
+	}})
+	assert.Error(t, err)
+}
+
+func Test_apiHandler_ListTrash(t *testing.T) {
+	h := newApiHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Api)
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "deleted_by"}).
+		AddRow(testData.ID, testData.CreatedAt, testData.UpdatedAt, testData.UpdatedAt, 1)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Post(result, h.GetRequestURL("ListTrash"), &types.ListTrashApisRequest{Params: query.Params{
+		Page: 0,
+		Size: 10,
+		Sort: "ignore count", // ignore test count
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// nil params error test
+	err = gohttp.Post(result, h.GetRequestURL("ListTrash"), nil)
+	assert.NoError(t, err)
+
+	// get error test
+	err = gohttp.Post(result, h.GetRequestURL("ListTrash"), &types.ListTrashApisRequest{Params: query.Params{
 		Page: 0,
 		Size: 10,
 		Sort: "unknown-column",
@@ -380,6 +500,69 @@ func Test_apiHandler_List(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_apiHandler_Restore(t *testing.T) {
+	h := newApiHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Api)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(testData.ID, testData.UpdatedAt))
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = gohttp.Post(result, h.GetRequestURL("Restore", 0), nil)
+	assert.NoError(t, err)
+
+	// restore error test
+	err = gohttp.Post(result, h.GetRequestURL("Restore", 111), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
+func Test_apiHandler_Purge(t *testing.T) {
+	h := newApiHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Api)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("DELETE .*").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Delete(result, h.GetRequestURL("Purge", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = gohttp.Delete(result, h.GetRequestURL("Purge", 0))
+	assert.NoError(t, err)
+
+	// purge error test
+	err = gohttp.Delete(result, h.GetRequestURL("Purge", 111))
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
 func TestNewApiHandler(t *testing.T) {
 	defer func() {
 		recover()
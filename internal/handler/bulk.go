@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
+
+	"go-admin/internal/bulk"
+	"go-admin/internal/types"
+)
+
+const (
+	maxImportSize  = 10 << 20 // maximum bytes of an imported file
+	exportPageSize = 500      // records read from the database at a time by an export
+
+	importCreated = "created"
+	importUpdated = "updated"
+	importInvalid = "invalid"
+)
+
+// errImportRollback rolls back the transaction of an import that has invalid rows or is a dry run
+var errImportRollback = errors.New("import rolled back")
+
+// errInvalidRow is returned by an upsert if the row can not be saved, the message is reported to the client
+type errInvalidRow string
+
+func (e errInvalidRow) Error() string {
+	return string(e)
+}
+
+// importRow a decoded row of an import, key is the unique key the row is matched by
+type importRow struct {
+	key   string
+	value interface{}
+}
+
+// decodeImport decode the request body into rows, the format is the format query parameter or derived from the content type
+func decodeImport(c *gin.Context, rows interface{}) error {
+	format, err := bulk.Format(c.Query("format"), c.ContentType())
+	if err != nil {
+		return err
+	}
+	return bulk.Decode(format, http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize), rows)
+}
+
+// runImport validate the rows by their binding rules and upsert them in one transaction,
+// upsert returns importCreated or importUpdated, or an errInvalidRow if the row can not be saved.
+// nothing is saved if a row is invalid or dryRun is true, any other error of upsert is returned.
+func runImport(db *gorm.DB, rows []importRow, dryRun bool, upsert func(tx *gorm.DB, value interface{}) (string, error)) (*types.ImportResult, error) {
+	result := &types.ImportResult{Rows: make([]*types.ImportRowResult, 0, len(rows))}
+	seen := map[string]int{}
+	for i, row := range rows {
+		r := &types.ImportRowResult{Row: i + 1, Key: row.key}
+		if err := binding.Validator.ValidateStruct(row.value); err != nil {
+			r.Action, r.Error = importInvalid, err.Error()
+		} else if n, ok := seen[row.key]; ok {
+			r.Action, r.Error = importInvalid, fmt.Sprintf("duplicate of row %d", n)
+		} else {
+			seen[row.key] = i + 1
+		}
+		result.Rows = append(result.Rows, r)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i, row := range rows {
+			r := result.Rows[i]
+			if r.Action == importInvalid {
+				continue
+			}
+			action, err := upsert(tx, row.value)
+			if err != nil {
+				var invalid errInvalidRow
+				if errors.As(err, &invalid) {
+					r.Action, r.Error = importInvalid, invalid.Error()
+					continue
+				}
+				return fmt.Errorf("row %d: %w", r.Row, err)
+			}
+			r.Action = action
+		}
+
+		for _, r := range result.Rows {
+			switch r.Action {
+			case importCreated:
+				result.Created++
+			case importUpdated:
+				result.Updated++
+			default:
+				result.Invalid++
+			}
+		}
+		if result.Invalid > 0 || dryRun {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+
+	result.Imported = err == nil
+	return result, nil
+}
+
+// getExportFormat get the format of an export from the format query parameter or the Accept header
+func getExportFormat(c *gin.Context) (string, error) {
+	return bulk.Format(c.Query("format"), c.GetHeader("Accept"))
+}
+
+// exportRecords write the records matching params to the response as an attachment named name,
+// the records are read page by page and next converts a page of records to rows.
+// the response is written from the first page, if an error occurs later c.Writer.Written() is true
+// and only the connection can be aborted.
+func exportRecords(c *gin.Context, format string, name string, rowType interface{}, params *query.Params,
+	next func(params *query.Params) ([]interface{}, error)) error {
+	params.Page = 0
+	params.Size = exportPageSize
+	rows, err := next(params)
+	if err != nil {
+		return err
+	}
+
+	c.Header("Content-Type", bulk.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Status(http.StatusOK)
+	encoder, err := bulk.NewEncoder(format, c.Writer, rowType)
+	if err != nil {
+		return err
+	}
+
+	for {
+		for _, row := range rows {
+			if err = encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < exportPageSize {
+			break
+		}
+
+		params.Page++
+		rows, err = next(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+func newBulkSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	if _, err = migration.Up(db, ggorm.DBDriverSqlite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func doBulkRequest(r http.Handler, path string, contentType string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// withCaller set the uid of the caller as middleware.Auth does
+func withCaller(uid string, fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("uid", uid)
+		fn(c)
+	}
+}
+
+func Test_userHandler_Import_Export(t *testing.T) {
+	db := newBulkSQLiteDB(t)
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(context.Background(), &model.User{Name: "foo", Password: "old", Email: "foo@example.com", Status: 1}))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &userHandler{db: db, iDao: iDao}
+	r.POST("/user/import", h.Import)
+	r.POST("/user/export", h.Export)
+
+	getResult := func(w *httptest.ResponseRecorder) *types.ImportResult {
+		out := &types.ImportRespond{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+		return &out.Data
+	}
+
+	// an invalid row rejects the whole file
+	csv := "name,password,email,status\nfoo,,foo@example.org,2\nbar,secret,bar,2\nbaz,,baz@example.com,1\n"
+	result := getResult(doBulkRequest(r, "/user/import", "text/csv", csv))
+	assert.False(t, result.Imported)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 2, result.Invalid)
+	assert.Equal(t, "invalid", result.Rows[1].Action) // invalid email
+	assert.Equal(t, "password is required to create the user", result.Rows[2].Error)
+	user, err := iDao.GetByName(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo@example.com", user.Email)
+
+	// a dry run reports the actions without saving them
+	csv = "name,password,email,status\nfoo,,foo@example.org,2\nbar,secret,bar@example.com,2\n"
+	result = getResult(doBulkRequest(r, "/user/import?dryRun=true", "text/csv", csv))
+	assert.False(t, result.Imported)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	user, _ = iDao.GetByName(context.Background(), "bar")
+	assert.Equal(t, uint64(0), user.ID)
+
+	result = getResult(doBulkRequest(r, "/user/import", "text/csv", csv))
+	assert.True(t, result.Imported)
+	user, _ = iDao.GetByName(context.Background(), "foo")
+	assert.Equal(t, "foo@example.org", user.Email)
+	assert.Equal(t, "old", user.Password) // the empty password leaves the password unchanged
+
+	// the password of an existing user is only changed on request
+	csv = "name,password\nfoo,new\n"
+	result = getResult(doBulkRequest(r, "/user/import", "text/csv", csv))
+	assert.True(t, result.Imported)
+	user, _ = iDao.GetByName(context.Background(), "foo")
+	assert.Equal(t, "old", user.Password)
+	result = getResult(doBulkRequest(r, "/user/import?resetPasswords=true", "text/csv", csv))
+	assert.True(t, result.Imported)
+	user, _ = iDao.GetByName(context.Background(), "foo")
+	assert.Equal(t, "new", user.Password)
+
+	// duplicate keys in the same file
+	yaml := "- name: qux\n  password: secret\n- name: qux\n  password: secret\n"
+	result = getResult(doBulkRequest(r, "/user/import?format=yaml", "application/octet-stream", yaml))
+	assert.False(t, result.Imported)
+	assert.Equal(t, "duplicate of row 1", result.Rows[1].Error)
+
+	// the export can be imported again, the passwords are not exported
+	w := doBulkRequest(r, "/user/export?format=csv", "application/json", `{"sort":"id"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="users.csv"`)
+	assert.Equal(t, "name,password,email,phone,avatar,age,gender,status\nfoo,,foo@example.org,,,0,0,2\nbar,,bar@example.com,,,0,0,2\n", w.Body.String())
+
+	w = doBulkRequest(r, "/user/export", "application/json", `{"columns":[{"name":"name","value":"bar"}]}`)
+	assert.Equal(t, "[\n{\"name\":\"bar\",\"email\":\"bar@example.com\",\"phone\":\"\",\"avatar\":\"\",\"age\":0,\"gender\":0,\"status\":2}\n]\n", w.Body.String())
+
+	w = doBulkRequest(r, "/user/export?format=xml", "application/json", `{}`)
+	assert.Contains(t, w.Body.String(), "unsupported format")
+}
+
+func Test_apiHandler_Import(t *testing.T) {
+	db := newBulkSQLiteDB(t)
+	iDao := dao.NewApiDao(db, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &apiHandler{db: db, iDao: iDao}
+	r.POST("/api/import", h.Import)
+
+	body := `[{"action":"GET","path":"/api/v1/user/:id","title":"get user"},{"action":"POST","path":"/api/v1/user/list"},{"action":"GET","path":"/api/v1/user/:id","title":"get user detail"}]`
+	w := doBulkRequest(r, "/api/import", "application/json", body)
+	assert.Contains(t, w.Body.String(), "duplicate of row 1")
+
+	body = `[{"action":"GET","path":"/api/v1/user/:id","title":"get user"},{"action":"POST","path":"/api/v1/user/list"}]`
+	w = doBulkRequest(r, "/api/import", "application/json", body)
+	assert.Contains(t, w.Body.String(), `"imported":true`)
+	body = `[{"action":"GET","path":"/api/v1/user/:id","title":"get user detail"}]`
+	w = doBulkRequest(r, "/api/import", "application/json", body)
+	assert.Contains(t, w.Body.String(), `"updated":1`)
+
+	api, err := iDao.GetByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "get user detail", api.Title)
+
+	w = doBulkRequest(r, "/api/import", "application/json", `[{"action":"FOO","path":"api"}]`)
+	assert.Contains(t, w.Body.String(), `"invalid":1`)
+	w = doBulkRequest(r, "/api/import", "application/json", `[]`)
+	assert.Contains(t, w.Body.String(), "no rows to import")
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/errcode"
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	"go-admin/internal/utils"
+)
+
+var _ ConfigMapHandler = (*configMapHandler)(nil)
+
+// ConfigMapHandler defining the handler interface
+type ConfigMapHandler interface {
+	List(c *gin.Context)
+	GetByName(c *gin.Context)
+	SetKey(c *gin.Context)
+	DeleteKey(c *gin.Context)
+}
+
+type configMapHandler struct {
+	newClient func() (kubernetes.Interface, error)
+	urDao     dao.UserRoleDao
+}
+
+// NewConfigMapHandler creating the handler interface
+func NewConfigMapHandler() ConfigMapHandler {
+	return &configMapHandler{
+		newClient: utils.NewKubeClient,
+		urDao:     dao.NewUserRoleDao(model.GetDB()),
+	}
+}
+
+// List of configMaps in namespace
+// @Summary list of configMaps
+// @Description list of configMaps in namespace
+// @Tags configMap
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Success 200 {object} types.ListConfigMapsRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/configmaps [get]
+// @Security BearerAuth
+func (h *configMapHandler) List(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	list, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List configMaps", err, ecode.ErrListConfigMap, logger.String("namespace", namespace))
+		return
+	}
+
+	configMaps := []*types.ConfigMapObjDetail{}
+	for i := range list.Items {
+		configMaps = append(configMaps, convertConfigMap(&list.Items[i]))
+	}
+
+	response.Success(c, gin.H{
+		"configMaps": configMaps,
+	})
+}
+
+// GetByName get a configMap by name
+// @Summary get configMap detail
+// @Description get configMap detail by name
+// @Tags configMap
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Success 200 {object} types.GetConfigMapRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/configmaps/{name} [get]
+// @Security BearerAuth
+func (h *configMapHandler) GetByName(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Get configMap", err, ecode.ErrGetConfigMap, logger.String("namespace", namespace), logger.String("name", name))
+		return
+	}
+
+	response.Success(c, gin.H{"configMap": convertConfigMap(configMap)})
+}
+
+// SetKey add or update a key of configMap, the other keys are kept as they are
+// @Summary set configMap key
+// @Description add or update a key of configMap, base64 values are stored in binaryData, the other keys are kept as they are,
+// @Description only for roles configured in k8s.writeRoles
+// @Tags configMap
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param key path string true "key"
+// @Param data body types.SetConfigMapKeyRequest true "value"
+// @Success 200 {object} types.SetConfigMapKeyRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/configmaps/{name}/keys/{key} [put]
+// @Security BearerAuth
+func (h *configMapHandler) SetKey(c *gin.Context) {
+	namespace, name, key, isAbort := getDataKeyFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+
+	form := &types.SetConfigMapKeyRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// a key must not be in both data and binaryData, so it is removed from the other one
+	data := map[string]interface{}{key: form.Value}
+	binaryData := map[string]interface{}{key: nil}
+	if form.Base64 {
+		value, err := base64.StdEncoding.DecodeString(form.Value)
+		if err != nil {
+			logger.Warn("DecodeString error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrConfigMapValue)
+			return
+		}
+		data[key] = nil
+		binaryData[key] = value
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"data":       data,
+		"binaryData": binaryData,
+	})
+	h.patch(c, namespace, name, patch, ecode.ErrSetConfigMapKey)
+}
+
+// DeleteKey delete a key of configMap, the other keys are kept as they are
+// @Summary delete configMap key
+// @Description delete a key of configMap, the other keys are kept as they are, only for roles configured in k8s.writeRoles
+// @Tags configMap
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param key path string true "key"
+// @Success 200 {object} types.DeleteConfigMapKeyRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/configmaps/{name}/keys/{key} [delete]
+// @Security BearerAuth
+func (h *configMapHandler) DeleteKey(c *gin.Context) {
+	namespace, name, key, isAbort := getDataKeyFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"data":       map[string]interface{}{key: nil},
+		"binaryData": map[string]interface{}{key: nil},
+	})
+	h.patch(c, namespace, name, patch, ecode.ErrDeleteConfigMapKey)
+}
+
+func (h *configMapHandler) patch(c *gin.Context, namespace string, name string, patch []byte, failErr *errcode.Error) {
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err := client.CoreV1().ConfigMaps(namespace).Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		responseK8sError(c, "Patch configMap", err, failErr, logger.String("namespace", namespace), logger.String("name", name))
+		return
+	}
+
+	response.Success(c)
+}
+
+func convertConfigMap(configMap *corev1.ConfigMap) *types.ConfigMapObjDetail {
+	items := []types.ConfigMapItem{}
+	for key, value := range configMap.Data {
+		items = append(items, types.ConfigMapItem{Key: key, Value: value, Size: len(value)})
+	}
+	for key, value := range configMap.BinaryData {
+		items = append(items, types.ConfigMapItem{
+			Key:    key,
+			Value:  base64.StdEncoding.EncodeToString(value),
+			Size:   len(value),
+			Base64: true,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+
+	return &types.ConfigMapObjDetail{
+		Namespace:       configMap.Namespace,
+		Name:            configMap.Name,
+		ResourceVersion: configMap.ResourceVersion,
+		CreatedAt:       configMap.CreationTimestamp.Time,
+		Items:           items,
+	}
+}
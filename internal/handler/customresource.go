@@ -0,0 +1,622 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/zhufuyi/sponge/pkg/errcode"
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/datascope"
+	"go-admin/internal/ecode"
+	"go-admin/internal/types"
+	k8sutils "go-admin/internal/utils"
+)
+
+// coreGroup is used in paths instead of the empty name of the core api group
+const coreGroup = "core"
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+var _ CustomResourceHandler = (*customResourceHandler)(nil)
+
+// CustomResourceHandler defining the handler interface
+type CustomResourceHandler interface {
+	ListAPIResources(c *gin.Context)
+	ListCRDs(c *gin.Context)
+	List(c *gin.Context)
+	GetByName(c *gin.Context)
+	UpdateByName(c *gin.Context)
+	DeleteByName(c *gin.Context)
+}
+
+type customResourceHandler struct {
+	newClient        func() (kubernetes.Interface, error)
+	newDynamicClient func() (dynamic.Interface, error)
+}
+
+// NewCustomResourceHandler creating the handler interface
+func NewCustomResourceHandler() CustomResourceHandler {
+	return &customResourceHandler{
+		newClient:        k8sutils.NewKubeClient,
+		newDynamicClient: k8sutils.NewDynamicClient,
+	}
+}
+
+// crdObject the fields of CustomResourceDefinition used by the handler
+type crdObject struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind   string `json:"kind"`
+			Plural string `json:"plural"`
+		} `json:"names"`
+		Scope    string `json:"scope"`
+		Versions []struct {
+			Name    string `json:"name"`
+			Served  bool   `json:"served"`
+			Storage bool   `json:"storage"`
+			Schema  *struct {
+				OpenAPIV3Schema json.RawMessage `json:"openAPIV3Schema"`
+			} `json:"schema"`
+			AdditionalPrinterColumns []types.PrinterColumn `json:"additionalPrinterColumns"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// ListAPIResources list all api groups and resources served by the cluster, including custom resources
+// @Summary list of api resources
+// @Description list all api groups and resources served by the cluster, including custom resources, the core group is named core
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Success 200 {object} types.ListAPIResourcesRespond{}
+// @Router /api/v1/k8s/apis [get]
+// @Security BearerAuth
+func (h *customResourceHandler) ListAPIResources(c *gin.Context) {
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+
+	// groups that failed to be discovered, e.g. an unavailable aggregated api, are reported instead of failing the request
+	failedGroups := []string{}
+	_, lists, err := client.Discovery().ServerGroupsAndResources()
+	if err != nil {
+		groupErr := &discovery.ErrGroupDiscoveryFailed{}
+		if !errors.As(err, &groupErr) {
+			responseK8sError(c, "ServerGroupsAndResources", err, ecode.ErrListAPIResources)
+			return
+		}
+		for gv := range groupErr.Groups {
+			failedGroups = append(failedGroups, gv.String())
+		}
+		sort.Strings(failedGroups)
+	}
+
+	ctx := middleware.WrapCtx(c)
+	crds, err := listCRDs(ctx, dynamicClient)
+	if err != nil && !apierrors.IsNotFound(err) {
+		responseK8sError(c, "List customResourceDefinitions", err, ecode.ErrListAPIResources)
+		return
+	}
+	customResources := map[string]bool{}
+	for _, crd := range crds {
+		customResources[crd.Spec.Names.Plural+"."+crd.Spec.Group] = true
+	}
+
+	resources := []types.APIResource{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") { // subresources, e.g. pods/log
+				continue
+			}
+			resources = append(resources, types.APIResource{
+				Group:          groupToPath(gv.Group),
+				Version:        gv.Version,
+				Resource:       r.Name,
+				Kind:           r.Kind,
+				Namespaced:     r.Namespaced,
+				Verbs:          r.Verbs,
+				CustomResource: customResources[r.Name+"."+gv.Group],
+			})
+		}
+	}
+
+	response.Success(c, gin.H{
+		"resources":    resources,
+		"failedGroups": failedGroups,
+	})
+}
+
+// ListCRDs list of customResourceDefinitions with their printer columns
+// @Summary list of customResourceDefinitions
+// @Description list of customResourceDefinitions with served versions and printer columns
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Success 200 {object} types.ListCRDsRespond{}
+// @Router /api/v1/k8s/crds [get]
+// @Security BearerAuth
+func (h *customResourceHandler) ListCRDs(c *gin.Context) {
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	crds, err := listCRDs(ctx, dynamicClient)
+	if err != nil {
+		responseK8sError(c, "List customResourceDefinitions", err, ecode.ErrListCRDs)
+		return
+	}
+
+	data := []*types.CRDObjDetail{}
+	for _, crd := range crds {
+		detail := &types.CRDObjDetail{
+			Name:     crd.Metadata.Name,
+			Group:    crd.Spec.Group,
+			Kind:     crd.Spec.Names.Kind,
+			Resource: crd.Spec.Names.Plural,
+			Scope:    crd.Spec.Scope,
+			Versions: []string{},
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Served {
+				detail.Versions = append(detail.Versions, v.Name)
+			}
+			if v.Storage {
+				detail.StorageVersion = v.Name
+				detail.PrinterColumns = v.AdditionalPrinterColumns
+			}
+		}
+		data = append(data, detail)
+	}
+
+	response.Success(c, gin.H{
+		"crds": data,
+	})
+}
+
+// List of custom resources rendered as table rows, the columns are the printer columns of the crd
+// @Summary list of custom resources
+// @Description list of a resource served by a crd, rendered with the printer columns of the crd,
+// @Description the namespaced resources are only listed in the namespaces of the data scope of the user,
+// @Description the cluster-scoped resources only if the data scope has all the namespaces
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Param group path string true "api group of the crd"
+// @Param version path string true "api version"
+// @Param resource path string true "resource, plural name"
+// @Param namespace query string false "namespace, if empty, all namespaces"
+// @Param limit query int false "size in each page, 0 means no limit" default(0)
+// @Param continue query string false "continue token of the previous page"
+// @Success 200 {object} types.ListCustomResourcesRespond{}
+// @Router /api/v1/k8s/apis/{group}/{version}/{resource} [get]
+// @Security BearerAuth
+func (h *customResourceHandler) List(c *gin.Context) {
+	namespace := c.Query("namespace")
+	scope, ok := getDataScope(c)
+	if !ok {
+		return
+	}
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+	gvr, crd, ok := h.getCustomResource(c, dynamicClient, ecode.ErrListCustomResources)
+	if !ok {
+		return
+	}
+
+	fields := []logger.Field{logger.String("resource", gvr.String()), logger.String("namespace", namespace)}
+	ctx := middleware.WrapCtx(c)
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+		Limit:    int64(utils.StrToInt(c.Query("limit"))),
+		Continue: c.Query("continue"),
+	})
+	if err != nil {
+		responseK8sError(c, "List resources", err, ecode.ErrListCustomResources, fields...)
+		return
+	}
+
+	columns := []types.PrinterColumn{{Name: "Name", Type: "string", JSONPath: ".metadata.name"}}
+	for _, v := range crd.Spec.Versions {
+		if v.Name == gvr.Version {
+			columns = append(columns, v.AdditionalPrinterColumns...)
+		}
+	}
+	if len(columns) == 1 {
+		columns = append(columns, types.PrinterColumn{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"})
+	}
+
+	rows := []types.CustomResourceRow{}
+	for _, item := range list.Items {
+		if !canSeeCustomResource(scope, item.GetNamespace()) {
+			continue
+		}
+		rows = append(rows, types.CustomResourceRow{
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+			Cells:     renderPrinterColumns(columns, item.Object),
+		})
+	}
+
+	response.Success(c, gin.H{
+		"columns":  columns,
+		"rows":     rows,
+		"continue": list.GetContinue(),
+	})
+}
+
+// GetByName get a custom resource by name
+// @Summary get custom resource detail
+// @Description get a resource served by a crd by name, in a namespace of the data scope of the user
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Param group path string true "api group of the crd"
+// @Param version path string true "api version"
+// @Param resource path string true "resource, plural name"
+// @Param name path string true "name"
+// @Param namespace query string false "namespace, required for namespaced resources"
+// @Success 200 {object} types.GetCustomResourceRespond{}
+// @Router /api/v1/k8s/apis/{group}/{version}/{resource}/{name} [get]
+// @Security BearerAuth
+func (h *customResourceHandler) GetByName(c *gin.Context) {
+	namespace, name := c.Query("namespace"), c.Param("name")
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+	gvr, crd, ok := h.getCustomResource(c, dynamicClient, ecode.ErrGetCustomResource)
+	if !ok || !checkCustomResourceNamespace(c, crd, namespace) {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	obj, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Get resource", err, ecode.ErrGetCustomResource,
+			logger.String("resource", gvr.String()), logger.String("namespace", namespace), logger.String("name", name))
+		return
+	}
+
+	response.Success(c, gin.H{"object": obj.Object})
+}
+
+// UpdateByName replace a custom resource, it is validated against the openAPIV3Schema of its crd first
+// @Summary update custom resource
+// @Description replace a resource served by a crd, metadata.resourceVersion is required, the object is validated against the openAPIV3Schema of the crd
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Param group path string true "api group of the crd"
+// @Param version path string true "api version"
+// @Param resource path string true "resource, plural name"
+// @Param name path string true "name"
+// @Param namespace query string false "namespace, required for namespaced resources"
+// @Param data body types.CustomResourceObject true "object"
+// @Success 200 {object} types.GetCustomResourceRespond{}
+// @Router /api/v1/k8s/apis/{group}/{version}/{resource}/{name} [put]
+// @Security BearerAuth
+func (h *customResourceHandler) UpdateByName(c *gin.Context) {
+	namespace, name := c.Query("namespace"), c.Param("name")
+	form := types.CustomResourceObject{}
+	err := c.ShouldBindJSON(&form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	obj := &unstructured.Unstructured{Object: form}
+	if obj.GetName() != name || obj.GetNamespace() != namespace {
+		logger.Warn("name or namespace of object does not match the path", logger.String("name", obj.GetName()),
+			logger.String("namespace", obj.GetNamespace()), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("metadata.name and metadata.namespace must match the path"))
+		return
+	}
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+	gvr, crd, ok := h.getCustomResource(c, dynamicClient, ecode.ErrUpdateCustomResource)
+	if !ok || !checkCustomResourceNamespace(c, crd, namespace) {
+		return
+	}
+
+	fields := []logger.Field{logger.String("resource", gvr.String()), logger.String("namespace", namespace), logger.String("name", name)}
+	if err = validateCustomResource(crd, gvr.Version, form); err != nil {
+		logger.Warn("validateCustomResource error", append(fields, logger.Err(err), middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrInvalidCustomResource.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	updated, err := dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		responseK8sError(c, "Update resource", err, ecode.ErrUpdateCustomResource, fields...)
+		return
+	}
+
+	logger.Info("update resource", append(fields, logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))...)
+	response.Success(c, gin.H{"object": updated.Object})
+}
+
+// DeleteByName delete a custom resource by name
+// @Summary delete custom resource
+// @Description delete a resource served by a crd by name, in a namespace of the data scope of the user
+// @Tags customResource
+// @accept json
+// @Produce json
+// @Param group path string true "api group of the crd"
+// @Param version path string true "api version"
+// @Param resource path string true "resource, plural name"
+// @Param name path string true "name"
+// @Param namespace query string false "namespace, required for namespaced resources"
+// @Success 200 {object} types.DeleteCustomResourceRespond{}
+// @Router /api/v1/k8s/apis/{group}/{version}/{resource}/{name} [delete]
+// @Security BearerAuth
+func (h *customResourceHandler) DeleteByName(c *gin.Context) {
+	namespace, name := c.Query("namespace"), c.Param("name")
+	dynamicClient, ok := h.getDynamicClient(c)
+	if !ok {
+		return
+	}
+	gvr, crd, ok := h.getCustomResource(c, dynamicClient, ecode.ErrDeleteCustomResource)
+	if !ok || !checkCustomResourceNamespace(c, crd, namespace) {
+		return
+	}
+
+	fields := []logger.Field{logger.String("resource", gvr.String()), logger.String("namespace", namespace), logger.String("name", name)}
+	ctx := middleware.WrapCtx(c)
+	err := dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		responseK8sError(c, "Delete resource", err, ecode.ErrDeleteCustomResource, fields...)
+		return
+	}
+
+	logger.Info("delete resource", append(fields, logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))...)
+	response.Success(c)
+}
+
+func (h *customResourceHandler) getDynamicClient(c *gin.Context) (dynamic.Interface, bool) {
+	client, err := h.newDynamicClient()
+	if err != nil {
+		logger.Error("NewDynamicClient error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return nil, false
+	}
+	return client, true
+}
+
+// getCustomResource resolve the resource of the path through the discovery, only the resources served by a crd
+// are accepted, the built-in resources such as the secrets have their own routes which mask and audit them
+func (h *customResourceHandler) getCustomResource(c *gin.Context, dynamicClient dynamic.Interface, failErr *errcode.Error) (schema.GroupVersionResource, *crdObject, bool) {
+	gvr := getGVRFromPath(c)
+	fields := []logger.Field{logger.String("resource", gvr.String()), middleware.GCtxRequestIDField(c)}
+	if gvr.Group == "" {
+		logger.Warn("built-in resource is not a custom resource", fields...)
+		response.Error(c, ecode.ErrNotCustomResource)
+		return gvr, nil, false
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return gvr, nil, false
+	}
+
+	list, err := client.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil && !apierrors.IsNotFound(err) {
+		responseK8sError(c, "ServerResourcesForGroupVersion", err, failErr, fields[0])
+		return gvr, nil, false
+	}
+	served := false
+	if list != nil {
+		for _, r := range list.APIResources {
+			if r.Name == gvr.Resource {
+				served = true
+				break
+			}
+		}
+	}
+	if !served {
+		logger.Warn("resource is not served", fields...)
+		response.Error(c, ecode.ErrNotCustomResource)
+		return gvr, nil, false
+	}
+
+	crd, err := getCRD(middleware.WrapCtx(c), dynamicClient, gvr)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Warn("resource is not defined by a customResourceDefinition", fields...)
+			response.Error(c, ecode.ErrNotCustomResource)
+			return gvr, nil, false
+		}
+		responseK8sError(c, "Get customResourceDefinition", err, failErr, fields[0])
+		return gvr, nil, false
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Name == gvr.Version && v.Served {
+			return gvr, crd, true
+		}
+	}
+	logger.Warn("version is not served by the customResourceDefinition", fields...)
+	response.Error(c, ecode.ErrNotCustomResource)
+	return gvr, nil, false
+}
+
+// checkCustomResourceNamespace the namespaced resources require a namespace of the data scope of the user,
+// the cluster-scoped resources require a data scope of all namespaces, no scope means not restricted,
+// if it fails, the error response is written and false is returned
+func checkCustomResourceNamespace(c *gin.Context, crd *crdObject, namespace string) bool {
+	if crd.Spec.Scope != "Namespaced" {
+		scope, ok := getDataScope(c)
+		if !ok {
+			return false
+		}
+		if !canSeeCustomResource(scope, "") {
+			logger.Warn("cluster-scoped resource is out of the data scope", logger.String("crd", crd.Metadata.Name),
+				logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Forbidden)
+			return false
+		}
+		return true
+	}
+	if namespace == "" {
+		response.Error(c, ecode.InvalidParams.WithDetails("namespace is required"))
+		return false
+	}
+	return checkNamespace(c, namespace)
+}
+
+// canSeeCustomResource a namespaced resource is visible in a namespace of the data scope, a cluster-scoped
+// resource (no namespace) if the data scope has all the namespaces, a nil scope is not restricted
+func canSeeCustomResource(scope *datascope.Scope, namespace string) bool {
+	if namespace == "" {
+		return scope == nil || scope.AllNamespaces
+	}
+	return scope.CanSeeNamespace(namespace)
+}
+
+func getGVRFromPath(c *gin.Context) schema.GroupVersionResource {
+	group := c.Param("group")
+	if group == coreGroup {
+		group = ""
+	}
+	return schema.GroupVersionResource{Group: group, Version: c.Param("version"), Resource: c.Param("resource")}
+}
+
+func groupToPath(group string) string {
+	if group == "" {
+		return coreGroup
+	}
+	return group
+}
+
+func listCRDs(ctx context.Context, client dynamic.Interface) ([]*crdObject, error) {
+	list, err := client.Resource(crdResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	crds := []*crdObject{}
+	for _, item := range list.Items {
+		crd, err := convertCRD(&item)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+// getCRD get the crd that defines the resource, it returns a NotFound error for built-in resources
+func getCRD(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource) (*crdObject, error) {
+	if gvr.Group == "" {
+		return nil, apierrors.NewNotFound(crdResource.GroupResource(), gvr.Resource)
+	}
+	obj, err := client.Resource(crdResource).Get(ctx, gvr.Resource+"."+gvr.Group, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return convertCRD(obj)
+}
+
+func convertCRD(obj *unstructured.Unstructured) (*crdObject, error) {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	crd := &crdObject{}
+	err = json.Unmarshal(data, crd)
+	return crd, err
+}
+
+// validateCustomResource validate object against the openAPIV3Schema of the crd version
+func validateCustomResource(crd *crdObject, version string, obj map[string]interface{}) error {
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		if v.Schema == nil || len(v.Schema.OpenAPIV3Schema) == 0 {
+			return nil
+		}
+
+		s := &spec.Schema{}
+		if err := json.Unmarshal(v.Schema.OpenAPIV3Schema, s); err != nil {
+			return fmt.Errorf("parse openAPIV3Schema error: %v", err)
+		}
+		result := validate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(obj)
+		if result.IsValid() {
+			return nil
+		}
+		msgs := []string{}
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Error())
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+
+	return fmt.Errorf("version %s is not defined in %s", version, crd.Metadata.Name)
+}
+
+// renderPrinterColumns evaluate the json path of columns against object, missing fields are rendered as nil
+func renderPrinterColumns(columns []types.PrinterColumn, obj map[string]interface{}) []interface{} {
+	cells := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		jp := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := jp.Parse("{" + column.JSONPath + "}"); err != nil {
+			cells = append(cells, nil)
+			continue
+		}
+		results, err := jp.FindResults(obj)
+		if err != nil || len(results) == 0 || len(results[0]) == 0 {
+			cells = append(cells, nil)
+			continue
+		}
+		if len(results[0]) == 1 {
+			cells = append(cells, results[0][0].Interface())
+			continue
+		}
+		buf := &bytes.Buffer{}
+		if err = jp.PrintResults(buf, results[0]); err != nil {
+			cells = append(cells, nil)
+			continue
+		}
+		cells = append(cells, buf.String())
+	}
+	return cells
+}
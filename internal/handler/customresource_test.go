@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go-admin/internal/datascope"
+)
+
+var crontabResource = schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}
+var clusterBackupResource = schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "clusterbackups"}
+
+func newCustomResourceHandler(t *testing.T) (*gin.Engine, dynamic.Interface) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "crontabs.stable.example.com"},
+		"spec": map[string]interface{}{
+			"group": "stable.example.com",
+			"names": map[string]interface{}{"kind": "CronTab", "plural": "crontabs"},
+			"scope": "Namespaced",
+			"versions": []interface{}{map[string]interface{}{
+				"name": "v1", "served": true, "storage": true,
+				"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"cronSpec": map[string]interface{}{"type": "string"},
+								"replicas": map[string]interface{}{"type": "integer", "minimum": int64(1)},
+							},
+						},
+					},
+				}},
+				"additionalPrinterColumns": []interface{}{
+					map[string]interface{}{"name": "Spec", "type": "string", "jsonPath": ".spec.cronSpec"},
+					map[string]interface{}{"name": "Replicas", "type": "integer", "jsonPath": ".spec.replicas"},
+				},
+			}},
+		},
+	}}
+	crontab := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "stable.example.com/v1",
+		"kind":       "CronTab",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "backup"},
+		"spec":       map[string]interface{}{"cronSpec": "* * * * */5", "replicas": int64(1)},
+	}}
+	clusterCRD := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "clusterbackups.stable.example.com"},
+		"spec": map[string]interface{}{
+			"group":    "stable.example.com",
+			"names":    map[string]interface{}{"kind": "ClusterBackup", "plural": "clusterbackups"},
+			"scope":    "Cluster",
+			"versions": []interface{}{map[string]interface{}{"name": "v1", "served": true, "storage": true}},
+		},
+	}}
+	clusterBackup := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "stable.example.com/v1",
+		"kind":       "ClusterBackup",
+		"metadata":   map[string]interface{}{"name": "nightly"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource:           "CustomResourceDefinitionList",
+		crontabResource:       "CronTabList",
+		clusterBackupResource: "ClusterBackupList",
+	}, crd, crontab, clusterCRD, clusterBackup)
+
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"get", "list"}},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+		}},
+		{GroupVersion: "stable.example.com/v1", APIResources: []metav1.APIResource{
+			{Name: "crontabs", Kind: "CronTab", Namespaced: true, Verbs: []string{"get", "list", "update"}},
+			{Name: "clusterbackups", Kind: "ClusterBackup", Namespaced: false, Verbs: []string{"get", "list"}},
+		}},
+	}
+
+	h := &customResourceHandler{
+		newClient:        func() (kubernetes.Interface, error) { return client, nil },
+		newDynamicClient: func() (dynamic.Interface, error) { return dynamicClient, nil },
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // the user 2 only sees the namespace prod
+		if c.GetHeader("X-Uid") != "2" {
+			return
+		}
+		ctx := datascope.WithResolver(c.Request.Context(), func(ctx context.Context) (*datascope.Scope, error) {
+			return &datascope.Scope{UserID: 2, Namespaces: []string{"prod"}}, nil
+		})
+		c.Request = c.Request.WithContext(ctx)
+	})
+	r.GET("/apis", h.ListAPIResources)
+	r.GET("/crds", h.ListCRDs)
+	r.GET("/apis/:group/:version/:resource", h.List)
+	r.GET("/apis/:group/:version/:resource/:name", h.GetByName)
+	r.PUT("/apis/:group/:version/:resource/:name", h.UpdateByName)
+	r.DELETE("/apis/:group/:version/:resource/:name", h.DeleteByName)
+	return r, dynamicClient
+}
+
+func Test_customResourceHandler_ListAPIResources(t *testing.T) {
+	r, _ := newCustomResourceHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "1", "/apis", nil)
+	assert.Contains(t, w.Body.String(), `"group":"core","version":"v1","resource":"pods"`)
+	assert.Contains(t, w.Body.String(), `"resource":"crontabs","kind":"CronTab","namespaced":true,"verbs":["get","list","update"],"customResource":true`)
+	assert.NotContains(t, w.Body.String(), "pods/log")
+
+	w = doJSONRequest(r, http.MethodGet, "1", "/crds", nil)
+	assert.Contains(t, w.Body.String(), `"name":"crontabs.stable.example.com"`)
+	assert.Contains(t, w.Body.String(), `"storageVersion":"v1"`)
+}
+
+func Test_customResourceHandler_List(t *testing.T) {
+	r, _ := newCustomResourceHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "1", "/apis/stable.example.com/v1/crontabs?namespace=default", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Replicas","type":"integer","jsonPath":".spec.replicas"`)
+	assert.Contains(t, w.Body.String(), `"cells":["backup","* * * * */5",1]`)
+}
+
+func Test_customResourceHandler_UpdateByName(t *testing.T) {
+	r, dynamicClient := newCustomResourceHandler(t)
+	path := "/apis/stable.example.com/v1/crontabs/backup?namespace=default"
+
+	obj := map[string]interface{}{
+		"apiVersion": "stable.example.com/v1",
+		"kind":       "CronTab",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "backup"},
+		"spec":       map[string]interface{}{"cronSpec": "0 * * * *", "replicas": 0},
+	}
+	// violates the schema
+	w := doJSONRequest(r, http.MethodPut, "1", path, obj)
+	assert.Contains(t, w.Body.String(), "replicas")
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+
+	obj["spec"] = map[string]interface{}{"cronSpec": "0 * * * *", "replicas": 2}
+	w = doJSONRequest(r, http.MethodPut, "1", path, obj)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+
+	updated, err := dynamicClient.Resource(crontabResource).Namespace("default").Get(context.Background(), "backup", metav1.GetOptions{})
+	assert.NoError(t, err)
+	cronSpec, _, _ := unstructured.NestedString(updated.Object, "spec", "cronSpec")
+	assert.Equal(t, "0 * * * *", cronSpec)
+
+	// name does not match the path
+	w = doJSONRequest(r, http.MethodPut, "1", "/apis/stable.example.com/v1/crontabs/other?namespace=default", obj)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+
+	w = doJSONRequest(r, http.MethodDelete, "1", path, nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodGet, "1", path, nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_customResourceHandler_NotCustomResource(t *testing.T) {
+	r, _ := newCustomResourceHandler(t)
+
+	for _, path := range []string{
+		"/apis/core/v1/secrets/db?namespace=default",             // built-in resources have their own routes
+		"/apis/v1/secrets/db?namespace=default",                  // not an api group
+		"/apis/stable.example.com/v2/crontabs/backup",            // version not served
+		"/apis/stable.example.com/v1/secrets/db",                 // not served in the group
+		"/apis/other.example.com/v1/crontabs/backup",             // group not served
+		"/apis/stable.example.com/v1/crontabs/backup?namespace=", // namespace required
+	} {
+		w := doJSONRequest(r, http.MethodGet, "1", path, nil)
+		assert.NotContains(t, w.Body.String(), `"code":0`, path)
+	}
+	w := doJSONRequest(r, http.MethodGet, "1", "/apis/core/v1/secrets", nil)
+	assert.Contains(t, w.Body.String(), "not served by a customResourceDefinition")
+	w = doJSONRequest(r, http.MethodDelete, "1", "/apis/core/v1/secrets/db?namespace=default", nil)
+	assert.Contains(t, w.Body.String(), "not served by a customResourceDefinition")
+}
+
+func Test_customResourceHandler_DataScope(t *testing.T) {
+	r, _ := newCustomResourceHandler(t)
+	path := "/apis/stable.example.com/v1/crontabs/backup?namespace=default"
+
+	w := doJSONRequest(r, http.MethodGet, "1", path, nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+
+	// the namespace default is out of the scope of the user 2
+	w = doJSONRequest(r, http.MethodGet, "2", path, nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodDelete, "2", path, nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodGet, "2", "/apis/stable.example.com/v1/crontabs?namespace=default", nil)
+	assert.Contains(t, w.Body.String(), `"rows":[]`)
+	w = doJSONRequest(r, http.MethodGet, "1", path, nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+
+	// the cluster-scoped resources require a data scope of all the namespaces
+	clusterPath := "/apis/stable.example.com/v1/clusterbackups"
+	w = doJSONRequest(r, http.MethodGet, "1", clusterPath+"/nightly", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodGet, "1", clusterPath, nil)
+	assert.Contains(t, w.Body.String(), `"name":"nightly"`)
+	w = doJSONRequest(r, http.MethodGet, "2", clusterPath+"/nightly", nil)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	w = doJSONRequest(r, http.MethodDelete, "2", clusterPath+"/nightly", nil)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	w = doJSONRequest(r, http.MethodGet, "2", clusterPath, nil)
+	assert.Contains(t, w.Body.String(), `"rows":[]`)
+}
@@ -0,0 +1,445 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+var _ DepartmentHandler = (*departmentHandler)(nil)
+
+// DepartmentHandler defining the handler interface
+type DepartmentHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	GetTree(c *gin.Context)
+	GetUserDepartments(c *gin.Context)
+	SetUserDepartments(c *gin.Context)
+	GetRoleDepartments(c *gin.Context)
+	SetRoleDepartments(c *gin.Context)
+}
+
+type departmentHandler struct {
+	iDao    dao.DepartmentDao
+	userDao dao.UserDao
+	roleDao dao.RoleDao
+}
+
+// NewDepartmentHandler creating the handler interface
+func NewDepartmentHandler() DepartmentHandler {
+	return &departmentHandler{
+		iDao: dao.NewDepartmentDao(model.GetDB()),
+		userDao: dao.NewUserDao(
+			model.GetDB(),
+			cache.NewUserCache(model.GetCacheType()),
+		),
+		roleDao: dao.NewRoleDao(
+			model.GetDB(),
+			cache.NewRoleCache(model.GetCacheType()),
+		),
+	}
+}
+
+// Create a record
+// @Summary create department
+// @Description submit information to create a department under its parent
+// @Tags department
+// @accept json
+// @Produce json
+// @Param data body types.CreateDepartmentRequest true "department information"
+// @Success 200 {object} types.CreateDepartmentRespond{}
+// @Router /api/v1/department [post]
+// @Security BearerAuth
+func (h *departmentHandler) Create(c *gin.Context) {
+	form := &types.CreateDepartmentRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	department := &model.Department{}
+	err = copier.Copy(department, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateDepartment)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.Create(ctx, department)
+	if err != nil {
+		if errors.Is(err, model.ErrDepartmentParent) {
+			response.Error(c, ecode.ErrDepartmentParent)
+			return
+		}
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"id": department.ID})
+}
+
+// DeleteByID delete a record by id
+// @Summary delete department
+// @Description delete a department without sub departments, its members and its grants to the custom data scopes of the roles
+// @Tags department
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteDepartmentByIDRespond{}
+// @Router /api/v1/department/{id} [delete]
+// @Security BearerAuth
+func (h *departmentHandler) DeleteByID(c *gin.Context) {
+	_, id, isAbort := getDepartmentIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrDepartmentHasChildren) {
+			response.Error(c, ecode.ErrDepartmentHasChildren)
+			return
+		}
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// UpdateByID update information by id
+// @Summary update department
+// @Description replace the information of a department, it can be moved under another parent but not under itself or its sub departments
+// @Tags department
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateDepartmentByIDRequest true "department information"
+// @Success 200 {object} types.UpdateDepartmentByIDRespond{}
+// @Router /api/v1/department/{id} [put]
+// @Security BearerAuth
+func (h *departmentHandler) UpdateByID(c *gin.Context) {
+	_, id, isAbort := getDepartmentIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateDepartmentByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.ID = id
+
+	department := &model.Department{}
+	err = copier.Copy(department, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDDepartment)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.UpdateByID(ctx, department)
+	if err != nil {
+		if errors.Is(err, model.ErrDepartmentParent) {
+			response.Error(c, ecode.ErrDepartmentParent)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetByID get a record by id
+// @Summary get department detail
+// @Description get department detail by id
+// @Tags department
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetDepartmentByIDRespond{}
+// @Router /api/v1/department/{id} [get]
+// @Security BearerAuth
+func (h *departmentHandler) GetByID(c *gin.Context) {
+	_, id, isAbort := getDepartmentIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	department, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"department": convertDepartment(department)})
+}
+
+// GetTree get the whole department tree
+// @Summary get the department tree
+// @Description get all the departments as a tree, the siblings are sorted by sort and id
+// @Tags department
+// @Produce json
+// @Success 200 {object} types.GetDepartmentTreeRespond{}
+// @Router /api/v1/department/tree [get]
+// @Security BearerAuth
+func (h *departmentHandler) GetTree(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	departments, err := h.iDao.GetAll(ctx)
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"departments": newDepartmentTree(departments)})
+}
+
+// GetUserDepartments get the departments of a user
+// @Summary get user departments
+// @Description get the ids of the departments the user is a member of
+// @Tags department
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} types.GetDepartmentsRespond{}
+// @Router /api/v1/user/{id}/departments [get]
+// @Security BearerAuth
+func (h *departmentHandler) GetUserDepartments(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	ids, err := h.iDao.GetDeptIDsByUserID(ctx, id)
+	if err != nil {
+		logger.Error("GetDeptIDsByUserID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"deptIds": ids})
+}
+
+// SetUserDepartments replace the departments of a user
+// @Summary set user departments
+// @Description replace the departments the user is a member of, the members of the roles whose data scope covers
+// @Description the departments see the user in their lists
+// @Tags department
+// @accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Param data body types.SetDepartmentsRequest true "department id list"
+// @Success 200 {object} types.SetDepartmentsRespond{}
+// @Router /api/v1/user/{id}/departments [put]
+// @Security BearerAuth
+func (h *departmentHandler) SetUserDepartments(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err := h.userDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	deptIDs, ok := h.bindDeptIDs(c)
+	if !ok {
+		return
+	}
+	err = h.iDao.SetUserDepartments(ctx, id, deptIDs)
+	if err != nil {
+		logger.Error("SetUserDepartments error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSetDepartments)
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetRoleDepartments get the departments of the custom data scope of a role
+// @Summary get role departments
+// @Description get the ids of the departments of the custom data scope of the role
+// @Tags department
+// @Produce json
+// @Param id path string true "role id"
+// @Success 200 {object} types.GetDepartmentsRespond{}
+// @Router /api/v1/role/{id}/departments [get]
+// @Security BearerAuth
+func (h *departmentHandler) GetRoleDepartments(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	ids, err := h.iDao.GetDeptIDsByRoleID(ctx, id)
+	if err != nil {
+		logger.Error("GetDeptIDsByRoleID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"deptIds": ids})
+}
+
+// SetRoleDepartments replace the departments of the custom data scope of a role
+// @Summary set role departments
+// @Description replace the departments of the custom data scope of the role, they are only used when the data scope mode
+// @Description of the role is custom, the members see the users and the namespaces of these departments
+// @Tags department
+// @accept json
+// @Produce json
+// @Param id path string true "role id"
+// @Param data body types.SetDepartmentsRequest true "department id list"
+// @Success 200 {object} types.SetDepartmentsRespond{}
+// @Router /api/v1/role/{id}/departments [put]
+// @Security BearerAuth
+func (h *departmentHandler) SetRoleDepartments(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err := h.roleDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	deptIDs, ok := h.bindDeptIDs(c)
+	if !ok {
+		return
+	}
+	err = h.iDao.SetRoleDepartments(ctx, id, deptIDs)
+	if err != nil {
+		logger.Error("SetRoleDepartments error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSetDepartments)
+		return
+	}
+
+	response.Success(c)
+}
+
+// bindDeptIDs bind the department ids of the request, they must exist,
+// if it fails, the error response is written and false is returned
+func (h *departmentHandler) bindDeptIDs(c *gin.Context) ([]uint64, bool) {
+	form := &types.SetDepartmentsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return nil, false
+	}
+
+	deptIDs := uniqueIDs(form.DeptIDs)
+	count, err := h.iDao.CountByIDs(middleware.WrapCtx(c), deptIDs)
+	if err != nil {
+		logger.Error("CountByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return nil, false
+	}
+	if count != int64(len(deptIDs)) {
+		response.Error(c, ecode.ErrDepartmentIDs)
+		return nil, false
+	}
+	return deptIDs, true
+}
+
+func getDepartmentIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
+
+// newDepartmentTree the tree of the departments, the departments are already sorted
+func newDepartmentTree(departments []*model.Department) []*types.DepartmentObjDetail {
+	nodes := make(map[uint64]*types.DepartmentObjDetail, len(departments))
+	for _, department := range departments {
+		nodes[department.ID] = convertDepartment(department)
+	}
+
+	roots := []*types.DepartmentObjDetail{}
+	for _, department := range departments {
+		node := nodes[department.ID]
+		if department.ParentID == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[department.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+func convertDepartment(department *model.Department) *types.DepartmentObjDetail {
+	return &types.DepartmentObjDetail{
+		ID:         utils.Uint64ToStr(department.ID),
+		ParentID:   utils.Uint64ToStr(department.ParentID),
+		Name:       department.Name,
+		Sort:       department.Sort,
+		Namespaces: department.Namespaces,
+		Remark:     department.Remark,
+		CreatedAt:  department.CreatedAt,
+		UpdatedAt:  department.UpdatedAt,
+	}
+}
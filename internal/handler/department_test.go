@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/datascope"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// newDepartmentRouter the users foo (id 1), bar (id 2), baz (id 3) and qux (id 4) of the role staff (id 1),
+// the lists are filtered by the data scope of the user of the X-Uid header
+func newDepartmentRouter(t *testing.T) (*gin.Engine, dao.RoleDao) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	userDao := dao.NewUserDao(db, nil)
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		require.NoError(t, userDao.Create(ctx, &model.User{Name: name, Status: model.UserStatusActivated}))
+	}
+	roleDao := dao.NewRoleDao(db, nil)
+	require.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: "staff", RoleKey: "staff", DataScope: "sandbox"}))
+	urDao := dao.NewUserRoleDao(db)
+	for id := uint64(1); id <= 4; id++ {
+		require.NoError(t, urDao.SetUserRoles(ctx, id, []uint64{1}))
+	}
+
+	deptDao := dao.NewDepartmentDao(db)
+	h := &departmentHandler{iDao: deptDao, userDao: userDao, roleDao: roleDao}
+	uh := &userHandler{iDao: userDao}
+	nh := &namespaceHandler{newClient: func() (kubernetes.Interface, error) {
+		return fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "backend"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sandbox"}},
+		), nil
+	}}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // replaces middleware.Auth and the data scope middleware of the routers
+		c.Set("uid", c.GetHeader("X-Uid"))
+		ctx := datascope.WithResolver(c.Request.Context(), func(ctx context.Context) (*datascope.Scope, error) {
+			uid, err := utils.StrToUint64E(c.GetString("uid"))
+			if err != nil || uid == 0 {
+				return nil, nil
+			}
+			return deptDao.GetDataScope(ctx, uid)
+		})
+		c.Request = c.Request.WithContext(ctx)
+	})
+	r.POST("/department", h.Create)
+	r.DELETE("/department/:id", h.DeleteByID)
+	r.PUT("/department/:id", h.UpdateByID)
+	r.GET("/department/:id", h.GetByID)
+	r.GET("/department/tree", h.GetTree)
+	r.GET("/user/:id/departments", h.GetUserDepartments)
+	r.PUT("/user/:id/departments", h.SetUserDepartments)
+	r.GET("/role/:id/departments", h.GetRoleDepartments)
+	r.PUT("/role/:id/departments", h.SetRoleDepartments)
+	r.POST("/user/list", uh.List)
+	r.GET("/k8s/namespaces", nh.List)
+	return r, roleDao
+}
+
+// departmentNames the names of the tree in depth first order, the children in parentheses
+func departmentNames(departments []*types.DepartmentObjDetail) string {
+	s := ""
+	for i, department := range departments {
+		if i > 0 {
+			s += " "
+		}
+		s += department.Name
+		if len(department.Children) > 0 {
+			s += "(" + departmentNames(department.Children) + ")"
+		}
+	}
+	return s
+}
+
+func getDepartmentTree(t *testing.T, r http.Handler) string {
+	w := doMeRequest(r, http.MethodGet, "/department/tree", "", "", nil)
+	reply := &struct {
+		Data struct {
+			Departments []*types.DepartmentObjDetail `json:"departments"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	return departmentNames(reply.Data.Departments)
+}
+
+// createDepartments the tree company(prod) > dev(dev) > backend(backend)
+func createDepartments(t *testing.T, r http.Handler) {
+	for _, body := range []string{
+		`{"name":"company","namespaces":"prod"}`,                 // 1
+		`{"parentId":1,"name":"dev","namespaces":"dev"}`,         // 2
+		`{"parentId":2,"name":"backend","namespaces":"backend"}`, // 3
+	} {
+		w := doMeRequest(r, http.MethodPost, "/department", "", "application/json", []byte(body))
+		require.Contains(t, w.Body.String(), `"code":0`, body)
+	}
+}
+
+func Test_departmentHandler_Tree(t *testing.T) {
+	r, _ := newDepartmentRouter(t)
+	createDepartments(t, r)
+	w := doMeRequest(r, http.MethodPost, "/department", "", "application/json", []byte(`{"name":"ops","sort":-1}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	assert.Equal(t, "ops company(dev(backend))", getDepartmentTree(t, r))
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   string
+		err    string
+	}{
+		{http.MethodPost, "/department", `{"parentId":9,"name":"x"}`, ecode.ErrDepartmentParent.Msg()},
+		{http.MethodPost, "/department", `{"name":""}`, ecode.InvalidParams.Msg()},
+		{http.MethodPut, "/department/1", `{"parentId":3,"name":"company"}`, ecode.ErrDepartmentParent.Msg()}, // under a descendant
+		{http.MethodPut, "/department/9", `{"name":"x"}`, ecode.NotFound.Msg()},
+		{http.MethodDelete, "/department/2", ``, ecode.ErrDepartmentHasChildren.Msg()},
+		{http.MethodPut, "/user/1/departments", `{"deptIds":[9]}`, ecode.ErrDepartmentIDs.Msg()},
+		{http.MethodPut, "/user/9/departments", `{"deptIds":[1]}`, ecode.NotFound.Msg()},
+		{http.MethodPut, "/role/9/departments", `{"deptIds":[1]}`, ecode.NotFound.Msg()},
+	} {
+		w = doMeRequest(r, tc.method, tc.path, "", "application/json", []byte(tc.body))
+		assert.Contains(t, w.Body.String(), tc.err, tc.path+" "+tc.body)
+	}
+
+	// the backend moves under the ops
+	w = doMeRequest(r, http.MethodPut, "/department/3", "", "application/json", []byte(`{"parentId":4,"name":"backend"}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/department/3", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"parentId":"4"`)
+	assert.Equal(t, "ops(backend) company(dev)", getDepartmentTree(t, r))
+
+	// the members of a deleted department are removed
+	w = doMeRequest(r, http.MethodPut, "/user/1/departments", "", "application/json", []byte(`{"deptIds":[3,2,3]}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/user/1/departments", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"deptIds":[2,3]`)
+	w = doMeRequest(r, http.MethodDelete, "/department/3", "", "", nil)
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/user/1/departments", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"deptIds":[2]`)
+}
+
+func listUserNames(t *testing.T, r http.Handler, uid string) []string {
+	w := doMeRequest(r, http.MethodPost, "/user/list", uid, "application/json", []byte(`{"page":0,"size":10,"sort":"id"}`))
+	reply := &struct {
+		Data struct {
+			Users []*types.UserObjDetail `json:"users"`
+			Total int                    `json:"total"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	names := []string{}
+	for _, user := range reply.Data.Users {
+		names = append(names, user.Name)
+	}
+	assert.Equal(t, len(names), reply.Data.Total)
+	return names
+}
+
+func listNamespaces(t *testing.T, r http.Handler, uid string) []string {
+	w := doMeRequest(r, http.MethodGet, "/k8s/namespaces", uid, "", nil)
+	reply := &struct {
+		Data struct {
+			Namespaces []string `json:"namespaces"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	return reply.Data.Namespaces
+}
+
+func Test_departmentHandler_DataScope(t *testing.T) {
+	r, roleDao := newDepartmentRouter(t)
+	ctx := context.Background()
+	createDepartments(t, r)
+	// foo and bar in dev, baz in backend, qux in company
+	for uid, body := range map[string]string{"1": `{"deptIds":[2]}`, "2": `{"deptIds":[2]}`, "3": `{"deptIds":[3]}`, "4": `{"deptIds":[1]}`} {
+		w := doMeRequest(r, http.MethodPut, "/user/"+uid+"/departments", "", "application/json", []byte(body))
+		require.Contains(t, w.Body.String(), `"code":0`)
+	}
+	setMode := func(mode string) {
+		role, err := roleDao.GetByID(ctx, 1)
+		require.NoError(t, err)
+		role.DataScopeMode = mode
+		role.Version = 0
+		require.NoError(t, roleDao.UpdateByID(ctx, role))
+	}
+
+	// the requests without a user are not filtered
+	assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, listUserNames(t, r, ""))
+	assert.Equal(t, []string{"backend", "dev", "prod", "sandbox"}, listNamespaces(t, r, ""))
+
+	// the mode all lists all the users but only the namespaces of the data scope
+	assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, listUserNames(t, r, "1"))
+	assert.Equal(t, []string{"sandbox"}, listNamespaces(t, r, "1"))
+
+	setMode(model.DataScopeModeDept)
+	assert.Equal(t, []string{"foo", "bar"}, listUserNames(t, r, "1"))
+	assert.Equal(t, []string{"dev", "sandbox"}, listNamespaces(t, r, "1"))
+
+	setMode(model.DataScopeModeDeptAndChildren)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, listUserNames(t, r, "1"))
+	assert.Equal(t, []string{"backend", "dev", "sandbox"}, listNamespaces(t, r, "1"))
+	assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, listUserNames(t, r, "4"))
+
+	setMode(model.DataScopeModeSelf)
+	assert.Equal(t, []string{"foo"}, listUserNames(t, r, "1"))
+	assert.Equal(t, []string{"sandbox"}, listNamespaces(t, r, "1"))
+
+	setMode(model.DataScopeModeCustom)
+	w := doMeRequest(r, http.MethodPut, "/role/1/departments", "", "application/json", []byte(`{"deptIds":[3]}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/role/1/departments", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"deptIds":[3]`)
+	assert.Equal(t, []string{"foo", "baz"}, listUserNames(t, r, "1"))
+	assert.Equal(t, []string{"backend", "sandbox"}, listNamespaces(t, r, "1"))
+}
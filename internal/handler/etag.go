@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag the strong etag of a record version
+func versionETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// setETag set the ETag header to the version of the record, it is sent back in the If-Match header to update the record
+func setETag(c *gin.Context, version uint64) {
+	c.Header("ETag", versionETag(version))
+}
+
+// isNotModified reports whether the If-None-Match header matches the version, if so the 304 status is written
+func isNotModified(c *gin.Context, version uint64) bool {
+	etag := versionETag(version)
+	for _, v := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		if v = strings.TrimPrefix(strings.TrimSpace(v), "W/"); v == etag || v == "*" {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// getIfMatchVersion get the version in the If-Match header, it returns 0 if the header is not set
+func getIfMatchVersion(c *gin.Context) (uint64, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(value, "W/"), `"`), 10, 64)
+	if err != nil || version == 0 {
+		return 0, fmt.Errorf("invalid If-Match header %q, it must be the ETag returned by get", value)
+	}
+	return version, nil
+}
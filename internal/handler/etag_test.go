@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHeaderContext(key string, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if value != "" {
+		c.Request.Header.Set(key, value)
+	}
+	return c, w
+}
+
+func Test_getIfMatchVersion(t *testing.T) {
+	for value, want := range map[string]uint64{"": 0, `"3"`: 3, `W/"4"`: 4, "5": 5} {
+		c, _ := newHeaderContext("If-Match", value)
+		version, err := getIfMatchVersion(c)
+		assert.NoError(t, err)
+		assert.Equal(t, want, version)
+	}
+
+	for _, value := range []string{"*", `"abc"`, `"0"`} {
+		c, _ := newHeaderContext("If-Match", value)
+		_, err := getIfMatchVersion(c)
+		assert.Error(t, err)
+	}
+}
+
+func Test_isNotModified(t *testing.T) {
+	c, w := newHeaderContext("If-None-Match", `"1", "2"`)
+	assert.True(t, isNotModified(c, 2))
+	assert.Equal(t, http.StatusNotModified, c.Writer.Status())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	c, _ = newHeaderContext("If-None-Match", `"1"`)
+	assert.False(t, isNotModified(c, 2))
+	c, _ = newHeaderContext("If-None-Match", "")
+	assert.False(t, isNotModified(c, 2))
+}
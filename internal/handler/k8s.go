@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/errcode"
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/datascope"
+	"go-admin/internal/ecode"
+)
+
+// getKubeClient create a kubernetes client, if it fails, the error response is written and false is returned
+func getKubeClient(c *gin.Context, newClient func() (kubernetes.Interface, error)) (kubernetes.Interface, bool) {
+	client, err := newClient()
+	if err != nil {
+		logger.Error("NewKubeClient error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return nil, false
+	}
+	return client, true
+}
+
+// getDataScope get the data scope of the request, nil if the namespaces are not restricted,
+// if it fails, the error response is written and false is returned
+func getDataScope(c *gin.Context) (*datascope.Scope, bool) {
+	scope, err := datascope.FromContext(middleware.WrapCtx(c))
+	if err != nil {
+		logger.Error("GetDataScope error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrDataScope)
+		return nil, false
+	}
+	return scope, true
+}
+
+// checkNamespace the namespace must be in the data scope of the user who sent the request,
+// if not, the error response is written and false is returned
+func checkNamespace(c *gin.Context, namespace string) bool {
+	scope, ok := getDataScope(c)
+	if !ok {
+		return false
+	}
+	if !scope.CanSeeNamespace(namespace) {
+		logger.Warn("namespace is out of the data scope", logger.String("namespace", namespace),
+			logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.Forbidden)
+		return false
+	}
+	return true
+}
+
+// checkK8sWrite the caller must hold one of the roles in k8s.writeRoles to change the resources of the namespace,
+// which must be in their data scope, if not, the error response is written and false is returned
+func checkK8sWrite(c *gin.Context, urDao dao.UserRoleDao, namespace string) bool {
+	return checkAnyRole(c, urDao, config.Get().K8s.WriteRoles) && checkNamespace(c, namespace)
+}
+
+// responseK8sError convert the error returned by the kubernetes api server to a response,
+// errors that have no dedicated code are returned as failErr.
+func responseK8sError(c *gin.Context, msg string, err error, failErr *errcode.Error, fields ...logger.Field) {
+	fields = append(fields, logger.Err(err), middleware.GCtxRequestIDField(c))
+	switch {
+	case apierrors.IsNotFound(err):
+		logger.Warn(msg+" not found", fields...)
+		response.Error(c, ecode.NotFound)
+	case apierrors.IsForbidden(err):
+		logger.Warn(msg+" forbidden", fields...)
+		response.Error(c, ecode.Forbidden)
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		logger.Warn(msg+" conflict", fields...)
+		response.Error(c, ecode.AlreadyExists)
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		logger.Warn(msg+" invalid", fields...)
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+	default:
+		logger.Error(msg+" error", fields...)
+		response.Error(c, failErr)
+	}
+}
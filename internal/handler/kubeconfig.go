@@ -0,0 +1,504 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/datascope"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	kubeutils "go-admin/internal/utils"
+)
+
+// labels of the service accounts and the bindings created for the kubeconfigs
+const (
+	kubeconfigManagedByLabel = "app.kubernetes.io/managed-by"
+	kubeconfigManagedBy      = "go-admin"
+	kubeconfigUserIDLabel    = "go-admin/user-id"
+
+	minTokenTTL = 600 // minimum lifetime of a token accepted by kubernetes, unit(second)
+)
+
+// errElevationEnding a temporary role of the user ends before the minimum lifetime of a token
+var errElevationEnding = errors.New("the temporary roles end before the minimum lifetime of a token")
+
+var _ KubeconfigHandler = (*kubeconfigHandler)(nil)
+var _ KubeconfigSyncer = (*kubeconfigHandler)(nil)
+
+// KubeconfigHandler defining the handler interface
+type KubeconfigHandler interface {
+	Generate(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+// KubeconfigSyncer re-syncs the bindings of the kubeconfig of a user with the data scope of their roles,
+// it is called out of the requests, e.g. when a temporary role of the user expires
+type KubeconfigSyncer interface {
+	Sync(ctx context.Context, userID uint64) error
+}
+
+type kubeconfigHandler struct {
+	newClient  func() (kubernetes.Interface, error)
+	restConfig func() (*rest.Config, error)
+	iDao       dao.UserDao
+	deptDao    dao.DepartmentDao
+	elevDao    dao.RoleElevationDao
+	cfg        config.Kubeconfig
+}
+
+// NewKubeconfigHandler creating the handler interface
+func NewKubeconfigHandler() KubeconfigHandler {
+	return newKubeconfigHandler()
+}
+
+// NewKubeconfigSyncer creating the syncer interface
+func NewKubeconfigSyncer() KubeconfigSyncer {
+	return newKubeconfigHandler()
+}
+
+func newKubeconfigHandler() *kubeconfigHandler {
+	return &kubeconfigHandler{
+		newClient:  kubeutils.NewKubeClient,
+		restConfig: kubeutils.GetKubeConfig,
+		iDao: dao.NewUserDao(
+			model.GetDB(),
+			cache.NewUserCache(model.GetCacheType()),
+		),
+		deptDao: dao.NewDepartmentDao(model.GetDB()),
+		elevDao: dao.NewRoleElevationDao(model.GetDB()),
+		cfg:     newKubeconfigConfig(config.Get().K8s.Kubeconfig),
+	}
+}
+
+func newKubeconfigConfig(cfg config.Kubeconfig) config.Kubeconfig {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "go-admin-users"
+	}
+	if cfg.ClusterRole == "" {
+		cfg.ClusterRole = "edit"
+	}
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = 8 * 60 * 60
+	}
+	if cfg.MaxTokenTTL <= 0 {
+		cfg.MaxTokenTTL = 24 * 60 * 60
+	}
+	if cfg.TokenTTL > cfg.MaxTokenTTL {
+		cfg.TokenTTL = cfg.MaxTokenTTL
+	}
+	if cfg.ClusterName == "" {
+		cfg.ClusterName = "kubernetes"
+	}
+	return cfg
+}
+
+// kubeconfigGrants the cluster roles to bind for the roles, by namespace, and the cluster roles bound in all
+// the namespaces for the roles whose data scope is DataScopeAll
+type kubeconfigGrants struct {
+	namespaces map[string][]string
+	cluster    []string
+}
+
+func (g *kubeconfigGrants) empty() bool {
+	return len(g.namespaces) == 0 && len(g.cluster) == 0
+}
+
+// firstNamespace the default namespace of the kubeconfig
+func (g *kubeconfigGrants) firstNamespace() string {
+	names := make([]string, 0, len(g.namespaces))
+	for ns := range g.namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// newKubeconfigGrants the grants of the data scopes of the roles, the namespaces of a role are the ones of its data
+// scope and of the departments of its data scope mode, the cluster role of a role is the one of its key in
+// cfg.RoleClusterRoles, or cfg.ClusterRole
+func newKubeconfigGrants(scope *datascope.Scope, cfg config.Kubeconfig) *kubeconfigGrants {
+	clusterRoles := map[string]string{}
+	for _, rc := range cfg.RoleClusterRoles {
+		clusterRoles[rc.RoleKey] = rc.ClusterRole
+	}
+
+	namespaces := map[string]map[string]bool{}
+	cluster := map[string]bool{}
+	for _, role := range scope.Roles {
+		clusterRole := clusterRoles[role.RoleKey]
+		if clusterRole == "" {
+			clusterRole = cfg.ClusterRole
+		}
+		if role.AllNamespaces {
+			cluster[clusterRole] = true
+			continue
+		}
+		for _, ns := range role.Namespaces {
+			if namespaces[ns] == nil {
+				namespaces[ns] = map[string]bool{}
+			}
+			namespaces[ns][clusterRole] = true
+		}
+	}
+
+	grants := &kubeconfigGrants{namespaces: map[string][]string{}, cluster: sortedKeys(cluster)}
+	for ns, set := range namespaces {
+		grants.namespaces[ns] = sortedKeys(set)
+	}
+	return grants
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// kubeconfigAccountName name of the service account of the user
+func kubeconfigAccountName(userID uint64) string {
+	return "go-admin-user-" + utils.Uint64ToStr(userID)
+}
+
+func kubeconfigLabels(userID uint64) map[string]string {
+	return map[string]string{
+		kubeconfigManagedByLabel: kubeconfigManagedBy,
+		kubeconfigUserIDLabel:    utils.Uint64ToStr(userID),
+	}
+}
+
+func kubeconfigSelector(userID uint64) string {
+	return kubeconfigManagedByLabel + "=" + kubeconfigManagedBy + "," + kubeconfigUserIDLabel + "=" + utils.Uint64ToStr(userID)
+}
+
+// Generate create a kubeconfig of the authenticated user
+// @Summary generate my kubeconfig
+// @Description create or reuse the service account of the user who sent the request, bind it to the cluster roles in the
+// @Description namespaces permitted by the data scope of the roles of the user and by the departments of their data scope
+// @Description modes, and return a kubeconfig file with a token of the service account that expires after expiresIn seconds.
+// @Description The bindings no longer permitted are removed. The token expires no later than the temporary roles of the user,
+// @Description the kubeconfig is refused if one of them ends before the minimum lifetime of a token.
+// @Tags kubeconfig
+// @Param expiresIn query int false "lifetime of the token in seconds, 0 is the default lifetime"
+// @Produce application/yaml
+// @Success 200 {file} file "kubeconfig file"
+// @Router /api/v1/k8s/kubeconfig [post]
+// @Security BearerAuth
+func (h *kubeconfigHandler) Generate(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	form := &types.GenerateKubeconfigRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ttl := form.ExpiresIn
+	if ttl == 0 {
+		ttl = h.cfg.TokenTTL
+	}
+	if ttl > h.cfg.MaxTokenTTL {
+		response.Error(c, ecode.ErrKubeconfigExpiresIn)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	scope, err := h.deptDao.GetDataScope(ctx, uid)
+	if err != nil {
+		logger.Error("GetDataScope error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	grants := newKubeconfigGrants(scope, h.cfg)
+	if grants.empty() {
+		response.Error(c, ecode.ErrKubeconfigNoNamespace)
+		return
+	}
+	ttl, err = h.elevatedTTL(ctx, uid, ttl)
+	if errors.Is(err, errElevationEnding) {
+		response.Error(c, ecode.ErrKubeconfigElevationEnding)
+		return
+	}
+	if err != nil {
+		logger.Error("GetActiveByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	restConfig, err := h.restConfig()
+	if err != nil {
+		logger.Error("GetKubeConfig error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	fields := []logger.Field{logger.Any("id", uid), logger.String("namespace", h.cfg.Namespace)}
+	name := kubeconfigAccountName(uid)
+	if err = h.ensureServiceAccount(ctx, client, uid); err != nil {
+		responseK8sError(c, "Create serviceaccount", err, ecode.ErrGenerateKubeconfig, fields...)
+		return
+	}
+	if err = h.syncBindings(ctx, client, uid, grants); err != nil {
+		responseK8sError(c, "Sync rolebindings", err, ecode.ErrGenerateKubeconfig, fields...)
+		return
+	}
+	expiration := int64(ttl)
+	tokenRequest, err := client.CoreV1().ServiceAccounts(h.cfg.Namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		responseK8sError(c, "Create token", err, ecode.ErrGenerateKubeconfig, fields...)
+		return
+	}
+
+	data, err := h.buildKubeconfig(restConfig, user.Name, grants.firstNamespace(), tokenRequest.Status.Token)
+	if err != nil {
+		logger.Error("buildKubeconfig error", append(fields, logger.Err(err), middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrGenerateKubeconfig)
+		return
+	}
+	logger.Info("kubeconfig generated", append(fields, logger.Int("expiresIn", ttl), middleware.GCtxRequestIDField(c))...)
+
+	c.Header("Cache-Control", "no-store") // the file holds the token
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="kubeconfig-%s.yaml"`, user.Name))
+	c.Data(200, "application/yaml", data)
+}
+
+// Revoke delete the service account and the bindings of the authenticated user
+// @Summary revoke my kubeconfigs
+// @Description delete the service account of the user who sent the request and its bindings, the tokens of all the
+// @Description kubeconfigs generated before are rejected by the api server at once.
+// @Tags kubeconfig
+// @Produce json
+// @Success 200 {object} types.RevokeKubeconfigRespond{}
+// @Router /api/v1/k8s/kubeconfig [delete]
+// @Security BearerAuth
+func (h *kubeconfigHandler) Revoke(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	fields := []logger.Field{logger.Any("id", uid), logger.String("namespace", h.cfg.Namespace)}
+	if err := h.syncBindings(ctx, client, uid, &kubeconfigGrants{}); err != nil {
+		responseK8sError(c, "Delete rolebindings", err, ecode.ErrRevokeKubeconfig, fields...)
+		return
+	}
+	err := client.CoreV1().ServiceAccounts(h.cfg.Namespace).Delete(ctx, kubeconfigAccountName(uid), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		responseK8sError(c, "Delete serviceaccount", err, ecode.ErrRevokeKubeconfig, fields...)
+		return
+	}
+	logger.Info("kubeconfig revoked", append(fields, middleware.GCtxRequestIDField(c))...)
+
+	response.Success(c)
+}
+
+// elevatedTTL the lifetime of the token bounded by the expiry of the temporary roles of the user, so that the
+// token does not outlive them, errElevationEnding if a role ends before the minimum lifetime of the tokens of kubernetes
+func (h *kubeconfigHandler) elevatedTTL(ctx context.Context, userID uint64, ttl int) (int, error) {
+	now := time.Now().Unix()
+	elevations, err := h.elevDao.GetActiveByUserID(ctx, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	bounded := ttl
+	for _, elevation := range elevations {
+		if left := int(elevation.ExpiresAt - now); left < bounded {
+			bounded = left
+		}
+	}
+	if bounded < ttl && bounded < minTokenTTL {
+		return 0, errElevationEnding
+	}
+	return bounded, nil
+}
+
+// Sync bind the service account of the user to the cluster roles permitted by the data scope of their roles now,
+// the bindings no longer permitted are removed, nothing is done if the user has no kubeconfig
+func (h *kubeconfigHandler) Sync(ctx context.Context, userID uint64) error {
+	client, err := h.newClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.CoreV1().ServiceAccounts(h.cfg.Namespace).Get(ctx, kubeconfigAccountName(userID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	scope, err := h.deptDao.GetDataScope(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return h.syncBindings(ctx, client, userID, newKubeconfigGrants(scope, h.cfg))
+}
+
+// ensureServiceAccount create the service account of the user if it does not exist
+func (h *kubeconfigHandler) ensureServiceAccount(ctx context.Context, client kubernetes.Interface, userID uint64) error {
+	accounts := client.CoreV1().ServiceAccounts(h.cfg.Namespace)
+	_, err := accounts.Get(ctx, kubeconfigAccountName(userID), metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	_, err = accounts.Create(ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeconfigAccountName(userID),
+			Namespace: h.cfg.Namespace,
+			Labels:    kubeconfigLabels(userID),
+		},
+	}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) { // created by a concurrent request
+		return nil
+	}
+	return err
+}
+
+// syncBindings bind the service account of the user to the cluster roles of the grants, the role bindings
+// and the cluster role bindings of the user that are not in the grants are deleted
+func (h *kubeconfigHandler) syncBindings(ctx context.Context, client kubernetes.Interface, userID uint64, grants *kubeconfigGrants) error {
+	account := kubeconfigAccountName(userID)
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: account, Namespace: h.cfg.Namespace}}
+	selector := metav1.ListOptions{LabelSelector: kubeconfigSelector(userID)}
+
+	wanted := map[string]bool{} // namespace/name
+	for ns, clusterRoles := range grants.namespaces {
+		for _, clusterRole := range clusterRoles {
+			binding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: account + "-" + clusterRole, Namespace: ns, Labels: kubeconfigLabels(userID)},
+				Subjects:   subjects,
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+			}
+			wanted[ns+"/"+binding.Name] = true
+			_, err := client.RbacV1().RoleBindings(ns).Create(ctx, binding, metav1.CreateOptions{})
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+		}
+	}
+	roleBindings, err := client.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, selector)
+	if err != nil {
+		return err
+	}
+	for _, binding := range roleBindings.Items {
+		if wanted[binding.Namespace+"/"+binding.Name] {
+			continue
+		}
+		err = client.RbacV1().RoleBindings(binding.Namespace).Delete(ctx, binding.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	wanted = map[string]bool{}
+	for _, clusterRole := range grants.cluster {
+		binding := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: account + "-" + clusterRole, Labels: kubeconfigLabels(userID)},
+			Subjects:   subjects,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+		}
+		wanted[binding.Name] = true
+		_, err = client.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, selector)
+	if err != nil {
+		return err
+	}
+	for _, binding := range clusterRoleBindings.Items {
+		if wanted[binding.Name] {
+			continue
+		}
+		err = client.RbacV1().ClusterRoleBindings().Delete(ctx, binding.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildKubeconfig the kubeconfig file of the token, the server and the certificate authority are the ones of
+// the config of the admin unless the server is set in the config
+func (h *kubeconfigHandler) buildKubeconfig(restConfig *rest.Config, userName string, namespace string, token string) ([]byte, error) {
+	server := h.cfg.Server
+	if server == "" {
+		server = restConfig.Host
+	}
+	caData := restConfig.TLSClientConfig.CAData
+	if len(caData) == 0 && restConfig.TLSClientConfig.CAFile != "" { // the in-cluster config refers to the file
+		b, err := os.ReadFile(restConfig.TLSClientConfig.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		caData = b
+	}
+	cluster := &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: caData,
+		InsecureSkipTLSVerify:    restConfig.TLSClientConfig.Insecure,
+	}
+
+	contextName := userName + "@" + h.cfg.ClusterName
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[h.cfg.ClusterName] = cluster
+	kubeconfig.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: token}
+	kubeconfig.Contexts[contextName] = &clientcmdapi.Context{Cluster: h.cfg.ClusterName, AuthInfo: userName, Namespace: namespace}
+	kubeconfig.CurrentContext = contextName
+	return clientcmd.Write(*kubeconfig)
+}
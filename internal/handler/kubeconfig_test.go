@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/datascope"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+)
+
+// newKubeconfigRouter the user foo (id 1) of the roles dev and viewer, the role viewer also permits the namespaces
+// of the departments of its members, and the user bar (id 2) without role
+func newKubeconfigRouter(t *testing.T) (*gin.Engine, *fake.Clientset, dao.UserRoleDao, dao.DepartmentDao) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	iDao := dao.NewUserDao(db, nil)
+	for _, name := range []string{"foo", "bar"} {
+		assert.NoError(t, iDao.Create(ctx, &model.User{Name: name, Status: model.UserStatusActivated}))
+	}
+	roleDao := dao.NewRoleDao(db, nil)
+	assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: "dev", RoleKey: "dev", DataScope: "team-a, team-b"}))
+	assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: "viewer", RoleKey: "viewer", DataScope: "team-b,team-c", DataScopeMode: model.DataScopeModeDept}))
+	assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: "ops", RoleKey: "ops", DataScope: "*"}))
+	urDao := dao.NewUserRoleDao(db)
+	assert.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{1, 2}))
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		request.Status.Token = "sa-token"
+		return true, request, nil
+	})
+
+	h := &kubeconfigHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		restConfig: func() (*rest.Config, error) {
+			return &rest.Config{Host: "https://10.0.0.1:6443", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")}}, nil
+		},
+		iDao:    iDao,
+		deptDao: dao.NewDepartmentDao(db),
+		elevDao: dao.NewRoleElevationDao(db),
+		cfg: newKubeconfigConfig(config.Kubeconfig{
+			Namespace:        "users",
+			RoleClusterRoles: []config.RoleClusterRole{{RoleKey: "viewer", ClusterRole: "view"}},
+			MaxTokenTTL:      3600,
+		}),
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.POST("/k8s/kubeconfig", h.Generate)
+	r.DELETE("/k8s/kubeconfig", h.Revoke)
+	return r, client, urDao, h.deptDao
+}
+
+func bindingNames(t *testing.T, client kubernetes.Interface) []string {
+	ctx := context.Background()
+	names := []string{}
+	roleBindings, err := client.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, binding := range roleBindings.Items {
+		names = append(names, binding.Namespace+"/"+binding.Name+"->"+binding.RoleRef.Name)
+	}
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, binding := range clusterRoleBindings.Items {
+		names = append(names, binding.Name+"->"+binding.RoleRef.Name)
+	}
+	return names
+}
+
+func Test_kubeconfigHandler_Generate(t *testing.T) {
+	r, client, urDao, deptDao := newKubeconfigRouter(t)
+	ctx := context.Background()
+
+	w := doMeRequest(r, http.MethodPost, "/k8s/kubeconfig?expiresIn=600", "1", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "kubeconfig-foo.yaml")
+	kubeconfig, err := clientcmd.Load(w.Body.Bytes())
+	require.NoError(t, err, w.Body.String())
+	assert.Equal(t, "foo@kubernetes", kubeconfig.CurrentContext)
+	assert.Equal(t, "team-a", kubeconfig.Contexts["foo@kubernetes"].Namespace)
+	assert.Equal(t, "https://10.0.0.1:6443", kubeconfig.Clusters["kubernetes"].Server)
+	assert.Equal(t, []byte("ca"), kubeconfig.Clusters["kubernetes"].CertificateAuthorityData)
+	assert.Equal(t, "sa-token", kubeconfig.AuthInfos["foo"].Token)
+
+	account, err := client.CoreV1().ServiceAccounts("users").Get(ctx, "go-admin-user-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1", account.Labels[kubeconfigUserIDLabel])
+	assert.ElementsMatch(t, []string{
+		"team-a/go-admin-user-1-edit->edit",
+		"team-b/go-admin-user-1-edit->edit",
+		"team-b/go-admin-user-1-view->view",
+		"team-c/go-admin-user-1-view->view",
+	}, bindingNames(t, client))
+
+	// the account is reused, the bindings follow the roles
+	assert.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{2, 3}))
+	w = doMeRequest(r, http.MethodPost, "/k8s/kubeconfig", "1", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{
+		"team-b/go-admin-user-1-view->view",
+		"team-c/go-admin-user-1-view->view",
+		"go-admin-user-1-edit->edit",
+	}, bindingNames(t, client))
+
+	// the namespaces of the departments of the data scope mode of a role are granted with its cluster role,
+	// the mode all of the role ops does not grant the namespaces of all the departments
+	require.NoError(t, deptDao.Create(ctx, &model.Department{Name: "support", Namespaces: "team-d"}))
+	require.NoError(t, deptDao.SetUserDepartments(ctx, 1, []uint64{1}))
+	w = doMeRequest(r, http.MethodPost, "/k8s/kubeconfig", "1", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{
+		"team-b/go-admin-user-1-view->view",
+		"team-c/go-admin-user-1-view->view",
+		"team-d/go-admin-user-1-view->view",
+		"go-admin-user-1-edit->edit",
+	}, bindingNames(t, client))
+
+	w = doMeRequest(r, http.MethodPost, "/k8s/kubeconfig?expiresIn=3601", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrKubeconfigExpiresIn.Msg())
+	w = doMeRequest(r, http.MethodPost, "/k8s/kubeconfig", "2", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrKubeconfigNoNamespace.Msg())
+	w = doMeRequest(r, http.MethodPost, "/k8s/kubeconfig", "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+
+	// the revocation deletes the account and the bindings, the tokens are rejected
+	w = doMeRequest(r, http.MethodDelete, "/k8s/kubeconfig", "1", "", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	assert.Empty(t, bindingNames(t, client))
+	_, err = client.CoreV1().ServiceAccounts("users").Get(ctx, "go-admin-user-1", metav1.GetOptions{})
+	assert.Error(t, err)
+	w = doMeRequest(r, http.MethodDelete, "/k8s/kubeconfig", "1", "", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_kubeconfigHandler_Sync(t *testing.T) {
+	r, client, urDao, deptDao := newKubeconfigRouter(t)
+	ctx := context.Background()
+	h := &kubeconfigHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		deptDao:   deptDao,
+		cfg:       newKubeconfigConfig(config.Kubeconfig{Namespace: "users"}),
+	}
+
+	// nothing is bound for a user without kubeconfig
+	require.NoError(t, h.Sync(ctx, 1))
+	assert.Empty(t, bindingNames(t, client))
+
+	w := doMeRequest(r, http.MethodPost, "/k8s/kubeconfig", "1", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, bindingNames(t, client), 4)
+
+	// the bindings of the roles the user no longer holds are removed
+	assert.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{1}))
+	require.NoError(t, h.Sync(ctx, 1))
+	assert.ElementsMatch(t, []string{
+		"team-a/go-admin-user-1-edit->edit",
+		"team-b/go-admin-user-1-edit->edit",
+	}, bindingNames(t, client))
+}
+
+func Test_newKubeconfigGrants(t *testing.T) {
+	cfg := newKubeconfigConfig(config.Kubeconfig{RoleClusterRoles: []config.RoleClusterRole{{RoleKey: "viewer", ClusterRole: "view"}}})
+	newScope := func(roles ...*model.Role) *datascope.Scope {
+		for i, role := range roles {
+			role.ID = uint64(i + 1)
+		}
+		dept := &model.Department{Namespaces: "c"}
+		dept.ID = 1
+		return datascope.New(1, roles, []uint64{1}, nil, []*model.Department{dept})
+	}
+
+	grants := newKubeconfigGrants(newScope(
+		&model.Role{RoleKey: "dev", DataScope: " b ,a,,b"},
+		&model.Role{RoleKey: "viewer", DataScope: "a", DataScopeMode: model.DataScopeModeDept},
+		&model.Role{RoleKey: "other", DataScope: ""},
+	), cfg)
+	assert.Equal(t, map[string][]string{"a": {"edit", "view"}, "b": {"edit"}, "c": {"view"}}, grants.namespaces)
+	assert.Empty(t, grants.cluster)
+	assert.Equal(t, "a", grants.firstNamespace())
+
+	grants = newKubeconfigGrants(newScope(&model.Role{RoleKey: "viewer", DataScope: "a,*"}), cfg)
+	assert.Equal(t, []string{"view"}, grants.cluster)
+	assert.Empty(t, grants.namespaces)
+	assert.Equal(t, "", grants.firstNamespace())
+	assert.True(t, newKubeconfigGrants(newScope(), cfg).empty())
+}
+
+func Test_kubeconfigHandler_elevatedTTL(t *testing.T) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	h := &kubeconfigHandler{elevDao: dao.NewRoleElevationDao(db)}
+	ttl, err := h.elevatedTTL(ctx, 1, 3600)
+	require.NoError(t, err)
+	assert.Equal(t, 3600, ttl)
+
+	// the token expires with the temporary role
+	now := time.Now().Unix()
+	require.NoError(t, h.elevDao.Create(ctx, &model.RoleElevation{UserID: 1, RoleID: 1, Reason: "incident", Duration: 1800}, now))
+	_, err = h.elevDao.Approve(ctx, 1, 2, "", now)
+	require.NoError(t, err)
+	ttl, err = h.elevatedTTL(ctx, 1, 3600)
+	require.NoError(t, err)
+	assert.InDelta(t, 1800, ttl, 2)
+	ttl, err = h.elevatedTTL(ctx, 1, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, ttl)
+
+	require.NoError(t, h.elevDao.Create(ctx, &model.RoleElevation{UserID: 1, RoleID: 2, Reason: "incident", Duration: 60}, now))
+	_, err = h.elevDao.Approve(ctx, 2, 2, "", now)
+	require.NoError(t, err)
+	// the temporary role ends before the minimum lifetime of kubernetes
+	_, err = h.elevatedTTL(ctx, 1, 3600)
+	assert.ErrorIs(t, err, errElevationEnding)
+	ttl, err = h.elevatedTTL(ctx, 1, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, ttl)
+}
@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+var _ MenuHandler = (*menuHandler)(nil)
+
+// MenuHandler defining the handler interface
+type MenuHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	GetTree(c *gin.Context)
+	GetMyTree(c *gin.Context)
+	GetRoleMenus(c *gin.Context)
+	SetRoleMenus(c *gin.Context)
+}
+
+type menuHandler struct {
+	iDao    dao.MenuDao
+	roleDao dao.RoleDao
+	urDao   dao.UserRoleDao
+}
+
+// NewMenuHandler creating the handler interface
+func NewMenuHandler() MenuHandler {
+	return &menuHandler{
+		iDao: dao.NewMenuDao(model.GetDB()),
+		roleDao: dao.NewRoleDao(
+			model.GetDB(),
+			cache.NewRoleCache(model.GetCacheType()),
+		),
+		urDao: dao.NewUserRoleDao(model.GetDB()),
+	}
+}
+
+// Create a record
+// @Summary create menu
+// @Description submit information to create a menu under its parent, the parent must be a directory or a menu
+// @Tags menu
+// @accept json
+// @Produce json
+// @Param data body types.CreateMenuRequest true "menu information"
+// @Success 200 {object} types.CreateMenuRespond{}
+// @Router /api/v1/menu [post]
+// @Security BearerAuth
+func (h *menuHandler) Create(c *gin.Context) {
+	form := &types.CreateMenuRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	menu := &model.Menu{}
+	err = copier.Copy(menu, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateMenu)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.Create(ctx, menu)
+	if err != nil {
+		if errors.Is(err, model.ErrMenuParent) {
+			response.Error(c, ecode.ErrMenuParent)
+			return
+		}
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"id": menu.ID})
+}
+
+// DeleteByID delete a record by id
+// @Summary delete menu
+// @Description delete a menu without children and its grants to the roles
+// @Tags menu
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteMenuByIDRespond{}
+// @Router /api/v1/menu/{id} [delete]
+// @Security BearerAuth
+func (h *menuHandler) DeleteByID(c *gin.Context) {
+	_, id, isAbort := getMenuIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrMenuHasChildren) {
+			response.Error(c, ecode.ErrMenuHasChildren)
+			return
+		}
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// UpdateByID update information by id
+// @Summary update menu
+// @Description replace the information of a menu, it can be moved under another parent but not under itself or its descendants
+// @Tags menu
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateMenuByIDRequest true "menu information"
+// @Success 200 {object} types.UpdateMenuByIDRespond{}
+// @Router /api/v1/menu/{id} [put]
+// @Security BearerAuth
+func (h *menuHandler) UpdateByID(c *gin.Context) {
+	_, id, isAbort := getMenuIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateMenuByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.ID = id
+
+	menu := &model.Menu{}
+	err = copier.Copy(menu, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDMenu)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.UpdateByID(ctx, menu)
+	if err != nil {
+		if errors.Is(err, model.ErrMenuParent) {
+			response.Error(c, ecode.ErrMenuParent)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetByID get a record by id
+// @Summary get menu detail
+// @Description get menu detail by id
+// @Tags menu
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetMenuByIDRespond{}
+// @Router /api/v1/menu/{id} [get]
+// @Security BearerAuth
+func (h *menuHandler) GetByID(c *gin.Context) {
+	_, id, isAbort := getMenuIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	menu, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"menu": convertMenu(menu)})
+}
+
+// GetTree get the whole menu tree
+// @Summary get the menu tree
+// @Description get all the menus and buttons as a tree, the siblings are sorted by sort and id
+// @Tags menu
+// @Produce json
+// @Success 200 {object} types.GetMenuTreeRespond{}
+// @Router /api/v1/menu/tree [get]
+// @Security BearerAuth
+func (h *menuHandler) GetTree(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	menus, err := h.iDao.GetAll(ctx)
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"menus": newMenuTree(menus, nil)})
+}
+
+// GetMyTree get the menu tree of the authenticated user
+// @Summary get my menu tree
+// @Description get the directories and menus granted to the roles of the user who sent the request, with their parents
+// @Description so that the tree stays connected, and the permission keys of the granted menus and buttons.
+// @Tags menu
+// @Produce json
+// @Success 200 {object} types.GetMyMenuTreeRespond{}
+// @Router /api/v1/menu/me/tree [get]
+// @Security BearerAuth
+func (h *menuHandler) GetMyTree(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	roles, err := h.urDao.GetInheritedRolesByUserID(ctx, uid)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	roleIDs := make([]uint64, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	granted, err := h.iDao.GetByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		logger.Error("GetByRoleIDs error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	menus, err := h.iDao.GetAll(ctx)
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	keep, permissions := pruneMenus(menus, granted)
+	response.Success(c, gin.H{
+		"menus":       newMenuTree(menus, keep),
+		"permissions": permissions,
+	})
+}
+
+// GetRoleMenus get the menus granted to a role
+// @Summary get role menus
+// @Description get the ids of the menus and buttons granted to the role
+// @Tags menu
+// @Produce json
+// @Param id path string true "role id"
+// @Success 200 {object} types.GetRoleMenusRespond{}
+// @Router /api/v1/role/{id}/menus [get]
+// @Security BearerAuth
+func (h *menuHandler) GetRoleMenus(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	ids, err := h.iDao.GetMenuIDsByRoleID(ctx, id)
+	if err != nil {
+		logger.Error("GetMenuIDsByRoleID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"menuIds": ids})
+}
+
+// SetRoleMenus replace the menus granted to a role
+// @Summary set role menus
+// @Description replace the menus and buttons granted to the role, the members see the granted menus in their menu tree
+// @Tags menu
+// @accept json
+// @Produce json
+// @Param id path string true "role id"
+// @Param data body types.SetRoleMenusRequest true "menu id list"
+// @Success 200 {object} types.SetRoleMenusRespond{}
+// @Router /api/v1/role/{id}/menus [put]
+// @Security BearerAuth
+func (h *menuHandler) SetRoleMenus(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.SetRoleMenusRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err = h.roleDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	menuIDs := uniqueIDs(form.MenuIDs)
+	menus, err := h.iDao.GetAll(ctx)
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	exists := make(map[uint64]bool, len(menus))
+	for _, menu := range menus {
+		exists[menu.ID] = true
+	}
+	for _, menuID := range menuIDs {
+		if !exists[menuID] {
+			response.Error(c, ecode.ErrRoleMenuIDs)
+			return
+		}
+	}
+
+	err = h.iDao.SetRoleMenus(ctx, id, menuIDs)
+	if err != nil {
+		logger.Error("SetRoleMenus error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSetRoleMenus)
+		return
+	}
+
+	response.Success(c)
+}
+
+func getMenuIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
+
+// pruneMenus the ids of the granted directories and menus with all their parents, the buttons are not kept,
+// and the sorted permission keys of the granted menus and buttons
+func pruneMenus(menus []*model.Menu, granted []*model.Menu) (map[uint64]bool, []string) {
+	parents := make(map[uint64]uint64, len(menus))
+	for _, menu := range menus {
+		parents[menu.ID] = menu.ParentID
+	}
+
+	keep := map[uint64]bool{}
+	seen := map[string]bool{}
+	permissions := []string{}
+	for _, menu := range granted {
+		if menu.Permission != "" && !seen[menu.Permission] {
+			seen[menu.Permission] = true
+			permissions = append(permissions, menu.Permission)
+		}
+		if menu.Type == model.MenuTypeButton {
+			continue
+		}
+		for id := menu.ID; id != 0 && !keep[id]; id = parents[id] {
+			keep[id] = true
+		}
+	}
+	sort.Strings(permissions)
+	return keep, permissions
+}
+
+// newMenuTree the tree of the menus, the menus are already sorted, only the menus of keep are in the tree if keep
+// is not nil, and a menu whose parent is not in the tree is not either
+func newMenuTree(menus []*model.Menu, keep map[uint64]bool) []*types.MenuObjDetail {
+	nodes := make(map[uint64]*types.MenuObjDetail, len(menus))
+	for _, menu := range menus {
+		if keep == nil || keep[menu.ID] {
+			nodes[menu.ID] = convertMenu(menu)
+		}
+	}
+
+	roots := []*types.MenuObjDetail{}
+	for _, menu := range menus {
+		node, ok := nodes[menu.ID]
+		if !ok {
+			continue
+		}
+		if menu.ParentID == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[menu.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+func convertMenu(menu *model.Menu) *types.MenuObjDetail {
+	return &types.MenuObjDetail{
+		ID:         utils.Uint64ToStr(menu.ID),
+		ParentID:   utils.Uint64ToStr(menu.ParentID),
+		Type:       menu.Type,
+		Title:      menu.Title,
+		Name:       menu.Name,
+		Path:       menu.Path,
+		Component:  menu.Component,
+		Icon:       menu.Icon,
+		Permission: menu.Permission,
+		Sort:       menu.Sort,
+		Hidden:     menu.Hidden,
+		CreatedAt:  menu.CreatedAt,
+		UpdatedAt:  menu.UpdatedAt,
+	}
+}
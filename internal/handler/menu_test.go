@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// newMenuRouter the user foo (id 1) of the role dev (id 1)
+func newMenuRouter(t *testing.T) *gin.Engine {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	assert.NoError(t, dao.NewUserDao(db, nil).Create(ctx, &model.User{Name: "foo", Status: model.UserStatusActivated}))
+	roleDao := dao.NewRoleDao(db, nil)
+	assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: "dev", RoleKey: "dev"}))
+	urDao := dao.NewUserRoleDao(db)
+	assert.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{1}))
+
+	h := &menuHandler{iDao: dao.NewMenuDao(db), roleDao: roleDao, urDao: urDao}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.GET("/menu/me/tree", h.GetMyTree)
+	r.POST("/menu", h.Create)
+	r.DELETE("/menu/:id", h.DeleteByID)
+	r.PUT("/menu/:id", h.UpdateByID)
+	r.GET("/menu/:id", h.GetByID)
+	r.GET("/menu/tree", h.GetTree)
+	r.GET("/role/:id/menus", h.GetRoleMenus)
+	r.PUT("/role/:id/menus", h.SetRoleMenus)
+	return r
+}
+
+type menuTreeReply struct {
+	Code int `json:"code"`
+	Data struct {
+		Menus       []*types.MenuObjDetail `json:"menus"`
+		Permissions []string               `json:"permissions"`
+	} `json:"data"`
+}
+
+func getMenuTree(t *testing.T, r http.Handler, path string, uid string) *menuTreeReply {
+	w := doMeRequest(r, http.MethodGet, path, uid, "", nil)
+	reply := &menuTreeReply{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	require.Equal(t, 0, reply.Code, w.Body.String())
+	return reply
+}
+
+// menuTitles the titles of the tree in depth first order, the children in parentheses
+func menuTitles(menus []*types.MenuObjDetail) string {
+	s := ""
+	for i, menu := range menus {
+		if i > 0 {
+			s += " "
+		}
+		s += menu.Title
+		if len(menu.Children) > 0 {
+			s += "(" + menuTitles(menu.Children) + ")"
+		}
+	}
+	return s
+}
+
+func Test_menuHandler_Tree(t *testing.T) {
+	r := newMenuRouter(t)
+	for _, body := range []string{
+		`{"type":"directory","title":"system","path":"/system","icon":"setting","sort":2}`,               // 1
+		`{"parentId":1,"type":"menu","title":"users","path":"user","component":"system/user/index"}`,     // 2
+		`{"parentId":2,"type":"button","title":"create user","permission":"user:create"}`,                // 3
+		`{"parentId":2,"type":"button","title":"delete user","permission":"user:delete"}`,                // 4
+		`{"parentId":1,"type":"menu","title":"roles","path":"role","component":"system/role/index"}`,     // 5
+		`{"type":"directory","title":"dashboard","path":"/dashboard","sort":1}`,                          // 6
+		`{"parentId":6,"type":"menu","title":"overview","path":"overview","permission":"overview:view"}`, // 7
+	} {
+		w := doMeRequest(r, http.MethodPost, "/menu", "", "application/json", []byte(body))
+		require.Contains(t, w.Body.String(), `"code":0`, body)
+	}
+
+	reply := getMenuTree(t, r, "/menu/tree", "")
+	assert.Equal(t, "dashboard(overview) system(users(create user delete user) roles)", menuTitles(reply.Data.Menus))
+
+	// the user sees the granted menus with their parents, the buttons only grant their permission keys
+	assert.Empty(t, getMenuTree(t, r, "/menu/me/tree", "1").Data.Menus)
+	w := doMeRequest(r, http.MethodPut, "/role/1/menus", "", "application/json", []byte(`{"menuIds":[2,3,7,7]}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/role/1/menus", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"menuIds":[2,3,7]`)
+	reply = getMenuTree(t, r, "/menu/me/tree", "1")
+	assert.Equal(t, "dashboard(overview) system(users)", menuTitles(reply.Data.Menus))
+	assert.Equal(t, []string{"overview:view", "user:create"}, reply.Data.Permissions)
+	assert.Equal(t, "/system", reply.Data.Menus[1].Path)
+	assert.Equal(t, "system/user/index", reply.Data.Menus[1].Children[0].Component)
+
+	w = doMeRequest(r, http.MethodGet, "/menu/me/tree", "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+	w = doMeRequest(r, http.MethodPut, "/role/1/menus", "", "application/json", []byte(`{"menuIds":[99]}`))
+	assert.Contains(t, w.Body.String(), ecode.ErrRoleMenuIDs.Msg())
+	w = doMeRequest(r, http.MethodPut, "/role/9/menus", "", "application/json", []byte(`{"menuIds":[1]}`))
+	assert.Contains(t, w.Body.String(), ecode.NotFound.Msg())
+
+	// the roles menu moves under the dashboard
+	w = doMeRequest(r, http.MethodPut, "/menu/5", "", "application/json", []byte(`{"parentId":6,"type":"menu","title":"roles","sort":-1}`))
+	require.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/menu/5", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"parentId":"6"`)
+	reply = getMenuTree(t, r, "/menu/tree", "")
+	assert.Equal(t, "dashboard(roles overview) system(users(create user delete user))", menuTitles(reply.Data.Menus))
+
+	// a menu with children is not deleted, the grants of a deleted menu are removed
+	w = doMeRequest(r, http.MethodDelete, "/menu/2", "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrMenuHasChildren.Msg())
+	w = doMeRequest(r, http.MethodDelete, "/menu/3", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodGet, "/role/1/menus", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"menuIds":[2,7]`)
+	w = doMeRequest(r, http.MethodGet, "/menu/3", "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.NotFound.Msg())
+}
+
+func Test_menuHandler_InvalidParent(t *testing.T) {
+	r := newMenuRouter(t)
+	for _, body := range []string{
+		`{"type":"directory","title":"system"}`,
+		`{"parentId":1,"type":"menu","title":"users"}`,
+		`{"parentId":2,"type":"button","title":"create user","permission":"user:create"}`,
+	} {
+		w := doMeRequest(r, http.MethodPost, "/menu", "", "application/json", []byte(body))
+		require.Contains(t, w.Body.String(), `"code":0`, body)
+	}
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   string
+		err    string
+	}{
+		{http.MethodPost, "/menu", `{"parentId":9,"type":"menu","title":"x"}`, ecode.ErrMenuParent.Msg()},
+		{http.MethodPost, "/menu", `{"parentId":3,"type":"menu","title":"x"}`, ecode.ErrMenuParent.Msg()}, // under a button
+		{http.MethodPost, "/menu", `{"type":"page","title":"x"}`, ecode.InvalidParams.Msg()},
+		{http.MethodPut, "/menu/1", `{"parentId":1,"type":"directory","title":"system"}`, ecode.ErrMenuParent.Msg()},
+		{http.MethodPut, "/menu/1", `{"parentId":2,"type":"directory","title":"system"}`, ecode.ErrMenuParent.Msg()}, // under a descendant
+		{http.MethodPut, "/menu/9", `{"type":"directory","title":"x"}`, ecode.NotFound.Msg()},
+	} {
+		w := doMeRequest(r, tc.method, tc.path, "", "application/json", []byte(tc.body))
+		assert.Contains(t, w.Body.String(), tc.err, tc.body)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+
+	"go-admin/internal/ecode"
+	"go-admin/internal/utils"
+)
+
+var _ NamespaceHandler = (*namespaceHandler)(nil)
+
+// NamespaceHandler defining the handler interface
+type NamespaceHandler interface {
+	List(c *gin.Context)
+}
+
+type namespaceHandler struct {
+	newClient func() (kubernetes.Interface, error)
+}
+
+// NewNamespaceHandler creating the handler interface
+func NewNamespaceHandler() NamespaceHandler {
+	return &namespaceHandler{
+		newClient: utils.NewKubeClient,
+	}
+}
+
+// List list of the namespaces of the cluster in the data scope of the user
+// @Summary list of namespaces
+// @Description list of the names of the namespaces of the cluster, only the namespaces permitted by the data scope of the
+// @Description roles of the user who sent the request and of their departments are listed
+// @Tags namespace
+// @Produce json
+// @Success 200 {object} types.ListNamespacesRespond{}
+// @Router /api/v1/k8s/namespaces [get]
+// @Security BearerAuth
+func (h *namespaceHandler) List(c *gin.Context) {
+	scope, ok := getDataScope(c)
+	if !ok {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List namespaces", err, ecode.ErrListNamespaces)
+		return
+	}
+
+	names := []string{}
+	for _, ns := range list.Items {
+		if scope.CanSeeNamespace(ns.Name) {
+			names = append(names, ns.Name)
+		}
+	}
+	sort.Strings(names)
+
+	response.Success(c, gin.H{"namespaces": names})
+}
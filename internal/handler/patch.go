@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm/schema"
+)
+
+// patchField a field of a model that can be changed by a patch
+type patchField struct {
+	column string
+	typ    reflect.Type
+	index  int // index of the field in the update request
+}
+
+// parseMergePatch convert a JSON merge patch (RFC 7386) of a record to the columns to update,
+// the fields that can be patched are the json fields of the update request found in the model,
+// a null value sets the field to its zero value and the absent fields are left unchanged.
+// the patched values are validated by the binding rules of the update request, the fields in required
+// can not be set to their zero value.
+// the version field of the patch is returned separately, it is 0 if it is absent.
+func parseMergePatch(data []byte, table interface{}, request interface{}, required ...string) (map[string]interface{}, uint64, error) {
+	patch := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, 0, fmt.Errorf("the patch must be a json object: %v", err)
+	}
+
+	fields := patchFields(table, request)
+	patched := reflect.New(reflect.Indirect(reflect.ValueOf(request)).Type()).Elem()
+	columns := map[string]interface{}{}
+	var version uint64
+	for name, raw := range patch {
+		if name == "version" {
+			if err := json.Unmarshal(raw, &version); err != nil {
+				return nil, 0, fmt.Errorf("invalid version: %v", err)
+			}
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, 0, fmt.Errorf("field %s can not be patched", name)
+		}
+		value := reflect.New(field.typ)
+		if string(raw) != "null" {
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				return nil, 0, fmt.Errorf("invalid value of field %s: %v", name, err)
+			}
+		}
+		if value.Elem().IsZero() && inStrings(required, name) {
+			return nil, 0, fmt.Errorf("field %s can not be empty", name)
+		}
+		columns[field.column] = value.Elem().Interface()
+		if f := patched.Field(field.index); value.Elem().Type().ConvertibleTo(f.Type()) {
+			f.Set(value.Elem().Convert(f.Type()))
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, 0, errors.New("the patch has no field to update")
+	}
+	// the fields absent from the patch are zero and unchanged, the rules of the update request accept them
+	if err := binding.Validator.ValidateStruct(patched.Addr().Interface()); err != nil {
+		return nil, 0, err
+	}
+	return columns, version, nil
+}
+
+func inStrings(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// patchFields the fields of the model that are also in the request, the key is the json name
+func patchFields(table interface{}, request interface{}) map[string]patchField {
+	allowed := map[string]int{} // index of the field in the request
+	rt := reflect.Indirect(reflect.ValueOf(request)).Type()
+	for i := 0; i < rt.NumField(); i++ {
+		if f := rt.Field(i); f.Name != "ID" && f.Name != "Version" {
+			allowed[jsonName(f)] = i
+		}
+	}
+
+	fields := map[string]patchField{}
+	mt := reflect.Indirect(reflect.ValueOf(table)).Type()
+	for i := 0; i < mt.NumField(); i++ {
+		f := mt.Field(i)
+		index, ok := allowed[jsonName(f)]
+		if f.Anonymous || !ok {
+			continue
+		}
+		column := schema.ParseTagSetting(f.Tag.Get("gorm"), ";")["COLUMN"]
+		if column == "" {
+			continue
+		}
+		fields[jsonName(f)] = patchField{column: column, typ: f.Type, index: index}
+	}
+	return fields
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
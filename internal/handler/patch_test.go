@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+func Test_parseMergePatch(t *testing.T) {
+	columns, version, err := parseMergePatch([]byte(`{"avatar":null,"status":0,"name":"foo","version":3}`),
+		&model.User{}, &types.UpdateUserByIDRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version)
+	assert.Equal(t, map[string]interface{}{"avatar": "", "status": 0, "name": "foo"}, columns)
+
+	// the id of the role request is not a patchable field
+	_, _, err = parseMergePatch([]byte(`{"roleId":2}`), &model.Role{}, &types.UpdateRoleByIDRequest{})
+	assert.Error(t, err)
+	columns, version, err = parseMergePatch([]byte(`{"remark":null}`), &model.Role{}, &types.UpdateRoleByIDRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+	assert.Equal(t, map[string]interface{}{"remark": ""}, columns)
+
+	for _, data := range []string{`[]`, `{}`, `{"version":1}`, `{"age":"foo"}`, `{"deletedBy":1}`, `{"version":"1","age":1}`,
+		`{"name":null}`, `{"password":""}`, `{"email":"foo"}`, `{"status":9}`, `{"age":-1}`} {
+		_, _, err = parseMergePatch([]byte(data), &model.User{}, &types.UpdateUserByIDRequest{}, "name", "password")
+		assert.Error(t, err, data)
+	}
+	columns, _, err = parseMergePatch([]byte(`{"email":"foo@example.com","status":3}`),
+		&model.User{}, &types.UpdateUserByIDRequest{}, "name", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"email": "foo@example.com", "status": 3}, columns)
+
+	_, _, err = parseMergePatch([]byte(`{"dataScopeMode":"everything"}`), &model.Role{}, &types.UpdateRoleByIDRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+)
+
+// getCallerID get the id of the user who sent the request, the uid is set by middleware.Auth
+func getCallerID(c *gin.Context) (uint64, bool) {
+	id, err := utils.StrToUint64E(c.GetString("uid"))
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// hasAnyRole reports whether the user holds at least one of the role keys, directly or through inheritance
+func hasAnyRole(ctx context.Context, urDao dao.UserRoleDao, userID uint64, roleKeys []string) (bool, error) {
+	roles, err := urDao.GetInheritedRolesByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		for _, key := range roleKeys {
+			if role.RoleKey == key {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// checkAnyRole the caller must hold one of the role keys, no role key permits nobody,
+// if not, the error response is written and false is returned
+func checkAnyRole(c *gin.Context, urDao dao.UserRoleDao, roleKeys []string) bool {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return false
+	}
+	allowed, err := hasAnyRole(middleware.WrapCtx(c), urDao, uid, roleKeys)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	if !allowed {
+		logger.Warn("role required", logger.Any("id", uid), logger.Any("roles", roleKeys),
+			logger.String("method", c.Request.Method), logger.String("path", c.FullPath()), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.Forbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,495 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	"go-admin/internal/utils"
+)
+
+const (
+	portForwardProxyPrefix    = "/api/v1/portforward/"
+	defaultPortForwardMax     = 5
+	defaultPortForwardTimeout = 30 * time.Minute
+)
+
+// errPortForwardTarget the pod or port to forward to could not be resolved
+var errPortForwardTarget = errors.New("port-forward target not found")
+
+var _ PortForwardHandler = (*portForwardHandler)(nil)
+
+// PortForwardHandler defining the handler interface
+type PortForwardHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Close(c *gin.Context)
+	Tunnel(c *gin.Context)
+	Proxy(c *gin.Context)
+}
+
+type portForwardHandler struct {
+	newClient func() (kubernetes.Interface, error)
+	urDao     dao.UserRoleDao
+	forward   func(namespace string, pod string, port int, stopCh chan struct{}) (string, <-chan error, error)
+	upgrader  websocket.Upgrader
+
+	mu       sync.Mutex
+	sessions map[string]*portForwardSession
+}
+
+type portForwardSession struct {
+	types.PortForwardSession
+	uid    string
+	addr   string // local address of the forward, e.g. 127.0.0.1:38017
+	stopCh chan struct{}
+	timer  *time.Timer
+}
+
+// NewPortForwardHandler creating the handler interface
+func NewPortForwardHandler() PortForwardHandler {
+	return &portForwardHandler{
+		newClient: utils.NewKubeClient,
+		urDao:     dao.NewUserRoleDao(model.GetDB()),
+		forward:   utils.ForwardPodPort,
+		sessions:  map[string]*portForwardSession{},
+	}
+}
+
+// Create open a port-forward session to a pod or a service
+// @Summary create port-forward
+// @Description open a port-forward to a pod, or to a ready pod backing a service, the session is reachable through the http reverse-proxy path or the websocket tunnel path until it expires or is closed,
+// @Description only for roles configured in k8s.portForwardRoles and the namespaces of the data scope of the user
+// @Tags portForward
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param data body types.CreatePortForwardRequest true "target"
+// @Success 200 {object} types.CreatePortForwardRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/portforward [post]
+// @Security BearerAuth
+func (h *portForwardHandler) Create(c *gin.Context) {
+	namespace := c.Param("namespace")
+	form := &types.CreatePortForwardRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	if !checkAnyRole(c, h.urDao, config.Get().K8s.PortForwardRoles) || !checkNamespace(c, namespace) {
+		return
+	}
+
+	uid := c.GetString("uid")
+	maxSessions, timeout := portForwardLimits()
+	if len(h.listSessions(uid)) >= maxSessions {
+		response.Error(c, ecode.ErrPortForwardLimit)
+		return
+	}
+
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	fields := []logger.Field{logger.String("namespace", namespace), logger.String("kind", form.Kind),
+		logger.String("name", form.Name), logger.String("port", form.Port)}
+	ctx := middleware.WrapCtx(c)
+	var pod *corev1.Pod
+	var port int
+	service := ""
+	if form.Kind == "service" {
+		service = form.Name
+		pod, port, err = resolveServicePort(ctx, client, namespace, form.Name, form.Port)
+	} else {
+		pod, err = client.CoreV1().Pods(namespace).Get(ctx, form.Name, metav1.GetOptions{})
+		if err == nil {
+			port, err = resolvePodPort(pod, intstr.Parse(form.Port))
+		}
+	}
+	if err != nil {
+		if errors.Is(err, errPortForwardTarget) {
+			logger.Warn("resolve port-forward target error", append(fields, logger.Err(err), middleware.GCtxRequestIDField(c))...)
+			response.Error(c, ecode.ErrPortForwardTarget.WithDetails(err.Error()))
+			return
+		}
+		responseK8sError(c, "Get port-forward target", err, ecode.ErrCreatePortForward, fields...)
+		return
+	}
+
+	stopCh := make(chan struct{})
+	addr, errCh, err := h.forward(namespace, pod.Name, port, stopCh)
+	if err != nil {
+		logger.Error("forward error", append(fields, logger.Err(err), middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrCreatePortForward)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		close(stopCh)
+		logger.Error("newSessionID error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreatePortForward)
+		return
+	}
+	now := time.Now()
+	session := &portForwardSession{
+		PortForwardSession: types.PortForwardSession{
+			ID:         id,
+			Namespace:  namespace,
+			Service:    service,
+			Pod:        pod.Name,
+			Port:       port,
+			ProxyPath:  portForwardProxyPrefix + id + "/",
+			TunnelPath: "/api/v1/k8s/portforward/sessions/" + id + "/tunnel",
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(timeout),
+		},
+		uid:    uid,
+		addr:   addr,
+		stopCh: stopCh,
+	}
+	h.addSession(session, timeout, errCh)
+
+	logger.Info("create port-forward", append(fields, logger.String("id", id), logger.String("pod", pod.Name),
+		logger.Int("podPort", port), logger.String("uid", uid), middleware.GCtxRequestIDField(c))...)
+	response.Success(c, gin.H{"session": session.PortForwardSession})
+}
+
+// List of port-forward sessions of the caller
+// @Summary list of port-forwards
+// @Description list of the running port-forward sessions opened by the caller
+// @Tags portForward
+// @accept json
+// @Produce json
+// @Success 200 {object} types.ListPortForwardsRespond{}
+// @Router /api/v1/k8s/portforward/sessions [get]
+// @Security BearerAuth
+func (h *portForwardHandler) List(c *gin.Context) {
+	sessions := []types.PortForwardSession{}
+	for _, session := range h.listSessions(c.GetString("uid")) {
+		sessions = append(sessions, session.PortForwardSession)
+	}
+
+	response.Success(c, gin.H{"sessions": sessions})
+}
+
+// Close a port-forward session of the caller
+// @Summary close port-forward
+// @Description close a port-forward session, the open connections through it are closed as well
+// @Tags portForward
+// @accept json
+// @Produce json
+// @Param id path string true "session id"
+// @Success 200 {object} types.ClosePortForwardRespond{}
+// @Router /api/v1/k8s/portforward/sessions/{id} [delete]
+// @Security BearerAuth
+func (h *portForwardHandler) Close(c *gin.Context) {
+	session, ok := h.getSession(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	h.closeSession(session.ID)
+	logger.Info("close port-forward", logger.String("id", session.ID), logger.String("uid", session.uid), middleware.GCtxRequestIDField(c))
+	response.Success(c)
+}
+
+// Tunnel carry raw tcp bytes of the forwarded port in binary websocket messages
+// @Summary port-forward websocket tunnel
+// @Description upgrade to websocket, binary messages are written to the forwarded port and the bytes read from it are sent back as binary messages, browsers may pass the jwt in the token query parameter
+// @Tags portForward
+// @Param id path string true "session id"
+// @Param token query string false "jwt, used if the Authorization header is not set"
+// @Success 101
+// @Router /api/v1/k8s/portforward/sessions/{id}/tunnel [get]
+// @Security BearerAuth
+func (h *portForwardHandler) Tunnel(c *gin.Context) {
+	session, ok := h.getSession(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	conn, err := net.DialTimeout("tcp", session.addr, 10*time.Second)
+	if err != nil {
+		logger.Warn("dial forwarded port error", logger.Err(err), logger.String("id", session.ID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrPortForwardConnection)
+		return
+	}
+	defer conn.Close() //nolint
+
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warn("websocket upgrade error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		return // the upgrader has written the error response
+	}
+	defer ws.Close() //nolint
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if err := ws.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+				return
+			}
+		}
+	}()
+
+	go func() {
+		select {
+		case <-done:
+		case <-session.stopCh: // the session was closed or expired
+		}
+		_ = conn.Close()
+		_ = ws.Close()
+	}()
+
+	for {
+		_, reader, err := ws.NextReader()
+		if err != nil {
+			break
+		}
+		if _, err = io.Copy(conn, reader); err != nil {
+			break
+		}
+	}
+	_ = conn.Close()
+	<-done
+}
+
+// Proxy reverse-proxy http requests to the forwarded port, the path after the session id is passed to the pod
+// @Summary port-forward http proxy
+// @Description reverse-proxy any http request, including websocket upgrades, to the forwarded port, the Authorization header and the token query parameter are not passed to the pod,
+// @Description the responses are sandboxed by their Content-Security-Policy so that the pages of the pod can not run script on the origin of the admin
+// @Tags portForward
+// @Param session path string true "session id"
+// @Param path path string true "path passed to the pod"
+// @Param token query string false "jwt, used if the Authorization header is not set"
+// @Success 200
+// @Router /api/v1/portforward/{session}/{path} [get]
+// @Security BearerAuth
+func (h *portForwardHandler) Proxy(c *gin.Context) {
+	session, ok := h.getSession(c, c.Param("session"))
+	if !ok {
+		return
+	}
+
+	prefix := portForwardProxyPrefix + session.ID
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = session.addr
+			req.URL.Path = c.Param("path")
+			req.URL.RawPath = ""
+			req.Host = session.addr
+			req.Header.Del(middleware.HeaderAuthorizationKey) // the admin token must not reach the pod
+			req.Header.Set("X-Forwarded-Prefix", prefix)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Set("Content-Security-Policy", "sandbox")
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			logger.Warn("proxy to forwarded port error", logger.Err(err), logger.String("id", session.ID), middleware.GCtxRequestIDField(c))
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+// getSession get the session of the caller, it responds NotFound for sessions of the other users
+func (h *portForwardHandler) getSession(c *gin.Context, id string) (*portForwardSession, bool) {
+	h.mu.Lock()
+	session, ok := h.sessions[id]
+	h.mu.Unlock()
+	if !ok || session.uid != c.GetString("uid") {
+		logger.Warn("port-forward session not found", logger.String("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.NotFound)
+		return nil, false
+	}
+	return session, true
+}
+
+func (h *portForwardHandler) listSessions(uid string) []*portForwardSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions := []*portForwardSession{}
+	for _, session := range h.sessions {
+		if session.uid == uid {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	return sessions
+}
+
+// addSession track the session, it is closed when it expires or the forward to the pod stops
+func (h *portForwardHandler) addSession(session *portForwardSession, timeout time.Duration, errCh <-chan error) {
+	h.mu.Lock()
+	h.sessions[session.ID] = session
+	session.timer = time.AfterFunc(timeout, func() {
+		logger.Info("port-forward expired", logger.String("id", session.ID), logger.String("uid", session.uid))
+		h.closeSession(session.ID)
+	})
+	h.mu.Unlock()
+
+	go func() {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				logger.Warn("port-forward stopped", logger.Err(err), logger.String("id", session.ID), logger.String("uid", session.uid))
+			}
+			h.closeSession(session.ID)
+		case <-session.stopCh:
+		}
+	}()
+}
+
+func (h *portForwardHandler) closeSession(id string) {
+	h.mu.Lock()
+	session, ok := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	session.timer.Stop()
+	close(session.stopCh)
+}
+
+func portForwardLimits() (int, time.Duration) {
+	maxSessions, timeout := defaultPortForwardMax, defaultPortForwardTimeout
+	cfg := config.Get().K8s
+	if cfg.MaxPortForwards > 0 {
+		maxSessions = cfg.MaxPortForwards
+	}
+	if cfg.PortForwardTimeout > 0 {
+		timeout = time.Duration(cfg.PortForwardTimeout) * time.Second
+	}
+	return maxSessions, timeout
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// resolveServicePort choose a ready pod backing the service and the pod port behind the service port
+func resolveServicePort(ctx context.Context, client kubernetes.Interface, namespace string, name string, port string) (*corev1.Pod, int, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, 0, fmt.Errorf("%w: service %s has no selector", errPortForwardTarget, name)
+	}
+
+	var svcPort *corev1.ServicePort
+	for i, p := range svc.Spec.Ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			svcPort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if svcPort == nil {
+		return nil, 0, fmt.Errorf("%w: service %s has no port %s", errPortForwardTarget, name, port)
+	}
+	targetPort := svcPort.TargetPort
+	if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+		targetPort = intstr.FromInt32(svcPort.Port)
+	}
+
+	list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if !isPodReady(pod) {
+			continue
+		}
+		podPort, err := resolvePodPort(pod, targetPort)
+		if err != nil {
+			continue
+		}
+		return pod, podPort, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w: service %s has no ready pod with port %s", errPortForwardTarget, name, targetPort.String())
+}
+
+// resolvePodPort resolve a named port by the container ports of pod
+func resolvePodPort(pod *corev1.Pod, port intstr.IntOrString) (int, error) {
+	if port.Type == intstr.Int {
+		if port.IntVal <= 0 || port.IntVal > 65535 {
+			return 0, fmt.Errorf("%w: invalid port %d", errPortForwardTarget, port.IntVal)
+		}
+		return int(port.IntVal), nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == port.StrVal {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: pod %s has no port named %s", errPortForwardTarget, pod.Name, port.StrVal)
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go-admin/configs"
+	"go-admin/internal/config"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+func newPortForwardHandler(t *testing.T) (*gin.Engine, *portForwardHandler, *httptest.Server) {
+	err := config.Init(configs.Path("admin.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ready := corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+	container := corev1.Container{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+			Status:     ready,
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("http")}},
+			},
+		},
+	)
+
+	// the pod port is served by a local http server instead of a SPDY forward
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path+"|"+r.Header.Get("Authorization"))
+	}))
+	h := &portForwardHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		urDao: &mockUserRoleDao{roles: map[uint64][]*model.Role{
+			1: {{RoleKey: "admin"}},
+			2: {{RoleKey: "viewer"}},
+			3: {{RoleKey: "admin"}},
+		}},
+		forward: func(namespace string, pod string, port int, stopCh chan struct{}) (string, <-chan error, error) {
+			return strings.TrimPrefix(backend.URL, "http://"), make(chan error), nil
+		},
+		sessions: map[string]*portForwardSession{},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/", func(c *gin.Context) {
+		c.Set("uid", c.GetHeader("X-Uid")) // instead of middleware.Auth
+	}, restrictScope("3", "prod"))
+	group.POST("/api/v1/k8s/namespaces/:namespace/portforward", h.Create)
+	group.GET("/api/v1/k8s/portforward/sessions", h.List)
+	group.DELETE("/api/v1/k8s/portforward/sessions/:id", h.Close)
+	group.GET("/api/v1/k8s/portforward/sessions/:id/tunnel", h.Tunnel)
+	group.Any("/api/v1/portforward/:session/*path", h.Proxy)
+	return r, h, backend
+}
+
+func createPortForward(t *testing.T, r *gin.Engine, req *types.CreatePortForwardRequest) types.PortForwardSession {
+	w := doJSONRequest(r, http.MethodPost, "1", "/api/v1/k8s/namespaces/default/portforward", req)
+	result := &struct {
+		Code int `json:"code"`
+		Data struct {
+			Session types.PortForwardSession `json:"session"`
+		} `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 0, result.Code)
+	return result.Data.Session
+}
+
+func Test_portForwardHandler_Create(t *testing.T) {
+	r, _, backend := newPortForwardHandler(t)
+	defer backend.Close()
+
+	// the service is resolved to the ready pod and the named target port
+	session := createPortForward(t, r, &types.CreatePortForwardRequest{Kind: "service", Name: "web", Port: "80"})
+	assert.Equal(t, "web-1", session.Pod)
+	assert.Equal(t, 8080, session.Port)
+	assert.Equal(t, "/api/v1/portforward/"+session.ID+"/", session.ProxyPath)
+
+	session = createPortForward(t, r, &types.CreatePortForwardRequest{Kind: "pod", Name: "web-0", Port: "http"})
+	assert.Equal(t, 8080, session.Port)
+
+	w := doJSONRequest(r, http.MethodPost, "1", "/api/v1/k8s/namespaces/default/portforward",
+		&types.CreatePortForwardRequest{Kind: "pod", Name: "web-0", Port: "grpc"})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+
+	w = doJSONRequest(r, http.MethodGet, "1", "/api/v1/k8s/portforward/sessions", nil)
+	assert.Equal(t, 2, strings.Count(w.Body.String(), `"pod":"web-`))
+	w = doJSONRequest(r, http.MethodGet, "2", "/api/v1/k8s/portforward/sessions", nil)
+	assert.Contains(t, w.Body.String(), `"sessions":[]`)
+
+	// only the port-forward roles open a session, in the namespaces of their data scope
+	for _, uid := range []string{"2", "3", ""} {
+		w = doJSONRequest(r, http.MethodPost, uid, "/api/v1/k8s/namespaces/default/portforward",
+			&types.CreatePortForwardRequest{Kind: "pod", Name: "web-1", Port: "8080"})
+		assert.NotContains(t, w.Body.String(), `"code":0`, uid)
+	}
+}
+
+func Test_portForwardHandler_Proxy(t *testing.T) {
+	r, h, backend := newPortForwardHandler(t)
+	defer backend.Close()
+	session := createPortForward(t, r, &types.CreatePortForwardRequest{Kind: "pod", Name: "web-1", Port: "8080"})
+
+	// a real server is required, the reverse proxy needs a CloseNotifier
+	server := httptest.NewServer(r)
+	defer server.Close()
+	get := func(uid string) string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+session.ProxyPath+"debug/vars", nil)
+		req.Header.Set("X-Uid", uid)
+		req.Header.Set("Authorization", "Bearer xxx")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(body), "|") { // served by the pod
+			assert.Equal(t, "sandbox", resp.Header.Get("Content-Security-Policy"))
+		}
+		return string(body)
+	}
+
+	assert.Equal(t, "/debug/vars|", get("1")) // the token is not passed on
+	// sessions of the other users are not reachable
+	assert.NotContains(t, get("2"), "|")
+
+	stopCh := h.sessions[session.ID].stopCh
+	w := doJSONRequest(r, http.MethodDelete, "1", "/api/v1/k8s/portforward/sessions/"+session.ID, nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	select {
+	case <-stopCh:
+	case <-time.After(time.Second):
+		t.Error("forward was not stopped")
+	}
+	assert.NotContains(t, get("1"), "|")
+}
+
+func Test_portForwardHandler_Tunnel(t *testing.T) {
+	r, _, backend := newPortForwardHandler(t)
+	defer backend.Close()
+	session := createPortForward(t, r, &types.CreatePortForwardRequest{Kind: "pod", Name: "web-1", Port: "8080"})
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+session.TunnelPath,
+		http.Header{"X-Uid": []string{"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	err = ws.WriteMessage(websocket.BinaryMessage, []byte("GET /tunnel HTTP/1.0\r\n\r\n"))
+	assert.NoError(t, err)
+	data := ""
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		data += string(msg)
+	}
+	assert.Contains(t, data, "200 OK")
+	assert.Contains(t, data, "/tunnel|")
+}
@@ -14,6 +14,8 @@ import (
 	"k8s.io/klog/v2"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/proxy"
 	"k8s.io/client-go/rest"
@@ -65,6 +67,12 @@ func (p *proxyHandler) Proxy(c *gin.Context) {
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
+	if isSecretPath(target.Path) {
+		// the secrets are only served by the secret routes, which mask their values and audit the reveals
+		logger.Warn("proxy to secrets is forbidden", logger.String("path", target.Path), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.Forbidden)
+		return
+	}
 
 	httpProxy := proxy.NewUpgradeAwareHandler(target, transport, false, false, nil)
 	httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
@@ -113,10 +121,20 @@ func parseTarget(target url.URL, host string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
-	// TODO: 检查 URL 是否规范
-	target.Path = target.Path[len("/api/v1/proxy"+"/"):]
+	target.Path = path.Clean("/" + strings.TrimPrefix(target.Path, "/api/v1/proxy/"))
+	target.RawPath = "" // the escaped path could differ from the checked one
 	target.Host = kubeURL.Host
 	target.Scheme = kubeURL.Scheme
 	logrus.Infoln(target.Path, target.Host, target.Scheme)
 	return &target, nil
 }
+
+// isSecretPath whether a segment of the path is the secrets resource, in any namespace or api group
+func isSecretPath(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if strings.EqualFold(segment, "secrets") {
+			return true
+		}
+	}
+	return false
+}
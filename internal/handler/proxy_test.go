@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseTarget(t *testing.T) {
+	u, err := url.Parse("/api/v1/proxy/api/v1/namespaces/default/%73ecrets/../secrets?watch=true")
+	assert.NoError(t, err)
+	target, err := parseTarget(*u, "https://10.0.0.1:6443")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1:6443/api/v1/namespaces/default/secrets?watch=true", target.String())
+}
+
+func Test_isSecretPath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"/api/v1/secrets":                          true,
+		"/api/v1/namespaces/default/secrets":       true,
+		"/api/v1/namespaces/default/secrets/token": true,
+		"/api/v1/namespaces/default/SECRETS":       true,
+		"/api/v1/namespaces/default/configmaps":    false,
+		"/apis/apps/v1/namespaces/secret/pods":     false,
+	} {
+		assert.Equal(t, want, isSecretPath(p), p)
+	}
+}
@@ -3,10 +3,13 @@ package handler
 import (
 	"errors"
 	"math"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
 
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
 	"github.com/zhufuyi/sponge/pkg/gin/middleware"
 	"github.com/zhufuyi/sponge/pkg/gin/response"
 	"github.com/zhufuyi/sponge/pkg/logger"
@@ -27,20 +30,28 @@ type RoleHandler interface {
 	DeleteByID(c *gin.Context)
 	DeleteByIDs(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	GetByCondition(c *gin.Context)
 	ListByIDs(c *gin.Context)
 	ListByLastID(c *gin.Context)
 	List(c *gin.Context)
+	ListTrash(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
 }
 
 type roleHandler struct {
+	db   *gorm.DB
 	iDao dao.RoleDao
 }
 
 // NewRoleHandler creating the handler interface
 func NewRoleHandler() RoleHandler {
 	return &roleHandler{
+		db: model.GetDB(),
 		iDao: dao.NewRoleDao(
 			model.GetDB(),
 			cache.NewRoleCache(model.GetCacheType()),
@@ -102,8 +113,13 @@ func (h *roleHandler) DeleteByID(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
+	err := h.iDao.DeleteByID(ctx, id, deletedBy)
 	if err != nil {
 		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -132,8 +148,13 @@ func (h *roleHandler) DeleteByIDs(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err = h.iDao.DeleteByIDs(ctx, form.IDs)
+	err = h.iDao.DeleteByIDs(ctx, form.IDs, deletedBy)
 	if err != nil {
 		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -151,7 +172,9 @@ func (h *roleHandler) DeleteByIDs(c *gin.Context) {
 // @Produce json
 // @Param id path string true "id"
 // @Param data body types.UpdateRoleByIDRequest true "role information"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
 // @Success 200 {object} types.UpdateRoleByIDRespond{}
+// @Header 200 {string} ETag "version of the updated role"
 // @Router /api/v1/role/{id} [put]
 // @Security BearerAuth
 func (h *roleHandler) UpdateByID(c *gin.Context) {
@@ -169,6 +192,20 @@ func (h *roleHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		form.Version = ifMatch
+	}
+	if form.Version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
 
 	role := &model.Role{}
 	err = copier.Copy(role, form)
@@ -180,11 +217,88 @@ func (h *roleHandler) UpdateByID(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, role)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("UpdateByID version conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRoleVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
+	setETag(c, form.Version+1)
+	response.Success(c)
+}
+
+// PatchByID partially update a record by id
+// @Summary patch role
+// @Description update the fields present in the JSON merge patch (RFC 7386) of the role, the absent fields are unchanged and null clears a field,
+// @Description the roleName and the roleKey can not be cleared, the values are validated by the rules of the update.
+// @Tags role
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateRoleByIDRequest true "the fields to change, the version is required unless the If-Match header is set"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
+// @Success 200 {object} types.UpdateRoleByIDRespond{}
+// @Header 200 {string} ETag "version of the updated role"
+// @Router /api/v1/role/{id} [patch]
+// @Security BearerAuth
+func (h *roleHandler) PatchByID(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		logger.Warn("GetRawData error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	columns, version, err := parseMergePatch(data, &model.Role{}, &types.UpdateRoleByIDRequest{}, "roleName", "roleKey")
+	if err != nil {
+		logger.Warn("parseMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		version = ifMatch
+	}
+	if version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.PatchByID(ctx, id, version, columns)
+	if err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("PatchByID version conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRoleVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("PatchByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("id", id), logger.Any("columns", columns), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, version+1)
 	response.Success(c)
 }
 
@@ -195,7 +309,9 @@ func (h *roleHandler) UpdateByID(c *gin.Context) {
 // @Param id path string true "id"
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag returned by a previous get, the status is 304 if the role has not been modified"
 // @Success 200 {object} types.GetRoleByIDRespond{}
+// @Header 200 {string} ETag "version of the role, send it in the If-Match header to update the role"
 // @Router /api/v1/role/{id} [get]
 // @Security BearerAuth
 func (h *roleHandler) GetByID(c *gin.Context) {
@@ -217,6 +333,9 @@ func (h *roleHandler) GetByID(c *gin.Context) {
 		}
 		return
 	}
+	if isNotModified(c, role.Version) {
+		return
+	}
 
 	data := &types.RoleObjDetail{}
 	err = copier.Copy(data, role)
@@ -226,6 +345,7 @@ func (h *roleHandler) GetByID(c *gin.Context) {
 	}
 	data.ID = idStr
 
+	setETag(c, role.Version)
 	response.Success(c, gin.H{"role": data})
 }
 
@@ -403,6 +523,226 @@ func (h *roleHandler) List(c *gin.Context) {
 	})
 }
 
+// ListTrash list of the soft deleted records by query parameters
+// @Summary list of deleted roles
+// @Description list of the roles in the trash by paging and conditions, they can be restored or purged
+// @Tags role
+// @accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListTrashRolesRespond{}
+// @Router /api/v1/role/trash/list [post]
+// @Security BearerAuth
+func (h *roleHandler) ListTrash(c *gin.Context) {
+	form := &types.ListTrashRolesRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	roles, total, err := h.iDao.GetTrash(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetTrash error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertRoles(roles)
+	if err != nil {
+		response.Error(c, ecode.ErrListTrashRole)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"roles": data,
+		"total": total,
+	})
+}
+
+// Restore a soft deleted record by id
+// @Summary restore role
+// @Description restore the deleted role by id, it fails if another role with the same role key exists
+// @Tags role
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreRoleRespond{}
+// @Router /api/v1/role/trash/{id}/restore [post]
+// @Security BearerAuth
+func (h *roleHandler) Restore(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Restore not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else if errors.Is(err, model.ErrRecordConflict) {
+			logger.Warn("Restore conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRoleConflict)
+		} else {
+			logger.Error("Restore error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRestoreRole)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Purge permanently delete a soft deleted record by id
+// @Summary purge role
+// @Description permanently delete the role in the trash by id, the roles that are not deleted can not be purged
+// @Tags role
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.PurgeRoleRespond{}
+// @Router /api/v1/role/trash/{id} [delete]
+// @Security BearerAuth
+func (h *roleHandler) Purge(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Purge(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Purge not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("Purge error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrPurgeRole)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Import create or update records from a file
+// @Summary import roles
+// @Description create or update roles from a csv, json or yaml file in one transaction, the roles are matched by role key.
+// @Description every row is validated and reported, nothing is saved if a row is invalid or dryRun is true.
+// @Tags role
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Content-Type"
+// @Param dryRun query bool false "validate the rows without saving them"
+// @Param data body []types.ImportRoleRow true "roles"
+// @Success 200 {object} types.ImportRespond{}
+// @Router /api/v1/role/import [post]
+// @Security BearerAuth
+func (h *roleHandler) Import(c *gin.Context) {
+	form := []*types.ImportRoleRow{}
+	err := decodeImport(c, &form)
+	if err != nil {
+		logger.Warn("decodeImport error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if len(form) == 0 {
+		response.Error(c, ecode.InvalidParams.WithDetails("no rows to import"))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	rows := make([]importRow, 0, len(form))
+	for _, row := range form {
+		rows = append(rows, importRow{key: row.RoleKey, value: row})
+	}
+
+	ctx := middleware.WrapCtx(c)
+	result, err := runImport(h.db.WithContext(ctx), rows, dryRun, func(tx *gorm.DB, value interface{}) (string, error) {
+		row := value.(*types.ImportRoleRow)
+		role := &model.Role{}
+		if err := copier.Copy(role, row); err != nil {
+			return "", err
+		}
+
+		record, err := h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "role_key", Value: row.RoleKey}}})
+		if errors.Is(err, model.ErrRecordNotFound) {
+			_, err = h.iDao.CreateByTx(ctx, tx, role)
+			return importCreated, err
+		}
+		if err != nil {
+			return "", err
+		}
+		role.ID = record.ID // the version is not checked, the import overwrites the non-empty fields
+		return importUpdated, h.iDao.UpdateByTx(ctx, tx, role)
+	})
+	if err != nil {
+		logger.Error("Import error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrImportRole)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Export write the records matching the query conditions to a file
+// @Summary export roles
+// @Description export the roles matching the query conditions to a csv, json or yaml file, the file can be imported again.
+// @Tags role
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Accept header"
+// @Param data body types.ExportRolesRequest true "query conditions"
+// @Success 200 {array} types.ImportRoleRow
+// @Router /api/v1/role/export [post]
+// @Security BearerAuth
+func (h *roleHandler) Export(c *gin.Context) {
+	format, err := getExportFormat(c)
+	if err != nil {
+		logger.Warn("getExportFormat error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	form := &types.ExportRolesRequest{}
+	err = c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = exportRecords(c, format, "roles", &types.ImportRoleRow{}, &query.Params{Sort: form.Sort, Columns: form.Columns}, func(params *query.Params) ([]interface{}, error) {
+		records, _, err := h.iDao.GetByColumns(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]interface{}, 0, len(records))
+		for _, record := range records {
+			row := &types.ImportRoleRow{}
+			if err = copier.Copy(row, record); err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	})
+	if err != nil {
+		logger.Error("Export error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() {
+			response.Error(c, ecode.ErrExportRole)
+		}
+		return
+	}
+}
+
 func getRoleIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -421,6 +761,9 @@ func convertRole(role *model.Role) (*types.RoleObjDetail, error) {
 		return nil, err
 	}
 	data.ID = utils.Uint64ToStr(role.ID)
+	if role.DeletedAt.Valid {
+		data.DeletedAt = &role.DeletedAt.Time
+	}
 	return data, nil
 }
 
@@ -0,0 +1,476 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+var _ RoleElevationHandler = (*roleElevationHandler)(nil)
+
+// RoleElevationHandler defining the handler interface
+type RoleElevationHandler interface {
+	Create(c *gin.Context)
+	ListMine(c *gin.Context)
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Approve(c *gin.Context)
+	Reject(c *gin.Context)
+	Cancel(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type roleElevationHandler struct {
+	iDao       dao.RoleElevationDao
+	roleDao    dao.RoleDao
+	urDao      dao.UserRoleDao
+	elevCache  cache.RoleElevationCache
+	kubeconfig KubeconfigSyncer
+	cfg        config.Elevation
+}
+
+// NewRoleElevationHandler creating the handler interface
+func NewRoleElevationHandler() RoleElevationHandler {
+	return &roleElevationHandler{
+		iDao: dao.NewRoleElevationDao(model.GetDB()),
+		roleDao: dao.NewRoleDao(
+			model.GetDB(),
+			cache.NewRoleCache(model.GetCacheType()),
+		),
+		urDao:      dao.NewUserRoleDao(model.GetDB()),
+		elevCache:  cache.NewRoleElevationCache(model.GetCacheType()),
+		kubeconfig: NewKubeconfigSyncer(),
+		cfg:        newElevationConfig(config.Get().Elevation),
+	}
+}
+
+func newElevationConfig(cfg config.Elevation) config.Elevation {
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = 14400
+	}
+	return cfg
+}
+
+// Create request a role for a limited time
+// @Summary request role elevation
+// @Description request to hold a role for duration seconds from the approval, with a justification, a member of the approver roles approves or rejects it
+// @Tags elevation
+// @accept json
+// @Produce json
+// @Param data body types.CreateElevationRequest true "elevation information"
+// @Success 200 {object} types.CreateElevationRespond{}
+// @Router /api/v1/elevation [post]
+// @Security BearerAuth
+func (h *roleElevationHandler) Create(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	if isApiTokenRequest(c) {
+		response.Error(c, ecode.ErrElevationApiToken)
+		return
+	}
+	form := &types.CreateElevationRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if form.Duration > h.cfg.MaxDuration {
+		response.Error(c, ecode.ErrElevationDuration)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	role, err := h.roleDao.GetByID(ctx, form.RoleID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrElevationRole)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", form.RoleID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if !h.requestable(role.RoleKey) {
+		response.Error(c, ecode.ErrElevationRole)
+		return
+	}
+	held, err := hasAnyRole(ctx, h.urDao, uid, []string{role.RoleKey})
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if held {
+		response.Error(c, ecode.ErrElevationHeld)
+		return
+	}
+
+	elevation := &model.RoleElevation{
+		UserID:   uid,
+		RoleID:   role.ID,
+		Reason:   form.Reason,
+		Duration: form.Duration,
+	}
+	err = h.iDao.Create(ctx, elevation, time.Now().Unix())
+	if err != nil {
+		if errors.Is(err, model.ErrRecordConflict) {
+			response.Error(c, ecode.ErrElevationExists)
+		} else {
+			logger.Error("Create error", logger.Err(err), logger.Any("elevation", elevation), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrCreateElevation)
+		}
+		return
+	}
+
+	logger.Info("role elevation requested", logger.Uint64("id", elevation.ID), logger.Uint64("userID", uid),
+		logger.String("roleKey", role.RoleKey), logger.Int("duration", form.Duration), middleware.GCtxRequestIDField(c))
+	response.Success(c, gin.H{"elevation": convertElevation(elevation, time.Now().Unix())})
+}
+
+// ListMine list the role elevations of the current user
+// @Summary list my role elevations
+// @Description list the role elevations requested by the current user, the latest first
+// @Tags elevation
+// @Produce json
+// @Success 200 {object} types.ListElevationsRespond{}
+// @Router /api/v1/elevation/me [get]
+// @Security BearerAuth
+func (h *roleElevationHandler) ListMine(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	h.list(c, uid, "")
+}
+
+// List list the role elevations of all the users
+// @Summary list role elevations
+// @Description list the role elevations of all the users for the approvers, the latest first
+// @Tags elevation
+// @Produce json
+// @Param status query string false "pending, approved, rejected, cancelled, revoked or expired"
+// @Success 200 {object} types.ListElevationsRespond{}
+// @Router /api/v1/elevation [get]
+// @Security BearerAuth
+func (h *roleElevationHandler) List(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	if !h.checkApprover(c, uid) {
+		return
+	}
+
+	h.list(c, 0, c.Query("status"))
+}
+
+// GetByID get a role elevation with its steps
+// @Summary get role elevation
+// @Description get a role elevation with all its steps, including the requests made while the role was held, for the requester and the approvers
+// @Tags elevation
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.GetElevationRespond{}
+// @Router /api/v1/elevation/{id} [get]
+// @Security BearerAuth
+func (h *roleElevationHandler) GetByID(c *gin.Context) {
+	uid, elevation, ok := h.getElevation(c)
+	if !ok {
+		return
+	}
+	if elevation.UserID != uid && !h.checkApprover(c, uid) {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	events, err := h.iDao.GetEvents(ctx, elevation.ID)
+	if err != nil {
+		logger.Error("GetEvents error", logger.Err(err), logger.Any("id", elevation.ID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data := convertElevation(elevation, time.Now().Unix())
+	for _, event := range events {
+		data.Events = append(data.Events, &types.ElevationEventDetail{
+			CreatedAt: event.CreatedAt,
+			ActorID:   event.ActorID,
+			Action:    event.Action,
+			Detail:    event.Detail,
+		})
+	}
+	response.Success(c, gin.H{"elevation": data})
+}
+
+// Approve approve a pending role elevation
+// @Summary approve role elevation
+// @Description approve a pending role elevation of another user, the role is held for its duration from now
+// @Tags elevation
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.ReviewElevationRequest true "review comment"
+// @Success 200 {object} types.GetElevationRespond{}
+// @Router /api/v1/elevation/{id}/approve [post]
+// @Security BearerAuth
+func (h *roleElevationHandler) Approve(c *gin.Context) {
+	h.review(c, model.ElevationActionApprove)
+}
+
+// Reject reject a pending role elevation
+// @Summary reject role elevation
+// @Description reject a pending role elevation of another user
+// @Tags elevation
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.ReviewElevationRequest true "review comment"
+// @Success 200 {object} types.GetElevationRespond{}
+// @Router /api/v1/elevation/{id}/reject [post]
+// @Security BearerAuth
+func (h *roleElevationHandler) Reject(c *gin.Context) {
+	h.review(c, model.ElevationActionReject)
+}
+
+// Revoke end an active role elevation before its expiry
+// @Summary revoke role elevation
+// @Description end an active role elevation before its expiry, by an approver or by the requester giving the role up, the bindings
+// @Description of the role are removed from the kubeconfig of the requester
+// @Tags elevation
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.ReviewElevationRequest true "comment"
+// @Success 200 {object} types.GetElevationRespond{}
+// @Router /api/v1/elevation/{id}/revoke [post]
+// @Security BearerAuth
+func (h *roleElevationHandler) Revoke(c *gin.Context) {
+	h.review(c, model.ElevationActionRevoke)
+}
+
+// Cancel withdraw a pending role elevation
+// @Summary cancel role elevation
+// @Description withdraw a pending role elevation of the current user
+// @Tags elevation
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.GetElevationRespond{}
+// @Router /api/v1/elevation/{id}/cancel [post]
+// @Security BearerAuth
+func (h *roleElevationHandler) Cancel(c *gin.Context) {
+	uid, elevation, ok := h.getElevation(c)
+	if !ok {
+		return
+	}
+	if elevation.UserID != uid {
+		response.Error(c, ecode.ErrElevationNotFound)
+		return
+	}
+
+	id := elevation.ID
+	elevation, err := h.iDao.Cancel(middleware.WrapCtx(c), id, uid)
+	if err != nil {
+		h.responseStepError(c, err, id)
+		return
+	}
+
+	logger.Info("role elevation cancelled", logger.Uint64("id", id), logger.Uint64("userID", uid), middleware.GCtxRequestIDField(c))
+	response.Success(c, gin.H{"elevation": convertElevation(elevation, time.Now().Unix())})
+}
+
+// review approve, reject or revoke a role elevation, only an approver reviews the requests of the other users,
+// the requester can revoke its own grant
+func (h *roleElevationHandler) review(c *gin.Context, action string) {
+	uid, elevation, ok := h.getElevation(c)
+	if !ok {
+		return
+	}
+	if isApiTokenRequest(c) {
+		response.Error(c, ecode.ErrElevationApiToken)
+		return
+	}
+	form := &types.ReviewElevationRequest{}
+	if c.Request.ContentLength > 0 {
+		err := c.ShouldBindJSON(form)
+		if err != nil {
+			logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InvalidParams)
+			return
+		}
+	}
+
+	self := elevation.UserID == uid
+	if self && action != model.ElevationActionRevoke {
+		response.Error(c, ecode.ErrElevationSelfReview)
+		return
+	}
+	if !self && !h.checkApprover(c, uid) {
+		return
+	}
+
+	id := elevation.ID
+	ctx := middleware.WrapCtx(c)
+	now := time.Now().Unix()
+	var err error
+	switch action {
+	case model.ElevationActionApprove:
+		elevation, err = h.iDao.Approve(ctx, id, uid, form.Comment, now)
+	case model.ElevationActionReject:
+		elevation, err = h.iDao.Reject(ctx, id, uid, form.Comment, now)
+	default:
+		elevation, err = h.iDao.Revoke(ctx, id, uid, form.Comment, now)
+	}
+	if err != nil {
+		h.responseStepError(c, err, id)
+		return
+	}
+
+	if action != model.ElevationActionReject {
+		h.grantChanged(c, elevation.UserID, action == model.ElevationActionRevoke)
+	}
+
+	logger.Info("role elevation reviewed", logger.Uint64("id", id), logger.String("action", action), logger.Uint64("actorID", uid),
+		logger.Uint64("userID", elevation.UserID), logger.Uint64("roleID", elevation.RoleID), logger.Int64("expiresAt", elevation.ExpiresAt),
+		middleware.GCtxRequestIDField(c))
+	response.Success(c, gin.H{"elevation": convertElevation(elevation, now)})
+}
+
+// grantChanged forget the cached grants of the user, the bindings of the kubeconfig of the user are re-synced
+// when a grant ends so that the cluster roles of the role are no longer bound
+func (h *roleElevationHandler) grantChanged(c *gin.Context, userID uint64, ended bool) {
+	ctx := middleware.WrapCtx(c)
+	if err := h.elevCache.Del(ctx, userID); err != nil {
+		logger.Warn("Del role elevations error", logger.Err(err), logger.Uint64("userID", userID), middleware.GCtxRequestIDField(c))
+	}
+	if !ended {
+		return
+	}
+	if err := h.kubeconfig.Sync(ctx, userID); err != nil {
+		logger.Warn("Sync kubeconfig error", logger.Err(err), logger.Uint64("userID", userID), middleware.GCtxRequestIDField(c))
+	}
+}
+
+func (h *roleElevationHandler) list(c *gin.Context, userID uint64, status string) {
+	elevations, err := h.iDao.List(middleware.WrapCtx(c), userID, status)
+	if err != nil {
+		logger.Error("List error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	now := time.Now().Unix()
+	data := make([]*types.ElevationObjDetail, 0, len(elevations))
+	for _, elevation := range elevations {
+		data = append(data, convertElevation(elevation, now))
+	}
+	response.Success(c, gin.H{"elevations": data})
+}
+
+// getElevation get the caller and the role elevation of the path,
+// if it fails, the error response is written and false is returned
+func (h *roleElevationHandler) getElevation(c *gin.Context) (uint64, *model.RoleElevation, bool) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return 0, nil, false
+	}
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return 0, nil, false
+	}
+
+	elevation, err := h.iDao.GetByID(middleware.WrapCtx(c), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrElevationNotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return 0, nil, false
+	}
+	return uid, elevation, true
+}
+
+// checkApprover check that the user holds an approver role, if it does not, the error response is written
+// and false is returned
+func (h *roleElevationHandler) checkApprover(c *gin.Context, uid uint64) bool {
+	allowed, err := hasAnyRole(middleware.WrapCtx(c), h.urDao, uid, h.cfg.ApproverRoles)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	if !allowed {
+		response.Error(c, ecode.ErrElevationForbidden)
+		return false
+	}
+	return true
+}
+
+// requestable whether the role can be requested, all the roles can be requested if the roles are not configured
+func (h *roleElevationHandler) requestable(roleKey string) bool {
+	if len(h.cfg.Roles) == 0 {
+		return true
+	}
+	for _, key := range h.cfg.Roles {
+		if key == roleKey {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *roleElevationHandler) responseStepError(c *gin.Context, err error, id uint64) {
+	switch {
+	case errors.Is(err, model.ErrElevationStatus):
+		response.Error(c, ecode.ErrElevationStatus)
+	case errors.Is(err, model.ErrRecordNotFound):
+		response.Error(c, ecode.ErrElevationNotFound)
+	default:
+		logger.Error("update role elevation error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReviewElevation)
+	}
+}
+
+func convertElevation(elevation *model.RoleElevation, now int64) *types.ElevationObjDetail {
+	return &types.ElevationObjDetail{
+		ID:            utils.Uint64ToStr(elevation.ID),
+		CreatedAt:     elevation.CreatedAt,
+		UserID:        elevation.UserID,
+		RoleID:        elevation.RoleID,
+		Reason:        elevation.Reason,
+		Duration:      elevation.Duration,
+		Status:        elevation.Status,
+		ReviewerID:    elevation.ReviewerID,
+		ReviewComment: elevation.ReviewComment,
+		ReviewedAt:    elevation.ReviewedAt,
+		ExpiresAt:     elevation.ExpiresAt,
+		EndedAt:       elevation.EndedAt,
+		EndedBy:       elevation.EndedBy,
+		Active:        elevation.Active(now),
+	}
+}
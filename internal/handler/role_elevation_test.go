@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+type mockKubeconfigSyncer struct {
+	users []uint64
+}
+
+func (m *mockKubeconfigSyncer) Sync(_ context.Context, userID uint64) error {
+	m.users = append(m.users, userID)
+	return nil
+}
+
+// newRoleElevationRouter the users foo (id 1) of the role staff (id 1), bar (id 2) of the role admin (id 2)
+// approving the requests and baz (id 3) without roles, only the role prod-admin (id 3) can be requested
+func newRoleElevationRouter(t *testing.T) (*gin.Engine, dao.RoleElevationDao, dao.UserRoleDao, dao.RolePermissionDao, *mockKubeconfigSyncer) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	userDao := dao.NewUserDao(db, nil)
+	for _, name := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, userDao.Create(ctx, &model.User{Name: name, Status: model.UserStatusActivated}))
+	}
+	roleDao := dao.NewRoleDao(db, nil)
+	for _, key := range []string{"staff", "admin", "prod-admin"} {
+		require.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: key, RoleKey: key}))
+	}
+	urDao := dao.NewUserRoleDao(db)
+	require.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{1}))
+	require.NoError(t, urDao.SetUserRoles(ctx, 2, []uint64{2}))
+
+	iDao := dao.NewRoleElevationDao(db)
+	syncer := &mockKubeconfigSyncer{}
+	h := &roleElevationHandler{
+		iDao:       iDao,
+		roleDao:    roleDao,
+		urDao:      urDao,
+		elevCache:  cache.NewRoleElevationCache(&model.CacheType{CType: "memory"}),
+		kubeconfig: syncer,
+		cfg:        newElevationConfig(config.Elevation{ApproverRoles: []string{"admin"}, Roles: []string{"prod-admin"}, MaxDuration: 3600}),
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.POST("/elevation", h.Create)
+	r.GET("/elevation", h.List)
+	r.GET("/elevation/me", h.ListMine)
+	r.GET("/elevation/:id", h.GetByID)
+	r.POST("/elevation/:id/approve", h.Approve)
+	r.POST("/elevation/:id/reject", h.Reject)
+	r.POST("/elevation/:id/cancel", h.Cancel)
+	r.POST("/elevation/:id/revoke", h.Revoke)
+	return r, iDao, urDao, dao.NewRolePermissionDao(db), syncer
+}
+
+func getElevation(t *testing.T, r http.Handler, method string, path string, uid string, body string) *types.ElevationObjDetail {
+	w := doMeRequest(r, method, path, uid, "application/json", []byte(body))
+	reply := &struct {
+		Code int `json:"code"`
+		Data struct {
+			Elevation *types.ElevationObjDetail `json:"elevation"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	require.Equal(t, 0, reply.Code, w.Body.String())
+	return reply.Data.Elevation
+}
+
+func roleKeysOf(t *testing.T, urDao dao.UserRoleDao, uid uint64) []string {
+	roles, err := urDao.GetInheritedRolesByUserID(context.Background(), uid)
+	require.NoError(t, err)
+	keys := []string{}
+	for _, role := range roles {
+		keys = append(keys, role.RoleKey)
+	}
+	return keys
+}
+
+func Test_roleElevationHandler_Approve(t *testing.T) {
+	r, _, urDao, rpDao, syncer := newRoleElevationRouter(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		uid  string
+		body string
+		err  string
+	}{
+		{"", `{"roleId":3,"reason":"incident","duration":600}`, "Unauthorized"},
+		{"1", `{"roleId":2,"reason":"incident","duration":600}`, ecode.ErrElevationRole.Msg()}, // not requestable
+		{"1", `{"roleId":9,"reason":"incident","duration":600}`, ecode.ErrElevationRole.Msg()},
+		{"1", `{"roleId":3,"reason":"incident","duration":7200}`, ecode.ErrElevationDuration.Msg()},
+		{"1", `{"roleId":3,"reason":"","duration":600}`, ecode.InvalidParams.Msg()},
+	} {
+		w := doMeRequest(r, http.MethodPost, "/elevation", tc.uid, "application/json", []byte(tc.body))
+		assert.Contains(t, w.Body.String(), tc.err, tc.body)
+	}
+
+	elevation := getElevation(t, r, http.MethodPost, "/elevation", "1", `{"roleId":3,"reason":"incident 42","duration":600}`)
+	assert.Equal(t, model.ElevationStatusPending, elevation.Status)
+	assert.False(t, elevation.Active)
+	w := doMeRequest(r, http.MethodPost, "/elevation", "1", "application/json", []byte(`{"roleId":3,"reason":"again","duration":600}`))
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationExists.Msg())
+	assert.Equal(t, []string{"staff"}, roleKeysOf(t, urDao, 1))
+
+	// only the other approvers review a request
+	w = doMeRequest(r, http.MethodPost, "/elevation/1/approve", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationSelfReview.Msg())
+	w = doMeRequest(r, http.MethodPost, "/elevation/1/approve", "3", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationForbidden.Msg())
+	w = doMeRequest(r, http.MethodGet, "/elevation?status=pending", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationForbidden.Msg())
+	w = doMeRequest(r, http.MethodGet, "/elevation?status=pending", "2", "", nil)
+	assert.Contains(t, w.Body.String(), `"reason":"incident 42"`)
+
+	// the approval and the revocation forget the grants cached for the requests of the user
+	elevCache := cache.NewRoleElevationCache(&model.CacheType{CType: "memory"})
+	require.NoError(t, elevCache.Set(ctx, 1, []*model.RoleElevation{}, time.Minute))
+	now := time.Now().Unix()
+	elevation = getElevation(t, r, http.MethodPost, "/elevation/1/approve", "2", `{"comment":"go ahead"}`)
+	_, err := elevCache.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrRoleElevationNotFound)
+	assert.Empty(t, syncer.users)
+	assert.Equal(t, model.ElevationStatusApproved, elevation.Status)
+	assert.True(t, elevation.Active)
+	assert.Equal(t, uint64(2), elevation.ReviewerID)
+	assert.InDelta(t, now+600, elevation.ExpiresAt, 2)
+	w = doMeRequest(r, http.MethodPost, "/elevation/1/reject", "2", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationStatus.Msg())
+
+	// the role is held with the roles bound to the user
+	assert.Equal(t, []string{"staff", "prod-admin"}, roleKeysOf(t, urDao, 1))
+	set, err := rpDao.GetPermissions(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, set.Roles, 2)
+	assert.False(t, set.Roles[0].Elevated)
+	assert.True(t, set.Roles[1].Elevated)
+	w = doMeRequest(r, http.MethodPost, "/elevation", "1", "application/json", []byte(`{"roleId":3,"reason":"again","duration":600}`))
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationHeld.Msg())
+
+	// the requester gives the role up, the bindings of the role are removed from the kubeconfig
+	require.NoError(t, elevCache.Set(ctx, 1, []*model.RoleElevation{}, time.Minute))
+	elevation = getElevation(t, r, http.MethodPost, "/elevation/1/revoke", "1", "")
+	_, err = elevCache.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrRoleElevationNotFound)
+	assert.Equal(t, []uint64{1}, syncer.users)
+	assert.Equal(t, model.ElevationStatusRevoked, elevation.Status)
+	assert.Equal(t, uint64(1), elevation.EndedBy)
+	assert.Equal(t, []string{"staff"}, roleKeysOf(t, urDao, 1))
+
+	elevation = getElevation(t, r, http.MethodGet, "/elevation/1", "1", "")
+	actions := []string{}
+	for _, event := range elevation.Events {
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{"request", "approve", "revoke"}, actions)
+	assert.Equal(t, "incident 42", elevation.Events[0].Detail)
+	assert.Equal(t, "go ahead", elevation.Events[1].Detail)
+	w = doMeRequest(r, http.MethodGet, "/elevation/1", "3", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationForbidden.Msg())
+	w = doMeRequest(r, http.MethodGet, "/elevation/9", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationNotFound.Msg())
+}
+
+func Test_roleElevationHandler_RejectCancelExpire(t *testing.T) {
+	r, iDao, urDao, _, _ := newRoleElevationRouter(t)
+	ctx := context.Background()
+
+	getElevation(t, r, http.MethodPost, "/elevation", "1", `{"roleId":3,"reason":"deploy","duration":60}`)
+	elevation := getElevation(t, r, http.MethodPost, "/elevation/1/reject", "2", `{"comment":"use the pipeline"}`)
+	assert.Equal(t, model.ElevationStatusRejected, elevation.Status)
+	assert.Equal(t, []string{"staff"}, roleKeysOf(t, urDao, 1))
+
+	// only the requester cancels a pending request
+	getElevation(t, r, http.MethodPost, "/elevation", "1", `{"roleId":3,"reason":"deploy","duration":60}`)
+	w := doMeRequest(r, http.MethodPost, "/elevation/2/cancel", "2", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationNotFound.Msg())
+	elevation = getElevation(t, r, http.MethodPost, "/elevation/2/cancel", "1", "")
+	assert.Equal(t, model.ElevationStatusCancelled, elevation.Status)
+
+	// the grant past its expiry is no longer held before the reaper ends it
+	getElevation(t, r, http.MethodPost, "/elevation", "1", `{"roleId":3,"reason":"deploy","duration":60}`)
+	elevation = getElevation(t, r, http.MethodPost, "/elevation/3/approve", "2", "")
+	assert.Equal(t, []string{"staff", "prod-admin"}, roleKeysOf(t, urDao, 1))
+	expired, err := iDao.ExpireDue(ctx, elevation.ExpiresAt-1)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	expired, err = iDao.ExpireDue(ctx, elevation.ExpiresAt)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, model.ElevationStatusExpired, expired[0].Status)
+	assert.Equal(t, elevation.ExpiresAt, expired[0].EndedAt)
+	assert.Equal(t, []string{"staff"}, roleKeysOf(t, urDao, 1))
+	w = doMeRequest(r, http.MethodPost, "/elevation/3/revoke", "2", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrElevationStatus.Msg())
+
+	w = doMeRequest(r, http.MethodGet, "/elevation/me", "1", "", nil)
+	reply := &struct {
+		Data struct {
+			Elevations []*types.ElevationObjDetail `json:"elevations"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	statuses := []string{}
+	for _, elevation := range reply.Data.Elevations {
+		statuses = append(statuses, elevation.Status)
+	}
+	assert.Equal(t, []string{"expired", "cancelled", "rejected"}, statuses)
+	w = doMeRequest(r, http.MethodGet, "/elevation/3", "2", "", nil)
+	assert.Contains(t, w.Body.String(), `"action":"expire"`)
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/permission"
+	"go-admin/internal/types"
+)
+
+var _ RolePermissionHandler = (*rolePermissionHandler)(nil)
+
+// RolePermissionHandler defining the handler interface
+type RolePermissionHandler interface {
+	GetParents(c *gin.Context)
+	SetParents(c *gin.Context)
+	GetApis(c *gin.Context)
+	SetApis(c *gin.Context)
+	GetPermissions(c *gin.Context)
+	ExplainPermission(c *gin.Context)
+	GetMyPermissions(c *gin.Context)
+}
+
+type rolePermissionHandler struct {
+	iDao    dao.RolePermissionDao
+	userDao dao.UserDao
+	roleDao dao.RoleDao
+}
+
+// NewRolePermissionHandler creating the handler interface
+func NewRolePermissionHandler() RolePermissionHandler {
+	return &rolePermissionHandler{
+		iDao: dao.NewRolePermissionDao(model.GetDB()),
+		userDao: dao.NewUserDao(
+			model.GetDB(),
+			cache.NewUserCache(model.GetCacheType()),
+		),
+		roleDao: dao.NewRoleDao(
+			model.GetDB(),
+			cache.NewRoleCache(model.GetCacheType()),
+		),
+	}
+}
+
+// GetParents get the parents of a role
+// @Summary get role parents
+// @Description get the ids of the roles the role inherits directly
+// @Tags permission
+// @Produce json
+// @Param id path string true "role id"
+// @Success 200 {object} types.GetRoleParentsRespond{}
+// @Router /api/v1/role/{id}/parents [get]
+// @Security BearerAuth
+func (h *rolePermissionHandler) GetParents(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ids, err := h.iDao.GetParentIDs(middleware.WrapCtx(c), id)
+	if err != nil {
+		logger.Error("GetParentIDs error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"parentIds": ids})
+}
+
+// SetParents replace the parents of a role
+// @Summary set role parents
+// @Description replace the roles the role inherits, the role holds the apis and the namespaces of its parents and of their parents, a role can not inherit itself
+// @Tags permission
+// @accept json
+// @Produce json
+// @Param id path string true "role id"
+// @Param data body types.SetRoleParentsRequest true "parent role id list"
+// @Success 200 {object} types.SetRoleParentsRespond{}
+// @Router /api/v1/role/{id}/parents [put]
+// @Security BearerAuth
+func (h *rolePermissionHandler) SetParents(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.SetRoleParentsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkRole(c, id) {
+		return
+	}
+	err = h.iDao.SetParents(ctx, id, uniqueIDs(form.ParentIDs))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRoleCycle):
+			response.Error(c, ecode.ErrRoleCycle)
+		case errors.Is(err, model.ErrRecordNotFound):
+			response.Error(c, ecode.ErrRoleParentIDs)
+		default:
+			logger.Error("SetParents error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrSetRoleParents)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetApis get the apis granted to a role
+// @Summary get role apis
+// @Description get the ids of the apis granted to the role directly, without the apis of its parents
+// @Tags permission
+// @Produce json
+// @Param id path string true "role id"
+// @Success 200 {object} types.GetRoleApisRespond{}
+// @Router /api/v1/role/{id}/apis [get]
+// @Security BearerAuth
+func (h *rolePermissionHandler) GetApis(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ids, err := h.iDao.GetApiIDs(middleware.WrapCtx(c), id)
+	if err != nil {
+		logger.Error("GetApiIDs error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"apiIds": ids})
+}
+
+// SetApis replace the apis granted to a role
+// @Summary set role apis
+// @Description replace the apis granted to the role, the roles inheriting the role are granted them too
+// @Tags permission
+// @accept json
+// @Produce json
+// @Param id path string true "role id"
+// @Param data body types.SetRoleApisRequest true "api id list"
+// @Success 200 {object} types.SetRoleApisRespond{}
+// @Router /api/v1/role/{id}/apis [put]
+// @Security BearerAuth
+func (h *rolePermissionHandler) SetApis(c *gin.Context) {
+	_, id, isAbort := getRoleIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.SetRoleApisRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	if !h.checkRole(c, id) {
+		return
+	}
+	err = h.iDao.SetApis(middleware.WrapCtx(c), id, uniqueIDs(form.ApiIDs))
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrRoleApiIDs)
+		} else {
+			logger.Error("SetApis error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrSetRoleApis)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetPermissions get the effective permissions of a user
+// @Summary get user permissions
+// @Description get the roles of the user with the roles they inherit, and the apis and the kubernetes namespaces they grant, each with the roles granting it
+// @Tags permission
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} types.GetPermissionsRespond{}
+// @Router /api/v1/user/{id}/permissions [get]
+// @Security BearerAuth
+func (h *rolePermissionHandler) GetPermissions(c *gin.Context) {
+	set, ok := h.getUserPermissions(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, gin.H{"permissions": convertPermissions(set)})
+}
+
+// ExplainPermission explain which roles grant a permission to a user
+// @Summary explain user permission
+// @Description get the roles granting the api of the method and the path, or the kubernetes namespace, to the user, with the chain of parents each role is held through
+// @Tags permission
+// @Produce json
+// @Param id path string true "user id"
+// @Param method query string false "http method of the api, e.g. GET"
+// @Param path query string false "route or request path of the api, e.g. /api/v1/user/:id or /api/v1/user/12"
+// @Param namespace query string false "kubernetes namespace"
+// @Success 200 {object} types.ExplainPermissionRespond{}
+// @Router /api/v1/user/{id}/permissions/explain [get]
+// @Security BearerAuth
+func (h *rolePermissionHandler) ExplainPermission(c *gin.Context) {
+	method, path, namespace := c.Query("method"), c.Query("path"), c.Query("namespace")
+	if (method == "" || path == "") == (namespace == "") {
+		response.Error(c, ecode.ErrExplainPermission)
+		return
+	}
+
+	set, ok := h.getUserPermissions(c)
+	if !ok {
+		return
+	}
+
+	var grants []*permission.RoleGrant
+	if namespace != "" {
+		grants = set.ExplainNamespace(namespace)
+	} else {
+		grants = set.ExplainApi(method, path)
+	}
+	response.Success(c, gin.H{
+		"granted": len(grants) > 0,
+		"roles":   convertGrantingRoles(grants),
+	})
+}
+
+// GetMyPermissions get the effective permissions of the current user
+// @Summary get my permissions
+// @Description get the roles of the user who sent the request with the roles they inherit, and the apis and the kubernetes namespaces they grant
+// @Tags permission
+// @Produce json
+// @Success 200 {object} types.GetPermissionsRespond{}
+// @Router /api/v1/user/me/permissions [get]
+// @Security BearerAuth
+func (h *rolePermissionHandler) GetMyPermissions(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	set, err := h.iDao.GetPermissions(middleware.WrapCtx(c), uid)
+	if err != nil {
+		logger.Error("GetPermissions error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetPermissions)
+		return
+	}
+
+	response.Success(c, gin.H{"permissions": convertPermissions(set)})
+}
+
+// checkRole check that the role exists, if it does not, the error response is written and false is returned
+func (h *rolePermissionHandler) checkRole(c *gin.Context, id uint64) bool {
+	_, err := h.roleDao.GetByID(middleware.WrapCtx(c), id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return false
+	}
+	return true
+}
+
+// getUserPermissions get the permissions of the user of the path,
+// if it fails, the error response is written and false is returned
+func (h *rolePermissionHandler) getUserPermissions(c *gin.Context) (*permission.Set, bool) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return nil, false
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err := h.userDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return nil, false
+	}
+
+	set, err := h.iDao.GetPermissions(ctx, id)
+	if err != nil {
+		logger.Error("GetPermissions error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetPermissions)
+		return nil, false
+	}
+	return set, true
+}
+
+func convertGrantingRoles(grants []*permission.RoleGrant) []*types.GrantingRole {
+	roles := make([]*types.GrantingRole, 0, len(grants))
+	for _, grant := range grants {
+		via := make([]string, 0, len(grant.Via))
+		for _, role := range grant.Via {
+			via = append(via, role.RoleKey)
+		}
+		roles = append(roles, &types.GrantingRole{
+			RoleID:    utils.Uint64ToStr(grant.Role.ID),
+			RoleKey:   grant.Role.RoleKey,
+			RoleName:  grant.Role.RoleName,
+			Inherited: grant.Inherited(),
+			Elevated:  grant.Elevated,
+			Via:       via,
+		})
+	}
+	return roles
+}
+
+func convertPermissions(set *permission.Set) *types.PermissionsDetail {
+	data := &types.PermissionsDetail{
+		Roles:              convertGrantingRoles(set.Roles),
+		Apis:               make([]*types.PermissionApiDetail, 0, len(set.Apis)),
+		Namespaces:         make([]*types.PermissionNamespaceDetail, 0, len(set.Namespaces)),
+		AllNamespaces:      len(set.AllNamespacesRoles) > 0,
+		AllNamespacesRoles: convertGrantingRoles(set.AllNamespacesRoles),
+	}
+	for _, grant := range set.Apis {
+		data.Apis = append(data.Apis, &types.PermissionApiDetail{
+			ID:     utils.Uint64ToStr(grant.Api.ID),
+			Title:  grant.Api.Title,
+			Path:   grant.Api.Path,
+			Action: grant.Api.Action,
+			Roles:  convertGrantingRoles(grant.Roles),
+		})
+	}
+	for _, grant := range set.Namespaces {
+		data.Namespaces = append(data.Namespaces, &types.PermissionNamespaceDetail{
+			Namespace: grant.Namespace,
+			Roles:     convertGrantingRoles(grant.Roles),
+		})
+	}
+	return data
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// newRolePermissionRouter the roles viewer (id 1), operator (id 2) and admin (id 3), the user foo (id 1) is an
+// operator, the apis GET /api/v1/user/:id (id 1) and POST /api/v1/user/list (id 2)
+func newRolePermissionRouter(t *testing.T) (*gin.Engine, dao.UserRoleDao) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	userDao := dao.NewUserDao(db, nil)
+	require.NoError(t, userDao.Create(ctx, &model.User{Name: "foo", Status: model.UserStatusActivated}))
+	roleDao := dao.NewRoleDao(db, nil)
+	for _, role := range []*model.Role{
+		{RoleName: "viewer", RoleKey: "viewer", DataScope: "dev"},
+		{RoleName: "operator", RoleKey: "operator", DataScope: "staging,dev"},
+		{RoleName: "admin", RoleKey: "admin", DataScope: "*"},
+	} {
+		require.NoError(t, roleDao.Create(ctx, role))
+	}
+	apiDao := dao.NewApiDao(db, nil)
+	require.NoError(t, apiDao.Create(ctx, &model.Api{Title: "get user", Path: "/api/v1/user/:id", Action: "GET"}))
+	require.NoError(t, apiDao.Create(ctx, &model.Api{Title: "list users", Path: "/api/v1/user/list", Action: "POST"}))
+	urDao := dao.NewUserRoleDao(db)
+	require.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{2}))
+
+	h := &rolePermissionHandler{iDao: dao.NewRolePermissionDao(db), userDao: userDao, roleDao: roleDao}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.GET("/user/me/permissions", h.GetMyPermissions)
+	r.GET("/role/:id/parents", h.GetParents)
+	r.PUT("/role/:id/parents", h.SetParents)
+	r.GET("/role/:id/apis", h.GetApis)
+	r.PUT("/role/:id/apis", h.SetApis)
+	r.GET("/user/:id/permissions", h.GetPermissions)
+	r.GET("/user/:id/permissions/explain", h.ExplainPermission)
+	return r, urDao
+}
+
+func getPermissions(t *testing.T, r http.Handler) *types.PermissionsDetail {
+	w := doMeRequest(r, http.MethodGet, "/user/me/permissions", "1", "", nil)
+	reply := &struct {
+		Data struct {
+			Permissions *types.PermissionsDetail `json:"permissions"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	require.NotNil(t, reply.Data.Permissions, w.Body.String())
+	return reply.Data.Permissions
+}
+
+func explainPermission(t *testing.T, r http.Handler, query string) []string {
+	w := doMeRequest(r, http.MethodGet, "/user/1/permissions/explain?"+query, "", "", nil)
+	reply := &struct {
+		Data struct {
+			Granted bool                  `json:"granted"`
+			Roles   []*types.GrantingRole `json:"roles"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	chains := []string{}
+	for _, role := range reply.Data.Roles {
+		chain := ""
+		for i, key := range role.Via {
+			if i > 0 {
+				chain += ">"
+			}
+			chain += key
+		}
+		chains = append(chains, chain)
+	}
+	assert.Equal(t, len(chains) > 0, reply.Data.Granted)
+	return chains
+}
+
+func Test_rolePermissionHandler_Parents(t *testing.T) {
+	r, _ := newRolePermissionRouter(t)
+	for _, tc := range []struct {
+		path string
+		body string
+	}{
+		{"/role/2/parents", `{"parentIds":[1]}`},
+		{"/role/3/parents", `{"parentIds":[2,2]}`},
+		{"/role/1/apis", `{"apiIds":[1]}`},
+		{"/role/2/apis", `{"apiIds":[2]}`},
+	} {
+		w := doMeRequest(r, http.MethodPut, tc.path, "", "application/json", []byte(tc.body))
+		require.Contains(t, w.Body.String(), `"code":0`, tc.path)
+	}
+	w := doMeRequest(r, http.MethodGet, "/role/3/parents", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"parentIds":[2]`)
+	w = doMeRequest(r, http.MethodGet, "/role/1/apis", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"apiIds":[1]`)
+
+	for _, tc := range []struct {
+		path string
+		body string
+		err  string
+	}{
+		{"/role/1/parents", `{"parentIds":[1]}`, ecode.ErrRoleCycle.Msg()},
+		{"/role/1/parents", `{"parentIds":[3]}`, ecode.ErrRoleCycle.Msg()}, // admin inherits viewer through operator
+		{"/role/1/parents", `{"parentIds":[9]}`, ecode.ErrRoleParentIDs.Msg()},
+		{"/role/9/parents", `{"parentIds":[1]}`, ecode.NotFound.Msg()},
+		{"/role/1/apis", `{"apiIds":[9]}`, ecode.ErrRoleApiIDs.Msg()},
+		{"/role/x/apis", `{"apiIds":[1]}`, ecode.InvalidParams.Msg()},
+	} {
+		w = doMeRequest(r, http.MethodPut, tc.path, "", "application/json", []byte(tc.body))
+		assert.Contains(t, w.Body.String(), tc.err, tc.path+" "+tc.body)
+	}
+	w = doMeRequest(r, http.MethodGet, "/role/1/parents", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"parentIds":[]`)
+}
+
+func Test_rolePermissionHandler_Permissions(t *testing.T) {
+	r, urDao := newRolePermissionRouter(t)
+	ctx := context.Background()
+	for path, body := range map[string]string{
+		"/role/2/parents": `{"parentIds":[1]}`,
+		"/role/3/parents": `{"parentIds":[2]}`,
+		"/role/1/apis":    `{"apiIds":[1]}`,
+		"/role/2/apis":    `{"apiIds":[2,1]}`,
+	} {
+		w := doMeRequest(r, http.MethodPut, path, "", "application/json", []byte(body))
+		require.Contains(t, w.Body.String(), `"code":0`, path)
+	}
+
+	w := doMeRequest(r, http.MethodGet, "/user/me/permissions", "", "", nil)
+	assert.Contains(t, w.Body.String(), "Unauthorized")
+
+	// the operator inherits the apis and the namespaces of the viewer
+	permissions := getPermissions(t, r)
+	require.Len(t, permissions.Roles, 2)
+	assert.Equal(t, []string{"operator", "viewer"}, permissions.Roles[1].Via)
+	assert.True(t, permissions.Roles[1].Inherited)
+	require.Len(t, permissions.Apis, 2)
+	assert.Equal(t, "/api/v1/user/:id", permissions.Apis[0].Path)
+	assert.Len(t, permissions.Apis[0].Roles, 2)
+	require.Len(t, permissions.Namespaces, 2)
+	assert.Equal(t, "dev", permissions.Namespaces[0].Namespace)
+	assert.Len(t, permissions.Namespaces[0].Roles, 2)
+	assert.False(t, permissions.AllNamespaces)
+
+	assert.Equal(t, []string{"operator", "operator>viewer"}, explainPermission(t, r, "method=get&path=/api/v1/user/12"))
+	assert.Equal(t, []string{"operator"}, explainPermission(t, r, "method=POST&path=/api/v1/user/list"))
+	assert.Empty(t, explainPermission(t, r, "method=DELETE&path=/api/v1/user/12"))
+	assert.Equal(t, []string{"operator", "operator>viewer"}, explainPermission(t, r, "namespace=dev"))
+	assert.Empty(t, explainPermission(t, r, "namespace=prod"))
+
+	// the admin inherits all of them and permits all the namespaces
+	require.NoError(t, urDao.SetUserRoles(ctx, 1, []uint64{3}))
+	assert.Equal(t, []string{"admin>operator", "admin>operator>viewer"}, explainPermission(t, r, "method=GET&path=/api/v1/user/:id"))
+	assert.Equal(t, []string{"admin"}, explainPermission(t, r, "namespace=prod"))
+	roles, err := urDao.GetInheritedRolesByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, roles, 3)
+
+	for _, path := range []string{
+		"/user/1/permissions/explain",
+		"/user/1/permissions/explain?method=GET",
+		"/user/1/permissions/explain?method=GET&path=/api/v1/user/1&namespace=dev",
+	} {
+		w = doMeRequest(r, http.MethodGet, path, "", "", nil)
+		assert.Contains(t, w.Body.String(), ecode.ErrExplainPermission.Msg(), path)
+	}
+	w = doMeRequest(r, http.MethodGet, "/user/9/permissions", "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.NotFound.Msg())
+	w = doMeRequest(r, http.MethodGet, "/user/1/permissions", "", "", nil)
+	assert.Contains(t, w.Body.String(), `"allNamespaces":true`)
+}
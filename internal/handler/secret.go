@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/errcode"
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	"go-admin/internal/utils"
+)
+
+// maskedValue replaces secret values that have not been revealed
+const maskedValue = "******"
+
+var _ SecretHandler = (*secretHandler)(nil)
+
+// SecretHandler defining the handler interface
+type SecretHandler interface {
+	List(c *gin.Context)
+	GetByName(c *gin.Context)
+	Reveal(c *gin.Context)
+	SetKey(c *gin.Context)
+	DeleteKey(c *gin.Context)
+}
+
+type secretHandler struct {
+	newClient func() (kubernetes.Interface, error)
+	urDao     dao.UserRoleDao
+}
+
+// NewSecretHandler creating the handler interface
+func NewSecretHandler() SecretHandler {
+	return &secretHandler{
+		newClient: utils.NewKubeClient,
+		urDao:     dao.NewUserRoleDao(model.GetDB()),
+	}
+}
+
+// List of secrets in namespace, values are masked
+// @Summary list of secrets
+// @Description list of secrets in namespace, values are masked
+// @Tags secret
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Success 200 {object} types.ListSecretsRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/secrets [get]
+// @Security BearerAuth
+func (h *secretHandler) List(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List secrets", err, ecode.ErrListSecret, logger.String("namespace", namespace))
+		return
+	}
+
+	secrets := []*types.SecretObjDetail{}
+	for i := range list.Items {
+		secrets = append(secrets, convertSecret(&list.Items[i], nil))
+	}
+
+	response.Success(c, gin.H{
+		"secrets": secrets,
+	})
+}
+
+// GetByName get a secret by name, values are masked
+// @Summary get secret detail
+// @Description get secret detail by name, values are masked, use the reveal api to get the values
+// @Tags secret
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Success 200 {object} types.GetSecretRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/secrets/{name} [get]
+// @Security BearerAuth
+func (h *secretHandler) GetByName(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Get secret", err, ecode.ErrGetSecret, logger.String("namespace", namespace), logger.String("name", name))
+		return
+	}
+
+	response.Success(c, gin.H{"secret": convertSecret(secret, nil)})
+}
+
+// Reveal get the decoded values of a secret, the caller must hold one of the roles
+// in k8s.secretRevealRoles, every attempt is written to the audit log
+// @Summary reveal secret values
+// @Description reveal the decoded values of secret keys, only for roles configured in k8s.secretRevealRoles
+// @Tags secret
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param data body types.RevealSecretRequest true "keys to reveal"
+// @Success 200 {object} types.GetSecretRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/secrets/{name}/reveal [post]
+// @Security BearerAuth
+func (h *secretHandler) Reveal(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	form := &types.RevealSecretRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	auditFields := []logger.Field{
+		logger.String("audit", "secret.reveal"),
+		logger.String("uid", c.GetString("uid")),
+		logger.String("userName", c.GetString("name")),
+		logger.String("namespace", namespace),
+		logger.String("name", name),
+		logger.Any("keys", form.Keys),
+		logger.String("reason", form.Reason),
+		logger.String("ip", c.ClientIP()),
+		middleware.GCtxRequestIDField(c),
+	}
+
+	uid, ok := getCallerID(c)
+	if !ok {
+		logger.Warn("reveal secret denied, unknown caller", auditFields...)
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+	allowed, err := hasAnyRole(ctx, h.urDao, uid, config.Get().K8s.SecretRevealRoles)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", append(auditFields, logger.Err(err))...)
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if !allowed {
+		logger.Warn("reveal secret denied", auditFields...)
+		response.Error(c, ecode.Forbidden)
+		return
+	}
+	if !checkNamespace(c, namespace) {
+		return
+	}
+
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Reveal secret", err, ecode.ErrRevealSecret, auditFields...)
+		return
+	}
+
+	revealKeys := form.Keys
+	if len(revealKeys) == 0 {
+		for key := range secret.Data {
+			revealKeys = append(revealKeys, key)
+		}
+	}
+	logger.Info("reveal secret", auditFields...)
+
+	response.Success(c, gin.H{"secret": convertSecret(secret, revealKeys)})
+}
+
+// SetKey add or update a key of secret, the other keys are kept as they are
+// @Summary set secret key
+// @Description add or update a key of secret, the value is base64 encoded when stored, the other keys are kept as they are,
+// @Description only for roles configured in k8s.writeRoles
+// @Tags secret
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param key path string true "key"
+// @Param data body types.SetSecretKeyRequest true "value"
+// @Success 200 {object} types.SetSecretKeyRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/secrets/{name}/keys/{key} [put]
+// @Security BearerAuth
+func (h *secretHandler) SetKey(c *gin.Context) {
+	namespace, name, key, isAbort := getDataKeyFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+
+	form := &types.SetSecretKeyRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	value := []byte(form.Value)
+	if form.Base64 {
+		value, err = base64.StdEncoding.DecodeString(form.Value)
+		if err != nil {
+			logger.Warn("DecodeString error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrSecretValue)
+			return
+		}
+	}
+
+	// a merge patch only touches the given key, concurrent edits of other keys are not lost
+	patch, _ := json.Marshal(map[string]interface{}{
+		"data": map[string][]byte{key: value},
+	})
+	h.patch(c, namespace, name, patch, ecode.ErrSetSecretKey)
+}
+
+// DeleteKey delete a key of secret, the other keys are kept as they are
+// @Summary delete secret key
+// @Description delete a key of secret, the other keys are kept as they are, only for roles configured in k8s.writeRoles
+// @Tags secret
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param key path string true "key"
+// @Success 200 {object} types.DeleteSecretKeyRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/secrets/{name}/keys/{key} [delete]
+// @Security BearerAuth
+func (h *secretHandler) DeleteKey(c *gin.Context) {
+	namespace, name, key, isAbort := getDataKeyFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{key: nil},
+	})
+	h.patch(c, namespace, name, patch, ecode.ErrDeleteSecretKey)
+}
+
+func (h *secretHandler) patch(c *gin.Context, namespace string, name string, patch []byte, failErr *errcode.Error) {
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err := client.CoreV1().Secrets(namespace).Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		responseK8sError(c, "Patch secret", err, failErr, logger.String("namespace", namespace), logger.String("name", name))
+		return
+	}
+
+	logger.Info("patch secret", logger.String("audit", "secret.patch"), logger.String("uid", c.GetString("uid")),
+		logger.String("namespace", namespace), logger.String("name", name), logger.String("key", c.Param("key")),
+		logger.String("method", c.Request.Method), middleware.GCtxRequestIDField(c))
+	response.Success(c)
+}
+
+func getDataKeyFromPath(c *gin.Context) (string, string, string, bool) {
+	namespace, name, key := c.Param("namespace"), c.Param("name"), c.Param("key")
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		logger.Warn("IsConfigMapKey error: ", logger.String("key", key), logger.Any("errs", errs), middleware.GCtxRequestIDField(c))
+		return "", "", "", true
+	}
+
+	return namespace, name, key, false
+}
+
+// convertSecret convert secret to detail, only the values of revealKeys are returned, the others are masked
+func convertSecret(secret *corev1.Secret, revealKeys []string) *types.SecretObjDetail {
+	reveal := make(map[string]bool, len(revealKeys))
+	for _, key := range revealKeys {
+		reveal[key] = true
+	}
+
+	items := []types.SecretItem{}
+	for key, value := range secret.Data {
+		item := types.SecretItem{Key: key, Value: maskedValue, Size: len(value)}
+		if reveal[key] {
+			if utf8.Valid(value) {
+				item.Value = string(value)
+			} else {
+				item.Value = base64.StdEncoding.EncodeToString(value)
+				item.Base64 = true
+			}
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+
+	return &types.SecretObjDetail{
+		Namespace:       secret.Namespace,
+		Name:            secret.Name,
+		Type:            string(secret.Type),
+		ResourceVersion: secret.ResourceVersion,
+		CreatedAt:       secret.CreationTimestamp.Time,
+		Items:           items,
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go-admin/configs"
+	"go-admin/internal/config"
+	"go-admin/internal/datascope"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+type mockUserRoleDao struct {
+	roles map[uint64][]*model.Role
+}
+
+func (d *mockUserRoleDao) GetRolesByUserID(_ context.Context, userID uint64) ([]*model.Role, error) {
+	return d.roles[userID], nil
+}
+
+func (d *mockUserRoleDao) GetInheritedRolesByUserID(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	return d.GetRolesByUserID(ctx, userID)
+}
+
+func (d *mockUserRoleDao) SetUserRoles(_ context.Context, userID uint64, roleIDs []uint64) error {
+	var roles []*model.Role
+	for _, id := range roleIDs {
+		role := &model.Role{}
+		role.ID = id
+		roles = append(roles, role)
+	}
+	d.roles[userID] = roles
+	return nil
+}
+
+func (d *mockUserRoleDao) SyncRolesByKeysByTx(context.Context, *gorm.DB, uint64, []string, []string) error {
+	return nil
+}
+
+// restrictScope limit the data scope of the user uid to the namespaces, as the data scope middleware of the routers
+func restrictScope(uid string, namespaces ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("uid") != uid {
+			return
+		}
+		ctx := datascope.WithResolver(c.Request.Context(), func(ctx context.Context) (*datascope.Scope, error) {
+			return &datascope.Scope{Namespaces: namespaces}, nil
+		})
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+func newSecretHandler(t *testing.T) (*gin.Engine, kubernetes.Interface) {
+	err := config.Init(configs.Path("admin.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+		Data: map[string][]byte{
+			"user":     []byte("root"),
+			"password": []byte("123456"),
+			"cert":     {0xff, 0xfe},
+		},
+	})
+	h := &secretHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		urDao: &mockUserRoleDao{roles: map[uint64][]*model.Role{
+			1: {{RoleKey: "admin"}},
+			2: {{RoleKey: "viewer"}},
+			3: {{RoleKey: "admin"}},
+		}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/", func(c *gin.Context) {
+		c.Set("uid", c.GetHeader("X-Uid")) // instead of middleware.Auth
+	}, restrictScope("3", "prod"))
+	group.GET("/namespaces/:namespace/secrets", h.List)
+	group.GET("/namespaces/:namespace/secrets/:name", h.GetByName)
+	group.POST("/namespaces/:namespace/secrets/:name/reveal", h.Reveal)
+	group.PUT("/namespaces/:namespace/secrets/:name/keys/:key", h.SetKey)
+	group.DELETE("/namespaces/:namespace/secrets/:name/keys/:key", h.DeleteKey)
+	return r, client
+}
+
+func doJSONRequest(r *gin.Engine, method string, uid string, path string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, strings.NewReader(string(data)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Uid", uid)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_secretHandler_GetByName(t *testing.T) {
+	r, _ := newSecretHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "2", "/namespaces/default/secrets/db", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "123456")
+	assert.Contains(t, w.Body.String(), maskedValue)
+}
+
+func Test_secretHandler_DataScope(t *testing.T) {
+	r, _ := newSecretHandler(t)
+
+	// the namespaces out of the data scope are forbidden
+	for _, path := range []string{"/namespaces/default/secrets", "/namespaces/default/secrets/db"} {
+		w := doJSONRequest(r, http.MethodGet, "3", path, nil)
+		assert.Contains(t, w.Body.String(), `"code":10008`, path)
+	}
+	w := doJSONRequest(r, http.MethodPost, "3", "/namespaces/default/secrets/db/reveal", &types.RevealSecretRequest{})
+	assert.NotContains(t, w.Body.String(), "123456")
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	w = doJSONRequest(r, http.MethodGet, "3", "/namespaces/prod/secrets", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_secretHandler_Reveal(t *testing.T) {
+	r, _ := newSecretHandler(t)
+
+	// role not allowed
+	w := doJSONRequest(r, http.MethodPost, "2", "/namespaces/default/secrets/db/reveal", &types.RevealSecretRequest{})
+	assert.NotContains(t, w.Body.String(), "123456")
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+
+	// no caller
+	w = doJSONRequest(r, http.MethodPost, "", "/namespaces/default/secrets/db/reveal", &types.RevealSecretRequest{})
+	assert.NotContains(t, w.Body.String(), "123456")
+
+	// reveal a single key
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/secrets/db/reveal", &types.RevealSecretRequest{Keys: []string{"password"}})
+	result := &struct {
+		Code int `json:"code"`
+		Data struct {
+			Secret types.SecretObjDetail `json:"secret"`
+		} `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 0, result.Code)
+	values := map[string]types.SecretItem{}
+	for _, item := range result.Data.Secret.Items {
+		values[item.Key] = item
+	}
+	assert.Equal(t, "123456", values["password"].Value)
+	assert.Equal(t, maskedValue, values["user"].Value)
+
+	// binary values are returned in base64
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/secrets/db/reveal", &types.RevealSecretRequest{Keys: []string{"cert"}})
+	assert.Contains(t, w.Body.String(), `"value":"//4=","size":2,"base64":true`)
+}
+
+func Test_secretHandler_SetKey(t *testing.T) {
+	r, client := newSecretHandler(t)
+
+	w := doJSONRequest(r, http.MethodPut, "1", "/namespaces/default/secrets/db/keys/password", &types.SetSecretKeyRequest{Value: "abc"})
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodPut, "1", "/namespaces/default/secrets/db/keys/token", &types.SetSecretKeyRequest{Value: "eHl6", Base64: true})
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doJSONRequest(r, http.MethodDelete, "1", "/namespaces/default/secrets/db/keys/cert", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+
+	secret, err := client.CoreV1().Secrets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"user":     []byte("root"),
+		"password": []byte("abc"),
+		"token":    []byte("xyz"),
+	}, secret.Data)
+
+	// only the write roles change the secrets of the namespaces of their data scope
+	w = doJSONRequest(r, http.MethodPut, "2", "/namespaces/default/secrets/db/keys/password", &types.SetSecretKeyRequest{Value: "x"})
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	w = doJSONRequest(r, http.MethodDelete, "3", "/namespaces/default/secrets/db/keys/password", nil)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+	w = doJSONRequest(r, http.MethodDelete, "", "/namespaces/default/secrets/db/keys/password", nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+
+	// invalid base64 value
+	w = doJSONRequest(r, http.MethodPut, "1", "/namespaces/default/secrets/db/keys/token", &types.SetSecretKeyRequest{Value: "!", Base64: true})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	// invalid key
+	w = doJSONRequest(r, http.MethodPut, "1", "/namespaces/default/secrets/db/keys/a:b", &types.SetSecretKeyRequest{Value: "x"})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
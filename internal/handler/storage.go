@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	"go-admin/internal/utils"
+)
+
+// annotation that marks the default storageClass
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+var _ StorageHandler = (*storageHandler)(nil)
+
+// StorageHandler defining the handler interface
+type StorageHandler interface {
+	ListPVCs(c *gin.Context)
+	ListPVs(c *gin.Context)
+	ListStorageClasses(c *gin.Context)
+	ExpandPVC(c *gin.Context)
+}
+
+type storageHandler struct {
+	newClient func() (kubernetes.Interface, error)
+	urDao     dao.UserRoleDao
+}
+
+// NewStorageHandler creating the handler interface
+func NewStorageHandler() StorageHandler {
+	return &storageHandler{
+		newClient: utils.NewKubeClient,
+		urDao:     dao.NewUserRoleDao(model.GetDB()),
+	}
+}
+
+// ListPVCs list of persistentVolumeClaims with the bound volume and the pods using them
+// @Summary list of persistentVolumeClaims
+// @Description list of persistentVolumeClaims with bound volume, storageClass, capacity, access modes and consuming pods, Pending and Lost claims are flagged,
+// @Description only the claims in the namespaces of the data scope of the user are listed
+// @Tags storage
+// @accept json
+// @Produce json
+// @Param namespace query string false "namespace, if empty, all namespaces"
+// @Success 200 {object} types.ListPVCsRespond{}
+// @Router /api/v1/k8s/storage/pvcs [get]
+// @Security BearerAuth
+func (h *storageHandler) ListPVCs(c *gin.Context) {
+	namespace := c.Query("namespace")
+	scope, ok := getDataScope(c)
+	if !ok {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	pvcs, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List persistentVolumeClaims", err, ecode.ErrListPVCs, logger.String("namespace", namespace))
+		return
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List pods", err, ecode.ErrListPVCs, logger.String("namespace", namespace))
+		return
+	}
+
+	// namespace/claimName -> pod names
+	consumers := map[string][]string{}
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				key := pod.Namespace + "/" + volume.PersistentVolumeClaim.ClaimName
+				consumers[key] = append(consumers[key], pod.Name)
+			}
+		}
+	}
+
+	data := []*types.PVCObjDetail{}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !scope.CanSeeNamespace(pvc.Namespace) {
+			continue
+		}
+		detail := convertPVC(pvc)
+		detail.Pods = consumers[pvc.Namespace+"/"+pvc.Name]
+		if detail.Pods == nil {
+			detail.Pods = []string{}
+		}
+		data = append(data, detail)
+	}
+
+	response.Success(c, gin.H{
+		"pvcs": data,
+	})
+}
+
+// ListPVs list of persistentVolumes, Released volumes whose claim no longer exists are flagged as orphaned
+// @Summary list of persistentVolumes
+// @Description list of persistentVolumes, Released volumes are flagged as orphaned,
+// @Description the claims in the namespaces out of the data scope of the user are not shown
+// @Tags storage
+// @accept json
+// @Produce json
+// @Success 200 {object} types.ListPVsRespond{}
+// @Router /api/v1/k8s/storage/pvs [get]
+// @Security BearerAuth
+func (h *storageHandler) ListPVs(c *gin.Context) {
+	scope, ok := getDataScope(c)
+	if !ok {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	pvs, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List persistentVolumes", err, ecode.ErrListPVs)
+		return
+	}
+
+	data := []*types.PVObjDetail{}
+	for i := range pvs.Items {
+		detail := convertPV(&pvs.Items[i])
+		if ref := pvs.Items[i].Spec.ClaimRef; ref != nil && !scope.CanSeeNamespace(ref.Namespace) {
+			detail.Claim = ""
+		}
+		data = append(data, detail)
+	}
+
+	response.Success(c, gin.H{
+		"pvs": data,
+	})
+}
+
+// ListStorageClasses list of storageClasses
+// @Summary list of storageClasses
+// @Description list of storageClasses
+// @Tags storage
+// @accept json
+// @Produce json
+// @Success 200 {object} types.ListStorageClassesRespond{}
+// @Router /api/v1/k8s/storage/classes [get]
+// @Security BearerAuth
+func (h *storageHandler) ListStorageClasses(c *gin.Context) {
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	classes, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		responseK8sError(c, "List storageClasses", err, ecode.ErrListStorageClasses)
+		return
+	}
+
+	data := []*types.StorageClassObjDetail{}
+	for i := range classes.Items {
+		data = append(data, convertStorageClass(&classes.Items[i]))
+	}
+
+	response.Success(c, gin.H{
+		"storageClasses": data,
+	})
+}
+
+// ExpandPVC request a larger size for persistentVolumeClaim, the storageClass must allow volume expansion
+// @Summary expand persistentVolumeClaim
+// @Description request a larger size for persistentVolumeClaim, the storageClass must set allowVolumeExpansion,
+// @Description only for roles configured in k8s.writeRoles
+// @Tags storage
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param name path string true "name"
+// @Param data body types.ExpandPVCRequest true "new size"
+// @Success 200 {object} types.ExpandPVCRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/pvcs/{name}/expand [post]
+// @Security BearerAuth
+func (h *storageHandler) ExpandPVC(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	form := &types.ExpandPVCRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+	size, err := resource.ParseQuantity(form.Size)
+	if err != nil {
+		logger.Warn("ParseQuantity error: ", logger.Err(err), logger.String("size", form.Size), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	fields := []logger.Field{logger.String("namespace", namespace), logger.String("name", name), logger.String("size", form.Size)}
+	ctx := middleware.WrapCtx(c)
+	pvc, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Get persistentVolumeClaim", err, ecode.ErrExpandPVC, fields...)
+		return
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		logger.Warn("persistentVolumeClaim is not bound", append(fields, middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrExpandPVC.WithDetails("claim is not bound"))
+		return
+	}
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.Cmp(current) <= 0 {
+		logger.Warn("size must be larger than the current request", append(fields, middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrExpandPVC.WithDetails("size must be larger than "+current.String()))
+		return
+	}
+
+	className := ""
+	if pvc.Spec.StorageClassName != nil {
+		className = *pvc.Spec.StorageClassName
+	}
+	if className == "" {
+		response.Error(c, ecode.ErrVolumeExpansionNotAllowed.WithDetails("claim has no storageClass"))
+		return
+	}
+	class, err := client.StorageV1().StorageClasses().Get(ctx, className, metav1.GetOptions{})
+	if err != nil {
+		responseK8sError(c, "Get storageClass", err, ecode.ErrExpandPVC, append(fields, logger.String("storageClass", className))...)
+		return
+	}
+	if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+		logger.Warn("storageClass does not allow volume expansion", append(fields, logger.String("storageClass", className), middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrVolumeExpansionNotAllowed.WithDetails("storageClass "+className))
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{string(corev1.ResourceStorage): size.String()},
+			},
+		},
+	})
+	_, err = client.CoreV1().PersistentVolumeClaims(namespace).Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		responseK8sError(c, "Patch persistentVolumeClaim", err, ecode.ErrExpandPVC, fields...)
+		return
+	}
+
+	logger.Info("expand persistentVolumeClaim", append(fields, logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))...)
+	response.Success(c)
+}
+
+func convertPVC(pvc *corev1.PersistentVolumeClaim) *types.PVCObjDetail {
+	detail := &types.PVCObjDetail{
+		Namespace:   pvc.Namespace,
+		Name:        pvc.Name,
+		Status:      string(pvc.Status.Phase),
+		VolumeName:  pvc.Spec.VolumeName,
+		AccessModes: convertAccessModes(pvc.Status.AccessModes),
+		CreatedAt:   pvc.CreationTimestamp.Time,
+	}
+	if pvc.Spec.StorageClassName != nil {
+		detail.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if pvc.Spec.VolumeMode != nil {
+		detail.VolumeMode = string(*pvc.Spec.VolumeMode)
+	}
+	if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		detail.Requested = q.String()
+	}
+	if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		detail.Capacity = q.String()
+	}
+	if len(detail.AccessModes) == 0 {
+		detail.AccessModes = convertAccessModes(pvc.Spec.AccessModes)
+	}
+
+	switch pvc.Status.Phase {
+	case corev1.ClaimPending, corev1.ClaimLost:
+		detail.Problem = string(pvc.Status.Phase)
+	}
+	return detail
+}
+
+func convertPV(pv *corev1.PersistentVolume) *types.PVObjDetail {
+	detail := &types.PVObjDetail{
+		Name:          pv.Name,
+		Status:        string(pv.Status.Phase),
+		StorageClass:  pv.Spec.StorageClassName,
+		AccessModes:   convertAccessModes(pv.Spec.AccessModes),
+		ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+		CreatedAt:     pv.CreationTimestamp.Time,
+		// a Released volume keeps its data, but the claim is gone and it can not be bound again without manual cleanup
+		Orphaned: pv.Status.Phase == corev1.VolumeReleased,
+	}
+	if q, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		detail.Capacity = q.String()
+	}
+	if pv.Spec.ClaimRef != nil {
+		detail.Claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+	}
+	return detail
+}
+
+func convertStorageClass(class *storagev1.StorageClass) *types.StorageClassObjDetail {
+	detail := &types.StorageClassObjDetail{
+		Name:                 class.Name,
+		Provisioner:          class.Provisioner,
+		AllowVolumeExpansion: class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion,
+		IsDefault:            class.Annotations[defaultStorageClassAnnotation] == "true",
+		CreatedAt:            class.CreationTimestamp.Time,
+	}
+	if class.ReclaimPolicy != nil {
+		detail.ReclaimPolicy = string(*class.ReclaimPolicy)
+	}
+	if class.VolumeBindingMode != nil {
+		detail.VolumeBindingMode = string(*class.VolumeBindingMode)
+	}
+	return detail
+}
+
+func convertAccessModes(modes []corev1.PersistentVolumeAccessMode) []string {
+	values := []string{}
+	for _, mode := range modes {
+		values = append(values, string(mode))
+	}
+	sort.Strings(values)
+	return values
+}
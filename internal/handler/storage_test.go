@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go-admin/configs"
+	"go-admin/internal/config"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+func newStorageHandler(t *testing.T) (*gin.Engine, kubernetes.Interface) {
+	err := config.Init(configs.Path("admin.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allow, deny := true, false
+	fast, slow := "fast", "slow"
+	newPVC := func(name string, class *string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: class,
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: fast}, AllowVolumeExpansion: &allow},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: slow}, AllowVolumeExpansion: &deny},
+		newPVC("data", &fast, corev1.ClaimBound),
+		newPVC("logs", &slow, corev1.ClaimBound),
+		newPVC("cache", &fast, corev1.ClaimPending),
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-old"},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "removed"},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+			}}}},
+		},
+	)
+	h := &storageHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		urDao: &mockUserRoleDao{roles: map[uint64][]*model.Role{
+			1: {{RoleKey: "admin"}},
+			2: {{RoleKey: "viewer"}},
+			3: {{RoleKey: "admin"}},
+		}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("uid", c.GetHeader("X-Uid")) // instead of middleware.Auth
+	}, restrictScope("3", "prod"))
+	r.GET("/storage/pvcs", h.ListPVCs)
+	r.GET("/storage/pvs", h.ListPVs)
+	r.POST("/namespaces/:namespace/pvcs/:name/expand", h.ExpandPVC)
+	return r, client
+}
+
+func Test_storageHandler_ListPVCs(t *testing.T) {
+	r, _ := newStorageHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "1", "/storage/pvcs?namespace=default", nil)
+	result := &struct {
+		Code int `json:"code"`
+		Data struct {
+			PVCs []types.PVCObjDetail `json:"pvcs"`
+		} `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 0, result.Code)
+	pvcs := map[string]types.PVCObjDetail{}
+	for _, pvc := range result.Data.PVCs {
+		pvcs[pvc.Name] = pvc
+	}
+	assert.Equal(t, []string{"web-0"}, pvcs["data"].Pods)
+	assert.Equal(t, "", pvcs["data"].Problem)
+	assert.Equal(t, "Pending", pvcs["cache"].Problem)
+	assert.Equal(t, []string{"ReadWriteOnce"}, pvcs["logs"].AccessModes)
+}
+
+func Test_storageHandler_ListPVs(t *testing.T) {
+	r, _ := newStorageHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "1", "/storage/pvs", nil)
+	assert.Contains(t, w.Body.String(), `"orphaned":true,"claim":"default/removed"`)
+
+	// the claim in a namespace out of the data scope is not shown
+	w = doJSONRequest(r, http.MethodGet, "3", "/storage/pvs", nil)
+	assert.Contains(t, w.Body.String(), `"orphaned":true,"claim":""`)
+}
+
+func Test_storageHandler_ExpandPVC(t *testing.T) {
+	r, client := newStorageHandler(t)
+
+	// no write role, or the namespace is out of the data scope
+	for _, uid := range []string{"2", "3"} {
+		w := doJSONRequest(r, http.MethodPost, uid, "/namespaces/default/pvcs/data/expand", &types.ExpandPVCRequest{Size: "20Gi"})
+		assert.Contains(t, w.Body.String(), `"code":10008`)
+	}
+
+	w := doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/pvcs/data/expand", &types.ExpandPVCRequest{Size: "20Gi"})
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	pvc, err := client.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "data", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "20Gi", pvc.Spec.Resources.Requests.Storage().String())
+
+	// storageClass does not allow expansion
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/pvcs/logs/expand", &types.ExpandPVCRequest{Size: "20Gi"})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	// smaller size
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/pvcs/data/expand", &types.ExpandPVCRequest{Size: "1Gi"})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	// not bound
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/pvcs/cache/expand", &types.ExpandPVCRequest{Size: "20Gi"})
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
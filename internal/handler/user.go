@@ -3,16 +3,22 @@ package handler
 import (
 	"errors"
 	"math"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
 
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
 	"github.com/zhufuyi/sponge/pkg/gin/middleware"
 	"github.com/zhufuyi/sponge/pkg/gin/response"
 	"github.com/zhufuyi/sponge/pkg/logger"
 	"github.com/zhufuyi/sponge/pkg/utils"
 
+	"go-admin/internal/authn"
+	"go-admin/internal/avatar"
 	"go-admin/internal/cache"
+	"go-admin/internal/config"
 	"go-admin/internal/dao"
 	"go-admin/internal/ecode"
 	"go-admin/internal/model"
@@ -24,28 +30,113 @@ var _ UserHandler = (*userHandler)(nil)
 // UserHandler defining the handler interface
 type UserHandler interface {
 	Login(c *gin.Context)
+	LoginTwoFactor(c *gin.Context)
+	LoginTwoFactorQRCode(c *gin.Context)
 	Register(c *gin.Context)
 	DeleteByID(c *gin.Context)
 	DeleteByIDs(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	GetByCondition(c *gin.Context)
 	ListByIDs(c *gin.Context)
 	ListByLastID(c *gin.Context)
 	List(c *gin.Context)
+	ListTrash(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+	GetRoles(c *gin.Context)
+	SetRoles(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
+	GetMe(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	UploadAvatar(c *gin.Context)
+	Unlock(c *gin.Context)
+	ListLoginHistory(c *gin.Context)
+	ListUserLoginHistory(c *gin.Context)
+	OIDCLogin(c *gin.Context)
+	OIDCCallback(c *gin.Context)
+	GetTwoFactor(c *gin.Context)
+	EnrollTwoFactor(c *gin.Context)
+	GetTwoFactorQRCode(c *gin.Context)
+	EnableTwoFactor(c *gin.Context)
+	DisableTwoFactor(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	ResetTwoFactor(c *gin.Context)
+	CreateApiToken(c *gin.Context)
+	ListApiTokens(c *gin.Context)
+	RevokeApiToken(c *gin.Context)
+	ListUserApiTokens(c *gin.Context)
+	RevokeUserApiToken(c *gin.Context)
+	Logout(c *gin.Context)
+	ListSessions(c *gin.Context)
+	RevokeSession(c *gin.Context)
+	ListUserSessions(c *gin.Context)
+	ForceLogout(c *gin.Context)
 }
 
 type userHandler struct {
-	iDao dao.UserDao
+	db    *gorm.DB
+	iDao  dao.UserDao
+	urDao dao.UserRoleDao
+
+	idDao     dao.UserIdentityDao
+	providers *loginProviders
+
+	lhDao    dao.LoginHistoryDao
+	attempts cache.LoginAttemptCache
+	login    config.Login
+	oidc     *oidcLogin
+
+	totpDao    dao.UserTOTPDao
+	challenges cache.LoginChallengeCache
+	twoFactor  config.TwoFactor
+
+	tokenDao dao.ApiTokenDao
+	apiDao   dao.ApiDao
+	apiToken config.ApiToken
+
+	sessions cache.SessionCache
+
+	avatars       avatar.Store
+	avatarSize    int // width and height of the stored avatars in pixels
+	avatarMaxSize int // maximum size of an uploaded avatar image in MB
 }
 
 // NewUserHandler creating the handler interface
 func NewUserHandler() UserHandler {
+	iDao := dao.NewUserDao(
+		model.GetDB(),
+		cache.NewUserCache(model.GetCacheType()),
+	)
 	return &userHandler{
-		iDao: dao.NewUserDao(
-			model.GetDB(),
-			cache.NewUserCache(model.GetCacheType()),
-		),
+		db:    model.GetDB(),
+		iDao:  iDao,
+		urDao: dao.NewUserRoleDao(model.GetDB()),
+
+		idDao:     dao.NewUserIdentityDao(model.GetDB()),
+		providers: newLoginProviders(config.Get().Auth.Providers, iDao),
+
+		lhDao:    dao.NewLoginHistoryDao(model.GetDB()),
+		attempts: cache.NewLoginAttemptCache(model.GetCacheType()),
+		login:    newLoginConfig(config.Get().Login),
+		oidc:     newOIDCLogin(config.Get().OIDC, model.GetCacheType()),
+
+		totpDao:    dao.NewUserTOTPDao(model.GetDB()),
+		challenges: cache.NewLoginChallengeCache(model.GetCacheType()),
+		twoFactor:  newTwoFactorConfig(config.Get().TwoFactor),
+
+		tokenDao: dao.NewApiTokenDao(model.GetDB()),
+		apiDao:   dao.NewApiDao(model.GetDB(), cache.NewApiCache(model.GetCacheType())),
+		apiToken: newApiTokenConfig(config.Get().ApiToken),
+
+		sessions: cache.NewSessionCache(model.GetCacheType()),
+
+		avatars:       newAvatarStore(&config.Get().Avatar),
+		avatarSize:    config.Get().Avatar.Size,
+		avatarMaxSize: config.Get().Avatar.MaxSize,
 	}
 }
 
@@ -56,8 +147,8 @@ func NewUserHandler() UserHandler {
 // @accept json
 // @Produce json
 // @Param data body types.LoginRequest true "user information"
-// @Success 200 {object} types.CreateUserRespond{}
-// @Router /api/v1/login [post]
+// @Success 200 {object} types.LoginRespond{}
+// @Router /api/v1/user/login [post]
 // @Security BearerAuth
 func (h *userHandler) Login(c *gin.Context) {
 	form := &types.LoginRequest{}
@@ -68,26 +159,118 @@ func (h *userHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user := &model.User{}
-	err = copier.Copy(user, form)
-	if err != nil {
-		response.Error(c, ecode.ErrCreateUser)
+	ctx := middleware.WrapCtx(c)
+	history := &model.LoginHistory{Name: form.Name, IP: c.ClientIP(), UserAgent: truncate(c.Request.UserAgent(), 255)}
+
+	// the locks are checked before the password, the password of a locked account can not be guessed
+	d, ok := h.lockedFor(c, loginIPKey+history.IP)
+	if !ok {
 		return
 	}
-	ctx := middleware.WrapCtx(c)
-	userInfo, err := h.iDao.GetByName(ctx, user.Name)
+	if d > 0 {
+		h.recordLogin(c, history, model.LoginResultThrottled)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+		response.Error(c, ecode.ErrLoginThrottled)
+		return
+	}
+	d, ok = h.lockedFor(c, loginAccountKey+form.Name)
+	if !ok {
+		return
+	}
+	if d > 0 {
+		h.recordLogin(c, history, model.LoginResultLocked)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+		response.Error(c, ecode.ErrLoginLocked)
+		return
+	}
+
+	acc, err := h.providers.chain.Authenticate(ctx, form.Name, form.Password)
 	if err != nil {
-		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if !errors.Is(err, authn.ErrInvalidCredentials) {
+			logger.Error("Authenticate error", logger.Err(err), logger.String("name", form.Name), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		if err != authn.ErrInvalidCredentials { //nolint
+			// some providers failed, e.g. an ldap server is down, the others rejected the credentials
+			logger.Warn("Authenticate error", logger.Err(err), logger.String("name", form.Name), middleware.GCtxRequestIDField(c))
+		}
+		userInfo, err := h.iDao.GetByName(ctx, form.Name)
+		if err != nil {
+			logger.Error("GetByName error", logger.Err(err), logger.String("name", form.Name), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		history.UserID = userInfo.ID
+		h.loginFailed(c, userInfo, history)
+		response.Error(c, ecode.ErrLogin)
 		return
 	}
-	if userInfo.Password != user.Password {
-		logger.Error(ecode.ErrLogin.Msg())
+
+	userInfo := acc.User
+	if userInfo == nil { // an account of a directory, it is linked to a local user
+		userInfo, err = h.provisionUser(ctx, &externalAccount{
+			Issuer:          ldapIssuer(acc.Provider),
+			Subject:         acc.Subject,
+			Username:        acc.Username,
+			Email:           acc.Email,
+			EmailVerified:   true, // the directory is managed by the administrators
+			ManagedRoleKeys: acc.ManagedRoleKeys,
+			RoleKeys:        acc.RoleKeys,
+		}, h.providers.policies[acc.Provider])
+		if err != nil {
+			if errors.Is(err, errUserNotLinked) {
+				logger.Warn("provisionUser not linked", logger.String("provider", acc.Provider), logger.String("subject", acc.Subject), middleware.GCtxRequestIDField(c))
+				h.recordLogin(c, history, model.LoginResultFailed)
+				response.Error(c, ecode.ErrUserNotLinked)
+				return
+			}
+			logger.Error("provisionUser error", logger.Err(err), logger.String("provider", acc.Provider), logger.String("subject", acc.Subject), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+	history.UserID = userInfo.ID
+	if userInfo.Status == model.UserStatusBlocked {
+		h.recordLogin(c, history, model.LoginResultBlocked)
+		response.Error(c, ecode.ErrUserBlocked)
+		return
+	}
+	if h.startTwoFactor(c, userInfo, history, respondChallenge) {
+		return
+	}
+
+	h.completeLogin(c, userInfo, history, gin.H{})
+}
+
+// completeLogin respond the token and the user with the data of the login
+func (h *userHandler) completeLogin(c *gin.Context, user *model.User, history *model.LoginHistory, data gin.H) {
+	token, ok := h.issueLoginToken(c, user, history)
+	if !ok {
+		return
+	}
+	detail, err := convertUser(user)
+	if err != nil {
 		response.Error(c, ecode.ErrLogin)
 		return
 	}
 
-	response.Success(c, userInfo)
+	data["token"] = token
+	data["user"] = detail
+	response.Success(c, data)
+}
+
+// issueLoginToken issue the token of a login that passed all the factors and record the success,
+// if it fails, the error response is written and false is returned
+func (h *userHandler) issueLoginToken(c *gin.Context, user *model.User, history *model.LoginHistory) (string, bool) {
+	token, err := h.issueToken(c, user)
+	if err != nil {
+		logger.Error("issueToken error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return "", false
+	}
+	h.loginSucceeded(c, user, history)
+	return token, true
 }
 
 // Register
@@ -98,7 +281,7 @@ func (h *userHandler) Login(c *gin.Context) {
 // @Produce json
 // @Param data body types.CreateUserRequest true "user information"
 // @Success 200 {object} types.CreateUserRespond{}
-// @Router /api/v1/reg [post]
+// @Router /api/v1/user/reg [post]
 // @Security BearerAuth
 func (h *userHandler) Register(c *gin.Context) {
 	form := &types.CreateUserRequest{}
@@ -144,8 +327,13 @@ func (h *userHandler) DeleteByID(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
+	err := h.iDao.DeleteByID(ctx, id, deletedBy)
 	if err != nil {
 		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -174,8 +362,13 @@ func (h *userHandler) DeleteByIDs(c *gin.Context) {
 		return
 	}
 
+	deletedBy, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
-	err = h.iDao.DeleteByIDs(ctx, form.IDs)
+	err = h.iDao.DeleteByIDs(ctx, form.IDs, deletedBy)
 	if err != nil {
 		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -193,7 +386,9 @@ func (h *userHandler) DeleteByIDs(c *gin.Context) {
 // @Produce json
 // @Param id path string true "id"
 // @Param data body types.UpdateUserByIDRequest true "user information"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
 // @Success 200 {object} types.UpdateUserByIDRespond{}
+// @Header 200 {string} ETag "version of the updated user"
 // @Router /api/v1/user/{id} [put]
 // @Security BearerAuth
 func (h *userHandler) UpdateByID(c *gin.Context) {
@@ -211,6 +406,20 @@ func (h *userHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		form.Version = ifMatch
+	}
+	if form.Version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
 
 	user := &model.User{}
 	err = copier.Copy(user, form)
@@ -222,11 +431,88 @@ func (h *userHandler) UpdateByID(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, user)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("UpdateByID version conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUserVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
+	setETag(c, form.Version+1)
+	response.Success(c)
+}
+
+// PatchByID partially update a record by id
+// @Summary patch user
+// @Description update the fields present in the JSON merge patch (RFC 7386) of the user, the absent fields are unchanged and null clears a field,
+// @Description the name and the password can not be cleared, the values are validated by the rules of the update.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateUserByIDRequest true "the fields to change, the version is required unless the If-Match header is set"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
+// @Success 200 {object} types.UpdateUserByIDRespond{}
+// @Header 200 {string} ETag "version of the updated user"
+// @Router /api/v1/user/{id} [patch]
+// @Security BearerAuth
+func (h *userHandler) PatchByID(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		logger.Warn("GetRawData error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	columns, version, err := parseMergePatch(data, &model.User{}, &types.UpdateUserByIDRequest{}, "name", "password")
+	if err != nil {
+		logger.Warn("parseMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		version = ifMatch
+	}
+	if version == 0 {
+		logger.Warn("version is required", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails("version or If-Match header is required"))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.PatchByID(ctx, id, version, columns)
+	if err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("PatchByID version conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUserVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("PatchByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("id", id), logger.Any("columns", columns), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, version+1)
 	response.Success(c)
 }
 
@@ -237,7 +523,9 @@ func (h *userHandler) UpdateByID(c *gin.Context) {
 // @Param id path string true "id"
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag returned by a previous get, the status is 304 if the user has not been modified"
 // @Success 200 {object} types.GetUserByIDRespond{}
+// @Header 200 {string} ETag "version of the user, send it in the If-Match header to update the user"
 // @Router /api/v1/user/{id} [get]
 // @Security BearerAuth
 func (h *userHandler) GetByID(c *gin.Context) {
@@ -259,6 +547,9 @@ func (h *userHandler) GetByID(c *gin.Context) {
 		}
 		return
 	}
+	if isNotModified(c, user.Version) {
+		return
+	}
 
 	data := &types.UserObjDetail{}
 	err = copier.Copy(data, user)
@@ -267,7 +558,9 @@ func (h *userHandler) GetByID(c *gin.Context) {
 		return
 	}
 	data.ID = idStr
+	data.Password = ""
 
+	setETag(c, user.Version)
 	response.Success(c, gin.H{"user": data})
 }
 
@@ -316,6 +609,7 @@ func (h *userHandler) GetByCondition(c *gin.Context) {
 		return
 	}
 	data.ID = utils.Uint64ToStr(user.ID)
+	data.Password = ""
 
 	response.Success(c, gin.H{"user": data})
 }
@@ -408,7 +702,8 @@ func (h *userHandler) ListByLastID(c *gin.Context) {
 
 // List of records by query parameters
 // @Summary list of users by query parameters
-// @Description list of users by paging and conditions
+// @Description list of users by paging and conditions, only the users in the data scope of the roles of the user
+// @Description who sent the request are listed
 // @Tags user
 // @accept json
 // @Produce json
@@ -445,6 +740,310 @@ func (h *userHandler) List(c *gin.Context) {
 	})
 }
 
+// GetRoles get the roles of user
+// @Summary get user roles
+// @Description get the roles bound to the user
+// @Tags user
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetUserRolesRespond{}
+// @Router /api/v1/user/{id}/roles [get]
+// @Security BearerAuth
+func (h *userHandler) GetRoles(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	roles, err := h.urDao.GetRolesByUserID(ctx, id)
+	if err != nil {
+		logger.Error("GetRolesByUserID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertRoles(roles)
+	if err != nil {
+		response.Error(c, ecode.ErrGetUserRoles)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"roles": data,
+	})
+}
+
+// SetRoles replace the roles of user
+// @Summary set user roles
+// @Description replace the roles bound to the user
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.SetUserRolesRequest true "role id list"
+// @Success 200 {object} types.SetUserRolesRespond{}
+// @Router /api/v1/user/{id}/roles [put]
+// @Security BearerAuth
+func (h *userHandler) SetRoles(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.SetUserRolesRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.urDao.SetUserRoles(ctx, id, form.RoleIDs)
+	if err != nil {
+		logger.Error("SetUserRoles error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSetUserRoles)
+		return
+	}
+
+	response.Success(c)
+}
+
+// ListTrash list of the soft deleted records by query parameters
+// @Summary list of deleted users
+// @Description list of the users in the trash by paging and conditions, they can be restored or purged
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListTrashUsersRespond{}
+// @Router /api/v1/user/trash/list [post]
+// @Security BearerAuth
+func (h *userHandler) ListTrash(c *gin.Context) {
+	form := &types.ListTrashUsersRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	users, total, err := h.iDao.GetTrash(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetTrash error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertUsers(users)
+	if err != nil {
+		response.Error(c, ecode.ErrListTrashUser)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"users": data,
+		"total": total,
+	})
+}
+
+// Restore a soft deleted record by id
+// @Summary restore user
+// @Description restore the deleted user by id, it fails if another user with the same name exists
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreUserRespond{}
+// @Router /api/v1/user/trash/{id}/restore [post]
+// @Security BearerAuth
+func (h *userHandler) Restore(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Restore not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else if errors.Is(err, model.ErrRecordConflict) {
+			logger.Warn("Restore conflict", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUserConflict)
+		} else {
+			logger.Error("Restore error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrRestoreUser)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Purge permanently delete a soft deleted record by id
+// @Summary purge user
+// @Description permanently delete the user in the trash by id, the users that are not deleted can not be purged
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.PurgeUserRespond{}
+// @Router /api/v1/user/trash/{id} [delete]
+// @Security BearerAuth
+func (h *userHandler) Purge(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.Purge(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("Purge not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("Purge error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrPurgeUser)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Import create or update records from a file
+// @Summary import users
+// @Description create or update users from a csv, json or yaml file in one transaction, the users are matched by name, a new user requires a password.
+// @Description the password of an existing user is only changed if resetPasswords is true.
+// @Description every row is validated and reported, nothing is saved if a row is invalid or dryRun is true.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Content-Type"
+// @Param dryRun query bool false "validate the rows without saving them"
+// @Param resetPasswords query bool false "change the passwords of the existing users to the ones of the rows"
+// @Param data body []types.ImportUserRow true "users"
+// @Success 200 {object} types.ImportRespond{}
+// @Router /api/v1/user/import [post]
+// @Security BearerAuth
+func (h *userHandler) Import(c *gin.Context) {
+	form := []*types.ImportUserRow{}
+	err := decodeImport(c, &form)
+	if err != nil {
+		logger.Warn("decodeImport error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if len(form) == 0 {
+		response.Error(c, ecode.InvalidParams.WithDetails("no rows to import"))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	resetPasswords, _ := strconv.ParseBool(c.Query("resetPasswords"))
+
+	rows := make([]importRow, 0, len(form))
+	for _, row := range form {
+		rows = append(rows, importRow{key: row.Name, value: row})
+	}
+
+	ctx := middleware.WrapCtx(c)
+	result, err := runImport(h.db.WithContext(ctx), rows, dryRun, func(tx *gorm.DB, value interface{}) (string, error) {
+		row := value.(*types.ImportUserRow)
+		user := &model.User{}
+		if err := copier.Copy(user, row); err != nil {
+			return "", err
+		}
+
+		record, err := h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "name", Value: row.Name}}})
+		if errors.Is(err, model.ErrRecordNotFound) {
+			if row.Password == "" {
+				return "", errInvalidRow("password is required to create the user")
+			}
+			_, err = h.iDao.CreateByTx(ctx, tx, user)
+			return importCreated, err
+		}
+		if err != nil {
+			return "", err
+		}
+		user.ID = record.ID // the version is not checked, the import overwrites the non-empty fields
+		if !resetPasswords {
+			user.Password = "" // left unchanged
+		}
+		return importUpdated, h.iDao.UpdateByTx(ctx, tx, user)
+	})
+	if err != nil {
+		logger.Error("Import error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrImportUser)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Export write the records matching the query conditions to a file
+// @Summary export users
+// @Description export the users matching the query conditions to a csv, json or yaml file, the file can be imported again.
+// @Description only the users in the data scope of the roles of the user who sent the request are exported.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param format query string false "format of the file, csv, json or yaml, default is derived from the Accept header"
+// @Param data body types.ExportUsersRequest true "query conditions"
+// @Success 200 {array} types.ImportUserRow
+// @Router /api/v1/user/export [post]
+// @Security BearerAuth
+func (h *userHandler) Export(c *gin.Context) {
+	format, err := getExportFormat(c)
+	if err != nil {
+		logger.Warn("getExportFormat error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	form := &types.ExportUsersRequest{}
+	err = c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = exportRecords(c, format, "users", &types.ImportUserRow{}, &query.Params{Sort: form.Sort, Columns: form.Columns}, func(params *query.Params) ([]interface{}, error) {
+		records, _, err := h.iDao.GetByColumns(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]interface{}, 0, len(records))
+		for _, record := range records {
+			row := &types.ImportUserRow{}
+			if err = copier.Copy(row, record); err != nil {
+				return nil, err
+			}
+			row.Password = "" // never exported
+			rows = append(rows, row)
+		}
+		return rows, nil
+	})
+	if err != nil {
+		logger.Error("Export error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() {
+			response.Error(c, ecode.ErrExportUser)
+		}
+		return
+	}
+}
+
 func getUserIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -463,6 +1062,10 @@ func convertUser(user *model.User) (*types.UserObjDetail, error) {
 		return nil, err
 	}
 	data.ID = utils.Uint64ToStr(user.ID)
+	data.Password = "" // the passwords are stored in plaintext by the local provider, they are never returned
+	if user.DeletedAt.Valid {
+		data.DeletedAt = &user.DeletedAt.Time
+	}
 	return data, nil
 }
 
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"go-admin/internal/authn"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+)
+
+// loginProviders the providers checking the name and password at the login, in the configured order
+type loginProviders struct {
+	chain    authn.Chain
+	policies map[string]provisionPolicy // the provisioning of the users of the external providers by name
+}
+
+// newLoginProviders create the providers of the config, the local users only if none is configured
+func newLoginProviders(providers []config.AuthProvider, userDao dao.UserDao) *loginProviders {
+	if len(providers) == 0 {
+		providers = []config.AuthProvider{{Name: authn.TypeLocal, Type: authn.TypeLocal}}
+	}
+
+	lp := &loginProviders{policies: map[string]provisionPolicy{}}
+	names := map[string]bool{}
+	for _, p := range providers {
+		if p.Name == "" {
+			p.Name = p.Type
+		}
+		if names[p.Name] {
+			panic("duplicate login provider " + p.Name)
+		}
+		names[p.Name] = true
+
+		switch p.Type {
+		case authn.TypeLocal:
+			lp.chain = append(lp.chain, authn.NewLocal(p.Name, userDao))
+
+		case authn.TypeLDAP:
+			a, err := authn.NewLDAP(p.Name, newLDAPOptions(p.Name, &p.LDAP))
+			if err != nil {
+				panic("authn.NewLDAP error: " + err.Error())
+			}
+			lp.chain = append(lp.chain, a)
+			lp.policies[p.Name] = provisionPolicy{
+				AutoCreate:  p.LDAP.AutoCreate,
+				LinkByEmail: p.LDAP.LinkByEmail,
+				LinkByName:  p.LDAP.LinkByName,
+			}
+
+		default:
+			panic("unknown type of the login provider " + p.Name + ": " + p.Type)
+		}
+	}
+	return lp
+}
+
+func newLDAPOptions(name string, cfg *config.LDAP) *authn.LDAPOptions {
+	bindPassword := cfg.BindPassword
+	if v := os.Getenv("LDAP_BIND_PASSWORD_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))); v != "" {
+		bindPassword = v
+	}
+	groupRoles := make([]authn.GroupRole, 0, len(cfg.GroupRoles))
+	for _, gr := range cfg.GroupRoles {
+		groupRoles = append(groupRoles, authn.GroupRole{Group: gr.Group, RoleKey: gr.RoleKey})
+	}
+
+	return &authn.LDAPOptions{
+		URL:                cfg.URL,
+		StartTLS:           cfg.StartTLS,
+		CAFile:             cfg.CaFile,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		BindDN:             cfg.BindDN,
+		BindPassword:       bindPassword,
+		BaseDN:             cfg.BaseDN,
+		UserFilter:         cfg.UserFilter,
+		NameAttribute:      cfg.NameAttribute,
+		EmailAttribute:     cfg.EmailAttribute,
+		GroupAttribute:     cfg.GroupAttribute,
+		GroupBaseDN:        cfg.GroupBaseDN,
+		GroupFilter:        cfg.GroupFilter,
+		GroupRoles:         groupRoles,
+		Timeout:            time.Duration(cfg.Timeout) * time.Second,
+	}
+}
+
+// ldapIssuer the issuer of the identities of an ldap provider
+func ldapIssuer(name string) string {
+	return authn.TypeLDAP + ":" + name
+}
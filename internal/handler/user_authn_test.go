@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+)
+
+func newLDAPLoginRouter(t *testing.T, providers []config.AuthProvider) (*gin.Engine, dao.UserDao, dao.UserRoleDao) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(ctx, &model.User{Name: "foo", Password: "123456", Email: "foo@example.com", Status: model.UserStatusActivated}))
+	roleDao := dao.NewRoleDao(db, nil)
+	for _, key := range []string{"admin", "viewer", "auditor"} {
+		assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: key, RoleKey: key}))
+	}
+
+	h := &userHandler{
+		db:        db,
+		iDao:      iDao,
+		urDao:     dao.NewUserRoleDao(db),
+		idDao:     dao.NewUserIdentityDao(db),
+		providers: newLoginProviders(providers, iDao),
+		lhDao:     dao.NewLoginHistoryDao(db),
+		attempts:  cache.NewLoginAttemptCache(&model.CacheType{CType: "memory"}),
+		sessions:  cache.NewSessionCache(&model.CacheType{CType: "memory"}),
+		login:     newLoginConfig(config.Login{}),
+		totpDao:   dao.NewUserTOTPDao(db),
+	}
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/user/login", h.Login)
+	return r, iDao, h.urDao
+}
+
+func newLDAPProvider(td *testdirectory.Directory, autoCreate bool) config.AuthProvider {
+	return config.AuthProvider{
+		Name: "corp",
+		Type: "ldap",
+		LDAP: config.LDAP{
+			URL:            fmt.Sprintf("ldap://%s:%d", td.Host(), td.Port()),
+			BindDN:         "cn=svc," + testdirectory.DefaultUserDN,
+			BindPassword:   "password",
+			BaseDN:         testdirectory.DefaultUserDN,
+			UserFilter:     "(cn=%s)",
+			NameAttribute:  "name",
+			EmailAttribute: "email",
+			AutoCreate:     autoCreate,
+			GroupRoles: []config.GroupRole{
+				{Group: "admins", RoleKey: "admin"},
+				{Group: "staff", RoleKey: "viewer"},
+			},
+		},
+	}
+}
+
+func Test_userHandler_Login_LDAP(t *testing.T) {
+	td := testdirectory.Start(t, testdirectory.WithNoTLS(t))
+	users := testdirectory.NewUsers(t, []string{"alice"}, testdirectory.WithMembersOf(t, testdirectory.NewMemberOf(t, []string{"admins"})...))
+	td.SetUsers(append(users, testdirectory.NewUsers(t, []string{"bob", "svc"})...)...)
+
+	r, iDao, urDao := newLDAPLoginRouter(t, []config.AuthProvider{{Name: "local", Type: "local"}, newLDAPProvider(td, true)})
+	ctx := context.Background()
+
+	// the local users log in before the directory is searched
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), `"token"`)
+
+	// the first login of a directory user creates the user with the roles of its groups
+	w = doLogin(r, "10.0.0.1", "alice", "password")
+	assert.Contains(t, w.Body.String(), `"token"`)
+	alice, err := iDao.GetByName(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", alice.Email)
+	assert.NotZero(t, alice.LoginAt)
+	roles, err := urDao.GetRolesByUserID(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "admin", roles[0].RoleKey)
+
+	// the next login gets the same user
+	w = doLogin(r, "10.0.0.1", "alice", "password")
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"id":"%d"`, alice.ID))
+
+	w = doLogin(r, "10.0.0.1", "alice", "wrong")
+	assert.Contains(t, w.Body.String(), ecode.ErrLogin.Msg())
+	w = doLogin(r, "10.0.0.1", "nobody", "password")
+	assert.Contains(t, w.Body.String(), ecode.ErrLogin.Msg())
+
+	// no user is created without autoCreate
+	r, _, _ = newLDAPLoginRouter(t, []config.AuthProvider{newLDAPProvider(td, false)})
+	w = doLogin(r, "10.0.0.1", "bob", "password")
+	assert.Contains(t, w.Body.String(), ecode.ErrUserNotLinked.Msg())
+	w = doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), ecode.ErrLogin.Msg())
+}
+
+func Test_userHandler_Login_LDAPDown(t *testing.T) {
+	td := testdirectory.Start(t, testdirectory.WithNoTLS(t))
+	provider := newLDAPProvider(td, true)
+	td.Stop()
+
+	// the local users still log in, the others are rejected
+	r, _, _ := newLDAPLoginRouter(t, []config.AuthProvider{provider, {Name: "local", Type: "local"}})
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), `"token"`)
+	w = doLogin(r, "10.0.0.1", "alice", "password")
+	assert.Contains(t, w.Body.String(), ecode.ErrLogin.Msg())
+
+	// the login fails if no provider is reachable
+	r, _, _ = newLDAPLoginRouter(t, []config.AuthProvider{provider})
+	w = doLogin(r, "10.0.0.1", "alice", "password")
+	assert.Equal(t, 500, w.Code)
+}
+
+func Test_newLoginProviders(t *testing.T) {
+	lp := newLoginProviders(nil, nil)
+	require.Len(t, lp.chain, 1)
+	assert.Equal(t, "local", lp.chain[0].Name())
+
+	assert.Panics(t, func() { newLoginProviders([]config.AuthProvider{{Type: "unknown"}}, nil) })
+	assert.Panics(t, func() { newLoginProviders([]config.AuthProvider{{Type: "local"}, {Type: "local"}}, nil) })
+	assert.Panics(t, func() { newLoginProviders([]config.AuthProvider{{Name: "corp", Type: "ldap"}}, nil) })
+
+	t.Setenv("LDAP_BIND_PASSWORD_CORP_EU", "secret")
+	opts := newLDAPOptions("corp-eu", &config.LDAP{BindPassword: "ignored", Timeout: 3})
+	assert.Equal(t, "secret", opts.BindPassword)
+	assert.Equal(t, "3s", opts.Timeout.String())
+}
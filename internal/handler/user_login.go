@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// the keys of the failure counters and the locks in the login attempt cache
+const (
+	loginIPKey      = "ip:"
+	loginAccountKey = "account:"
+	loginBlockKey   = "block:"
+)
+
+const (
+	defaultLoginFailureWindow = 900   // seconds
+	defaultLoginLockDuration  = 900   // seconds
+	defaultLoginBlockWindow   = 86400 // seconds
+)
+
+// newLoginConfig fill the durations that are not set, the zero thresholds disable their check
+func newLoginConfig(cfg config.Login) config.Login {
+	if cfg.FailureWindow <= 0 {
+		cfg.FailureWindow = defaultLoginFailureWindow
+	}
+	if cfg.LockDuration <= 0 {
+		cfg.LockDuration = defaultLoginLockDuration
+	}
+	if cfg.BlockWindow <= 0 {
+		cfg.BlockWindow = defaultLoginBlockWindow
+	}
+	return cfg
+}
+
+// lockedFor the remaining duration of the lock of the key, the login is denied if the cache fails,
+// otherwise a failing cache would disable the locks, false means the error was responded
+func (h *userHandler) lockedFor(c *gin.Context, key string) (time.Duration, bool) {
+	d, err := h.attempts.LockedFor(middleware.WrapCtx(c), key)
+	if err != nil {
+		logger.Error("LockedFor error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return 0, false
+	}
+	return d, true
+}
+
+// countFailure add a failure of the key and lock it when the failures reach max, max 0 means no limit
+func (h *userHandler) countFailure(c *gin.Context, key string, max int) {
+	if max <= 0 {
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+	n, err := h.attempts.Incr(ctx, key, time.Duration(h.login.FailureWindow)*time.Second)
+	if err != nil {
+		logger.Error("Incr error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		return
+	}
+	if n >= int64(max) {
+		err = h.attempts.Lock(ctx, key, time.Duration(h.login.LockDuration)*time.Second)
+		if err != nil {
+			logger.Error("Lock error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+			return
+		}
+		logger.Warn("too many failed logins, locked", logger.String("key", key), logger.Int64("failures", n), middleware.GCtxRequestIDField(c))
+	}
+}
+
+// loginFailed count the failure of the ip and the account, the user is blocked when its failures reach
+// the block threshold in the block window, user.ID is 0 if no user has the name
+func (h *userHandler) loginFailed(c *gin.Context, user *model.User, history *model.LoginHistory) {
+	h.countFailure(c, loginIPKey+history.IP, h.login.MaxIPFailures)
+	h.countFailure(c, loginAccountKey+history.Name, h.login.MaxAccountFailures)
+
+	if user.ID != 0 && user.Status != model.UserStatusBlocked && h.login.BlockFailures > 0 {
+		ctx := middleware.WrapCtx(c)
+		n, err := h.attempts.Incr(ctx, loginBlockKey+user.Name, time.Duration(h.login.BlockWindow)*time.Second)
+		if err != nil {
+			logger.Error("Incr error", logger.Err(err), logger.String("name", user.Name), middleware.GCtxRequestIDField(c))
+		} else if n >= int64(h.login.BlockFailures) {
+			err = h.iDao.UpdateByID(ctx, &model.User{Model: user.Model, Status: model.UserStatusBlocked})
+			if err != nil {
+				logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+			} else {
+				logger.Warn("too many failed logins, user blocked", logger.Any("id", user.ID), logger.Int64("failures", n), middleware.GCtxRequestIDField(c))
+			}
+		}
+	}
+
+	h.recordLogin(c, history, model.LoginResultFailed)
+}
+
+// loginSucceeded clear the failures of the account and save the login time
+func (h *userHandler) loginSucceeded(c *gin.Context, user *model.User, history *model.LoginHistory) {
+	_ = h.resetLoginFailures(c, user.Name)
+
+	user.LoginAt = uint64(time.Now().Unix())
+	err := h.iDao.UpdateByID(middleware.WrapCtx(c), &model.User{Model: user.Model, LoginAt: user.LoginAt})
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+	}
+
+	h.recordLogin(c, history, model.LoginResultSuccess)
+}
+
+// resetLoginFailures delete the failures and the locks of the account, the failures of the ips are kept
+func (h *userHandler) resetLoginFailures(c *gin.Context, name string) error {
+	ctx := middleware.WrapCtx(c)
+	for _, key := range []string{loginAccountKey + name, loginBlockKey + name} {
+		if err := h.attempts.Reset(ctx, key); err != nil {
+			logger.Error("Reset error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+			return err
+		}
+	}
+	return nil
+}
+
+// recordLogin save the attempt in the login history, the login does not fail if it can not be saved
+func (h *userHandler) recordLogin(c *gin.Context, history *model.LoginHistory, result string) {
+	history.Result = result
+	err := h.lhDao.Create(middleware.WrapCtx(c), history)
+	if err != nil {
+		logger.Error("Create login history error", logger.Err(err), logger.Any("history", history), middleware.GCtxRequestIDField(c))
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// Unlock a user
+// @Summary unlock user
+// @Description clear the failed logins and the temporary lock of the user, a blocked user is activated again
+// @Tags user
+// @Param id path string true "id"
+// @Produce json
+// @Success 200 {object} types.UnlockUserRespond{}
+// @Router /api/v1/user/{id}/unlock [post]
+// @Security BearerAuth
+func (h *userHandler) Unlock(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	if err = h.resetLoginFailures(c, user.Name); err != nil {
+		response.Error(c, ecode.ErrUnlockUser)
+		return
+	}
+	if user.Status == model.UserStatusBlocked {
+		err = h.iDao.UpdateByID(ctx, &model.User{Model: user.Model, Status: model.UserStatusActivated})
+		if err != nil {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUnlockUser)
+			return
+		}
+	}
+
+	response.Success(c)
+}
+
+// ListLoginHistory of users by query parameters
+// @Summary list login history
+// @Description list the login attempts by paging and conditions, e.g. the failed logins of a user or an ip
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListLoginHistoryRespond{}
+// @Router /api/v1/user/login/history [post]
+// @Security BearerAuth
+func (h *userHandler) ListLoginHistory(c *gin.Context) {
+	form := &types.ListLoginHistoryRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	records, total, err := h.lhDao.GetByColumns(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetByColumns error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"loginHistory": convertLoginHistory(records),
+		"total":        total,
+	})
+}
+
+// ListUserLoginHistory of a user by query parameters
+// @Summary list login history of user
+// @Description list the login attempts of a user by paging and conditions, the users can list their own attempts
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListLoginHistoryRespond{}
+// @Router /api/v1/user/{id}/login/history [post]
+// @Security BearerAuth
+func (h *userHandler) ListUserLoginHistory(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.ListLoginHistoryRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	records, total, err := h.lhDao.GetByUserID(ctx, id, &form.Params)
+	if err != nil {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", id), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"loginHistory": convertLoginHistory(records),
+		"total":        total,
+	})
+}
+
+func convertLoginHistory(records []*model.LoginHistory) []*types.LoginHistoryObjDetail {
+	data := make([]*types.LoginHistoryObjDetail, 0, len(records))
+	for _, record := range records {
+		data = append(data, &types.LoginHistoryObjDetail{
+			ID:        utils.Uint64ToStr(record.ID),
+			CreatedAt: record.CreatedAt,
+			UserID:    record.UserID,
+			Name:      record.Name,
+			IP:        record.IP,
+			UserAgent: record.UserAgent,
+			Result:    record.Result,
+		})
+	}
+	return data
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/model"
+)
+
+func newUserLoginRouter(t *testing.T, login config.Login) (*gin.Engine, dao.UserDao) {
+	db := newBulkSQLiteDB(t)
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(context.Background(), &model.User{Name: "foo", Password: "123456", Email: "foo@example.com", Status: model.UserStatusActivated}))
+
+	h := &userHandler{
+		db:       db,
+		iDao:     iDao,
+		urDao:    dao.NewUserRoleDao(db),
+		lhDao:    dao.NewLoginHistoryDao(db),
+		attempts: cache.NewLoginAttemptCache(&model.CacheType{CType: "memory"}),
+		sessions: cache.NewSessionCache(&model.CacheType{CType: "memory"}),
+		login:    newLoginConfig(login),
+		totpDao:  dao.NewUserTOTPDao(db),
+	}
+	h.providers = newLoginProviders(nil, iDao)
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/user/login", h.Login)
+	r.POST("/user/:id/unlock", h.Unlock)
+	r.POST("/user/login/history", h.ListLoginHistory)
+	r.POST("/user/:id/login/history", h.ListUserLoginHistory)
+	return r, iDao
+}
+
+func doLogin(r http.Handler, ip string, name string, password string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/user/login", strings.NewReader(`{"name":"`+name+`","password":"`+password+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-agent")
+	req.RemoteAddr = ip + ":12345"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_userHandler_Login_AccountLock(t *testing.T) {
+	r, iDao := newUserLoginRouter(t, config.Login{MaxAccountFailures: 3})
+
+	for i := 0; i < 3; i++ {
+		w := doLogin(r, "10.0.0.1", "foo", "wrong")
+		assert.Contains(t, w.Body.String(), "username or passwd error")
+	}
+	// the right password is rejected while the account is locked, from any address
+	w := doLogin(r, "10.0.0.2", "foo", "123456")
+	assert.Contains(t, w.Body.String(), "the account is locked temporarily")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = doBulkRequest(r, "/user/1/unlock", "application/json", "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doLogin(r, "10.0.0.2", "foo", "123456")
+	assert.Contains(t, w.Body.String(), `"token"`)
+	assert.Contains(t, w.Body.String(), `"password":""`) // the password is not returned
+	user, err := iDao.GetByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NotZero(t, user.LoginAt)
+}
+
+func Test_userHandler_Login_IPThrottle(t *testing.T) {
+	r, _ := newUserLoginRouter(t, config.Login{MaxIPFailures: 2})
+
+	doLogin(r, "10.0.0.1", "bar", "wrong")
+	doLogin(r, "10.0.0.1", "baz", "wrong")
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), "retry later")
+	w = doLogin(r, "10.0.0.2", "foo", "123456")
+	assert.Contains(t, w.Body.String(), `"token"`)
+}
+
+// failingAttemptCache an attempt cache whose locks can not be read
+type failingAttemptCache struct {
+	cache.LoginAttemptCache
+}
+
+func (c *failingAttemptCache) LockedFor(context.Context, string) (time.Duration, error) {
+	return 0, errors.New("connection refused")
+}
+
+func Test_userHandler_Login_CacheError(t *testing.T) {
+	db := newBulkSQLiteDB(t)
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(context.Background(), &model.User{Name: "foo", Password: "123456", Status: model.UserStatusActivated}))
+	h := &userHandler{
+		iDao:      iDao,
+		lhDao:     dao.NewLoginHistoryDao(db),
+		attempts:  &failingAttemptCache{cache.NewLoginAttemptCache(&model.CacheType{CType: "memory"})},
+		login:     newLoginConfig(config.Login{MaxAccountFailures: 3}),
+		providers: newLoginProviders(nil, iDao),
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/user/login", h.Login)
+
+	// the locks can not be checked, the login is denied
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), `"token"`)
+}
+
+func Test_userHandler_Login_Block(t *testing.T) {
+	r, iDao := newUserLoginRouter(t, config.Login{BlockFailures: 2})
+	ctx := context.Background()
+
+	doLogin(r, "10.0.0.1", "foo", "wrong")
+	doLogin(r, "10.0.0.2", "foo", "wrong")
+	user, err := iDao.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.UserStatusBlocked, user.Status)
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), "is blocked")
+
+	w = doBulkRequest(r, "/user/1/unlock", "application/json", "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	user, err = iDao.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.UserStatusActivated, user.Status)
+	w = doLogin(r, "10.0.0.1", "foo", "123456")
+	assert.Contains(t, w.Body.String(), `"token"`)
+
+	w = doBulkRequest(r, "/user/99/unlock", "application/json", "")
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_userHandler_ListLoginHistory(t *testing.T) {
+	r, _ := newUserLoginRouter(t, config.Login{MaxAccountFailures: 1})
+
+	doLogin(r, "10.0.0.1", "foo", "wrong")
+	doLogin(r, "10.0.0.1", "foo", "123456") // locked
+	doLogin(r, "10.0.0.1", "nobody", "wrong")
+
+	w := doBulkRequest(r, "/user/login/history", "application/json", `{"page":0,"size":10}`)
+	body := w.Body.String()
+	assert.Contains(t, body, `"total":3`)
+	assert.Contains(t, body, `"result":"locked"`)
+	assert.Contains(t, body, `"userAgent":"test-agent"`)
+	assert.Contains(t, body, `"ip":"10.0.0.1"`)
+
+	w = doBulkRequest(r, "/user/login/history", "application/json",
+		`{"page":0,"size":10,"columns":[{"name":"result","value":"failed"}]}`)
+	assert.Contains(t, w.Body.String(), `"total":2`)
+
+	// the attempts of a user, the columns cannot select the attempts of the others
+	w = doBulkRequest(r, "/user/1/login/history", "application/json", `{"page":0,"size":10}`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	w = doBulkRequest(r, "/user/1/login/history", "application/json",
+		`{"page":0,"size":10,"columns":[{"name":"result","value":"failed","logic":"||"},{"name":"name","value":"nobody"}]}`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	assert.NotContains(t, w.Body.String(), "nobody")
+}
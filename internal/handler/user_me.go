@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/avatar"
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+const (
+	defaultAvatarSize    = 256 // pixels
+	defaultAvatarMaxSize = 5   // MB
+)
+
+// newAvatarStore create the avatar store of the storage in the config, the local disk is the default
+func newAvatarStore(cfg *config.Avatar) avatar.Store {
+	if cfg.Storage == "s3" {
+		secretKey := cfg.S3.SecretKey
+		if v := os.Getenv("AVATAR_S3_SECRET_KEY"); v != "" {
+			secretKey = v
+		}
+		store, err := avatar.NewS3Store(&avatar.S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			UseSSL:    cfg.S3.UseSSL,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: secretKey,
+			PublicURL: cfg.S3.PublicURL,
+		})
+		if err != nil {
+			panic("avatar.NewS3Store error: " + err.Error())
+		}
+		return store
+	}
+
+	dir, urlPrefix := cfg.Local.Dir, cfg.Local.URLPrefix
+	if dir == "" {
+		dir = avatar.DefaultDir
+	}
+	if urlPrefix == "" {
+		urlPrefix = avatar.DefaultURLPrefix
+	}
+	return avatar.NewLocalStore(dir, urlPrefix)
+}
+
+// GetMe get the profile of the authenticated user
+// @Summary get my profile
+// @Description get the profile of the user who sent the request, the password is not returned
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.GetMeRespond{}
+// @Header 200 {string} ETag "version of the profile"
+// @Router /api/v1/user/me [get]
+// @Security BearerAuth
+func (h *userHandler) GetMe(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if isNotModified(c, user.Version) {
+		return
+	}
+
+	data, err := convertUser(user)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDUser)
+		return
+	}
+
+	setETag(c, user.Version)
+	response.Success(c, gin.H{"user": data})
+}
+
+// UpdateMe update the profile of the authenticated user
+// @Summary update my profile
+// @Description update the email, phone, age and gender of the user who sent the request, the empty fields are unchanged
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.UpdateMeRequest true "profile information"
+// @Param If-Match header string false "ETag returned by get, it takes precedence over the version in the body"
+// @Success 200 {object} types.UpdateMeRespond{}
+// @Header 200 {string} ETag "version of the updated profile"
+// @Router /api/v1/user/me [put]
+// @Security BearerAuth
+func (h *userHandler) UpdateMe(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.UpdateMeRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ifMatch, err := getIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("getIfMatchVersion error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if ifMatch != 0 {
+		form.Version = ifMatch
+	}
+
+	user := &model.User{}
+	err = copier.Copy(user, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDUser)
+		return
+	}
+	user.ID = uid
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.UpdateByID(ctx, user)
+	if err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			logger.Warn("UpdateByID version conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUserVersionConflict)
+		} else if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	if form.Version != 0 {
+		setETag(c, form.Version+1)
+	}
+	response.Success(c)
+}
+
+// ChangePassword change the password of the authenticated user
+// @Summary change my password
+// @Description change the password of the user who sent the request, the current password must be given
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.ChangePasswordRequest true "current and new password"
+// @Success 200 {object} types.ChangePasswordRespond{}
+// @Router /api/v1/user/me/password [put]
+// @Security BearerAuth
+func (h *userHandler) ChangePassword(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.ChangePasswordRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(user.Password), []byte(form.CurrentPassword)) != 1 {
+		logger.Warn("wrong current password", logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrWrongPassword)
+		return
+	}
+
+	// the version read above is checked, a concurrent change of the password fails
+	err = h.iDao.UpdateByID(ctx, &model.User{Model: user.Model, Password: form.NewPassword, Version: user.Version})
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrChangePassword)
+		return
+	}
+
+	response.Success(c)
+}
+
+// UploadAvatar upload the avatar of the authenticated user
+// @Summary upload my avatar
+// @Description upload a png, jpeg, gif or webp image of at most 4096x4096 pixels, it is cropped to a square, resized and stored as png,
+// @Description the url of the stored image is saved in the avatar of the user who sent the request
+// @Tags user
+// @accept multipart/form-data
+// @Produce json
+// @Param file formData file true "avatar image"
+// @Success 200 {object} types.UploadAvatarRespond{}
+// @Router /api/v1/user/me/avatar [post]
+// @Security BearerAuth
+func (h *userHandler) UploadAvatar(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	maxSize, size := h.avatarMaxSize, h.avatarSize
+	if maxSize <= 0 {
+		maxSize = defaultAvatarMaxSize
+	}
+	if size <= 0 {
+		size = defaultAvatarSize
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize)<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Warn("FormFile error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(fmt.Sprintf("the file is required and its size is at most %d MB", maxSize)))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Warn("Open error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	defer file.Close() //nolint
+	data, err := avatar.Resize(file, size)
+	if err != nil {
+		logger.Warn("Resize error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		if errors.Is(err, avatar.ErrImageTooLarge) {
+			response.Error(c, ecode.ErrAvatarTooLarge.WithDetails(fmt.Sprintf("the image has at most %d pixels", avatar.MaxPixels)))
+		} else {
+			response.Error(c, ecode.ErrAvatarImage)
+		}
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	// a new key for every upload, the cached images of the previous url are not shown
+	key := fmt.Sprintf("%s/%d.png", utils.Uint64ToStr(uid), time.Now().UnixNano())
+	url, err := h.avatars.Put(ctx, key, data, avatar.ContentType)
+	if err != nil {
+		logger.Error("Put avatar error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadAvatar)
+		return
+	}
+	err = h.iDao.UpdateByID(ctx, &model.User{Model: user.Model, Avatar: url})
+	if err != nil {
+		_ = h.avatars.Delete(ctx, url)
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadAvatar)
+		return
+	}
+	if user.Avatar != "" {
+		if err = h.avatars.Delete(ctx, user.Avatar); err != nil {
+			logger.Warn("Delete avatar error", logger.Err(err), logger.String("avatar", user.Avatar), middleware.GCtxRequestIDField(c))
+		}
+	}
+
+	response.Success(c, gin.H{"avatar": url})
+}
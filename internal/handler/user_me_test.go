@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"go-admin/internal/avatar"
+	"go-admin/internal/dao"
+	"go-admin/internal/model"
+)
+
+func newUserMeRouter(t *testing.T) (*gin.Engine, dao.UserDao, string) {
+	db := newBulkSQLiteDB(t)
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(context.Background(), &model.User{Name: "foo", Password: "123456", Email: "foo@example.com", Status: 2}))
+
+	dir := t.TempDir()
+	h := &userHandler{db: db, iDao: iDao, avatars: avatar.NewLocalStore(dir, "/avatars"), avatarSize: 32, avatarMaxSize: 1}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.GET("/user/me", h.GetMe)
+	r.PUT("/user/me", h.UpdateMe)
+	r.PUT("/user/me/password", h.ChangePassword)
+	r.POST("/user/me/avatar", h.UploadAvatar)
+	return r, iDao, dir
+}
+
+func doMeRequest(r http.Handler, method string, path string, uid string, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Uid", uid)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_userHandler_Me(t *testing.T) {
+	r, iDao, _ := newUserMeRouter(t)
+	ctx := context.Background()
+
+	w := doMeRequest(r, http.MethodGet, "/user/me", "", "", nil)
+	assert.Contains(t, w.Body.String(), "Unauthorized")
+	w = doMeRequest(r, http.MethodGet, "/user/me", "1", "", nil)
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+	assert.Contains(t, w.Body.String(), `"password":""`)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	// only the profile fields can be changed
+	w = doMeRequest(r, http.MethodPut, "/user/me", "1", "application/json", []byte(`{"email":"bar@example.com","phone":"123","version":1}`))
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	w = doMeRequest(r, http.MethodPut, "/user/me", "1", "application/json", []byte(`{"phone":"456","version":1}`))
+	assert.NotContains(t, w.Body.String(), `"code":0`) // version conflict
+	w = doMeRequest(r, http.MethodPut, "/user/me", "1", "application/json", []byte(`{"email":"bar"}`))
+	assert.NotContains(t, w.Body.String(), `"code":0`) // invalid email
+	user, err := iDao.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar@example.com", user.Email)
+	assert.Equal(t, "123", user.Phone)
+	assert.Equal(t, 2, user.Status)
+}
+
+func Test_userHandler_ChangePassword(t *testing.T) {
+	r, iDao, _ := newUserMeRouter(t)
+
+	w := doMeRequest(r, http.MethodPut, "/user/me/password", "1", "application/json", []byte(`{"currentPassword":"654321","newPassword":"abcdef"}`))
+	assert.Contains(t, w.Body.String(), "the current password is wrong")
+	w = doMeRequest(r, http.MethodPut, "/user/me/password", "1", "application/json", []byte(`{"currentPassword":"123456","newPassword":"123456"}`))
+	assert.NotContains(t, w.Body.String(), `"code":0`) // same password
+	w = doMeRequest(r, http.MethodPut, "/user/me/password", "1", "application/json", []byte(`{"currentPassword":"123456","newPassword":"abc"}`))
+	assert.NotContains(t, w.Body.String(), `"code":0`) // too short
+
+	w = doMeRequest(r, http.MethodPut, "/user/me/password", "1", "application/json", []byte(`{"currentPassword":"123456","newPassword":"abcdef"}`))
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	user, err := iDao.GetByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef", user.Password)
+}
+
+func Test_userHandler_UploadAvatar(t *testing.T) {
+	r, iDao, dir := newUserMeRouter(t)
+	ctx := context.Background()
+
+	upload := func(data []byte) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("file", "avatar.png")
+		_, _ = fw.Write(data)
+		_ = mw.Close()
+		return doMeRequest(r, http.MethodPost, "/user/me/avatar", "1", mw.FormDataContentType(), body.Bytes())
+	}
+
+	img := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(img, image.NewRGBA(image.Rect(0, 0, 100, 50))))
+	w := upload(img.Bytes())
+	assert.Contains(t, w.Body.String(), `"avatar":"/avatars/1/`)
+	user, err := iDao.GetByID(ctx, 1)
+	assert.NoError(t, err)
+	first := user.Avatar
+	data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(first, "/avatars/")))
+	assert.NoError(t, err)
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 32, cfg.Width)
+	assert.Equal(t, 32, cfg.Height)
+
+	// the previous avatar is deleted
+	w = upload(img.Bytes())
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	_, err = os.Stat(filepath.Join(dir, strings.TrimPrefix(first, "/avatars/")))
+	assert.True(t, os.IsNotExist(err))
+
+	w = upload([]byte("<html><body>foo</body></html>"))
+	assert.Contains(t, w.Body.String(), "the avatar must be a png, jpeg, gif or webp image")
+	w = upload(make([]byte, 2<<20))
+	assert.NotContains(t, w.Body.String(), `"code":0`) // larger than the maximum size
+}
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/sso"
+	"go-admin/internal/types"
+)
+
+const (
+	oidcStateCookie   = "oidc_state"
+	oidcStateDuration = 10 * time.Minute // time to log in at the provider
+)
+
+// oidcLogin the single sign-on of the users, the provider is discovered at the first login,
+// the service starts even if the provider is not reachable
+type oidcLogin struct {
+	cfg    config.OIDC
+	states cache.OIDCStateCache
+
+	mu       sync.Mutex
+	provider *sso.Provider
+}
+
+func newOIDCLogin(cfg config.OIDC, cacheType *model.CacheType) *oidcLogin {
+	if v := os.Getenv("OIDC_CLIENT_SECRET"); v != "" {
+		cfg.ClientSecret = v
+	}
+	return &oidcLogin{
+		cfg:    cfg,
+		states: cache.NewOIDCStateCache(cacheType),
+	}
+}
+
+func (o *oidcLogin) getProvider() (*sso.Provider, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider == nil {
+		// the context is kept by the provider to fetch the keys, it must not be the context of a request
+		p, err := sso.NewProvider(context.Background(), &sso.Options{
+			Issuer:       o.cfg.Issuer,
+			ClientID:     o.cfg.ClientID,
+			ClientSecret: o.cfg.ClientSecret,
+			RedirectURL:  o.cfg.RedirectURL,
+			Scopes:       o.cfg.Scopes,
+			GroupsClaim:  o.cfg.GroupsClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+		o.provider = p
+	}
+	return o.provider, nil
+}
+
+// roleKeys the role keys managed by the group mapping and the keys granted by the groups of the user
+func (o *oidcLogin) roleKeys(groups []string) ([]string, []string) {
+	member := map[string]bool{}
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	var managed, granted []string
+	for _, gr := range o.cfg.GroupRoles {
+		managed = append(managed, gr.RoleKey)
+		if member[gr.Group] {
+			granted = append(granted, gr.RoleKey)
+		}
+	}
+	return managed, granted
+}
+
+// OIDCLogin start a single sign-on
+// @Summary single sign-on
+// @Description redirect the browser to the OpenID Connect provider, it redirects back to the callback after the login
+// @Tags user
+// @Success 302
+// @Router /api/v1/user/oidc/login [get]
+func (h *userHandler) OIDCLogin(c *gin.Context) {
+	if h.oidc == nil || !h.oidc.cfg.Enable {
+		response.Error(c, ecode.ErrOIDCDisabled)
+		return
+	}
+
+	provider, err := h.oidc.getProvider()
+	if err != nil {
+		logger.Error("NewProvider error", logger.Err(err), logger.String("issuer", h.oidc.cfg.Issuer), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+	req, err := provider.NewAuthRequest()
+	if err != nil {
+		logger.Error("NewAuthRequest error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+	err = h.oidc.states.Set(middleware.WrapCtx(c), req.State, &cache.OIDCState{Nonce: req.Nonce, Verifier: req.Verifier}, oidcStateDuration)
+	if err != nil {
+		logger.Error("Set oidc state error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+
+	// the state is bound to the browser, a callback with the code of another browser is rejected
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, req.State, int(oidcStateDuration.Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, req.URL)
+}
+
+// OIDCCallback finish a single sign-on
+// @Summary single sign-on callback
+// @Description the provider redirects the browser here after the login, the user is linked or created and its roles are synced with its groups,
+// @Description the token is returned as json, or in the fragment of the redirect to the success url if it is configured,
+// @Description a user who must send a TOTP code gets the challenge of POST /api/v1/user/login/2fa the same way instead
+// @Tags user
+// @Produce json
+// @Param code query string true "authorization code"
+// @Param state query string true "state of the login request"
+// @Success 200 {object} types.LoginRespond{}
+// @Router /api/v1/user/oidc/callback [get]
+func (h *userHandler) OIDCCallback(c *gin.Context) {
+	if h.oidc == nil || !h.oidc.cfg.Enable {
+		response.Error(c, ecode.ErrOIDCDisabled)
+		return
+	}
+
+	form := &types.OIDCCallbackRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if form.Error != "" {
+		logger.Warn("oidc provider error", logger.String("error", form.Error), logger.String("description", form.ErrorDescription), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin.WithDetails(form.Error))
+		return
+	}
+	if form.Code == "" || form.State == "" {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/", "", c.Request.TLS != nil, true)
+	if subtle.ConstantTimeCompare([]byte(cookie), []byte(form.State)) != 1 {
+		logger.Warn("oidc state does not match the cookie", middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	state, err := h.oidc.states.Take(ctx, form.State)
+	if err != nil {
+		logger.Warn("Take oidc state error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+	provider, err := h.oidc.getProvider()
+	if err != nil {
+		logger.Error("NewProvider error", logger.Err(err), logger.String("issuer", h.oidc.cfg.Issuer), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+	claims, err := provider.Exchange(ctx, form.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		logger.Warn("Exchange error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrOIDCLogin)
+		return
+	}
+
+	managed, granted := h.oidc.roleKeys(claims.Groups)
+	user, err := h.provisionUser(ctx, &externalAccount{
+		Issuer:          provider.Issuer(),
+		Subject:         claims.Subject,
+		Username:        claims.Username,
+		Email:           claims.Email,
+		EmailVerified:   claims.EmailVerified,
+		ManagedRoleKeys: managed,
+		RoleKeys:        granted,
+	}, provisionPolicy{AutoCreate: h.oidc.cfg.AutoCreate, LinkByEmail: h.oidc.cfg.LinkByEmail})
+	if err != nil {
+		if errors.Is(err, errUserNotLinked) {
+			logger.Warn("provisionUser not linked", logger.String("subject", claims.Subject), logger.String("email", claims.Email), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUserNotLinked)
+		} else {
+			logger.Error("provisionUser error", logger.Err(err), logger.String("subject", claims.Subject), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrOIDCLogin)
+		}
+		return
+	}
+
+	history := &model.LoginHistory{UserID: user.ID, Name: user.Name, IP: c.ClientIP(), UserAgent: truncate(c.Request.UserAgent(), 255)}
+	if user.Status == model.UserStatusBlocked {
+		h.recordLogin(c, history, model.LoginResultBlocked)
+		response.Error(c, ecode.ErrUserBlocked)
+		return
+	}
+	if h.startTwoFactor(c, user, history, h.respondOIDCChallenge) {
+		return
+	}
+	if h.oidc.cfg.SuccessURL == "" {
+		h.completeLogin(c, user, history, gin.H{})
+		return
+	}
+
+	token, ok := h.issueLoginToken(c, user, history)
+	if !ok {
+		return
+	}
+	// the fragment is not sent to the servers, the token does not appear in their logs
+	c.Redirect(http.StatusFound, h.oidc.cfg.SuccessURL+"#token="+url.QueryEscape(token))
+}
+
+// respondOIDCChallenge pass the challenge of the login to the success url, the frontend sends the code to
+// POST /api/v1/user/login/2fa and gets the QR code of a secret to enroll from GET /api/v1/user/login/2fa/qrcode
+func (h *userHandler) respondOIDCChallenge(c *gin.Context, reply *types.TwoFactorChallenge) {
+	if h.oidc.cfg.SuccessURL == "" {
+		respondChallenge(c, reply)
+		return
+	}
+	fragment := url.Values{}
+	fragment.Set("challenge", reply.Challenge)
+	fragment.Set("expiresIn", strconv.Itoa(reply.ExpiresIn))
+	if reply.Enroll {
+		fragment.Set("enroll", "true")
+	}
+	c.Redirect(http.StatusFound, h.oidc.cfg.SuccessURL+"#"+fragment.Encode())
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/model"
+	"go-admin/internal/sso/ssotest"
+)
+
+type oidcTestEnv struct {
+	r      *gin.Engine
+	iss    *ssotest.Issuer
+	h      *userHandler
+	iDao   dao.UserDao
+	urDao  dao.UserRoleDao
+	client *http.Client
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	iss := ssotest.NewIssuer()
+	t.Cleanup(iss.Close)
+
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(ctx, &model.User{Name: "foo", Password: "123456", Email: "foo@example.com", Status: model.UserStatusActivated}))
+	roleDao := dao.NewRoleDao(db, nil)
+	for _, key := range []string{"admin", "viewer", "auditor"} {
+		assert.NoError(t, roleDao.Create(ctx, &model.Role{RoleName: key, RoleKey: key}))
+	}
+
+	cfg := config.OIDC{
+		Enable:      true,
+		Issuer:      iss.URL,
+		ClientID:    ssotest.ClientID,
+		RedirectURL: "http://localhost/user/oidc/callback",
+		AutoCreate:  true,
+		LinkByEmail: true,
+		GroupRoles: []config.GroupRole{
+			{Group: "admins", RoleKey: "admin"},
+			{Group: "staff", RoleKey: "viewer"},
+		},
+	}
+	h := &userHandler{
+		db:       db,
+		iDao:     iDao,
+		urDao:    dao.NewUserRoleDao(db),
+		idDao:    dao.NewUserIdentityDao(db),
+		lhDao:    dao.NewLoginHistoryDao(db),
+		attempts: cache.NewLoginAttemptCache(&model.CacheType{CType: "memory"}),
+		sessions: cache.NewSessionCache(&model.CacheType{CType: "memory"}),
+		oidc:     newOIDCLogin(cfg, &model.CacheType{CType: "memory"}),
+
+		totpDao:    dao.NewUserTOTPDao(db),
+		challenges: cache.NewLoginChallengeCache(&model.CacheType{CType: "memory"}),
+		twoFactor:  newTwoFactorConfig(config.TwoFactor{}),
+	}
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/user/oidc/login", h.OIDCLogin)
+	r.GET("/user/oidc/callback", h.OIDCCallback)
+
+	return &oidcTestEnv{
+		r:      r,
+		iss:    iss,
+		h:      h,
+		iDao:   iDao,
+		urDao:  h.urDao,
+		client: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }},
+	}
+}
+
+// login start the login, log in at the issuer with the claims and return the response of the callback
+func (e *oidcTestEnv) login(t *testing.T, claims map[string]interface{}) *httptest.ResponseRecorder {
+	e.iss.Claims = claims
+
+	w := httptest.NewRecorder()
+	e.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/oidc/login", nil))
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	resp, err := e.client.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, req)
+	return w
+}
+
+func (e *oidcTestEnv) roleKeys(t *testing.T, userID uint64) []string {
+	roles, err := e.urDao.GetRolesByUserID(context.Background(), userID)
+	require.NoError(t, err)
+	keys := []string{}
+	for _, role := range roles {
+		keys = append(keys, role.RoleKey)
+	}
+	return keys
+}
+
+func Test_userHandler_OIDC_Provisioning(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+
+	// the first login creates the user with the roles of its groups
+	w := e.login(t, map[string]interface{}{
+		"sub": "u-1", "preferred_username": "alice", "email": "alice@example.com", "email_verified": true,
+		"groups": []string{"admins", "staff", "others"},
+	})
+	assert.Contains(t, w.Body.String(), `"token"`)
+	assert.Contains(t, w.Body.String(), `"name":"alice"`)
+	alice, err := e.iDao.GetByName(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", alice.Email)
+	assert.Equal(t, model.UserStatusActivated, alice.Status)
+	assert.NotZero(t, alice.LoginAt)
+	assert.ElementsMatch(t, []string{"admin", "viewer"}, e.roleKeys(t, alice.ID))
+
+	// the roles are synced at the next login, the roles that are not mapped are kept
+	assert.NoError(t, e.urDao.SetUserRoles(ctx, alice.ID, []uint64{1, 3}))
+	w = e.login(t, map[string]interface{}{"sub": "u-1", "preferred_username": "renamed", "groups": []string{"staff"}})
+	assert.Contains(t, w.Body.String(), `"name":"alice"`)
+	assert.ElementsMatch(t, []string{"viewer", "auditor"}, e.roleKeys(t, alice.ID))
+
+	// a verified email links the local user
+	w = e.login(t, map[string]interface{}{"sub": "u-2", "email": "foo@example.com", "email_verified": true})
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+	assert.Contains(t, w.Body.String(), `"id":"1"`)
+	w = e.login(t, map[string]interface{}{"sub": "u-2", "email": "foo@example.com", "email_verified": true})
+	assert.Contains(t, w.Body.String(), `"id":"1"`)
+
+	// an email that is not verified is not linked, the taken name gets a suffix
+	w = e.login(t, map[string]interface{}{"sub": "u-3", "preferred_username": "foo", "email": "foo@example.com"})
+	assert.Contains(t, w.Body.String(), `"token"`)
+	assert.Contains(t, w.Body.String(), `"name":"foo-`)
+	assert.NotContains(t, w.Body.String(), `"id":"1"`)
+
+	// the users are not created
+	e.h.oidc.cfg.AutoCreate = false
+	w = e.login(t, map[string]interface{}{"sub": "u-4", "preferred_username": "bob"})
+	assert.Contains(t, w.Body.String(), "no user is linked to the account of the identity provider")
+
+	// a blocked user can not log in
+	assert.NoError(t, e.iDao.UpdateByID(ctx, &model.User{Model: alice.Model, Status: model.UserStatusBlocked}))
+	w = e.login(t, map[string]interface{}{"sub": "u-1"})
+	assert.Contains(t, w.Body.String(), "is blocked")
+
+	// the token is passed to the success url
+	e.h.oidc.cfg.SuccessURL = "http://localhost/#/login"
+	w = e.login(t, map[string]interface{}{"sub": "u-2"})
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Location"), "http://localhost/#/login#token="))
+}
+
+func Test_userHandler_OIDC_TwoFactor(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "go-admin", AccountName: "foo"})
+	require.NoError(t, err)
+	require.NoError(t, e.h.totpDao.Enable(context.Background(), 1, key.Secret(), 0, nil))
+
+	// the user who enabled the TOTP gets a challenge instead of the token
+	claims := map[string]interface{}{"sub": "u-1", "email": "foo@example.com", "email_verified": true}
+	w := e.login(t, claims)
+	assert.Contains(t, w.Body.String(), `"challenge"`)
+	assert.NotContains(t, w.Body.String(), `"token"`)
+
+	e.h.oidc.cfg.SuccessURL = "http://localhost/#/login"
+	w = e.login(t, claims)
+	assert.Equal(t, http.StatusFound, w.Code)
+	location := w.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "http://localhost/#/login#challenge="), location)
+	assert.NotContains(t, location, "token=")
+}
+
+func Test_userHandler_OIDC_State(t *testing.T) {
+	e := newOIDCTestEnv(t)
+
+	w := httptest.NewRecorder()
+	e.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/oidc/login", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	cookie := w.Result().Cookies()[0]
+	resp, err := e.client.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	// the callback of another browser is rejected
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil))
+	assert.Contains(t, w.Body.String(), "single sign-on failed")
+
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), `"token"`)
+
+	// the state is used once
+	req = httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), "single sign-on failed")
+
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/oidc/callback?error=access_denied", nil))
+	assert.Contains(t, w.Body.String(), "access_denied")
+
+	e.h.oidc.cfg.Enable = false
+	w = httptest.NewRecorder()
+	e.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/oidc/login", nil))
+	assert.Contains(t, w.Body.String(), "single sign-on is not enabled")
+}
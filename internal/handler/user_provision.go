@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm/query"
+
+	"go-admin/internal/model"
+)
+
+// errUserNotLinked no user is linked to the external account and none is created
+var errUserNotLinked = errors.New("no user is linked to the external account")
+
+// externalAccount an account authenticated by an external identity provider, e.g. OpenID Connect or LDAP
+type externalAccount struct {
+	Issuer        string // identifies the provider, the subject is unique at the issuer
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+
+	ManagedRoleKeys []string // the role keys synced with the provider
+	RoleKeys        []string // the role keys granted by the provider, a subset of the managed keys
+}
+
+// provisionPolicy how the first login of an external account gets its user
+type provisionPolicy struct {
+	AutoCreate  bool // create a user if none is linked
+	LinkByEmail bool // link the local user with the same verified email
+	LinkByName  bool // link the local user with the same name
+}
+
+// provisionUser get the user linked to the external account, the first login is linked to a local user
+// or creates a user by the policy, then the managed roles of the user are synced with the account
+func (h *userHandler) provisionUser(ctx context.Context, acc *externalAccount, policy provisionPolicy) (*model.User, error) {
+	var user *model.User
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		identity, err := h.idDao.GetBySubjectByTx(ctx, tx, acc.Issuer, acc.Subject)
+		switch {
+		case err == nil:
+			user, err = h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "id", Value: identity.UserID}}})
+			if err != nil {
+				if errors.Is(err, model.ErrRecordNotFound) { // the user is deleted
+					return errUserNotLinked
+				}
+				return err
+			}
+			if identity.Email != accountEmail(acc) {
+				if err = h.idDao.UpdateEmailByTx(ctx, tx, identity.ID, accountEmail(acc)); err != nil {
+					return err
+				}
+			}
+
+		case errors.Is(err, model.ErrRecordNotFound):
+			user, err = h.linkUser(ctx, tx, acc, policy)
+			if err != nil {
+				return err
+			}
+			_, err = h.idDao.CreateByTx(ctx, tx, &model.UserIdentity{
+				UserID:  user.ID,
+				Issuer:  acc.Issuer,
+				Subject: acc.Subject,
+				Email:   accountEmail(acc),
+			})
+			if err != nil {
+				return err
+			}
+
+		default:
+			return err
+		}
+
+		return h.urDao.SyncRolesByKeysByTx(ctx, tx, user.ID, acc.ManagedRoleKeys, acc.RoleKeys)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// linkUser get the local user with the same name or verified email as the account, or create a user
+func (h *userHandler) linkUser(ctx context.Context, tx *gorm.DB, acc *externalAccount, policy provisionPolicy) (*model.User, error) {
+	if policy.LinkByName && acc.Username != "" {
+		user, err := h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "name", Value: acc.Username}}})
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, model.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	email := accountEmail(acc)
+	if policy.LinkByEmail && acc.EmailVerified && email != "" {
+		user, err := h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{{Name: "email", Value: email}}})
+		if err == nil {
+			// the email must identify a single user, a shared email is not linked to any of them
+			_, err = h.iDao.GetByConditionByTx(ctx, tx, &query.Conditions{Columns: []query.Column{
+				{Name: "email", Value: email},
+				{Name: "id", Exp: "!=", Value: user.ID},
+			}})
+			if err == nil {
+				return nil, errUserNotLinked
+			}
+			if !errors.Is(err, model.ErrRecordNotFound) {
+				return nil, err
+			}
+			return user, nil
+		}
+		if !errors.Is(err, model.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if !policy.AutoCreate {
+		return nil, errUserNotLinked
+	}
+
+	password, err := randomHex(16) // the external users do not log in with a local password
+	if err != nil {
+		return nil, err
+	}
+	name := accountUserName(acc)
+	for i := 0; ; i++ {
+		user := &model.User{Name: name, Password: password, Email: email, Status: model.UserStatusActivated}
+		// a savepoint, the transaction goes on if the name is taken
+		err = tx.Transaction(func(tx *gorm.DB) error {
+			_, err := h.iDao.CreateByTx(ctx, tx, user)
+			return err
+		})
+		if err == nil {
+			return user, nil
+		}
+		if i == 2 {
+			return nil, err
+		}
+		suffix, err := randomHex(4)
+		if err != nil {
+			return nil, err
+		}
+		name = truncate(accountUserName(acc), 41) + "-" + suffix
+	}
+}
+
+// accountUserName the name of a created user, the username, the local part of the email or the subject
+func accountUserName(acc *externalAccount) string {
+	name := acc.Username
+	if name == "" {
+		name, _, _ = strings.Cut(acc.Email, "@")
+	}
+	if name == "" {
+		name = acc.Subject
+	}
+	return truncate(name, 50)
+}
+
+// accountEmail the email of the account if it fits the column
+func accountEmail(acc *externalAccount) string {
+	if len(acc.Email) > 50 {
+		return ""
+	}
+	return acc.Email
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/jwt"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/session"
+	"go-admin/internal/types"
+)
+
+// issueToken generate the jwt of a login and record its session, the jwt is rejected once the session is revoked
+func (h *userHandler) issueToken(c *gin.Context, user *model.User) (string, error) {
+	token, err := jwt.GenerateToken(utils.Uint64ToStr(user.ID), user.Name)
+	if err != nil {
+		return "", err
+	}
+	s, err := session.New(token, user.ID, c.ClientIP(), truncate(c.Request.UserAgent(), 255))
+	if err != nil {
+		return "", err
+	}
+	err = h.sessions.Set(middleware.WrapCtx(c), s)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Logout revoke the session of the request
+// @Summary logout
+// @Description revoke the session of the jwt of the request, the jwt is rejected afterwards.
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.LogoutRespond{}
+// @Router /api/v1/user/logout [post]
+// @Security BearerAuth
+func (h *userHandler) Logout(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	id := c.GetString(session.ContextKey)
+	if id == "" { // e.g. a request authenticated by an api token
+		response.Error(c, ecode.ErrSessionNotFound)
+		return
+	}
+	h.revokeSession(c, uid, id)
+}
+
+// ListSessions list the sessions of the authenticated user
+// @Summary list my sessions
+// @Description list the active login sessions of the user who sent the request with their devices and addresses, the latest first.
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.ListSessionsRespond{}
+// @Router /api/v1/user/me/sessions [get]
+// @Security BearerAuth
+func (h *userHandler) ListSessions(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	h.listSessions(c, uid)
+}
+
+// RevokeSession revoke a session of the authenticated user
+// @Summary revoke my session
+// @Description revoke a login session of the user who sent the request, e.g. of a lost device, its jwt is rejected at once.
+// @Tags user
+// @Param sessionID path string true "session id"
+// @Produce json
+// @Success 200 {object} types.RevokeSessionRespond{}
+// @Router /api/v1/user/me/sessions/{sessionID} [delete]
+// @Security BearerAuth
+func (h *userHandler) RevokeSession(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	h.revokeSession(c, uid, c.Param("sessionID"))
+}
+
+// ListUserSessions list the sessions of a user
+// @Summary list the sessions of a user
+// @Description list the active login sessions of a user with their devices and addresses, the latest first.
+// @Tags user
+// @Param id path string true "id"
+// @Produce json
+// @Success 200 {object} types.ListSessionsRespond{}
+// @Router /api/v1/user/{id}/sessions [get]
+// @Security BearerAuth
+func (h *userHandler) ListUserSessions(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	h.listSessions(c, id)
+}
+
+// ForceLogout revoke all the sessions of a user
+// @Summary force the logout of a user
+// @Description revoke all the login sessions of a user, the jwt of the user are rejected at once. the api tokens are not revoked.
+// @Tags user
+// @Param id path string true "id"
+// @Produce json
+// @Success 200 {object} types.ForceLogoutRespond{}
+// @Router /api/v1/user/{id}/sessions [delete]
+// @Security BearerAuth
+func (h *userHandler) ForceLogout(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	callerID, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	err := h.sessions.DelAll(middleware.WrapCtx(c), id)
+	if err != nil {
+		logger.Error("DelAll error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	logger.Info("user forced to log out", logger.Any("id", id), logger.Any("by", callerID), middleware.GCtxRequestIDField(c))
+
+	response.Success(c)
+}
+
+func (h *userHandler) listSessions(c *gin.Context, userID uint64) {
+	sessions, err := h.sessions.List(middleware.WrapCtx(c), userID)
+	if err != nil {
+		logger.Error("List sessions error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	current := c.GetString(session.ContextKey)
+	data := make([]*types.SessionObjDetail, 0, len(sessions))
+	for _, s := range sessions {
+		data = append(data, &types.SessionObjDetail{
+			ID:         s.ID,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == current,
+		})
+	}
+	response.Success(c, gin.H{"sessions": data})
+}
+
+// revokeSession delete the session if it belongs to the user
+func (h *userHandler) revokeSession(c *gin.Context, userID uint64, id string) {
+	ctx := middleware.WrapCtx(c)
+	_, err := h.sessions.Get(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, cache.ErrSessionNotFound) {
+			response.Error(c, ecode.ErrSessionNotFound)
+		} else {
+			logger.Error("Get session error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	err = h.sessions.Del(ctx, userID, id)
+	if err != nil {
+		logger.Error("Del session error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	logger.Info("session revoked", logger.Any("id", userID), logger.String("sessionID", id), middleware.GCtxRequestIDField(c))
+
+	response.Success(c)
+}
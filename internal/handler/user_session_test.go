@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/session"
+	"go-admin/internal/types"
+)
+
+// newUserSessionRouter the user foo (id 1) logs in with the password 123456, the routes of the user
+// are authenticated by middleware.Auth with the verification of the sessions
+func newUserSessionRouter(t *testing.T) (*gin.Engine, cache.SessionCache) {
+	r, iDao := newUserLoginRouter(t, config.Login{})
+	sessions := cache.NewSessionCache(&model.CacheType{CType: "memory"})
+	require.NoError(t, sessions.DelAll(context.Background(), 1))
+
+	h := &userHandler{iDao: iDao, sessions: sessions}
+	auth := middleware.Auth(middleware.WithVerify(session.Verify(sessions)))
+	r.GET("/user/me", auth, h.GetMe)
+	r.POST("/user/logout", auth, h.Logout)
+	r.GET("/user/me/sessions", auth, h.ListSessions)
+	r.DELETE("/user/me/sessions/:sessionID", auth, h.RevokeSession)
+	r.GET("/user/:id/sessions", auth, h.ListUserSessions)
+	r.DELETE("/user/:id/sessions", auth, h.ForceLogout)
+	return r, sessions
+}
+
+func loginToken(t *testing.T, r http.Handler) string {
+	w := doLogin(r, "10.0.0.1", "foo", "123456")
+	reply := &struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	require.NotEmpty(t, reply.Data.Token, w.Body.String())
+	return reply.Data.Token
+}
+
+func listSessionsReply(t *testing.T, r http.Handler, path string, credential string) []types.SessionObjDetail {
+	w := doTokenRequest(r, http.MethodGet, path, credential, "")
+	reply := &struct {
+		Data struct {
+			Sessions []types.SessionObjDetail `json:"sessions"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	return reply.Data.Sessions
+}
+
+func Test_userHandler_Sessions(t *testing.T) {
+	r, sessions := newUserSessionRouter(t)
+	ctx := context.Background()
+	token := loginToken(t, r)
+	now := time.Now().Unix()
+	other := &cache.Session{ID: "other", UserID: 1, IP: "10.0.0.9", UserAgent: "phone", CreatedAt: now - 60, ExpiresAt: now + 3600}
+	require.NoError(t, sessions.Set(ctx, other))
+
+	// the login records the session of its jwt with the device and the address
+	list := listSessionsReply(t, r, "/user/me/sessions", token)
+	require.Len(t, list, 2)
+	assert.Equal(t, session.ID(token), list[0].ID)
+	assert.True(t, list[0].Current)
+	assert.Equal(t, "10.0.0.1", list[0].IP)
+	assert.Equal(t, "test-agent", list[0].UserAgent)
+	assert.NotZero(t, list[0].ExpiresAt)
+	assert.Equal(t, "other", list[1].ID)
+	assert.False(t, list[1].Current)
+
+	w := doTokenRequest(r, http.MethodDelete, "/user/me/sessions/unknown", token, "")
+	assert.Contains(t, w.Body.String(), ecode.ErrSessionNotFound.Msg())
+	w = doTokenRequest(r, http.MethodDelete, "/user/me/sessions/other", token, "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	assert.Len(t, listSessionsReply(t, r, "/user/1/sessions", token), 1)
+
+	// the jwt of a revoked session is rejected
+	w = doTokenRequest(r, http.MethodGet, "/user/me", token, "")
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+	w = doTokenRequest(r, http.MethodPost, "/user/logout", token, "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doTokenRequest(r, http.MethodGet, "/user/me", token, "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+}
+
+func Test_userHandler_ForceLogout(t *testing.T) {
+	r, sessions := newUserSessionRouter(t)
+	token := loginToken(t, r)
+	now := time.Now().Unix()
+	require.NoError(t, sessions.Set(context.Background(), &cache.Session{ID: "other", UserID: 1, ExpiresAt: now + 3600}))
+
+	w := doTokenRequest(r, http.MethodDelete, "/user/1/sessions", "", "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+	w = doTokenRequest(r, http.MethodDelete, "/user/1/sessions", token, "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	list, err := sessions.List(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	w = doTokenRequest(r, http.MethodGet, "/user/me/sessions", token, "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+
+	// a new login is accepted again
+	token = loginToken(t, r)
+	w = doTokenRequest(r, http.MethodGet, "/user/me", token, "")
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 
 	"go-admin/internal/cache"
 	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
 	"go-admin/internal/model"
 	"go-admin/internal/types"
 )
@@ -45,22 +47,22 @@ func newUserHandler() *gotest.Handler {
 
 	testFns := []gotest.RouterInfo{
 		{
-			FuncName:    "Create",
+			FuncName:    "Register",
 			Method:      http.MethodPost,
-			Path:        "/user",
-			HandlerFunc: iHandler.Create,
+			Path:        "/user/reg",
+			HandlerFunc: iHandler.Register,
 		},
 		{
 			FuncName:    "DeleteByID",
 			Method:      http.MethodDelete,
 			Path:        "/user/:id",
-			HandlerFunc: iHandler.DeleteByID,
+			HandlerFunc: withCaller("1", iHandler.DeleteByID),
 		},
 		{
 			FuncName:    "DeleteByIDs",
 			Method:      http.MethodPost,
 			Path:        "/user/delete/ids",
-			HandlerFunc: iHandler.DeleteByIDs,
+			HandlerFunc: withCaller("1", iHandler.DeleteByIDs),
 		},
 		{
 			FuncName:    "UpdateByID",
@@ -68,6 +70,12 @@ func newUserHandler() *gotest.Handler {
 			Path:        "/user/:id",
 			HandlerFunc: iHandler.UpdateByID,
 		},
+		{
+			FuncName:    "PatchByID",
+			Method:      http.MethodPatch,
+			Path:        "/user/:id",
+			HandlerFunc: iHandler.PatchByID,
+		},
 		{
 			FuncName:    "GetByID",
 			Method:      http.MethodGet,
@@ -98,6 +106,24 @@ func newUserHandler() *gotest.Handler {
 			Path:        "/user/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "ListTrash",
+			Method:      http.MethodPost,
+			Path:        "/user/trash/list",
+			HandlerFunc: iHandler.ListTrash,
+		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/user/trash/:id/restore",
+			HandlerFunc: iHandler.Restore,
+		},
+		{
+			FuncName:    "Purge",
+			Method:      http.MethodDelete,
+			Path:        "/user/trash/:id",
+			HandlerFunc: iHandler.Purge,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -106,7 +132,7 @@ func newUserHandler() *gotest.Handler {
 	return h
 }
 
-func Test_userHandler_Create(t *testing.T) {
+func Test_userHandler_Register(t *testing.T) {
 	h := newUserHandler()
 	defer h.Close()
 	testData := &types.CreateUserRequest{}
@@ -120,7 +146,7 @@ func Test_userHandler_Create(t *testing.T) {
 	h.MockDao.SQLMock.ExpectCommit()
 
 	result := &gohttp.StdResult{}
-	err := gohttp.Post(result, h.GetRequestURL("Create"), testData)
+	err := gohttp.Post(result, h.GetRequestURL("Register"), testData)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -136,7 +162,7 @@ func Test_userHandler_DeleteByID(t *testing.T) {
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, 1, h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -165,7 +191,7 @@ func Test_userHandler_DeleteByIDs(t *testing.T) {
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, 1, h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -192,12 +218,21 @@ func Test_userHandler_UpdateByID(t *testing.T) {
 	defer h.Close()
 	testData := &types.UpdateUserByIDRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.User))
+	testData.Version = 1
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, testData.ID, testData.Version). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
+	// the version has been changed by another update
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(h.MockDao.AnyTime, testData.ID, testData.Version).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
 	result := &gohttp.StdResult{}
 	err := gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
@@ -208,6 +243,17 @@ func Test_userHandler_UpdateByID(t *testing.T) {
 		t.Fatalf("%+v", result)
 	}
 
+	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrUserVersionConflict.Code(), result.Code)
+
+	// missing version error test
+	testData.Version = 0
+	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	testData.Version = 1
+
 	// zero id error test
 	err = gohttp.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
 	assert.NoError(t, err)
@@ -217,13 +263,52 @@ func Test_userHandler_UpdateByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_userHandler_PatchByID(t *testing.T) {
+	h := newUserHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.User)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs("", 0, h.MockDao.AnyTime, testData.ID, 1). // the zero values are updated
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"avatar": nil, "status": 0, "version": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// unknown field error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"foo": 1, "version": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// missing version error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"age": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// zero id error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", 0), map[string]interface{}{"avatar": nil, "status": 0, "version": 1})
+	assert.NoError(t, err)
+
+	// update error test
+	err = gohttp.Patch(result, h.GetRequestURL("PatchByID", 111), map[string]interface{}{"avatar": nil, "status": 0, "version": 1})
+	assert.Error(t, err)
+}
+
 func Test_userHandler_GetByID(t *testing.T) {
 	h := newUserHandler()
 	defer h.Close()
 	testData := h.TestData.(*model.User)
 
-	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
-		AddRow(testData.ID, testData.CreatedAt, testData.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "password", "created_at", "updated_at"}).
+		AddRow(testData.ID, "secret", testData.CreatedAt, testData.UpdatedAt)
 
 	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
 		WithArgs(testData.ID).
@@ -237,6 +322,8 @@ func Test_userHandler_GetByID(t *testing.T) {
 	if result.Code != 0 {
 		t.Fatalf("%+v", result)
 	}
+	data, _ := json.Marshal(result.Data)
+	assert.Contains(t, string(data), `"password":""`) // the password is not returned
 
 	// zero id error test
 	err = gohttp.Get(result, h.GetRequestURL("GetByID", 0))
@@ -259,7 +346,7 @@ func Test_userHandler_GetByCondition(t *testing.T) {
 
 	result := &gohttp.StdResult{}
 	err := gohttp.Post(result, h.GetRequestURL("GetByCondition"), &types.GetUserByConditionRequest{
-		query.Conditions{
+		Conditions: query.Conditions{
 			Columns: []query.Column{
 				{
 					Name:  "id",
@@ -281,7 +368,7 @@ func Test_userHandler_GetByCondition(t *testing.T) {
 
 	// get error test
 	err = gohttp.Post(result, h.GetRequestURL("GetByCondition"), &types.GetUserByConditionRequest{
-		query.Conditions{
+		Conditions: query.Conditions{
 			Columns: []query.Column{
 				{
 					Name:  "id",
@@ -355,7 +442,7 @@ func Test_userHandler_List(t *testing.T) {
 	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 
 	result := &gohttp.StdResult{}
-	err := gohttp.Post(result, h.GetRequestURL("List"), &types.ListUsersRequest{query.Params{
+	err := gohttp.Post(result, h.GetRequestURL("List"), &types.ListUsersRequest{Params: query.Params{
 		Page: 0,
 		Size: 10,
 		Sort: "ignore count", // ignore test count
@@ -372,7 +459,43 @@ func Test_userHandler_List(t *testing.T) {
 	assert.NoError(t, err)
 
 	// get error test
-	err = gohttp.Post(result, h.GetRequestURL("List"), &types.ListUsersRequest{query.Params{
+	err = gohttp.Post(result, h.GetRequestURL("List"), &types.ListUsersRequest{Params: query.Params{
+		Page: 0,
+		Size: 10,
+		Sort: "unknown-column",
+	}})
+	assert.Error(t, err)
+}
+
+func Test_userHandler_ListTrash(t *testing.T) {
+	h := newUserHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.User)
+
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "deleted_by"}).
+		AddRow(testData.ID, testData.CreatedAt, testData.UpdatedAt, testData.UpdatedAt, 1)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Post(result, h.GetRequestURL("ListTrash"), &types.ListTrashUsersRequest{Params: query.Params{
+		Page: 0,
+		Size: 10,
+		Sort: "ignore count", // ignore test count
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// nil params error test
+	err = gohttp.Post(result, h.GetRequestURL("ListTrash"), nil)
+	assert.NoError(t, err)
+
+	// get error test
+	err = gohttp.Post(result, h.GetRequestURL("ListTrash"), &types.ListTrashUsersRequest{Params: query.Params{
 		Page: 0,
 		Size: 10,
 		Sort: "unknown-column",
@@ -380,6 +503,71 @@ func Test_userHandler_List(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_userHandler_Restore(t *testing.T) {
+	h := newUserHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.User)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(testData.ID, testData.UpdatedAt))
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = gohttp.Post(result, h.GetRequestURL("Restore", 0), nil)
+	assert.NoError(t, err)
+
+	// restore error test
+	err = gohttp.Post(result, h.GetRequestURL("Restore", 111), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
+func Test_userHandler_Purge(t *testing.T) {
+	h := newUserHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.User)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("DELETE .*").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE .*").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &gohttp.StdResult{}
+	err := gohttp.Delete(result, h.GetRequestURL("Purge", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = gohttp.Delete(result, h.GetRequestURL("Purge", 0))
+	assert.NoError(t, err)
+
+	// purge error test
+	err = gohttp.Delete(result, h.GetRequestURL("Purge", 111))
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
 func TestNewUserHandler(t *testing.T) {
 	defer func() {
 		recover()
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/apitoken"
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+const day = 24 * 60 * 60 // seconds
+
+func newApiTokenConfig(cfg config.ApiToken) config.ApiToken {
+	if cfg.DefaultLifetime <= 0 {
+		cfg.DefaultLifetime = 90
+	}
+	if cfg.MaxLifetime < 0 {
+		cfg.MaxLifetime = 0
+	}
+	if cfg.MaxLifetime > 0 && cfg.DefaultLifetime > cfg.MaxLifetime {
+		cfg.DefaultLifetime = cfg.MaxLifetime
+	}
+	if cfg.MaxPerUser <= 0 {
+		cfg.MaxPerUser = 20
+	}
+	return cfg
+}
+
+// apiTokenExpiresAt the unix time a token created at now with the lifetime in days expires, 0 if it never expires,
+// false if the lifetime exceeds the maximum
+func (h *userHandler) apiTokenExpiresAt(now int64, expiresIn int) (int64, bool) {
+	switch {
+	case expiresIn == 0:
+		expiresIn = h.apiToken.DefaultLifetime
+	case expiresIn < 0:
+		return 0, h.apiToken.MaxLifetime == 0
+	}
+	if h.apiToken.MaxLifetime > 0 && expiresIn > h.apiToken.MaxLifetime {
+		return 0, false
+	}
+	return now + int64(expiresIn)*day, true
+}
+
+// isApiTokenRequest whether the request is authenticated by a personal api token instead of a jwt,
+// a leaked token must not be able to create more tokens or to hide itself
+func isApiTokenRequest(c *gin.Context) bool {
+	return c.GetUint64(apitoken.ContextKey) != 0
+}
+
+// CreateApiToken create a personal api token of the authenticated user
+// @Summary create an api token
+// @Description create a personal api token for the automations, the token is returned once and only its hash is stored.
+// @Description it is sent as the header "Authorization: Bearer <token>" and only calls the apis of apiIds as the user.
+// @Description the requests authenticated by an api token can not create tokens.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.CreateApiTokenRequest true "token information"
+// @Success 200 {object} types.CreateApiTokenRespond{}
+// @Router /api/v1/user/me/tokens [post]
+// @Security BearerAuth
+func (h *userHandler) CreateApiToken(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	if isApiTokenRequest(c) {
+		response.Error(c, ecode.ErrApiTokenForbidden)
+		return
+	}
+	form := &types.CreateApiTokenRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	now := time.Now().Unix()
+	expiresAt, ok := h.apiTokenExpiresAt(now, form.ExpiresIn)
+	if !ok {
+		response.Error(c, ecode.ErrApiTokenLifetime)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	apiIDs := uniqueIDs(form.ApiIDs)
+	apis, err := h.apiDao.GetByIDs(ctx, apiIDs)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if len(apis) != len(apiIDs) {
+		response.Error(c, ecode.ErrApiTokenScope)
+		return
+	}
+
+	count, err := h.tokenDao.CountActive(ctx, uid, now)
+	if err != nil {
+		logger.Error("CountActive error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if count >= int64(h.apiToken.MaxPerUser) {
+		response.Error(c, ecode.ErrApiTokenLimit)
+		return
+	}
+
+	token, hash, err := apitoken.Generate()
+	if err != nil {
+		logger.Error("Generate error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateApiToken)
+		return
+	}
+	record := &model.ApiToken{UserID: uid, Name: form.Name, Hint: apitoken.Hint(token), TokenHash: hash, ExpiresAt: expiresAt}
+	err = h.tokenDao.Create(ctx, record, apiIDs)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateApiToken)
+		return
+	}
+	logger.Info("api token created", logger.Any("id", uid), logger.Any("tokenID", record.ID), middleware.GCtxRequestIDField(c))
+
+	scopes := make([]*model.Api, 0, len(apiIDs))
+	for _, id := range apiIDs {
+		scopes = append(scopes, apis[id])
+	}
+	response.Success(c, gin.H{"token": token, "apiToken": convertApiToken(record, scopes, now)})
+}
+
+// ListApiTokens list the personal api tokens of the authenticated user
+// @Summary list my api tokens
+// @Description list the api tokens of the user who sent the request, including the revoked and expired ones, the latest first.
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.ListApiTokensRespond{}
+// @Router /api/v1/user/me/tokens [get]
+// @Security BearerAuth
+func (h *userHandler) ListApiTokens(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	h.listApiTokens(c, uid)
+}
+
+// RevokeApiToken revoke a personal api token of the authenticated user
+// @Summary revoke my api token
+// @Description revoke an api token of the user who sent the request, the requests sent with it are unauthorized at once.
+// @Tags user
+// @Param tokenID path string true "token id"
+// @Produce json
+// @Success 200 {object} types.RevokeApiTokenRespond{}
+// @Router /api/v1/user/me/tokens/{tokenID} [delete]
+// @Security BearerAuth
+func (h *userHandler) RevokeApiToken(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	if isApiTokenRequest(c) {
+		response.Error(c, ecode.ErrApiTokenForbidden)
+		return
+	}
+	h.revokeApiToken(c, uid, uid)
+}
+
+// ListUserApiTokens list the personal api tokens of a user
+// @Summary list the api tokens of a user
+// @Description list the api tokens of a user, including the revoked and expired ones, the latest first.
+// @Tags user
+// @Param id path string true "id"
+// @Produce json
+// @Success 200 {object} types.ListApiTokensRespond{}
+// @Router /api/v1/user/{id}/tokens [get]
+// @Security BearerAuth
+func (h *userHandler) ListUserApiTokens(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	h.listApiTokens(c, id)
+}
+
+// RevokeUserApiToken revoke a personal api token of a user
+// @Summary revoke an api token of a user
+// @Description revoke an api token of a user, e.g. a leaked one, the requests sent with it are unauthorized at once.
+// @Tags user
+// @Param id path string true "id"
+// @Param tokenID path string true "token id"
+// @Produce json
+// @Success 200 {object} types.RevokeApiTokenRespond{}
+// @Router /api/v1/user/{id}/tokens/{tokenID} [delete]
+// @Security BearerAuth
+func (h *userHandler) RevokeUserApiToken(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	callerID, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+	h.revokeApiToken(c, id, callerID)
+}
+
+func (h *userHandler) listApiTokens(c *gin.Context, userID uint64) {
+	ctx := middleware.WrapCtx(c)
+	records, err := h.tokenDao.ListByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("ListByUserID error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	ids := make([]uint64, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	scopes, err := h.tokenDao.GetScopes(ctx, ids)
+	if err != nil {
+		logger.Error("GetScopes error", logger.Err(err), logger.Any("id", userID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	now := time.Now().Unix()
+	data := make([]*types.ApiTokenObjDetail, 0, len(records))
+	for _, record := range records {
+		data = append(data, convertApiToken(record, scopes[record.ID], now))
+	}
+	response.Success(c, gin.H{"apiTokens": data})
+}
+
+// revokeApiToken revoke the token of the path parameter tokenID if it belongs to the user
+func (h *userHandler) revokeApiToken(c *gin.Context, userID uint64, revokedBy uint64) {
+	tokenID, err := utils.StrToUint64E(c.Param("tokenID"))
+	if err != nil || tokenID == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("tokenID", c.Param("tokenID")), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	record, err := h.tokenDao.GetByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrApiTokenNotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("tokenID", tokenID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if record.UserID != userID {
+		response.Error(c, ecode.ErrApiTokenNotFound)
+		return
+	}
+
+	_, err = h.tokenDao.Revoke(ctx, tokenID, revokedBy, time.Now().Unix())
+	if err != nil {
+		logger.Error("Revoke error", logger.Err(err), logger.Any("tokenID", tokenID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	logger.Info("api token revoked", logger.Any("id", userID), logger.Any("tokenID", tokenID),
+		logger.Any("revokedBy", revokedBy), middleware.GCtxRequestIDField(c))
+
+	response.Success(c)
+}
+
+func convertApiToken(record *model.ApiToken, apis []*model.Api, now int64) *types.ApiTokenObjDetail {
+	data := &types.ApiTokenObjDetail{
+		ID:         utils.Uint64ToStr(record.ID),
+		CreatedAt:  record.CreatedAt,
+		Name:       record.Name,
+		Hint:       record.Hint,
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedAt: record.LastUsedAt,
+		LastUsedIP: record.LastUsedIP,
+		RevokedAt:  record.RevokedAt,
+		Active:     record.Active(now),
+		Scopes:     make([]types.ApiTokenScope, 0, len(apis)),
+	}
+	for _, api := range apis {
+		data.Scopes = append(data.Scopes, types.ApiTokenScope{
+			ID:     utils.Uint64ToStr(api.ID),
+			Method: api.Action,
+			Path:   api.Path,
+			Title:  api.Title,
+		})
+	}
+	return data
+}
+
+// uniqueIDs the ids without the duplicates, in the order of their first occurrence
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	unique := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
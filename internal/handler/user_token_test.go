@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/apitoken"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// newApiTokenRouter the user foo (id 1) and the apis GET /api/v1/user/me (id 1), GET /api/v1/user/me/tokens (id 2)
+// and POST /api/v1/user/me/tokens (id 3), the routes of the user are authenticated by apitoken.Auth
+func newApiTokenRouter(t *testing.T, cfg config.ApiToken) *gin.Engine {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	iDao := dao.NewUserDao(db, nil)
+	assert.NoError(t, iDao.Create(ctx, &model.User{Name: "foo", Password: "123456", Status: model.UserStatusActivated}))
+	apiDao := dao.NewApiDao(db, nil)
+	assert.NoError(t, apiDao.Create(ctx, &model.Api{Path: "/api/v1/user/me", Action: http.MethodGet, Title: "get my profile"}))
+	assert.NoError(t, apiDao.Create(ctx, &model.Api{Path: "/api/v1/user/me/tokens", Action: http.MethodGet}))
+	assert.NoError(t, apiDao.Create(ctx, &model.Api{Path: "/api/v1/user/me/tokens", Action: http.MethodPost}))
+
+	h := &userHandler{
+		db:       db,
+		iDao:     iDao,
+		urDao:    dao.NewUserRoleDao(db),
+		tokenDao: dao.NewApiTokenDao(db),
+		apiDao:   apiDao,
+		apiToken: newApiTokenConfig(cfg),
+	}
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/api/v1", apitoken.Auth(h.tokenDao, iDao, middleware.Auth(), nil))
+	group.GET("/user/me", h.GetMe)
+	group.POST("/user/me/tokens", h.CreateApiToken)
+	group.GET("/user/me/tokens", h.ListApiTokens)
+	group.DELETE("/user/me/tokens/:tokenID", h.RevokeApiToken)
+	group.GET("/user/:id/tokens", h.ListUserApiTokens)
+	group.DELETE("/user/:id/tokens/:tokenID", h.RevokeUserApiToken)
+	return r
+}
+
+func doTokenRequest(r http.Handler, method string, path string, credential string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type apiTokenReply struct {
+	Code int `json:"code"`
+	Data struct {
+		Token     string                    `json:"token"`
+		ApiToken  types.ApiTokenObjDetail   `json:"apiToken"`
+		ApiTokens []types.ApiTokenObjDetail `json:"apiTokens"`
+	} `json:"data"`
+}
+
+func decodeApiTokenReply(t *testing.T, w *httptest.ResponseRecorder) *apiTokenReply {
+	reply := &apiTokenReply{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	return reply
+}
+
+func Test_userHandler_ApiTokens(t *testing.T) {
+	r := newApiTokenRouter(t, config.ApiToken{MaxLifetime: 30, MaxPerUser: 2})
+	session, err := jwt.GenerateToken("1", "foo")
+	require.NoError(t, err)
+
+	w := doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, `{"name":"ci","apiIds":[1,1]}`)
+	reply := decodeApiTokenReply(t, w)
+	require.Equal(t, 0, reply.Code, w.Body.String())
+	token := reply.Data.Token
+	assert.True(t, apitoken.Is(token))
+	assert.Equal(t, apitoken.Hint(token), reply.Data.ApiToken.Hint)
+	assert.True(t, reply.Data.ApiToken.Active)
+	assert.NotZero(t, reply.Data.ApiToken.ExpiresAt) // the default lifetime is bounded by the maximum
+	require.Len(t, reply.Data.ApiToken.Scopes, 1)
+	assert.Equal(t, "/api/v1/user/me", reply.Data.ApiToken.Scopes[0].Path)
+
+	// the token calls its scopes as its user, and nothing else
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/me", token, "")
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/me/tokens", token, "")
+	assert.Contains(t, w.Body.String(), ecode.Forbidden.Msg())
+
+	// the list never shows the tokens
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/me/tokens", session, "")
+	reply = decodeApiTokenReply(t, w)
+	require.Len(t, reply.Data.ApiTokens, 1)
+	assert.NotZero(t, reply.Data.ApiTokens[0].LastUsedAt)
+	assert.NotContains(t, w.Body.String(), token)
+	tokenID := reply.Data.ApiTokens[0].ID
+
+	// the invalid requests
+	for _, tc := range []struct {
+		body string
+		err  string
+	}{
+		{`{"name":"ci","apiIds":[]}`, ecode.InvalidParams.Msg()},
+		{`{"name":"ci","apiIds":[9]}`, ecode.ErrApiTokenScope.Msg()},
+		{`{"name":"ci","apiIds":[1],"expiresIn":31}`, ecode.ErrApiTokenLifetime.Msg()},
+		{`{"name":"ci","apiIds":[1],"expiresIn":-1}`, ecode.ErrApiTokenLifetime.Msg()},
+	} {
+		w = doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, tc.body)
+		assert.Contains(t, w.Body.String(), tc.err, tc.body)
+	}
+
+	w = doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, `{"name":"deploy","apiIds":[2],"expiresIn":30}`)
+	reply = decodeApiTokenReply(t, w)
+	require.Equal(t, 0, reply.Code, w.Body.String())
+	second := reply.Data.Token
+	w = doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, `{"name":"more","apiIds":[1]}`)
+	assert.Contains(t, w.Body.String(), ecode.ErrApiTokenLimit.Msg())
+
+	// the revocation is not in the scopes of the second token, the user and the admins revoke the tokens
+	w = doTokenRequest(r, http.MethodDelete, "/api/v1/user/me/tokens/"+tokenID, second, "")
+	assert.Contains(t, w.Body.String(), ecode.Forbidden.Msg())
+	w = doTokenRequest(r, http.MethodDelete, "/api/v1/user/me/tokens/"+tokenID, session, "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/me", token, "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/1/tokens", "", "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/1/tokens", session, "")
+	reply = decodeApiTokenReply(t, w)
+	require.Len(t, reply.Data.ApiTokens, 2)
+	assert.True(t, reply.Data.ApiTokens[0].Active)
+	assert.False(t, reply.Data.ApiTokens[1].Active)
+	assert.NotZero(t, reply.Data.ApiTokens[1].RevokedAt)
+
+	w = doTokenRequest(r, http.MethodDelete, "/api/v1/user/2/tokens/"+reply.Data.ApiTokens[0].ID, session, "")
+	assert.Contains(t, w.Body.String(), ecode.ErrApiTokenNotFound.Msg())
+	w = doTokenRequest(r, http.MethodDelete, "/api/v1/user/1/tokens/"+reply.Data.ApiTokens[0].ID, session, "")
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	w = doTokenRequest(r, http.MethodGet, "/api/v1/user/me/tokens", second, "")
+	assert.Contains(t, w.Body.String(), ecode.Unauthorized.Msg())
+
+	// the revoked tokens free their place
+	w = doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, `{"name":"more","apiIds":[1]}`)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_userHandler_CreateApiToken_TokenRequest(t *testing.T) {
+	r := newApiTokenRouter(t, config.ApiToken{})
+	session, err := jwt.GenerateToken("1", "foo")
+	require.NoError(t, err)
+
+	// the tokens may never expire if they have no maximum lifetime
+	w := doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", session, `{"name":"ci","apiIds":[3],"expiresIn":-1}`)
+	reply := decodeApiTokenReply(t, w)
+	require.Equal(t, 0, reply.Code, w.Body.String())
+	assert.Zero(t, reply.Data.ApiToken.ExpiresAt)
+
+	// a token scoped to the token creation still can not create tokens
+	w = doTokenRequest(r, http.MethodPost, "/api/v1/user/me/tokens", reply.Data.Token, `{"name":"more","apiIds":[1]}`)
+	assert.Contains(t, w.Body.String(), ecode.ErrApiTokenForbidden.Msg())
+}
@@ -0,0 +1,709 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/ecode"
+	"go-admin/internal/mfa"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// keys of the codes checked for a login challenge and for a user in the attempts cache
+const (
+	loginChallengeKey = "challenge:"
+	twoFactorUserKey  = "totp:"
+)
+
+func newTwoFactorConfig(cfg config.TwoFactor) config.TwoFactor {
+	if cfg.Issuer == "" {
+		cfg.Issuer = "go-admin"
+	}
+	if cfg.ChallengeTimeout <= 0 {
+		cfg.ChallengeTimeout = 300
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RecoveryCodes <= 0 {
+		cfg.RecoveryCodes = 10
+	}
+	if cfg.QRCodeSize <= 0 {
+		cfg.QRCodeSize = 256
+	}
+	return cfg
+}
+
+// startTwoFactor respond a challenge instead of the token if the user enabled the TOTP or a role of the user
+// requires it, the users that must use it but did not enable it enroll a secret with the challenge.
+// the challenge is written by respond, it returns false if the login does not need a code.
+func (h *userHandler) startTwoFactor(c *gin.Context, user *model.User, history *model.LoginHistory, respond func(*gin.Context, *types.TwoFactorChallenge)) bool {
+	ctx := middleware.WrapCtx(c)
+	totp, err := h.totpDao.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return true
+	}
+	enabled := err == nil && totp.Enabled
+	if !enabled {
+		required, err := h.twoFactorRequired(ctx, user.ID)
+		if err != nil {
+			logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return true
+		}
+		if !required {
+			return false
+		}
+	}
+
+	challenge := &cache.LoginChallenge{UserID: user.ID}
+	reply := &types.TwoFactorChallenge{ExpiresIn: h.twoFactor.ChallengeTimeout}
+	if !enabled {
+		key, err := mfa.NewKey(h.twoFactor.Issuer, user.Name)
+		if err != nil {
+			logger.Error("NewKey error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return true
+		}
+		challenge.Secret = key.Secret
+		reply.Enroll, reply.Secret, reply.URI = true, key.Secret, key.URI
+	}
+	reply.Challenge, err = randomHex(32)
+	if err == nil {
+		err = h.challenges.Set(ctx, reply.Challenge, challenge, time.Duration(h.twoFactor.ChallengeTimeout)*time.Second)
+	}
+	if err != nil {
+		logger.Error("Set challenge error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return true
+	}
+
+	h.recordLogin(c, history, model.LoginResultChallenge)
+	respond(c, reply)
+	return true
+}
+
+// respondChallenge respond the challenge of a login in the body
+func respondChallenge(c *gin.Context, reply *types.TwoFactorChallenge) {
+	response.Success(c, gin.H{"challenge": reply})
+}
+
+// twoFactorRequired whether a role of the user, bound or inherited, requires the TOTP
+func (h *userHandler) twoFactorRequired(ctx context.Context, userID uint64) (bool, error) {
+	roles, err := h.urDao.GetInheritedRolesByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.RequireTwoFactor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verifyTwoFactor check a TOTP code or a recovery code of the enabled TOTP, a code is accepted once
+func (h *userHandler) verifyTwoFactor(ctx context.Context, totp *model.UserTOTP, code string) (bool, error) {
+	if mfa.IsTOTPCode(code) {
+		counter, ok := mfa.Validate(totp.Secret, code, totp.LastCounter, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return h.totpDao.UseCounter(ctx, totp.ID, counter)
+	}
+	return h.totpDao.UseRecoveryCode(ctx, totp.UserID, mfa.HashRecoveryCode(code))
+}
+
+// checkTwoFactorCode verify a code of the enabled TOTP of the authenticated user, the codes checked in the challenge
+// timeout are limited by twoFactor.maxAttempts the same as a login challenge, it responds the error if the code is rejected
+func (h *userHandler) checkTwoFactorCode(c *gin.Context, totp *model.UserTOTP, code string) bool {
+	ctx := middleware.WrapCtx(c)
+	key := twoFactorUserKey + utils.Uint64ToStr(totp.UserID)
+	n, err := h.attempts.Incr(ctx, key, time.Duration(h.twoFactor.ChallengeTimeout)*time.Second)
+	if err != nil {
+		logger.Error("Incr error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	if n > int64(h.twoFactor.MaxAttempts) {
+		logger.Warn("too many wrong two-factor codes", logger.Any("id", totp.UserID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrTwoFactorLocked)
+		return false
+	}
+
+	ok, err := h.verifyTwoFactor(ctx, totp, code)
+	if err != nil {
+		logger.Error("verifyTwoFactor error", logger.Err(err), logger.Any("id", totp.UserID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	if !ok {
+		response.Error(c, ecode.ErrTwoFactorCode)
+		return false
+	}
+	if err = h.attempts.Reset(ctx, key); err != nil {
+		logger.Error("Reset error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+	}
+	return true
+}
+
+// newRecoveryCodes the recovery codes shown to the user and their hashes to store
+func (h *userHandler) newRecoveryCodes() ([]string, []string, error) {
+	codes, err := mfa.NewRecoveryCodes(h.twoFactor.RecoveryCodes)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, mfa.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// getEnabledTOTP get the enabled TOTP of the caller, it responds the error if there is none
+func (h *userHandler) getEnabledTOTP(c *gin.Context, uid uint64) (*model.UserTOTP, bool) {
+	totp, err := h.totpDao.GetByUserID(middleware.WrapCtx(c), uid)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return nil, false
+	}
+	if err != nil || !totp.Enabled {
+		response.Error(c, ecode.ErrTwoFactorNotEnabled)
+		return nil, false
+	}
+	return totp, true
+}
+
+// writeQRCode respond the png of the QR code of the secret of the user
+func (h *userHandler) writeQRCode(c *gin.Context, user *model.User, secret string) {
+	b, err := mfa.QRCode(mfa.URI(h.twoFactor.Issuer, user.Name, secret), h.twoFactor.QRCodeSize)
+	if err != nil {
+		logger.Error("QRCode error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	c.Header("Cache-Control", "no-store") // the image is the secret
+	c.Data(http.StatusOK, "image/png", b)
+}
+
+// LoginTwoFactor complete a login with the code of the challenge
+// @Summary log in with the two-factor code
+// @Description the second step of a login that responded a challenge, the code is a TOTP code or an unused recovery code.
+// @Description if the challenge enrolls a secret, the TOTP code of that secret enables it and the recovery codes are returned once.
+// @Description a challenge accepts a limited number of wrong codes, the password must then be sent again.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.LoginTwoFactorRequest true "challenge and code"
+// @Success 200 {object} types.LoginTwoFactorRespond{}
+// @Router /api/v1/user/login/2fa [post]
+func (h *userHandler) LoginTwoFactor(c *gin.Context) {
+	form := &types.LoginTwoFactorRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	challenge, ok := h.getChallenge(c, form.Challenge)
+	if !ok {
+		return
+	}
+	n, err := h.attempts.Incr(ctx, loginChallengeKey+form.Challenge, time.Duration(h.twoFactor.ChallengeTimeout)*time.Second)
+	if err != nil {
+		logger.Error("Incr error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if n > int64(h.twoFactor.MaxAttempts) {
+		_ = h.challenges.Del(ctx, form.Challenge)
+		logger.Warn("too many wrong two-factor codes", logger.Any("id", challenge.UserID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrTwoFactorChallenge)
+		return
+	}
+
+	user, err := h.iDao.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) { // deleted since the password was verified
+			response.Error(c, ecode.ErrTwoFactorChallenge)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", challenge.UserID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	history := &model.LoginHistory{UserID: user.ID, Name: user.Name, IP: c.ClientIP(), UserAgent: truncate(c.Request.UserAgent(), 255)}
+	if user.Status == model.UserStatusBlocked {
+		_ = h.challenges.Del(ctx, form.Challenge)
+		h.recordLogin(c, history, model.LoginResultBlocked)
+		response.Error(c, ecode.ErrUserBlocked)
+		return
+	}
+
+	data := gin.H{}
+	if challenge.Secret != "" {
+		// the secret enrolled at the login is enabled by its first code
+		counter, ok := mfa.Validate(challenge.Secret, form.Code, 0, time.Now())
+		if !ok {
+			h.loginFailed(c, user, history)
+			response.Error(c, ecode.ErrTwoFactorCode)
+			return
+		}
+		codes, hashes, err := h.newRecoveryCodes()
+		if err == nil {
+			err = h.totpDao.Enable(ctx, user.ID, challenge.Secret, counter, hashes)
+		}
+		if err != nil {
+			if errors.Is(err, model.ErrTOTPEnabled) { // enabled by another login in the meantime
+				_ = h.challenges.Del(ctx, form.Challenge)
+				response.Error(c, ecode.ErrTwoFactorChallenge)
+				return
+			}
+			logger.Error("Enable error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		data["recoveryCodes"] = codes
+	} else {
+		totp, err := h.totpDao.GetByUserID(ctx, user.ID)
+		if err != nil {
+			if errors.Is(err, model.ErrRecordNotFound) { // reset since the password was verified
+				_ = h.challenges.Del(ctx, form.Challenge)
+				response.Error(c, ecode.ErrTwoFactorChallenge)
+			} else {
+				logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+				response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			}
+			return
+		}
+		ok, err := h.verifyTwoFactor(ctx, totp, form.Code)
+		if err != nil {
+			logger.Error("verifyTwoFactor error", logger.Err(err), logger.Any("id", user.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		if !ok {
+			h.loginFailed(c, user, history)
+			response.Error(c, ecode.ErrTwoFactorCode)
+			return
+		}
+	}
+
+	_ = h.challenges.Del(ctx, form.Challenge)
+	h.completeLogin(c, user, history, data)
+}
+
+// LoginTwoFactorQRCode get the QR code of the secret enrolled by a login challenge
+// @Summary get the QR code of a login challenge
+// @Description get the png image of the QR code of the secret enrolled by the login challenge, it is scanned by the authenticator app.
+// @Tags user
+// @Produce png
+// @Param challenge query string true "challenge returned by the login"
+// @Success 200 {file} file "png image"
+// @Router /api/v1/user/login/2fa/qrcode [get]
+func (h *userHandler) LoginTwoFactorQRCode(c *gin.Context) {
+	form := &types.TwoFactorQRCodeRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	challenge, ok := h.getChallenge(c, form.Challenge)
+	if !ok {
+		return
+	}
+	if challenge.Secret == "" {
+		response.Error(c, ecode.ErrTwoFactorNotEnrolled)
+		return
+	}
+	user, err := h.iDao.GetByID(middleware.WrapCtx(c), challenge.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrTwoFactorChallenge)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", challenge.UserID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	h.writeQRCode(c, user, challenge.Secret)
+}
+
+func (h *userHandler) getChallenge(c *gin.Context, token string) (*cache.LoginChallenge, bool) {
+	challenge, err := h.challenges.Get(middleware.WrapCtx(c), token)
+	if err != nil {
+		if errors.Is(err, cache.ErrLoginChallengeNotFound) {
+			response.Error(c, ecode.ErrTwoFactorChallenge)
+		} else {
+			logger.Error("Get challenge error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return nil, false
+	}
+	return challenge, true
+}
+
+// GetTwoFactor get the two-factor status of the authenticated user
+// @Summary get my two-factor status
+// @Description get whether the TOTP of the user who sent the request is enabled or required, and the number of unused recovery codes
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.GetTwoFactorRespond{}
+// @Router /api/v1/user/me/totp [get]
+// @Security BearerAuth
+func (h *userHandler) GetTwoFactor(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	totp, err := h.totpDao.GetByUserID(ctx, uid)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	required, err := h.twoFactorRequired(ctx, uid)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	count, err := h.totpDao.CountRecoveryCodes(ctx, uid)
+	if err != nil {
+		logger.Error("CountRecoveryCodes error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"enabled":           totp != nil && totp.Enabled,
+		"pending":           totp != nil && !totp.Enabled,
+		"required":          required,
+		"recoveryCodesLeft": count,
+	})
+}
+
+// EnrollTwoFactor create a TOTP secret of the authenticated user
+// @Summary enroll a TOTP secret
+// @Description create a secret for the authenticator app of the user who sent the request, a secret enrolled before and not enabled is replaced.
+// @Description the secret is enabled by sending a code of the app to /api/v1/user/me/totp/enable.
+// @Tags user
+// @Produce json
+// @Success 200 {object} types.EnrollTwoFactorRespond{}
+// @Router /api/v1/user/me/totp [post]
+// @Security BearerAuth
+func (h *userHandler) EnrollTwoFactor(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	key, err := mfa.NewKey(h.twoFactor.Issuer, user.Name)
+	if err == nil {
+		err = h.totpDao.SavePending(ctx, uid, key.Secret)
+	}
+	if err != nil {
+		if errors.Is(err, model.ErrTOTPEnabled) {
+			response.Error(c, ecode.ErrTwoFactorEnabled)
+			return
+		}
+		logger.Error("SavePending error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"secret": key.Secret, "uri": key.URI})
+}
+
+// GetTwoFactorQRCode get the QR code of the enrolled secret of the authenticated user
+// @Summary get the QR code of my TOTP secret
+// @Description get the png image of the QR code of the secret enrolled and not enabled yet, it is scanned by the authenticator app.
+// @Tags user
+// @Produce png
+// @Success 200 {file} file "png image"
+// @Router /api/v1/user/me/totp/qrcode [get]
+// @Security BearerAuth
+func (h *userHandler) GetTwoFactorQRCode(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	totp, err := h.totpDao.GetByUserID(ctx, uid)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if err != nil || totp.Enabled { // the secret of an enabled TOTP is not shown again
+		response.Error(c, ecode.ErrTwoFactorNotEnrolled)
+		return
+	}
+	user, err := h.iDao.GetByID(ctx, uid)
+	if err != nil {
+		logger.Error("GetByID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	h.writeQRCode(c, user, totp.Secret)
+}
+
+// EnableTwoFactor enable the enrolled secret of the authenticated user
+// @Summary enable my TOTP
+// @Description enable the enrolled secret with a code of the authenticator app, the recovery codes are returned once.
+// @Description the next logins require a code.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} types.RecoveryCodesRespond{}
+// @Router /api/v1/user/me/totp/enable [post]
+// @Security BearerAuth
+func (h *userHandler) EnableTwoFactor(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.TwoFactorCodeRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	totp, err := h.totpDao.GetByUserID(ctx, uid)
+	if err != nil && !errors.Is(err, model.ErrRecordNotFound) {
+		logger.Error("GetByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if err != nil {
+		response.Error(c, ecode.ErrTwoFactorNotEnrolled)
+		return
+	}
+	if totp.Enabled {
+		response.Error(c, ecode.ErrTwoFactorEnabled)
+		return
+	}
+	counter, ok := mfa.Validate(totp.Secret, form.Code, 0, time.Now())
+	if !ok {
+		response.Error(c, ecode.ErrTwoFactorCode)
+		return
+	}
+
+	codes, hashes, err := h.newRecoveryCodes()
+	if err == nil {
+		err = h.totpDao.Enable(ctx, uid, totp.Secret, counter, hashes)
+	}
+	if err != nil {
+		if errors.Is(err, model.ErrTOTPEnabled) {
+			response.Error(c, ecode.ErrTwoFactorEnabled)
+			return
+		}
+		logger.Error("Enable error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"recoveryCodes": codes})
+}
+
+// DisableTwoFactor disable the TOTP of the authenticated user
+// @Summary disable my TOTP
+// @Description disable the TOTP and delete the recovery codes, a TOTP code or a recovery code is required.
+// @Description it can not be disabled if a role of the user requires it.
+// @Description a limited number of codes is checked in the challenge timeout, the same as a login challenge.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.TwoFactorCodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} types.DisableTwoFactorRespond{}
+// @Router /api/v1/user/me/totp/disable [post]
+// @Security BearerAuth
+func (h *userHandler) DisableTwoFactor(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.TwoFactorCodeRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	totp, ok := h.getEnabledTOTP(c, uid)
+	if !ok {
+		return
+	}
+	required, err := h.twoFactorRequired(ctx, uid)
+	if err != nil {
+		logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if required {
+		response.Error(c, ecode.ErrTwoFactorRequired)
+		return
+	}
+	if !h.checkTwoFactorCode(c, totp, form.Code) {
+		return
+	}
+
+	err = h.totpDao.DeleteByUserID(ctx, uid)
+	if err != nil {
+		logger.Error("DeleteByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// RegenerateRecoveryCodes replace the recovery codes of the authenticated user
+// @Summary regenerate my recovery codes
+// @Description replace the recovery codes, the codes used or not are invalidated and the new codes are returned once.
+// @Description a limited number of codes is checked in the challenge timeout, the same as a login challenge.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param data body types.TwoFactorCodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} types.RecoveryCodesRespond{}
+// @Router /api/v1/user/me/totp/recovery-codes [post]
+// @Security BearerAuth
+func (h *userHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.TwoFactorCodeRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	totp, ok := h.getEnabledTOTP(c, uid)
+	if !ok {
+		return
+	}
+	if !h.checkTwoFactorCode(c, totp, form.Code) {
+		return
+	}
+
+	codes, hashes, err := h.newRecoveryCodes()
+	if err == nil {
+		err = h.totpDao.ReplaceRecoveryCodes(ctx, uid, hashes)
+	}
+	if err != nil {
+		logger.Error("ReplaceRecoveryCodes error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"recoveryCodes": codes})
+}
+
+// ResetTwoFactor delete the TOTP of a user
+// @Summary reset the TOTP of a user
+// @Description delete the TOTP and the recovery codes of a user who lost the authenticator, the user enrolls again at the next login
+// @Description if a role requires it. the users resetting their own TOTP send a TOTP code or a recovery code,
+// @Description a limited number of codes is checked in the challenge timeout, the same as a login challenge.
+// @Tags user
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.TwoFactorCodeRequest false "TOTP code or recovery code, required for the own TOTP"
+// @Success 200 {object} types.ResetTwoFactorRespond{}
+// @Router /api/v1/user/{id}/totp [delete]
+// @Security BearerAuth
+func (h *userHandler) ResetTwoFactor(c *gin.Context) {
+	_, id, isAbort := getUserIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	uid, ok := getCallerID(c)
+	if !ok {
+		response.Error(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if uid == id { // a stolen session can not remove the second factor of its user
+		form := &types.TwoFactorCodeRequest{}
+		err := c.ShouldBindJSON(form)
+		if err != nil {
+			logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InvalidParams)
+			return
+		}
+		totp, ok := h.getEnabledTOTP(c, id)
+		if !ok {
+			return
+		}
+		if !h.checkTwoFactorCode(c, totp, form.Code) {
+			return
+		}
+	}
+
+	err := h.totpDao.DeleteByUserID(ctx, id)
+	if err != nil {
+		logger.Error("DeleteByUserID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	logger.Info("two-factor authentication reset", logger.Any("id", id), logger.Any("by", uid), middleware.GCtxRequestIDField(c))
+
+	response.Success(c)
+}
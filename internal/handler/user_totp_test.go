@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+// newTwoFactorRouter the users foo (id 1) and bar (id 2, member of the role ops requiring the TOTP)
+func newTwoFactorRouter(t *testing.T) (*gin.Engine, dao.UserTOTPDao) {
+	db := newBulkSQLiteDB(t)
+	ctx := context.Background()
+	iDao := dao.NewUserDao(db, nil)
+	for _, name := range []string{"foo", "bar"} {
+		assert.NoError(t, iDao.Create(ctx, &model.User{Name: name, Password: "123456", Status: model.UserStatusActivated}))
+	}
+	assert.NoError(t, dao.NewRoleDao(db, nil).Create(ctx, &model.Role{RoleName: "ops", RoleKey: "ops", RequireTwoFactor: true}))
+	urDao := dao.NewUserRoleDao(db)
+	assert.NoError(t, urDao.SetUserRoles(ctx, 2, []uint64{1}))
+
+	h := &userHandler{
+		db:         db,
+		iDao:       iDao,
+		urDao:      urDao,
+		providers:  newLoginProviders(nil, iDao),
+		lhDao:      dao.NewLoginHistoryDao(db),
+		attempts:   cache.NewLoginAttemptCache(&model.CacheType{CType: "memory"}),
+		sessions:   cache.NewSessionCache(&model.CacheType{CType: "memory"}),
+		login:      newLoginConfig(config.Login{}),
+		totpDao:    dao.NewUserTOTPDao(db),
+		challenges: cache.NewLoginChallengeCache(&model.CacheType{CType: "memory"}),
+		twoFactor:  newTwoFactorConfig(config.TwoFactor{MaxAttempts: 3, RecoveryCodes: 4}),
+	}
+	jwt.Init()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("uid", c.GetHeader("X-Uid")) }) // replaces middleware.Auth
+	r.POST("/user/login", h.Login)
+	r.POST("/user/login/2fa", h.LoginTwoFactor)
+	r.GET("/user/login/2fa/qrcode", h.LoginTwoFactorQRCode)
+	r.GET("/user/me/totp", h.GetTwoFactor)
+	r.POST("/user/me/totp", h.EnrollTwoFactor)
+	r.GET("/user/me/totp/qrcode", h.GetTwoFactorQRCode)
+	r.POST("/user/me/totp/enable", h.EnableTwoFactor)
+	r.POST("/user/me/totp/disable", h.DisableTwoFactor)
+	r.POST("/user/me/totp/recovery-codes", h.RegenerateRecoveryCodes)
+	r.DELETE("/user/:id/totp", h.ResetTwoFactor)
+	return r, h.totpDao
+}
+
+type twoFactorReply struct {
+	Code int `json:"code"`
+	Data struct {
+		Token         string                    `json:"token"`
+		Challenge     *types.TwoFactorChallenge `json:"challenge"`
+		Secret        string                    `json:"secret"`
+		RecoveryCodes []string                  `json:"recoveryCodes"`
+		Enabled       bool                      `json:"enabled"`
+		Required      bool                      `json:"required"`
+	} `json:"data"`
+}
+
+func decodeTwoFactorReply(t *testing.T, w *httptest.ResponseRecorder) *twoFactorReply {
+	reply := &twoFactorReply{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), reply), w.Body.String())
+	return reply
+}
+
+func sendTwoFactorCode(r http.Handler, challenge string, code string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(&types.LoginTwoFactorRequest{Challenge: challenge, Code: code})
+	return doMeRequest(r, http.MethodPost, "/user/login/2fa", "", "application/json", body)
+}
+
+func generateCode(t *testing.T, secret string, offset time.Duration) string {
+	code, err := totp.GenerateCode(secret, time.Now().Add(offset))
+	require.NoError(t, err)
+	return code
+}
+
+func Test_userHandler_TwoFactor_Login(t *testing.T) {
+	r, _ := newTwoFactorRouter(t)
+
+	// the users without a role requiring it log in with the password
+	reply := decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "foo", "123456"))
+	assert.NotEmpty(t, reply.Data.Token)
+
+	// the members of the role enroll at the login
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "bar", "123456"))
+	assert.Empty(t, reply.Data.Token)
+	challenge := reply.Data.Challenge
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.Enroll)
+	assert.NotEmpty(t, challenge.Secret)
+	assert.Contains(t, challenge.URI, "otpauth://totp/go-admin:bar?")
+	assert.Equal(t, 300, challenge.ExpiresIn)
+
+	w := doMeRequest(r, http.MethodGet, "/user/login/2fa/qrcode?challenge="+challenge.Challenge, "", "", nil)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	_, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	assert.NoError(t, err)
+
+	w = sendTwoFactorCode(r, challenge.Challenge, "000000")
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	reply = decodeTwoFactorReply(t, sendTwoFactorCode(r, challenge.Challenge, generateCode(t, challenge.Secret, 0)))
+	assert.NotEmpty(t, reply.Data.Token)
+	require.Len(t, reply.Data.RecoveryCodes, 4)
+	recoveryCodes := reply.Data.RecoveryCodes
+	// a challenge is completed once
+	w = sendTwoFactorCode(r, challenge.Challenge, generateCode(t, challenge.Secret, 0))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorChallenge.Msg())
+
+	// the next logins require a code of the enabled secret, a code is accepted once
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "bar", "123456"))
+	require.NotNil(t, reply.Data.Challenge)
+	assert.False(t, reply.Data.Challenge.Enroll)
+	assert.Empty(t, reply.Data.Challenge.Secret)
+	next := reply.Data.Challenge.Challenge
+	w = sendTwoFactorCode(r, next, generateCode(t, challenge.Secret, 0))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	w = doMeRequest(r, http.MethodGet, "/user/login/2fa/qrcode?challenge="+next, "", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorNotEnrolled.Msg())
+	reply = decodeTwoFactorReply(t, sendTwoFactorCode(r, next, generateCode(t, challenge.Secret, 30*time.Second)))
+	assert.NotEmpty(t, reply.Data.Token)
+
+	// a recovery code replaces the code once
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "bar", "123456"))
+	next = reply.Data.Challenge.Challenge
+	reply = decodeTwoFactorReply(t, sendTwoFactorCode(r, next, recoveryCodes[0]))
+	assert.NotEmpty(t, reply.Data.Token)
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "bar", "123456"))
+	next = reply.Data.Challenge.Challenge
+	w = sendTwoFactorCode(r, next, recoveryCodes[0])
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+
+	// the wrong codes of a challenge are limited
+	w = sendTwoFactorCode(r, next, "000000")
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	w = sendTwoFactorCode(r, next, "000000")
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	w = sendTwoFactorCode(r, next, recoveryCodes[1])
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorChallenge.Msg())
+
+	w = sendTwoFactorCode(r, "unknown", "000000")
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorChallenge.Msg())
+}
+
+func Test_userHandler_TwoFactor_Me(t *testing.T) {
+	r, totpDao := newTwoFactorRouter(t)
+	ctx := context.Background()
+	codeBody := func(code string) []byte {
+		b, _ := json.Marshal(&types.TwoFactorCodeRequest{Code: code})
+		return b
+	}
+
+	reply := decodeTwoFactorReply(t, doMeRequest(r, http.MethodGet, "/user/me/totp", "1", "", nil))
+	assert.False(t, reply.Data.Enabled)
+	assert.False(t, reply.Data.Required)
+	w := doMeRequest(r, http.MethodGet, "/user/me/totp/qrcode", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorNotEnrolled.Msg())
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/enable", "1", "application/json", codeBody("123456"))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorNotEnrolled.Msg())
+
+	// enroll, a new enrollment replaces the secret
+	_ = decodeTwoFactorReply(t, doMeRequest(r, http.MethodPost, "/user/me/totp", "1", "", nil))
+	reply = decodeTwoFactorReply(t, doMeRequest(r, http.MethodPost, "/user/me/totp", "1", "", nil))
+	secret := reply.Data.Secret
+	require.NotEmpty(t, secret)
+	w = doMeRequest(r, http.MethodGet, "/user/me/totp/qrcode", "1", "", nil)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/enable", "1", "application/json", codeBody("000000"))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	reply = decodeTwoFactorReply(t, doMeRequest(r, http.MethodPost, "/user/me/totp/enable", "1", "application/json", codeBody(generateCode(t, secret, 0))))
+	assert.Len(t, reply.Data.RecoveryCodes, 4)
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp", "1", "", nil)
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorEnabled.Msg())
+	reply = decodeTwoFactorReply(t, doMeRequest(r, http.MethodGet, "/user/me/totp", "1", "", nil))
+	assert.True(t, reply.Data.Enabled)
+
+	// the login of a user who enabled it requires a code
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "foo", "123456"))
+	require.NotNil(t, reply.Data.Challenge)
+	assert.False(t, reply.Data.Challenge.Enroll)
+
+	// the recovery codes are replaced
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/recovery-codes", "1", "application/json", codeBody("000000"))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	reply = decodeTwoFactorReply(t, doMeRequest(r, http.MethodPost, "/user/me/totp/recovery-codes", "1", "application/json", codeBody(generateCode(t, secret, 30*time.Second))))
+	require.Len(t, reply.Data.RecoveryCodes, 4)
+	count, err := totpDao.CountRecoveryCodes(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	// disabled with a recovery code
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/disable", "1", "application/json", codeBody(reply.Data.RecoveryCodes[0]))
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	_, err = totpDao.GetByUserID(ctx, 1)
+	assert.ErrorIs(t, err, model.ErrRecordNotFound)
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "foo", "123456"))
+	assert.NotEmpty(t, reply.Data.Token)
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/disable", "1", "application/json", codeBody("000000"))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorNotEnabled.Msg())
+
+	// the members of a role requiring it can not disable it, an admin resets it
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "go-admin", AccountName: "bar"})
+	require.NoError(t, err)
+	require.NoError(t, totpDao.Enable(ctx, 2, key.Secret(), 0, nil))
+	w = doMeRequest(r, http.MethodPost, "/user/me/totp/disable", "2", "application/json", codeBody(generateCode(t, key.Secret(), 0)))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorRequired.Msg())
+	w = doMeRequest(r, http.MethodDelete, "/user/2/totp", "", "", nil)
+	assert.Contains(t, w.Body.String(), "Unauthorized")
+	// the users resetting their own TOTP send a code
+	w = doMeRequest(r, http.MethodDelete, "/user/2/totp", "2", "application/json", codeBody("000000"))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	w = doMeRequest(r, http.MethodDelete, "/user/2/totp", "2", "", nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+	w = doMeRequest(r, http.MethodDelete, "/user/2/totp", "1", "", nil)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	reply = decodeTwoFactorReply(t, doLogin(r, "10.0.0.1", "bar", "123456"))
+	require.NotNil(t, reply.Data.Challenge)
+	assert.True(t, reply.Data.Challenge.Enroll)
+
+	w = doMeRequest(r, http.MethodGet, "/user/me/totp", "", "", nil)
+	assert.Contains(t, w.Body.String(), "Unauthorized")
+}
+
+func Test_userHandler_TwoFactor_Attempts(t *testing.T) {
+	r, totpDao := newTwoFactorRouter(t)
+	codeBody := func(code string) []byte {
+		b, _ := json.Marshal(&types.TwoFactorCodeRequest{Code: code})
+		return b
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "go-admin", AccountName: "foo"})
+	require.NoError(t, err)
+	require.NoError(t, totpDao.Enable(context.Background(), 1, key.Secret(), 0, nil))
+
+	// the wrong codes of the endpoints are counted together, the right code is rejected after max attempts
+	paths := []string{"/user/me/totp/recovery-codes", "/user/me/totp/disable", "/user/1/totp"}
+	for _, path := range paths {
+		method := http.MethodPost
+		if path == "/user/1/totp" {
+			method = http.MethodDelete
+		}
+		w := doMeRequest(r, method, path, "1", "application/json", codeBody("000000"))
+		assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorCode.Msg())
+	}
+	w := doMeRequest(r, http.MethodPost, "/user/me/totp/disable", "1", "application/json", codeBody(generateCode(t, key.Secret(), 0)))
+	assert.Contains(t, w.Body.String(), ecode.ErrTwoFactorLocked.Msg())
+	_, err = totpDao.GetByUserID(context.Background(), 1)
+	assert.NoError(t, err)
+}
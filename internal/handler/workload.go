@@ -0,0 +1,537 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+	k8sutils "go-admin/internal/utils"
+)
+
+const (
+	// annotation keys used by the deployment controller and kubectl
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+
+	workloadKindDeployment  = "deployments"
+	workloadKindStatefulSet = "statefulsets"
+	workloadKindDaemonSet   = "daemonsets"
+)
+
+// annotations of replicaSet that are not copied to deployment on rollback, the same as kubectl
+var rollbackSkippedAnnotations = map[string]bool{
+	revisionAnnotation:                          true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	corev1.LastAppliedConfigAnnotation:          true,
+}
+
+// errDeploymentPaused a paused deployment ignores the changes of its template, it is not rolled back
+var errDeploymentPaused = errors.New("deployment is paused, resume it before rolling back")
+
+var _ WorkloadHandler = (*workloadHandler)(nil)
+
+// WorkloadHandler defining the handler interface
+type WorkloadHandler interface {
+	ListRevisions(c *gin.Context)
+	DiffRevisions(c *gin.Context)
+	Rollback(c *gin.Context)
+}
+
+type workloadHandler struct {
+	newClient func() (kubernetes.Interface, error)
+	urDao     dao.UserRoleDao
+}
+
+// NewWorkloadHandler creating the handler interface
+func NewWorkloadHandler() WorkloadHandler {
+	return &workloadHandler{
+		newClient: k8sutils.NewKubeClient,
+		urDao:     dao.NewUserRoleDao(model.GetDB()),
+	}
+}
+
+// workloadRevision a revision of workload and its pod template
+type workloadRevision struct {
+	types.WorkloadRevision
+	template *corev1.PodTemplateSpec
+	data     []byte // raw data of controllerRevision, it is a patch of the workload
+}
+
+// ListRevisions list the revisions of workload
+// @Summary list of workload revisions
+// @Description list the revisions of deployment (replicaSets), statefulSet or daemonSet (controllerRevisions), the newest first
+// @Tags workload
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param kind path string true "deployments, statefulsets or daemonsets"
+// @Param name path string true "name"
+// @Success 200 {object} types.ListWorkloadRevisionsRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/revisions [get]
+// @Security BearerAuth
+func (h *workloadHandler) ListRevisions(c *gin.Context) {
+	namespace, kind, name, isAbort := getWorkloadFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	revisions, err := listWorkloadRevisions(ctx, client, namespace, kind, name)
+	if err != nil {
+		responseK8sError(c, "ListRevisions", err, ecode.ErrListWorkloadRevisions,
+			logger.String("namespace", namespace), logger.String("kind", kind), logger.String("name", name))
+		return
+	}
+
+	data := []types.WorkloadRevision{}
+	for _, r := range revisions {
+		data = append(data, r.WorkloadRevision)
+	}
+	response.Success(c, gin.H{
+		"revisions": data,
+	})
+}
+
+// DiffRevisions compare the pod templates of two revisions
+// @Summary diff workload revisions
+// @Description compare the pod templates of two revisions, to defaults to the current revision
+// @Tags workload
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param kind path string true "deployments, statefulsets or daemonsets"
+// @Param name path string true "name"
+// @Param from query int true "revision to compare from"
+// @Param to query int false "revision to compare to, default is the current revision"
+// @Success 200 {object} types.DiffWorkloadRevisionsRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/revisions/diff [get]
+// @Security BearerAuth
+func (h *workloadHandler) DiffRevisions(c *gin.Context) {
+	namespace, kind, name, isAbort := getWorkloadFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkNamespace(c, namespace) {
+		return
+	}
+	from, to := int64(utils.StrToInt(c.Query("from"))), int64(utils.StrToInt(c.Query("to")))
+	if from < 1 || to < 0 {
+		logger.Warn("revision error: ", logger.String("from", c.Query("from")), logger.String("to", c.Query("to")), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	revisions, err := listWorkloadRevisions(ctx, client, namespace, kind, name)
+	if err != nil {
+		responseK8sError(c, "DiffRevisions", err, ecode.ErrDiffWorkloadRevisions,
+			logger.String("namespace", namespace), logger.String("kind", kind), logger.String("name", name))
+		return
+	}
+	if to == 0 && len(revisions) > 0 {
+		to = revisions[0].Revision
+	}
+	fromRevision, toRevision := findRevision(revisions, from), findRevision(revisions, to)
+	if fromRevision == nil || toRevision == nil {
+		logger.Warn("revision not found", logger.Int64("from", from), logger.Int64("to", to), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrWorkloadRevisionNotFound)
+		return
+	}
+
+	changes, err := diffObjects(fromRevision.template, toRevision.template)
+	if err != nil {
+		logger.Error("diffObjects error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrDiffWorkloadRevisions)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"from":    fromRevision.WorkloadRevision,
+		"to":      toRevision.WorkloadRevision,
+		"changes": changes,
+	})
+}
+
+// Rollback roll back workload to a revision
+// @Summary roll back workload
+// @Description roll back the pod template of workload to a revision, revision 0 means the previous revision,
+// @Description only for roles configured in k8s.writeRoles, a paused deployment is not rolled back
+// @Tags workload
+// @accept json
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param kind path string true "deployments, statefulsets or daemonsets"
+// @Param name path string true "name"
+// @Param data body types.RollbackWorkloadRequest true "revision"
+// @Success 200 {object} types.RollbackWorkloadRespond{}
+// @Router /api/v1/k8s/namespaces/{namespace}/workloads/{kind}/{name}/rollback [post]
+// @Security BearerAuth
+func (h *workloadHandler) Rollback(c *gin.Context) {
+	namespace, kind, name, isAbort := getWorkloadFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.RollbackWorkloadRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if !checkK8sWrite(c, h.urDao, namespace) {
+		return
+	}
+	client, ok := getKubeClient(c, h.newClient)
+	if !ok {
+		return
+	}
+
+	fields := []logger.Field{logger.String("namespace", namespace), logger.String("kind", kind),
+		logger.String("name", name), logger.Int64("revision", form.Revision)}
+	ctx := middleware.WrapCtx(c)
+	revisions, err := listWorkloadRevisions(ctx, client, namespace, kind, name)
+	if err != nil {
+		responseK8sError(c, "Rollback", err, ecode.ErrRollbackWorkload, fields...)
+		return
+	}
+
+	var target *workloadRevision
+	if form.Revision == 0 {
+		// the previous revision is the newest one that is not in use
+		for _, r := range revisions {
+			if !r.Current {
+				target = r
+				break
+			}
+		}
+	} else {
+		target = findRevision(revisions, form.Revision)
+	}
+	if target == nil {
+		logger.Warn("revision not found", append(fields, middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.ErrWorkloadRevisionNotFound)
+		return
+	}
+	if target.Current {
+		response.Success(c, gin.H{"revision": target.Revision, "skipped": true})
+		return
+	}
+
+	switch kind {
+	case workloadKindDeployment:
+		err = rollbackDeployment(ctx, client, namespace, name, target)
+	case workloadKindStatefulSet:
+		_, err = client.AppsV1().StatefulSets(namespace).Patch(ctx, name, k8stypes.StrategicMergePatchType, target.data, metav1.PatchOptions{})
+	case workloadKindDaemonSet:
+		_, err = client.AppsV1().DaemonSets(namespace).Patch(ctx, name, k8stypes.StrategicMergePatchType, target.data, metav1.PatchOptions{})
+	}
+	if errors.Is(err, errDeploymentPaused) {
+		logger.Warn("rollback paused deployment", append(fields, middleware.GCtxRequestIDField(c))...)
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if err != nil {
+		responseK8sError(c, "Rollback", err, ecode.ErrRollbackWorkload, fields...)
+		return
+	}
+
+	logger.Info("rollback workload", append(fields, logger.String("uid", c.GetString("uid")), middleware.GCtxRequestIDField(c))...)
+	response.Success(c, gin.H{"revision": target.Revision, "skipped": false})
+}
+
+func getWorkloadFromPath(c *gin.Context) (string, string, string, bool) {
+	namespace, kind, name := c.Param("namespace"), c.Param("kind"), c.Param("name")
+	switch kind {
+	case workloadKindDeployment, workloadKindStatefulSet, workloadKindDaemonSet:
+		return namespace, kind, name, false
+	}
+
+	logger.Warn("unsupported workload kind: ", logger.String("kind", kind), middleware.GCtxRequestIDField(c))
+	return "", "", "", true
+}
+
+func findRevision(revisions []*workloadRevision, revision int64) *workloadRevision {
+	for _, r := range revisions {
+		if r.Revision == revision {
+			return r
+		}
+	}
+	return nil
+}
+
+// listWorkloadRevisions list the revisions owned by workload, sorted by revision in descending order
+func listWorkloadRevisions(ctx context.Context, client kubernetes.Interface, namespace string, kind string, name string) ([]*workloadRevision, error) {
+	var (
+		revisions []*workloadRevision
+		err       error
+	)
+	switch kind {
+	case workloadKindDeployment:
+		revisions, err = listDeploymentRevisions(ctx, client, namespace, name)
+	case workloadKindStatefulSet:
+		var sts *appsv1.StatefulSet
+		sts, err = client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			revisions, err = listControllerRevisions(ctx, client, &sts.ObjectMeta, sts.Spec.Selector, sts.Status.UpdateRevision)
+		}
+	case workloadKindDaemonSet:
+		var ds *appsv1.DaemonSet
+		ds, err = client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			revisions, err = listControllerRevisions(ctx, client, &ds.ObjectMeta, ds.Spec.Selector, "")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	if len(revisions) > 0 && !hasCurrentRevision(revisions) {
+		revisions[0].Current = true
+	}
+	return revisions, nil
+}
+
+func hasCurrentRevision(revisions []*workloadRevision) bool {
+	for _, r := range revisions {
+		if r.Current {
+			return true
+		}
+	}
+	return false
+}
+
+func listDeploymentRevisions(ctx context.Context, client kubernetes.Interface, namespace string, name string) ([]*workloadRevision, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []*workloadRevision{}
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if !metav1.IsControlledBy(rs, deployment) {
+			continue
+		}
+		revision, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		template := rs.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		revisions = append(revisions, &workloadRevision{
+			WorkloadRevision: types.WorkloadRevision{
+				Revision:    revision,
+				Name:        rs.Name,
+				ChangeCause: rs.Annotations[changeCauseAnnotation],
+				Images:      getImages(template),
+				CreatedAt:   rs.CreationTimestamp.Time,
+				Current:     rs.Annotations[revisionAnnotation] == deployment.Annotations[revisionAnnotation],
+			},
+			template: template,
+		})
+	}
+
+	return revisions, nil
+}
+
+// listControllerRevisions list the controllerRevisions of statefulSet or daemonSet, if currentName is empty,
+// the revision with the highest number is the current one
+func listControllerRevisions(ctx context.Context, client kubernetes.Interface, owner *metav1.ObjectMeta,
+	labelSelector *metav1.LabelSelector, currentName string) ([]*workloadRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.AppsV1().ControllerRevisions(owner.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []*workloadRevision{}
+	for i := range list.Items {
+		cr := &list.Items[i]
+		if ref := metav1.GetControllerOf(cr); ref == nil || ref.UID != owner.UID {
+			continue
+		}
+		// the data of controllerRevision is a patch with the pod template, {"spec":{"template":{...}}}
+		patch := &struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}{}
+		if err = json.Unmarshal(cr.Data.Raw, patch); err != nil {
+			return nil, fmt.Errorf("unmarshal controllerRevision %s error: %v", cr.Name, err)
+		}
+		template := &patch.Spec.Template
+		revisions = append(revisions, &workloadRevision{
+			WorkloadRevision: types.WorkloadRevision{
+				Revision:    cr.Revision,
+				Name:        cr.Name,
+				ChangeCause: cr.Annotations[changeCauseAnnotation],
+				Images:      getImages(template),
+				CreatedAt:   cr.CreationTimestamp.Time,
+				Current:     currentName != "" && cr.Name == currentName,
+			},
+			template: template,
+			data:     cr.Data.Raw,
+		})
+	}
+
+	return revisions, nil
+}
+
+// rollbackDeployment replace the pod template of deployment with the template of the revision, the same as kubectl
+func rollbackDeployment(ctx context.Context, client kubernetes.Interface, namespace string, name string, target *workloadRevision) error {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if deployment.Spec.Paused {
+		return errDeploymentPaused
+	}
+
+	rs, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, target.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{}
+	for k, v := range deployment.Annotations {
+		if rollbackSkippedAnnotations[k] {
+			annotations[k] = v
+		}
+	}
+	for k, v := range rs.Annotations {
+		if !rollbackSkippedAnnotations[k] {
+			annotations[k] = v
+		}
+	}
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": target.template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.AppsV1().Deployments(namespace).Patch(ctx, name, k8stypes.JSONPatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func getImages(template *corev1.PodTemplateSpec) []string {
+	images := []string{}
+	for _, container := range template.Spec.InitContainers {
+		images = append(images, container.Image)
+	}
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// diffObjects compare two objects by their json representation, the changes are sorted by path
+func diffObjects(from interface{}, to interface{}) ([]types.RevisionChange, error) {
+	fromValues, err := flattenObject(from)
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := flattenObject(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []types.RevisionChange{}
+	for path, fromValue := range fromValues {
+		toValue, ok := toValues[path]
+		if !ok {
+			changes = append(changes, types.RevisionChange{Path: path, From: fromValue})
+		} else if !reflect.DeepEqual(fromValue, toValue) {
+			changes = append(changes, types.RevisionChange{Path: path, From: fromValue, To: toValue})
+		}
+	}
+	for path, toValue := range toValues {
+		if _, ok := fromValues[path]; !ok {
+			changes = append(changes, types.RevisionChange{Path: path, To: toValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes, nil
+}
+
+// flattenObject convert object to a map of json path to scalar value, e.g. spec.containers[0].image
+func flattenObject(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, item := range val {
+				if prefix == "" {
+					walk(k, item)
+				} else {
+					walk(prefix+"."+k, item)
+				}
+			}
+		case []interface{}:
+			for i, item := range val {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), item)
+			}
+		default:
+			values[prefix] = val
+		}
+	}
+	walk("", value)
+
+	return values, nil
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go-admin/configs"
+	"go-admin/internal/config"
+	"go-admin/internal/model"
+	"go-admin/internal/types"
+)
+
+func newPodTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+	}
+}
+
+func newWorkloadHandler(t *testing.T) (*gin.Engine, kubernetes.Interface) {
+	err := config.Init(configs.Path("admin.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "d1",
+			Annotations: map[string]string{revisionAnnotation: "2"}},
+		Spec: appsv1.DeploymentSpec{Selector: selector, Template: newPodTemplate("web:v2")},
+	}
+	isController := true
+	newReplicaSet := func(name string, revision string, image string) *appsv1.ReplicaSet {
+		template := newPodTemplate(image)
+		template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "web"},
+				Annotations: map[string]string{revisionAnnotation: revision, changeCauseAnnotation: "set image " + image},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web",
+					UID: "d1", Controller: &isController}}},
+			Spec: appsv1.ReplicaSetSpec{Selector: selector, Template: template},
+		}
+	}
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "s1"},
+		Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: newPodTemplate("web:v2")},
+		Status:     appsv1.StatefulSetStatus{UpdateRevision: "web-2"},
+	}
+	newControllerRevision := func(name string, revision int64, image string) *appsv1.ControllerRevision {
+		data, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": newPodTemplate(image)}})
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "web",
+					UID: "s1", Controller: &isController}}},
+			Data:     runtime.RawExtension{Raw: data},
+			Revision: revision,
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		deployment, newReplicaSet("web-1", "1", "web:v1"), newReplicaSet("web-2", "2", "web:v2"),
+		sts, newControllerRevision("web-1", 1, "web:v1"), newControllerRevision("web-2", 2, "web:v2"),
+	)
+	h := &workloadHandler{
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+		urDao: &mockUserRoleDao{roles: map[uint64][]*model.Role{
+			1: {{RoleKey: "admin"}},
+			2: {{RoleKey: "viewer"}},
+			3: {{RoleKey: "admin"}},
+		}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("uid", c.GetHeader("X-Uid")) // instead of middleware.Auth
+	}, restrictScope("3", "prod"))
+	r.GET("/namespaces/:namespace/workloads/:kind/:name/revisions", h.ListRevisions)
+	r.GET("/namespaces/:namespace/workloads/:kind/:name/revisions/diff", h.DiffRevisions)
+	r.POST("/namespaces/:namespace/workloads/:kind/:name/rollback", h.Rollback)
+	return r, client
+}
+
+func Test_workloadHandler_ListRevisions(t *testing.T) {
+	r, _ := newWorkloadHandler(t)
+
+	for _, kind := range []string{workloadKindDeployment, workloadKindStatefulSet} {
+		w := doJSONRequest(r, http.MethodGet, "1", "/namespaces/default/workloads/"+kind+"/web/revisions", nil)
+		result := &struct {
+			Code int `json:"code"`
+			Data struct {
+				Revisions []types.WorkloadRevision `json:"revisions"`
+			} `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+		assert.Equal(t, 0, result.Code)
+		assert.Len(t, result.Data.Revisions, 2)
+		assert.Equal(t, int64(2), result.Data.Revisions[0].Revision)
+		assert.True(t, result.Data.Revisions[0].Current)
+		assert.Equal(t, []string{"web:v1"}, result.Data.Revisions[1].Images)
+	}
+
+	// the namespace is out of the data scope
+	w := doJSONRequest(r, http.MethodGet, "3", "/namespaces/default/workloads/deployments/web/revisions", nil)
+	assert.Contains(t, w.Body.String(), `"code":10008`)
+
+	w = doJSONRequest(r, http.MethodGet, "1", "/namespaces/default/workloads/pods/web/revisions", nil)
+	assert.Contains(t, w.Body.String(), `"code":10001`)
+}
+
+func Test_workloadHandler_DiffRevisions(t *testing.T) {
+	r, _ := newWorkloadHandler(t)
+
+	w := doJSONRequest(r, http.MethodGet, "1", "/namespaces/default/workloads/deployments/web/revisions/diff?from=1", nil)
+	result := &struct {
+		Code int `json:"code"`
+		Data struct {
+			Changes []types.RevisionChange `json:"changes"`
+		} `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, []types.RevisionChange{{Path: "spec.containers[0].image", From: "web:v1", To: "web:v2"}}, result.Data.Changes)
+
+	w = doJSONRequest(r, http.MethodGet, "1", "/namespaces/default/workloads/deployments/web/revisions/diff?from=9", nil)
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
+
+func Test_workloadHandler_Rollback(t *testing.T) {
+	r, client := newWorkloadHandler(t)
+	ctx := context.Background()
+
+	// no write role, or the namespace is out of the data scope
+	for _, uid := range []string{"2", "3"} {
+		w := doJSONRequest(r, http.MethodPost, uid, "/namespaces/default/workloads/deployments/web/rollback", &types.RollbackWorkloadRequest{})
+		assert.Contains(t, w.Body.String(), `"code":10008`)
+	}
+	deployment, err := client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "web:v2", deployment.Spec.Template.Spec.Containers[0].Image)
+
+	w := doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/workloads/deployments/web/rollback", &types.RollbackWorkloadRequest{})
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	deployment, err = client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "web:v1", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, deployment.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	assert.Equal(t, "set image web:v1", deployment.Annotations[changeCauseAnnotation])
+	assert.Equal(t, "2", deployment.Annotations[revisionAnnotation])
+
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/workloads/statefulsets/web/rollback", &types.RollbackWorkloadRequest{Revision: 1})
+	assert.Contains(t, w.Body.String(), `"code":0`)
+	sts, err := client.AppsV1().StatefulSets("default").Get(ctx, "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "web:v1", sts.Spec.Template.Spec.Containers[0].Image)
+
+	// already in use
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/workloads/statefulsets/web/rollback", &types.RollbackWorkloadRequest{Revision: 2})
+	assert.Contains(t, w.Body.String(), `"skipped":true`)
+
+	// a paused deployment is not rolled back
+	r, client = newWorkloadHandler(t)
+	deployment, err = client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	deployment.Spec.Paused = true
+	_, err = client.AppsV1().Deployments("default").Update(ctx, deployment, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	w = doJSONRequest(r, http.MethodPost, "1", "/namespaces/default/workloads/deployments/web/rollback", &types.RollbackWorkloadRequest{Revision: 1})
+	assert.Contains(t, w.Body.String(), "paused")
+	assert.NotContains(t, w.Body.String(), `"code":0`)
+}
@@ -0,0 +1,138 @@
+// Package mfa is the second factor of the logins, the TOTP codes (RFC 6238) of an authenticator app
+// and the one-time recovery codes replacing them when the authenticator is lost.
+package mfa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+)
+
+// the settings of the codes, the defaults of the authenticator apps
+const (
+	Period = 30 // seconds of a time step
+	Skew   = 1  // the codes of the previous and the next time step are accepted too
+	Digits = otp.DigitsSix
+)
+
+// recoveryEncoding the lowercase Crockford base32, a recovery code is typed by hand and has no i, l, o or u
+var recoveryEncoding = base32.NewEncoding("0123456789abcdefghjkmnpqrstvwxyz").WithPadding(base32.NoPadding)
+
+// Key a new TOTP secret of an account
+type Key struct {
+	Secret string // base32 secret, the manual entry in the app
+	URI    string // otpauth:// uri, the content of the QR code
+}
+
+// NewKey create a random secret of the account at the issuer
+func NewKey(issuer string, account string) (*Key, error) {
+	b := make([]byte, 20) // the size of the sha1 hmac key
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	return &Key{Secret: secret, URI: URI(issuer, account, secret)}, nil
+}
+
+// URI the otpauth:// uri of the secret of the account at the issuer, the format read by the authenticator apps
+func URI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", otp.AlgorithmSHA1.String())
+	v.Set("digits", Digits.String())
+	v.Set("period", strconv.Itoa(Period))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// QRCode the png image of the QR code of an otpauth:// uri, size is the width and height in pixels
+func QRCode(uri string, size int) ([]byte, error) {
+	key, err := otp.NewKeyFromURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	img, err := key.Image(size, size)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate check the code of the secret at the time, a code is accepted once: the time step of the code
+// must be after lastCounter, the time step of the last accepted code. It returns the time step of the code.
+func Validate(secret string, code string, lastCounter uint64, t time.Time) (uint64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits.Length() {
+		return 0, false
+	}
+	opts := hotp.ValidateOpts{Digits: Digits, Algorithm: otp.AlgorithmSHA1}
+	current := uint64(t.Unix()) / Period
+	for counter := current - Skew; counter <= current+Skew; counter++ {
+		if counter <= lastCounter {
+			continue
+		}
+		ok, err := hotp.ValidateCustom(code, counter, secret, opts)
+		if err == nil && ok {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// IsTOTPCode whether the code looks like a TOTP code, otherwise it is a recovery code
+func IsTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits.Length() {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRecoveryCodes create n random recovery codes, e.g. k7m2p-x9qfa, they are only shown once,
+// HashRecoveryCode of them is stored
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s := recoveryEncoding.EncodeToString(b)[:10] // 50 random bits
+		codes = append(codes, s[:5]+"-"+s[5:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode the sha256 of a recovery code, the case, the spaces and the dashes are ignored,
+// the letters read as digits are the digits
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "", "i", "1", "l", "1", "o", "0").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,79 @@
+package mfa
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKey(t *testing.T) {
+	key, err := NewKey("go-admin", "foo")
+	require.NoError(t, err)
+	assert.Len(t, key.Secret, 32)
+	assert.True(t, strings.HasPrefix(key.URI, "otpauth://totp/go-admin:foo?"))
+	assert.Contains(t, key.URI, "secret="+key.Secret)
+	assert.Equal(t, key.URI, URI("go-admin", "foo", key.Secret))
+
+	b, err := QRCode(key.URI, 200)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, 200, img.Bounds().Dx())
+}
+
+func TestValidate(t *testing.T) {
+	key, err := NewKey("go-admin", "foo")
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	current := uint64(now.Unix()) / Period
+
+	code, err := totp.GenerateCode(key.Secret, now)
+	require.NoError(t, err)
+	counter, ok := Validate(key.Secret, code, 0, now)
+	assert.True(t, ok)
+	assert.Equal(t, current, counter)
+	// a code is accepted once
+	_, ok = Validate(key.Secret, code, counter, now)
+	assert.False(t, ok)
+
+	// the clock of the phone is late by a step
+	code, err = totp.GenerateCode(key.Secret, now.Add(-Period*time.Second))
+	require.NoError(t, err)
+	counter, ok = Validate(key.Secret, code, 0, now)
+	assert.True(t, ok)
+	assert.Equal(t, current-1, counter)
+
+	code, err = totp.GenerateCode(key.Secret, now.Add(-3*Period*time.Second))
+	require.NoError(t, err)
+	_, ok = Validate(key.Secret, code, 0, now)
+	assert.False(t, ok)
+
+	_, ok = Validate(key.Secret, "12345", 0, now)
+	assert.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	assert.Len(t, codes, 10)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[0-9a-hjkmnp-tv-z]{5}-[0-9a-hjkmnp-tv-z]{5}$`, code)
+		assert.False(t, IsTOTPCode(code))
+		seen[code] = true
+	}
+	assert.Len(t, seen, 10)
+
+	assert.Equal(t, HashRecoveryCode("ab1c0-defgh"), HashRecoveryCode(" ABlCO DEFGH"))
+	assert.NotEqual(t, HashRecoveryCode("ab1c0-defgh"), HashRecoveryCode("ab1c0-defgk"))
+	assert.Len(t, HashRecoveryCode("ab1c0-defgh"), 64)
+
+	assert.True(t, IsTOTPCode(" 012345 "))
+	assert.False(t, IsTOTPCode("01234a"))
+}
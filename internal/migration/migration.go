@@ -0,0 +1,274 @@
+// Package migration applies the versioned schema migrations embedded in the binary,
+// the applied versions and their checksums are recorded in the migrations table.
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// the sql files of each dialect are named <version>_<name>.up.sql and <version>_<name>.down.sql
+//
+//go:embed sql
+var sqlFS embed.FS
+
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrChecksumMismatch an applied migration has been modified after it was applied
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Migration a versioned schema change
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of the up and down sql
+}
+
+// Status state of a migration
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // true if the checksum of the applied migration differs from the embedded one
+}
+
+// record a row of the migrations table
+type record struct {
+	Version   int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;type:varchar(255);NOT NULL"`
+	Checksum  string    `gorm:"column:checksum;type:varchar(64);NOT NULL"`
+	AppliedAt time.Time `gorm:"column:applied_at;NOT NULL"`
+}
+
+// TableName table name
+func (r *record) TableName() string {
+	return "migrations"
+}
+
+// Load the migrations of the database driver, sorted by version
+func Load(driver string) ([]*Migration, error) {
+	dir, err := dialectDir(driver)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(sqlFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[int]*Migration{}
+	for _, entry := range entries {
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		data, err := fs.ReadFile(sqlFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			migrations[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	list := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up sql", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up + "\n" + m.Down))
+		m.Checksum = hex.EncodeToString(sum[:])
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Up apply all pending migrations in order, it refuses to run if an applied migration has been modified
+func Up(db *gorm.DB, driver string) ([]*Migration, error) {
+	migrations, applied, err := prepare(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkApplied(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := execSQL(tx, m.Up); err != nil {
+				return err
+			}
+			return tx.Create(&record{Version: m.Version, Name: m.Name, Checksum: m.Checksum, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migrate up %d_%s error: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// Down revert the last steps applied migrations, in reverse order
+func Down(db *gorm.DB, driver string, steps int) ([]*Migration, error) {
+	migrations, applied, err := prepare(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkApplied(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return done, fmt.Errorf("migration %d_%s can not be reverted, it has no down sql", m.Version, m.Name)
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := execSQL(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", m.Version).Delete(&record{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migrate down %d_%s error: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// GetStatus get the state of every embedded migration
+func GetStatus(db *gorm.DB, driver string) ([]*Status, error) {
+	migrations, applied, err := prepare(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Status, 0, len(migrations))
+	for _, m := range migrations {
+		s := &Status{Version: m.Version, Name: m.Name}
+		if r, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+			s.Modified = r.Checksum != m.Checksum
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// prepare load the migrations and the applied records, the migrations table is created if it does not exist
+func prepare(db *gorm.DB, driver string) ([]*Migration, map[int]*record, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !db.Migrator().HasTable(&record{}) {
+		if err = db.Migrator().CreateTable(&record{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	var records []*record
+	if err = db.Order("version").Find(&records).Error; err != nil {
+		return nil, nil, err
+	}
+	applied := map[int]*record{}
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+
+	return migrations, applied, nil
+}
+
+func checkApplied(migrations []*Migration, applied map[int]*record) error {
+	for _, m := range migrations {
+		if r, ok := applied[m.Version]; ok && r.Checksum != m.Checksum {
+			return fmt.Errorf("%w: migration %d_%s has been modified after it was applied", ErrChecksumMismatch, m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// execSQL execute the statements one by one, the drivers do not all support multiple statements in one call
+func execSQL(tx *gorm.DB, sql string) error {
+	for _, stmt := range splitStatements(sql) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements split sql by the semicolons at the end of lines, comment lines are dropped
+func splitStatements(sql string) []string {
+	var stmts []string
+	var buf strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		}
+	}
+	if s := strings.TrimSpace(buf.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+	return stmts
+}
+
+func dialectDir(driver string) (string, error) {
+	switch strings.ToLower(driver) {
+	case ggorm.DBDriverMysql, ggorm.DBDriverTidb:
+		return "sql/mysql", nil
+	case ggorm.DBDriverPostgresql, "postgres":
+		return "sql/postgresql", nil
+	case ggorm.DBDriverSqlite:
+		return "sql/sqlite", nil
+	}
+	return "", fmt.Errorf("unsupported database driver: %s", driver)
+}
@@ -0,0 +1,104 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	for _, driver := range []string{"mysql", "tidb", "postgresql", "postgres", "sqlite"} {
+		migrations, err := Load(driver)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+		for i, m := range migrations {
+			assert.NotEmpty(t, m.Up, m.Name)
+			assert.NotEmpty(t, m.Down, m.Name)
+			if i > 0 {
+				assert.Greater(t, m.Version, migrations[i-1].Version)
+			}
+		}
+	}
+
+	// every dialect has the same migrations
+	mysql, _ := Load("mysql")
+	for _, driver := range []string{"postgresql", "sqlite"} {
+		migrations, _ := Load(driver)
+		assert.Equal(t, len(mysql), len(migrations), driver)
+		for i := range migrations {
+			assert.Equal(t, mysql[i].Version, migrations[i].Version, driver)
+			assert.Equal(t, mysql[i].Name, migrations[i].Name, driver)
+		}
+	}
+
+	_, err := Load("oracle")
+	assert.Error(t, err)
+}
+
+func TestUpDown(t *testing.T) {
+	db := newSQLiteDB(t)
+	migrations, _ := Load("sqlite")
+
+	done, err := Up(db, "sqlite")
+	assert.NoError(t, err)
+	assert.Len(t, done, len(migrations))
+	assert.True(t, db.Migrator().HasTable("user"))
+
+	// nothing to do the second time
+	done, err = Up(db, "sqlite")
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	status, err := GetStatus(db, "sqlite")
+	assert.NoError(t, err)
+	for _, s := range status {
+		assert.True(t, s.Applied)
+		assert.False(t, s.Modified)
+	}
+
+	done, err = Down(db, "sqlite", len(migrations))
+	assert.NoError(t, err)
+	assert.Len(t, done, len(migrations))
+	assert.False(t, db.Migrator().HasTable("user"))
+
+	status, err = GetStatus(db, "sqlite")
+	assert.NoError(t, err)
+	for _, s := range status {
+		assert.False(t, s.Applied)
+	}
+}
+
+func TestUp_checksumMismatch(t *testing.T) {
+	db := newSQLiteDB(t)
+	_, err := Up(db, "sqlite")
+	assert.NoError(t, err)
+
+	err = db.Model(&record{}).Where("version = ?", 1).Update("checksum", "modified").Error
+	assert.NoError(t, err)
+
+	status, err := GetStatus(db, "sqlite")
+	assert.NoError(t, err)
+	assert.True(t, status[0].Modified)
+	_, err = Up(db, "sqlite")
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = Down(db, "sqlite", 1)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func Test_splitStatements(t *testing.T) {
+	stmts := splitStatements("-- comment\nCREATE TABLE a (\n  id int\n);\n\nDROP TABLE b;\nSELECT 1")
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id int\n);", "DROP TABLE b;", "SELECT 1"}, stmts)
+}
@@ -0,0 +1,67 @@
+-- tables of users, roles and apis, IF NOT EXISTS keeps the tables of databases created before migrations were introduced
+
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `name` varchar(50) NOT NULL COMMENT 'username',
+  `password` varchar(100) NOT NULL COMMENT 'password',
+  `email` varchar(50) NOT NULL COMMENT 'email',
+  `phone` varchar(30) NOT NULL COMMENT 'phone number',
+  `avatar` varchar(200) DEFAULT NULL COMMENT 'avatar',
+  `age` smallint(6) NOT NULL COMMENT 'age',
+  `gender` smallint(6) NOT NULL COMMENT 'gender, 1:Male, 2:Female, other values:unknown',
+  `status` smallint(6) NOT NULL COMMENT 'account status, 1:inactive, 2:activated, 3:blocked',
+  `login_at` bigint(20) NOT NULL COMMENT 'login timestamp',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `role_id` int(11) DEFAULT NULL,
+  `role_name` text,
+  `status` text,
+  `role_key` text,
+  `role_sort` int(11) DEFAULT NULL,
+  `flag` text,
+  `remark` text,
+  `admin` decimal(10,0) DEFAULT NULL,
+  `data_scope` text,
+  `create_by` int(11) DEFAULT NULL,
+  `update_by` int(11) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_role_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `api` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `handle` text,
+  `title` text,
+  `path` text,
+  `type` text,
+  `action` text,
+  `create_by` int(11) DEFAULT NULL,
+  `update_by` int(11) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_api_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_role` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `role_id` bigint(20) NOT NULL COMMENT 'role id, refers to role.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_role_deleted_at` (`deleted_at`),
+  KEY `idx_user_role_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+ALTER TABLE `role` DROP INDEX `uk_role_role_key`, DROP COLUMN `undeleted_role_key`;
+ALTER TABLE `user` DROP INDEX `uk_user_name`, DROP COLUMN `undeleted_name`;
+
+ALTER TABLE `api` DROP COLUMN `deleted_by`;
+ALTER TABLE `role` DROP COLUMN `deleted_by`;
+ALTER TABLE `user` DROP COLUMN `deleted_by`;
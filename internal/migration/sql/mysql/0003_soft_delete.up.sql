@@ -0,0 +1,13 @@
+-- soft deleted rows record who deleted them, the unique names only apply to the rows that are not deleted
+
+ALTER TABLE `user` ADD COLUMN `deleted_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the user who soft deleted the record';
+ALTER TABLE `role` ADD COLUMN `deleted_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the user who soft deleted the record';
+ALTER TABLE `api` ADD COLUMN `deleted_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the user who soft deleted the record';
+
+-- mysql has no partial index, the generated columns are NULL for the deleted rows and NULLs never collide in a unique index
+ALTER TABLE `user`
+  ADD COLUMN `undeleted_name` varchar(50) GENERATED ALWAYS AS (IF(`deleted_at` IS NULL, `name`, NULL)) VIRTUAL,
+  ADD UNIQUE KEY `uk_user_name` (`undeleted_name`);
+ALTER TABLE `role`
+  ADD COLUMN `undeleted_role_key` varchar(255) GENERATED ALWAYS AS (IF(`deleted_at` IS NULL, `role_key`, NULL)) VIRTUAL,
+  ADD UNIQUE KEY `uk_role_role_key` (`undeleted_role_key`);
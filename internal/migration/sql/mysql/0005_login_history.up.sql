@@ -0,0 +1,17 @@
+-- every login attempt with its client and result
+
+CREATE TABLE IF NOT EXISTS `login_history` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id, 0 if no user has the name',
+  `name` varchar(50) NOT NULL COMMENT 'username of the attempt',
+  `ip` varchar(64) NOT NULL COMMENT 'client ip',
+  `user_agent` varchar(255) NOT NULL COMMENT 'user agent of the client',
+  `result` varchar(20) NOT NULL COMMENT 'success, failed, locked, throttled or blocked',
+  PRIMARY KEY (`id`),
+  KEY `idx_login_history_deleted_at` (`deleted_at`),
+  KEY `idx_login_history_user_id` (`user_id`),
+  KEY `idx_login_history_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
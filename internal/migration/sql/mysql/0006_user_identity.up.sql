@@ -0,0 +1,16 @@
+-- the accounts of the users at the external identity providers
+
+CREATE TABLE IF NOT EXISTS `user_identity` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `issuer` varchar(255) NOT NULL COMMENT 'issuer url of the identity provider',
+  `subject` varchar(255) NOT NULL COMMENT 'id of the account at the identity provider',
+  `email` varchar(50) NOT NULL COMMENT 'email claimed by the identity provider at the last login',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_identity_deleted_at` (`deleted_at`),
+  KEY `idx_user_identity_user_id` (`user_id`),
+  UNIQUE KEY `idx_user_identity_issuer_subject` (`issuer`(191), `subject`(191))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
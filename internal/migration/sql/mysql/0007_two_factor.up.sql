@@ -0,0 +1,30 @@
+-- the TOTP two-factor authentication of the users, and the roles whose members must use it
+
+ALTER TABLE `role` ADD COLUMN `require_two_factor` tinyint(1) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `user_totp` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `secret` varchar(64) NOT NULL COMMENT 'base32 secret of the authenticator',
+  `enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'false until the enrollment is confirmed with a code',
+  `last_counter` bigint(20) NOT NULL DEFAULT 0 COMMENT 'time step of the last accepted code, a code is accepted once',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_totp_deleted_at` (`deleted_at`),
+  UNIQUE KEY `idx_user_totp_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_code` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `code_hash` varchar(64) NOT NULL COMMENT 'sha256 of the recovery code',
+  `used_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time the code was used, 0 if it is unused',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_recovery_code_deleted_at` (`deleted_at`),
+  KEY `idx_user_recovery_code_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,33 @@
+-- the personal api tokens of the users and the apis they may call
+
+CREATE TABLE IF NOT EXISTS `api_token` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `name` varchar(100) NOT NULL COMMENT 'name given by the user, e.g. the pipeline using it',
+  `hint` varchar(20) NOT NULL COMMENT 'start of the token, to recognize it',
+  `token_hash` varchar(64) NOT NULL COMMENT 'sha256 of the token',
+  `expires_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time the token expires, 0 if it never expires',
+  `last_used_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time of the last request, updated at most once a minute',
+  `last_used_ip` varchar(64) NOT NULL DEFAULT '' COMMENT 'client ip of the last request',
+  `revoked_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time the token was revoked, 0 if it is not revoked',
+  `revoked_by` bigint(20) NOT NULL DEFAULT 0 COMMENT 'id of the user who revoked the token',
+  PRIMARY KEY (`id`),
+  KEY `idx_api_token_deleted_at` (`deleted_at`),
+  KEY `idx_api_token_user_id` (`user_id`),
+  UNIQUE KEY `idx_api_token_token_hash` (`token_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `api_token_scope` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `token_id` bigint(20) NOT NULL COMMENT 'token id, refers to api_token.id',
+  `api_id` bigint(20) NOT NULL COMMENT 'api id, refers to api.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_api_token_scope_deleted_at` (`deleted_at`),
+  KEY `idx_api_token_scope_token_id` (`token_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,34 @@
+-- the menu tree of the admin frontend and the menus granted to the roles
+
+CREATE TABLE IF NOT EXISTS `menu` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `parent_id` bigint(20) NOT NULL DEFAULT 0 COMMENT 'parent menu id, 0 for a top level menu',
+  `type` varchar(20) NOT NULL COMMENT 'directory, menu or button',
+  `title` varchar(100) NOT NULL COMMENT 'shown in the navigation',
+  `name` varchar(100) NOT NULL DEFAULT '' COMMENT 'route name of the frontend',
+  `path` varchar(255) NOT NULL DEFAULT '' COMMENT 'route path of the frontend',
+  `component` varchar(255) NOT NULL DEFAULT '' COMMENT 'view component of the frontend',
+  `icon` varchar(100) NOT NULL DEFAULT '',
+  `permission` varchar(100) NOT NULL DEFAULT '' COMMENT 'permission key checked by the frontend',
+  `sort` int(11) NOT NULL DEFAULT 0 COMMENT 'the siblings are sorted in ascending order',
+  `hidden` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'routed but not shown in the navigation',
+  PRIMARY KEY (`id`),
+  KEY `idx_menu_deleted_at` (`deleted_at`),
+  KEY `idx_menu_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_menu` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `role_id` bigint(20) NOT NULL COMMENT 'role id, refers to role.id',
+  `menu_id` bigint(20) NOT NULL COMMENT 'menu id, refers to menu.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_menu_deleted_at` (`deleted_at`),
+  KEY `idx_role_menu_role_id` (`role_id`),
+  KEY `idx_role_menu_menu_id` (`menu_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,43 @@
+-- the department tree, the memberships of the users and the data scope modes of the roles
+
+ALTER TABLE `role` ADD COLUMN `data_scope_mode` varchar(20) NOT NULL DEFAULT 'all' COMMENT 'all, dept, dept_and_children, self or custom';
+
+CREATE TABLE IF NOT EXISTS `department` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `parent_id` bigint(20) NOT NULL DEFAULT 0 COMMENT 'parent department id, 0 for a top level department',
+  `name` varchar(100) NOT NULL COMMENT 'department name',
+  `sort` int(11) NOT NULL DEFAULT 0 COMMENT 'the siblings are sorted in ascending order',
+  `namespaces` text COMMENT 'kubernetes namespaces of the department, comma separated',
+  `remark` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_department_deleted_at` (`deleted_at`),
+  KEY `idx_department_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_department` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'user id',
+  `dept_id` bigint(20) NOT NULL COMMENT 'department id, refers to department.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_department_deleted_at` (`deleted_at`),
+  KEY `idx_user_department_user_id` (`user_id`),
+  KEY `idx_user_department_dept_id` (`dept_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_department` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `role_id` bigint(20) NOT NULL COMMENT 'role id, refers to role.id',
+  `dept_id` bigint(20) NOT NULL COMMENT 'department id of the custom data scope, refers to department.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_department_deleted_at` (`deleted_at`),
+  KEY `idx_role_department_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
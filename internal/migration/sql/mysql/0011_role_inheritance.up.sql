@@ -0,0 +1,26 @@
+-- the parents whose grants the roles inherit, and the apis granted to the roles
+
+CREATE TABLE IF NOT EXISTS `role_parent` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `role_id` bigint(20) NOT NULL COMMENT 'role id, refers to role.id',
+  `parent_id` bigint(20) NOT NULL COMMENT 'id of the inherited role, refers to role.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_parent_deleted_at` (`deleted_at`),
+  KEY `idx_role_parent_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_api` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `role_id` bigint(20) NOT NULL COMMENT 'role id, refers to role.id',
+  `api_id` bigint(20) NOT NULL COMMENT 'api id, refers to api.id',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_api_deleted_at` (`deleted_at`),
+  KEY `idx_role_api_role_id` (`role_id`),
+  KEY `idx_role_api_api_id` (`api_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
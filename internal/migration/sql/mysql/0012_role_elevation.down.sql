@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `role_elevation_event`;
+DROP TABLE IF EXISTS `role_elevation`;
@@ -0,0 +1,37 @@
+-- the temporary roles requested by the users with the steps of their approval
+
+CREATE TABLE IF NOT EXISTS `role_elevation` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint(20) NOT NULL COMMENT 'id of the requester, refers to user.id',
+  `role_id` bigint(20) NOT NULL COMMENT 'id of the requested role, refers to role.id',
+  `reason` varchar(500) NOT NULL COMMENT 'justification given by the requester',
+  `duration` int(11) NOT NULL COMMENT 'seconds the role is held from the approval',
+  `status` varchar(20) NOT NULL COMMENT 'pending, approved, rejected, cancelled, revoked or expired',
+  `reviewer_id` bigint(20) NOT NULL DEFAULT 0 COMMENT 'id of the user who approved or rejected the request',
+  `review_comment` varchar(500) NOT NULL DEFAULT '' COMMENT 'comment of the reviewer',
+  `reviewed_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time of the approval or the rejection',
+  `expires_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time the role is no longer held, 0 until the approval',
+  `ended_at` bigint(20) NOT NULL DEFAULT 0 COMMENT 'unix time of the revocation or the expiry',
+  `ended_by` bigint(20) NOT NULL DEFAULT 0 COMMENT 'id of the user who revoked the grant, 0 if it expired',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_elevation_deleted_at` (`deleted_at`),
+  KEY `idx_role_elevation_user_id` (`user_id`),
+  KEY `idx_role_elevation_status` (`status`, `expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_elevation_event` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `elevation_id` bigint(20) NOT NULL COMMENT 'refers to role_elevation.id',
+  `actor_id` bigint(20) NOT NULL DEFAULT 0 COMMENT 'id of the user who did the step, 0 for the expiry',
+  `action` varchar(20) NOT NULL COMMENT 'request, approve, reject, cancel, revoke, expire or use',
+  `detail` varchar(500) NOT NULL DEFAULT '' COMMENT 'the reason, the comment, or the method, the path and the status of a request',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_elevation_event_deleted_at` (`deleted_at`),
+  KEY `idx_role_elevation_event_elevation_id` (`elevation_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_role;
+DROP TABLE IF EXISTS api;
+DROP TABLE IF EXISTS role;
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,3 @@
+-- apis whose routes are no longer registered are marked stale by the api sync
+
+ALTER TABLE api ADD COLUMN stale boolean NOT NULL DEFAULT false;
@@ -0,0 +1,16 @@
+-- every login attempt with its client and result
+
+CREATE TABLE IF NOT EXISTS login_history (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  name varchar(50) NOT NULL,
+  ip varchar(64) NOT NULL,
+  user_agent varchar(255) NOT NULL,
+  result varchar(20) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_login_history_deleted_at ON login_history (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_login_history_user_id ON login_history (user_id);
+CREATE INDEX IF NOT EXISTS idx_login_history_created_at ON login_history (created_at);
@@ -0,0 +1,15 @@
+-- the accounts of the users at the external identity providers
+
+CREATE TABLE IF NOT EXISTS user_identity (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  issuer varchar(255) NOT NULL,
+  subject varchar(255) NOT NULL,
+  email varchar(50) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_identity_deleted_at ON user_identity (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_identity_user_id ON user_identity (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identity_issuer_subject ON user_identity (issuer, subject);
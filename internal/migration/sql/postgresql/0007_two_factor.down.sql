@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_totp;
+ALTER TABLE role DROP COLUMN require_two_factor;
@@ -0,0 +1,28 @@
+-- the TOTP two-factor authentication of the users, and the roles whose members must use it
+
+ALTER TABLE role ADD COLUMN require_two_factor boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS user_totp (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  secret varchar(64) NOT NULL,
+  enabled boolean NOT NULL DEFAULT false,
+  last_counter bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_user_totp_deleted_at ON user_totp (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_totp_user_id ON user_totp (user_id);
+
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  code_hash varchar(64) NOT NULL,
+  used_at bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_user_recovery_code_deleted_at ON user_recovery_code (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_recovery_code_user_id ON user_recovery_code (user_id);
@@ -0,0 +1,31 @@
+-- the personal api tokens of the users and the apis they may call
+
+CREATE TABLE IF NOT EXISTS api_token (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  name varchar(100) NOT NULL,
+  hint varchar(20) NOT NULL,
+  token_hash varchar(64) NOT NULL,
+  expires_at bigint NOT NULL DEFAULT 0,
+  last_used_at bigint NOT NULL DEFAULT 0,
+  last_used_ip varchar(64) NOT NULL DEFAULT '',
+  revoked_at bigint NOT NULL DEFAULT 0,
+  revoked_by bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_api_token_deleted_at ON api_token (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_api_token_user_id ON api_token (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_token_token_hash ON api_token (token_hash);
+
+CREATE TABLE IF NOT EXISTS api_token_scope (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  token_id bigint NOT NULL,
+  api_id bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_api_token_scope_deleted_at ON api_token_scope (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_api_token_scope_token_id ON api_token_scope (token_id);
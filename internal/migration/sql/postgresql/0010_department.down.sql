@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS role_department;
+DROP TABLE IF EXISTS user_department;
+DROP TABLE IF EXISTS department;
+ALTER TABLE role DROP COLUMN data_scope_mode;
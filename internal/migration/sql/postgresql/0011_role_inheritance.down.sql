@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS role_api;
+DROP TABLE IF EXISTS role_parent;
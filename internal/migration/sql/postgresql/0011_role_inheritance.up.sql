@@ -0,0 +1,24 @@
+-- the parents whose grants the roles inherit, and the apis granted to the roles
+
+CREATE TABLE IF NOT EXISTS role_parent (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  role_id bigint NOT NULL,
+  parent_id bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_role_parent_deleted_at ON role_parent (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_role_parent_role_id ON role_parent (role_id);
+
+CREATE TABLE IF NOT EXISTS role_api (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  role_id bigint NOT NULL,
+  api_id bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_role_api_deleted_at ON role_api (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_role_api_role_id ON role_api (role_id);
+CREATE INDEX IF NOT EXISTS idx_role_api_api_id ON role_api (api_id);
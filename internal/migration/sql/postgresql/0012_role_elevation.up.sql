@@ -0,0 +1,35 @@
+-- the temporary roles requested by the users with the steps of their approval
+
+CREATE TABLE IF NOT EXISTS role_elevation (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  user_id bigint NOT NULL,
+  role_id bigint NOT NULL,
+  reason varchar(500) NOT NULL,
+  duration int NOT NULL,
+  status varchar(20) NOT NULL,
+  reviewer_id bigint NOT NULL DEFAULT 0,
+  review_comment varchar(500) NOT NULL DEFAULT '',
+  reviewed_at bigint NOT NULL DEFAULT 0,
+  expires_at bigint NOT NULL DEFAULT 0,
+  ended_at bigint NOT NULL DEFAULT 0,
+  ended_by bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_role_elevation_deleted_at ON role_elevation (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_role_elevation_user_id ON role_elevation (user_id);
+CREATE INDEX IF NOT EXISTS idx_role_elevation_status ON role_elevation (status, expires_at);
+
+CREATE TABLE IF NOT EXISTS role_elevation_event (
+  id bigserial PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  elevation_id bigint NOT NULL,
+  actor_id bigint NOT NULL DEFAULT 0,
+  action varchar(20) NOT NULL,
+  detail varchar(500) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_role_elevation_event_deleted_at ON role_elevation_event (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_role_elevation_event_elevation_id ON role_elevation_event (elevation_id);
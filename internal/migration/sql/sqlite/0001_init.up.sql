@@ -0,0 +1,63 @@
+-- tables of users, roles and apis, IF NOT EXISTS keeps the tables of databases created before migrations were introduced
+
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `name` varchar(50) NOT NULL,
+  `password` varchar(100) NOT NULL,
+  `email` varchar(50) NOT NULL,
+  `phone` varchar(30) NOT NULL,
+  `avatar` varchar(200),
+  `age` smallint NOT NULL,
+  `gender` smallint NOT NULL,
+  `status` smallint NOT NULL,
+  `login_at` bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_user_deleted_at` ON `user` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `role` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `role_id` integer,
+  `role_name` text,
+  `status` text,
+  `role_key` text,
+  `role_sort` integer,
+  `flag` text,
+  `remark` text,
+  `admin` decimal(10),
+  `data_scope` text,
+  `create_by` integer,
+  `update_by` integer
+);
+CREATE INDEX IF NOT EXISTS `idx_role_deleted_at` ON `role` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `api` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `handle` text,
+  `title` text,
+  `path` text,
+  `type` text,
+  `action` text,
+  `create_by` integer,
+  `update_by` integer
+);
+CREATE INDEX IF NOT EXISTS `idx_api_deleted_at` ON `api` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `user_role` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `user_id` bigint NOT NULL,
+  `role_id` bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_user_role_deleted_at` ON `user_role` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_user_role_user_id` ON `user_role` (`user_id`);
@@ -0,0 +1 @@
+ALTER TABLE `api` DROP COLUMN `stale`;
@@ -0,0 +1,6 @@
+DROP INDEX `uk_role_role_key`;
+DROP INDEX `uk_user_name`;
+
+ALTER TABLE `api` DROP COLUMN `deleted_by`;
+ALTER TABLE `role` DROP COLUMN `deleted_by`;
+ALTER TABLE `user` DROP COLUMN `deleted_by`;
@@ -0,0 +1,8 @@
+-- soft deleted rows record who deleted them, the unique names only apply to the rows that are not deleted
+
+ALTER TABLE `user` ADD COLUMN `deleted_by` bigint NOT NULL DEFAULT 0;
+ALTER TABLE `role` ADD COLUMN `deleted_by` bigint NOT NULL DEFAULT 0;
+ALTER TABLE `api` ADD COLUMN `deleted_by` bigint NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX `uk_user_name` ON `user` (`name`) WHERE `deleted_at` IS NULL;
+CREATE UNIQUE INDEX `uk_role_role_key` ON `role` (`role_key`) WHERE `deleted_at` IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE `api` DROP COLUMN `version`;
+ALTER TABLE `role` DROP COLUMN `version`;
+ALTER TABLE `user` DROP COLUMN `version`;
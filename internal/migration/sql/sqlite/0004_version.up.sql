@@ -0,0 +1,5 @@
+-- the version is incremented by every update, an update carrying an older version is rejected
+
+ALTER TABLE `user` ADD COLUMN `version` bigint NOT NULL DEFAULT 1;
+ALTER TABLE `role` ADD COLUMN `version` bigint NOT NULL DEFAULT 1;
+ALTER TABLE `api` ADD COLUMN `version` bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `role_menu`;
+DROP TABLE IF EXISTS `menu`;
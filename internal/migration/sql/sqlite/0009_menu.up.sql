@@ -0,0 +1,32 @@
+-- the menu tree of the admin frontend and the menus granted to the roles
+
+CREATE TABLE IF NOT EXISTS `menu` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `parent_id` bigint NOT NULL DEFAULT 0,
+  `type` varchar(20) NOT NULL,
+  `title` varchar(100) NOT NULL,
+  `name` varchar(100) NOT NULL DEFAULT '',
+  `path` varchar(255) NOT NULL DEFAULT '',
+  `component` varchar(255) NOT NULL DEFAULT '',
+  `icon` varchar(100) NOT NULL DEFAULT '',
+  `permission` varchar(100) NOT NULL DEFAULT '',
+  `sort` integer NOT NULL DEFAULT 0,
+  `hidden` boolean NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS `idx_menu_deleted_at` ON `menu` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_menu_parent_id` ON `menu` (`parent_id`);
+
+CREATE TABLE IF NOT EXISTS `role_menu` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `role_id` bigint NOT NULL,
+  `menu_id` bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_role_menu_deleted_at` ON `role_menu` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_role_menu_role_id` ON `role_menu` (`role_id`);
+CREATE INDEX IF NOT EXISTS `idx_role_menu_menu_id` ON `role_menu` (`menu_id`);
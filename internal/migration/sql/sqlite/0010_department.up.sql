@@ -0,0 +1,40 @@
+-- the department tree, the memberships of the users and the data scope modes of the roles
+
+ALTER TABLE `role` ADD COLUMN `data_scope_mode` varchar(20) NOT NULL DEFAULT 'all';
+
+CREATE TABLE IF NOT EXISTS `department` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `parent_id` bigint NOT NULL DEFAULT 0,
+  `name` varchar(100) NOT NULL,
+  `sort` integer NOT NULL DEFAULT 0,
+  `namespaces` text,
+  `remark` varchar(255) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS `idx_department_deleted_at` ON `department` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_department_parent_id` ON `department` (`parent_id`);
+
+CREATE TABLE IF NOT EXISTS `user_department` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `user_id` bigint NOT NULL,
+  `dept_id` bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_user_department_deleted_at` ON `user_department` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_user_department_user_id` ON `user_department` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_user_department_dept_id` ON `user_department` (`dept_id`);
+
+CREATE TABLE IF NOT EXISTS `role_department` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `role_id` bigint NOT NULL,
+  `dept_id` bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_role_department_deleted_at` ON `role_department` (`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_role_department_role_id` ON `role_department` (`role_id`);
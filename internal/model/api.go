@@ -7,13 +7,16 @@ import (
 type Api struct {
 	ggorm.Model `gorm:"embedded"` // embed id and time
 
-	Handle   string `gorm:"column:handle;type:text" json:"handle"`
-	Title    string `gorm:"column:title;type:text" json:"title"`
-	Path     string `gorm:"column:path;type:text" json:"path"`
-	Type     string `gorm:"column:type;type:text" json:"type"`
-	Action   string `gorm:"column:action;type:text" json:"action"`
-	CreateBy int    `gorm:"column:create_by;type:int(11)" json:"createBy"`
-	UpdateBy int    `gorm:"column:update_by;type:int(11)" json:"updateBy"`
+	Handle    string `gorm:"column:handle;type:text" json:"handle"`
+	Title     string `gorm:"column:title;type:text" json:"title"`
+	Path      string `gorm:"column:path;type:text" json:"path"`
+	Type      string `gorm:"column:type;type:text" json:"type"`
+	Action    string `gorm:"column:action;type:text" json:"action"`
+	CreateBy  int    `gorm:"column:create_by;type:int" json:"createBy"`
+	UpdateBy  int    `gorm:"column:update_by;type:int" json:"updateBy"`
+	Stale     bool   `gorm:"column:stale;NOT NULL;default:false" json:"stale"`                  // true if no registered route matches the path and action any more
+	DeletedBy uint64 `gorm:"column:deleted_by;type:bigint;NOT NULL;default:0" json:"deletedBy"` // id of the user who soft deleted the record
+	Version   uint64 `gorm:"column:version;type:bigint;NOT NULL;default:1" json:"version"`      // incremented by every update, for optimistic concurrency control
 }
 
 // TableName table name
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// ApiToken a personal api token of a user, the revoked and expired tokens are kept for the audit
+type ApiToken struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID     uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`                          // user id
+	Name       string `gorm:"column:name;type:varchar(100);NOT NULL" json:"name"`                         // name given by the user, e.g. the pipeline using it
+	Hint       string `gorm:"column:hint;type:varchar(20);NOT NULL" json:"hint"`                          // start of the token, to recognize it
+	TokenHash  string `gorm:"column:token_hash;type:varchar(64);NOT NULL" json:"-"`                       // sha256 of the token
+	ExpiresAt  int64  `gorm:"column:expires_at;type:bigint;NOT NULL;default:0" json:"expiresAt"`          // unix time the token expires, 0 if it never expires
+	LastUsedAt int64  `gorm:"column:last_used_at;type:bigint;NOT NULL;default:0" json:"lastUsedAt"`       // unix time of the last request, updated at most once a minute
+	LastUsedIP string `gorm:"column:last_used_ip;type:varchar(64);NOT NULL;default:''" json:"lastUsedIp"` // client ip of the last request
+	RevokedAt  int64  `gorm:"column:revoked_at;type:bigint;NOT NULL;default:0" json:"revokedAt"`          // unix time the token was revoked, 0 if it is not revoked
+	RevokedBy  uint64 `gorm:"column:revoked_by;type:bigint;NOT NULL;default:0" json:"revokedBy"`          // id of the user who revoked the token
+}
+
+// TableName table name
+func (m *ApiToken) TableName() string {
+	return "api_token"
+}
+
+// Active whether the token is neither revoked nor expired at the unix time
+func (m *ApiToken) Active(now int64) bool {
+	return m.RevokedAt == 0 && (m.ExpiresAt == 0 || m.ExpiresAt > now)
+}
+
+// ApiTokenScope allows a token to call an api, a token can call the apis of its scopes only
+type ApiTokenScope struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	TokenID uint64 `gorm:"column:token_id;type:bigint;NOT NULL" json:"tokenId"` // token id, refers to api_token.id
+	ApiID   uint64 `gorm:"column:api_id;type:bigint;NOT NULL" json:"apiId"`     // api id, refers to api.id
+}
+
+// TableName table name
+func (m *ApiTokenScope) TableName() string {
+	return "api_token_scope"
+}
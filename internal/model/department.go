@@ -0,0 +1,53 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// Department a node of the organization tree, the users are members of departments
+type Department struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	ParentID   uint64 `gorm:"column:parent_id;type:bigint;NOT NULL;default:0" json:"parentId"` // parent department id, 0 for a top level department
+	Name       string `gorm:"column:name;type:varchar(100);NOT NULL" json:"name"`              // department name
+	Sort       int    `gorm:"column:sort;type:int;NOT NULL;default:0" json:"sort"`             // the siblings are sorted in ascending order
+	Namespaces string `gorm:"column:namespaces;type:text" json:"namespaces"`                   // kubernetes namespaces of the department, comma separated
+	Remark     string `gorm:"column:remark;type:varchar(255);NOT NULL;default:''" json:"remark"`
+}
+
+// TableName table name
+func (m *Department) TableName() string {
+	return "department"
+}
+
+// NamespaceList the kubernetes namespaces of the department, without the duplicates
+func (m *Department) NamespaceList() []string {
+	namespaces, _ := (&Role{DataScope: m.Namespaces}).Namespaces()
+	return namespaces
+}
+
+// UserDepartment makes a user a member of a department, a user can be a member of several departments
+type UserDepartment struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"` // user id
+	DeptID uint64 `gorm:"column:dept_id;type:bigint;NOT NULL" json:"deptId"` // department id, refers to department.id
+}
+
+// TableName table name
+func (m *UserDepartment) TableName() string {
+	return "user_department"
+}
+
+// RoleDepartment a department of the custom data scope of a role
+type RoleDepartment struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	RoleID uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"` // role id, refers to role.id
+	DeptID uint64 `gorm:"column:dept_id;type:bigint;NOT NULL" json:"deptId"` // department id, refers to department.id
+}
+
+// TableName table name
+func (m *RoleDepartment) TableName() string {
+	return "role_department"
+}
@@ -2,6 +2,7 @@
 package model
 
 import (
+	"errors"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +24,33 @@ var (
 
 	// ErrRecordNotFound no records found
 	ErrRecordNotFound = gorm.ErrRecordNotFound
+
+	// ErrRecordConflict the record conflicts with an existing record on a unique column
+	ErrRecordConflict = errors.New("record conflict")
+
+	// ErrVersionConflict the record has been updated since the version in the update was read
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrTOTPEnabled the user already has an enabled TOTP
+	ErrTOTPEnabled = errors.New("totp is already enabled")
+
+	// ErrMenuParent the parent of a menu does not exist, is a button, or is the menu itself or one of its descendants
+	ErrMenuParent = errors.New("invalid parent menu")
+
+	// ErrMenuHasChildren a menu with children can not be deleted
+	ErrMenuHasChildren = errors.New("menu has children")
+
+	// ErrDepartmentParent the parent of a department does not exist, or is the department itself or one of its descendants
+	ErrDepartmentParent = errors.New("invalid parent department")
+
+	// ErrDepartmentHasChildren a department with sub departments can not be deleted
+	ErrDepartmentHasChildren = errors.New("department has children")
+
+	// ErrRoleCycle a role would inherit itself through its parents
+	ErrRoleCycle = errors.New("role inheritance cycle")
+
+	// ErrElevationStatus a role elevation is not in the status the step requires, e.g. approving a rejected request
+	ErrElevationStatus = errors.New("invalid role elevation status")
 )
 
 var (
@@ -114,6 +142,10 @@ func InitDB() {
 	switch strings.ToLower(config.Get().Database.Driver) {
 	case ggorm.DBDriverMysql, ggorm.DBDriverTidb:
 		InitMysql()
+	case ggorm.DBDriverPostgresql, "postgres":
+		InitPostgresql()
+	case ggorm.DBDriverSqlite:
+		InitSqlite()
 	default:
 		panic("InitDB error, unsupported database driver: " + config.Get().Database.Driver)
 	}
@@ -155,6 +187,58 @@ func InitMysql() {
 	}
 }
 
+// InitPostgresql connect postgresql
+func InitPostgresql() {
+	opts := []ggorm.Option{
+		ggorm.WithMaxIdleConns(config.Get().Database.Postgresql.MaxIdleConns),
+		ggorm.WithMaxOpenConns(config.Get().Database.Postgresql.MaxOpenConns),
+		ggorm.WithConnMaxLifetime(time.Duration(config.Get().Database.Postgresql.ConnMaxLifetime) * time.Minute),
+	}
+	if config.Get().Database.Postgresql.EnableLog {
+		opts = append(opts,
+			ggorm.WithLogging(logger.Get()),
+			ggorm.WithLogRequestIDKey("request_id"),
+		)
+	}
+
+	if config.Get().App.EnableTrace {
+		opts = append(opts, ggorm.WithEnableTrace())
+	}
+
+	var dsn = utils.AdaptivePostgresqlDsn(config.Get().Database.Postgresql.Dsn)
+	var err error
+	db, err = ggorm.InitPostgresql(dsn, opts...)
+	if err != nil {
+		panic("InitPostgresql error: " + err.Error())
+	}
+}
+
+// InitSqlite connect sqlite
+func InitSqlite() {
+	opts := []ggorm.Option{
+		ggorm.WithMaxIdleConns(config.Get().Database.Sqlite.MaxIdleConns),
+		ggorm.WithMaxOpenConns(config.Get().Database.Sqlite.MaxOpenConns),
+		ggorm.WithConnMaxLifetime(time.Duration(config.Get().Database.Sqlite.ConnMaxLifetime) * time.Minute),
+	}
+	if config.Get().Database.Sqlite.EnableLog {
+		opts = append(opts,
+			ggorm.WithLogging(logger.Get()),
+			ggorm.WithLogRequestIDKey("request_id"),
+		)
+	}
+
+	if config.Get().App.EnableTrace {
+		opts = append(opts, ggorm.WithEnableTrace())
+	}
+
+	var dbFile = utils.AdaptiveSqlite(config.Get().Database.Sqlite.DBFile)
+	var err error
+	db, err = ggorm.InitSqlite(dbFile, opts...)
+	if err != nil {
+		panic("InitSqlite error: " + err.Error())
+	}
+}
+
 // GetDB get db
 func GetDB() *gorm.DB {
 	if db == nil {
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// the results of a login attempt
+const (
+	LoginResultSuccess   = "success"   // the password is correct
+	LoginResultFailed    = "failed"    // the user does not exist or the password is wrong
+	LoginResultLocked    = "locked"    // the account is locked temporarily by too many failures
+	LoginResultThrottled = "throttled" // the ip is locked temporarily by too many failures
+	LoginResultBlocked   = "blocked"   // the user status is blocked
+	LoginResultChallenge = "challenge" // the password is correct, the TOTP code is required
+)
+
+// LoginHistory a login attempt
+type LoginHistory struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time, the created time is the time of the attempt
+
+	UserID    uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`             // user id, 0 if no user has the name
+	Name      string `gorm:"column:name;type:varchar(50);NOT NULL" json:"name"`             // username of the attempt
+	IP        string `gorm:"column:ip;type:varchar(64);NOT NULL" json:"ip"`                 // client ip
+	UserAgent string `gorm:"column:user_agent;type:varchar(255);NOT NULL" json:"userAgent"` // user agent of the client
+	Result    string `gorm:"column:result;type:varchar(20);NOT NULL" json:"result"`         // success, failed, locked, throttled, blocked or challenge
+}
+
+// TableName table name
+func (m *LoginHistory) TableName() string {
+	return "login_history"
+}
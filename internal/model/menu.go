@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// the types of the menus, the directories and the menus are shown in the navigation of the frontend,
+// the buttons only grant a permission key to the actions of their parent menu
+const (
+	MenuTypeDirectory = "directory"
+	MenuTypeMenu      = "menu"
+	MenuTypeButton    = "button"
+)
+
+// Menu a node of the menu tree of the admin frontend
+type Menu struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	ParentID   uint64 `gorm:"column:parent_id;type:bigint;NOT NULL;default:0" json:"parentId"`         // parent menu id, 0 for a top level menu
+	Type       string `gorm:"column:type;type:varchar(20);NOT NULL" json:"type"`                       // directory, menu or button
+	Title      string `gorm:"column:title;type:varchar(100);NOT NULL" json:"title"`                    // shown in the navigation
+	Name       string `gorm:"column:name;type:varchar(100);NOT NULL;default:''" json:"name"`           // route name of the frontend
+	Path       string `gorm:"column:path;type:varchar(255);NOT NULL;default:''" json:"path"`           // route path of the frontend
+	Component  string `gorm:"column:component;type:varchar(255);NOT NULL;default:''" json:"component"` // view component of the frontend
+	Icon       string `gorm:"column:icon;type:varchar(100);NOT NULL;default:''" json:"icon"`
+	Permission string `gorm:"column:permission;type:varchar(100);NOT NULL;default:''" json:"permission"` // permission key checked by the frontend, e.g. user:create
+	Sort       int    `gorm:"column:sort;type:int;NOT NULL;default:0" json:"sort"`                       // the siblings are sorted in ascending order
+	Hidden     bool   `gorm:"column:hidden;NOT NULL;default:false" json:"hidden"`                        // routed but not shown in the navigation
+}
+
+// TableName table name
+func (m *Menu) TableName() string {
+	return "menu"
+}
+
+// RoleMenu grants a menu to a role, the members of the role see the menu
+type RoleMenu struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	RoleID uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"` // role id, refers to role.id
+	MenuID uint64 `gorm:"column:menu_id;type:bigint;NOT NULL" json:"menuId"` // menu id, refers to menu.id
+}
+
+// TableName table name
+func (m *RoleMenu) TableName() string {
+	return "role_menu"
+}
@@ -1,26 +1,90 @@
 package model
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/zhufuyi/sponge/pkg/ggorm"
 )
 
+// DataScopeAll the data scope of the roles permitted in all the kubernetes namespaces
+const DataScopeAll = "*"
+
+// the data scope modes of a role, the records of the users visible to its members
+const (
+	DataScopeModeAll             = "all"               // all the users
+	DataScopeModeDept            = "dept"              // the users of the departments of the member
+	DataScopeModeDeptAndChildren = "dept_and_children" // the users of the departments of the member and of their sub departments
+	DataScopeModeSelf            = "self"              // only the member
+	DataScopeModeCustom          = "custom"            // the users of the departments chosen for the role
+)
+
 type Role struct {
 	ggorm.Model `gorm:"embedded"` // embed id and time
 
-	RoleID    int    `gorm:"column:role_id;type:int(11);primary_key" json:"roleId"`
-	RoleName  string `gorm:"column:role_name;type:text" json:"roleName"`
-	Status    string `gorm:"column:status;type:text" json:"status"`
-	RoleKey   string `gorm:"column:role_key;type:text" json:"roleKey"`
-	RoleSort  int    `gorm:"column:role_sort;type:int(11)" json:"roleSort"`
-	Flag      string `gorm:"column:flag;type:text" json:"flag"`
-	Remark    string `gorm:"column:remark;type:text" json:"remark"`
-	Admin     string `gorm:"column:admin;type:decimal(10)" json:"admin"`
-	DataScope string `gorm:"column:data_scope;type:text" json:"dataScope"`
-	CreateBy  int    `gorm:"column:create_by;type:int(11)" json:"createBy"`
-	UpdateBy  int    `gorm:"column:update_by;type:int(11)" json:"updateBy"`
+	RoleID           int    `gorm:"column:role_id;type:int;primary_key" json:"roleId"`
+	RoleName         string `gorm:"column:role_name;type:text" json:"roleName"`
+	Status           string `gorm:"column:status;type:text" json:"status"`
+	RoleKey          string `gorm:"column:role_key;type:text" json:"roleKey"`
+	RoleSort         int    `gorm:"column:role_sort;type:int" json:"roleSort"`
+	Flag             string `gorm:"column:flag;type:text" json:"flag"`
+	Remark           string `gorm:"column:remark;type:text" json:"remark"`
+	Admin            string `gorm:"column:admin;type:decimal(10)" json:"admin"`
+	DataScope        string `gorm:"column:data_scope;type:text" json:"dataScope"`                                      // kubernetes namespaces permitted to the members, comma separated, * is all
+	DataScopeMode    string `gorm:"column:data_scope_mode;type:varchar(20);NOT NULL;default:all" json:"dataScopeMode"` // the users visible to the members, one of the DataScopeMode constants
+	RequireTwoFactor bool   `gorm:"column:require_two_factor;NOT NULL;default:false" json:"requireTwoFactor"`          // the members must log in with a TOTP code
+	CreateBy         int    `gorm:"column:create_by;type:int" json:"createBy"`
+	UpdateBy         int    `gorm:"column:update_by;type:int" json:"updateBy"`
+	DeletedBy        uint64 `gorm:"column:deleted_by;type:bigint;NOT NULL;default:0" json:"deletedBy"` // id of the user who soft deleted the record
+	Version          uint64 `gorm:"column:version;type:bigint;NOT NULL;default:1" json:"version"`      // incremented by every update, for optimistic concurrency control
 }
 
 // TableName table name
 func (m *Role) TableName() string {
 	return "role"
 }
+
+// Namespaces the kubernetes namespaces permitted by the data scope, sorted and without duplicates,
+// all is true if the data scope permits all the namespaces
+func (m *Role) Namespaces() (namespaces []string, all bool) {
+	seen := map[string]bool{}
+	for _, ns := range strings.Split(m.DataScope, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == DataScopeAll {
+			return nil, true
+		}
+		if ns != "" && !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, false
+}
+
+// RoleParent makes a role inherit the grants of a parent role, a composite role has several parents,
+// e.g. the role admin has the parent operator whose parent is viewer
+type RoleParent struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	RoleID   uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"`     // role id, refers to role.id
+	ParentID uint64 `gorm:"column:parent_id;type:bigint;NOT NULL" json:"parentId"` // id of the inherited role, refers to role.id
+}
+
+// TableName table name
+func (m *RoleParent) TableName() string {
+	return "role_parent"
+}
+
+// RoleApi grants an api to a role, the roles inheriting the role are granted the api too
+type RoleApi struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	RoleID uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"` // role id, refers to role.id
+	ApiID  uint64 `gorm:"column:api_id;type:bigint;NOT NULL" json:"apiId"`   // api id, refers to api.id
+}
+
+// TableName table name
+func (m *RoleApi) TableName() string {
+	return "role_api"
+}
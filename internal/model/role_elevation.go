@@ -0,0 +1,69 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// the statuses of a role elevation
+const (
+	ElevationStatusPending   = "pending"   // waiting for an approver
+	ElevationStatusApproved  = "approved"  // the role is held until expires_at
+	ElevationStatusRejected  = "rejected"  // refused by an approver
+	ElevationStatusCancelled = "cancelled" // withdrawn by the requester before the review
+	ElevationStatusRevoked   = "revoked"   // ended by an approver before expires_at
+	ElevationStatusExpired   = "expired"   // ended at expires_at
+)
+
+// the actions recorded in the events of a role elevation
+const (
+	ElevationActionRequest = "request"
+	ElevationActionApprove = "approve"
+	ElevationActionReject  = "reject"
+	ElevationActionCancel  = "cancel"
+	ElevationActionRevoke  = "revoke"
+	ElevationActionExpire  = "expire"
+	ElevationActionUse     = "use" // a request made by the user while the role is held
+)
+
+// RoleElevation a request of a user to hold a role for a limited time, the role is held from the approval
+// until expires_at, the records are kept for the audit
+type RoleElevation struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID        uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`                                // id of the requester, refers to user.id
+	RoleID        uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"`                                // id of the requested role, refers to role.id
+	Reason        string `gorm:"column:reason;type:varchar(500);NOT NULL" json:"reason"`                           // justification given by the requester
+	Duration      int    `gorm:"column:duration;type:int;NOT NULL" json:"duration"`                                // seconds the role is held from the approval
+	Status        string `gorm:"column:status;type:varchar(20);NOT NULL" json:"status"`                            // see ElevationStatus*
+	ReviewerID    uint64 `gorm:"column:reviewer_id;type:bigint;NOT NULL;default:0" json:"reviewerId"`              // id of the user who approved or rejected the request
+	ReviewComment string `gorm:"column:review_comment;type:varchar(500);NOT NULL;default:''" json:"reviewComment"` // comment of the reviewer
+	ReviewedAt    int64  `gorm:"column:reviewed_at;type:bigint;NOT NULL;default:0" json:"reviewedAt"`              // unix time of the approval or the rejection
+	ExpiresAt     int64  `gorm:"column:expires_at;type:bigint;NOT NULL;default:0" json:"expiresAt"`                // unix time the role is no longer held, 0 until the approval
+	EndedAt       int64  `gorm:"column:ended_at;type:bigint;NOT NULL;default:0" json:"endedAt"`                    // unix time of the revocation or the expiry
+	EndedBy       uint64 `gorm:"column:ended_by;type:bigint;NOT NULL;default:0" json:"endedBy"`                    // id of the user who revoked the grant, 0 if it expired
+}
+
+// TableName table name
+func (m *RoleElevation) TableName() string {
+	return "role_elevation"
+}
+
+// Active whether the role is held at the unix time
+func (m *RoleElevation) Active(now int64) bool {
+	return m.Status == ElevationStatusApproved && m.ExpiresAt > now
+}
+
+// RoleElevationEvent a step of a role elevation
+type RoleElevationEvent struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	ElevationID uint64 `gorm:"column:elevation_id;type:bigint;NOT NULL" json:"elevationId"`       // refers to role_elevation.id
+	ActorID     uint64 `gorm:"column:actor_id;type:bigint;NOT NULL;default:0" json:"actorId"`     // id of the user who did the step, 0 for the expiry
+	Action      string `gorm:"column:action;type:varchar(20);NOT NULL" json:"action"`             // see ElevationAction*
+	Detail      string `gorm:"column:detail;type:varchar(500);NOT NULL;default:''" json:"detail"` // the reason, the comment, or the method, the path and the status of a request
+}
+
+// TableName table name
+func (m *RoleElevationEvent) TableName() string {
+	return "role_elevation_event"
+}
@@ -4,18 +4,27 @@ import (
 	"github.com/zhufuyi/sponge/pkg/ggorm"
 )
 
+// the account status of a user
+const (
+	UserStatusInactive  = 1
+	UserStatusActivated = 2
+	UserStatusBlocked   = 3 // set by too many failed logins or by an admin, the user can not login
+)
+
 type User struct {
 	ggorm.Model `gorm:"embedded"` // embed id and time
 
-	Name     string `gorm:"column:name;type:char(50);NOT NULL" json:"name"`                   // username
-	Password string `gorm:"column:password;type:char(100);NOT NULL" json:"password"`          // password
-	Email    string `gorm:"column:email;type:char(50);NOT NULL" json:"email"`                 // email
-	Phone    string `gorm:"column:phone;type:char(30);NOT NULL" json:"phone"`                 // phone number
-	Avatar   string `gorm:"column:avatar;type:varchar(200)" json:"avatar"`                    // avatar
-	Age      int    `gorm:"column:age;type:tinyint(4);NOT NULL" json:"age"`                   // age
-	Gender   int    `gorm:"column:gender;type:tinyint(4);NOT NULL" json:"gender"`             // gender, 1:Male, 2:Female, other values:unknown
-	Status   int    `gorm:"column:status;type:tinyint(4);NOT NULL" json:"status"`             // account status, 1:inactive, 2:activated, 3:blocked
-	LoginAt  uint64 `gorm:"column:login_at;type:bigint(20) unsigned;NOT NULL" json:"loginAt"` // login timestamp
+	Name      string `gorm:"column:name;type:varchar(50);NOT NULL" json:"name"`                 // username
+	Password  string `gorm:"column:password;type:varchar(100);NOT NULL" json:"password"`        // password
+	Email     string `gorm:"column:email;type:varchar(50);NOT NULL" json:"email"`               // email
+	Phone     string `gorm:"column:phone;type:varchar(30);NOT NULL" json:"phone"`               // phone number
+	Avatar    string `gorm:"column:avatar;type:varchar(200)" json:"avatar"`                     // avatar
+	Age       int    `gorm:"column:age;type:smallint;NOT NULL" json:"age"`                      // age
+	Gender    int    `gorm:"column:gender;type:smallint;NOT NULL" json:"gender"`                // gender, 1:Male, 2:Female, other values:unknown
+	Status    int    `gorm:"column:status;type:smallint;NOT NULL" json:"status"`                // account status, 1:inactive, 2:activated, 3:blocked
+	LoginAt   uint64 `gorm:"column:login_at;type:bigint;NOT NULL" json:"loginAt"`               // login timestamp
+	DeletedBy uint64 `gorm:"column:deleted_by;type:bigint;NOT NULL;default:0" json:"deletedBy"` // id of the user who soft deleted the record
+	Version   uint64 `gorm:"column:version;type:bigint;NOT NULL;default:1" json:"version"`      // incremented by every update, for optimistic concurrency control
 }
 
 // TableName table name
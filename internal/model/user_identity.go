@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// UserIdentity links a user to its account at an external identity provider, a user can have several
+type UserIdentity struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID  uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`        // user id
+	Issuer  string `gorm:"column:issuer;type:varchar(255);NOT NULL" json:"issuer"`   // issuer url of the identity provider
+	Subject string `gorm:"column:subject;type:varchar(255);NOT NULL" json:"subject"` // id of the account at the identity provider
+	Email   string `gorm:"column:email;type:varchar(50);NOT NULL" json:"email"`      // email claimed by the identity provider at the last login
+}
+
+// TableName table name
+func (m *UserIdentity) TableName() string {
+	return "user_identity"
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// UserRole binds a user to a role, a user can hold several roles
+type UserRole struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"` // user id
+	RoleID uint64 `gorm:"column:role_id;type:bigint;NOT NULL" json:"roleId"` // role id, refers to role.id
+}
+
+// TableName table name
+func (m *UserRole) TableName() string {
+	return "user_role"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+)
+
+// UserTOTP the TOTP authenticator of a user, a user has at most one
+type UserTOTP struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID      uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`           // user id
+	Secret      string `gorm:"column:secret;type:varchar(64);NOT NULL" json:"-"`            // base32 secret of the authenticator
+	Enabled     bool   `gorm:"column:enabled;NOT NULL;default:false" json:"enabled"`        // false until the enrollment is confirmed with a code
+	LastCounter uint64 `gorm:"column:last_counter;type:bigint;NOT NULL;default:0" json:"-"` // time step of the last accepted code, a code is accepted once
+}
+
+// TableName table name
+func (m *UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+// UserRecoveryCode a one-time code replacing the TOTP code when the authenticator is lost
+type UserRecoveryCode struct {
+	ggorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID   uint64 `gorm:"column:user_id;type:bigint;NOT NULL" json:"userId"`           // user id
+	CodeHash string `gorm:"column:code_hash;type:varchar(64);NOT NULL" json:"-"`         // sha256 of the recovery code
+	UsedAt   int64  `gorm:"column:used_at;type:bigint;NOT NULL;default:0" json:"usedAt"` // unix time the code was used, 0 if it is unused
+}
+
+// TableName table name
+func (m *UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
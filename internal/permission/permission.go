@@ -0,0 +1,175 @@
+// Package permission computes the effective permissions of a user, the roles bound to the user and the
+// roles they inherit through their parents, with the Api grants and the namespace scopes of all of them.
+// Every permission keeps the roles that granted it, so that an administrator can tell where it comes from.
+package permission
+
+import (
+	"sort"
+	"strings"
+
+	"go-admin/internal/model"
+)
+
+// RoleGrant a role held by a user
+type RoleGrant struct {
+	Role     *model.Role
+	Via      []*model.Role // the chain from the role bound to the user to the role, the role is the last one
+	Elevated bool          // the first role of the chain is held through a temporary elevation, not bound to the user
+}
+
+// Inherited whether the role is held through the parents of a role bound to the user
+func (g *RoleGrant) Inherited() bool {
+	return len(g.Via) > 1
+}
+
+// Inherit the roles bound to the user followed by the roles they inherit, each role once with its shortest
+// chain, the parents that are not in roles, e.g. the deleted ones, are skipped with their own parents
+func Inherit(direct []*model.Role, roles map[uint64]*model.Role, parents map[uint64][]uint64) []*RoleGrant {
+	grants := []*RoleGrant{}
+	seen := map[uint64]bool{}
+	queue := []*RoleGrant{}
+	for _, role := range direct {
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			queue = append(queue, &RoleGrant{Role: role, Via: []*model.Role{role}})
+		}
+	}
+	for len(queue) > 0 {
+		grant := queue[0]
+		queue = queue[1:]
+		grants = append(grants, grant)
+		for _, parentID := range parents[grant.Role.ID] {
+			parent, ok := roles[parentID]
+			if !ok || seen[parentID] {
+				continue
+			}
+			seen[parentID] = true
+			via := append(append([]*model.Role{}, grant.Via...), parent)
+			queue = append(queue, &RoleGrant{Role: parent, Via: via})
+		}
+	}
+	return grants
+}
+
+// Ancestors the role and all the roles it inherits through the parents
+func Ancestors(roleID uint64, parents map[uint64][]uint64) map[uint64]bool {
+	seen := map[uint64]bool{}
+	queue := []uint64{roleID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		queue = append(queue, parents[id]...)
+	}
+	return seen
+}
+
+// ApiGrant an api granted to the user with the roles granting it
+type ApiGrant struct {
+	Api   *model.Api
+	Roles []*RoleGrant
+}
+
+// NamespaceGrant a kubernetes namespace permitted to the user with the roles permitting it
+type NamespaceGrant struct {
+	Namespace string
+	Roles     []*RoleGrant
+}
+
+// Set the effective permissions of a user
+type Set struct {
+	Roles              []*RoleGrant
+	Apis               []*ApiGrant       // sorted by path and action
+	Namespaces         []*NamespaceGrant // sorted by namespace
+	AllNamespacesRoles []*RoleGrant      // the roles whose data scope is model.DataScopeAll
+}
+
+// New merge the Api grants and the namespace scopes of the roles, apis are the apis granted to each role
+func New(roles []*RoleGrant, apis map[uint64][]*model.Api) *Set {
+	set := &Set{Roles: roles}
+	apiGrants := map[uint64]*ApiGrant{}
+	nsGrants := map[string]*NamespaceGrant{}
+	for _, grant := range roles {
+		for _, api := range apis[grant.Role.ID] {
+			if apiGrants[api.ID] == nil {
+				apiGrants[api.ID] = &ApiGrant{Api: api}
+				set.Apis = append(set.Apis, apiGrants[api.ID])
+			}
+			apiGrants[api.ID].Roles = append(apiGrants[api.ID].Roles, grant)
+		}
+
+		namespaces, all := grant.Role.Namespaces()
+		if all {
+			set.AllNamespacesRoles = append(set.AllNamespacesRoles, grant)
+			continue
+		}
+		for _, ns := range namespaces {
+			if nsGrants[ns] == nil {
+				nsGrants[ns] = &NamespaceGrant{Namespace: ns}
+				set.Namespaces = append(set.Namespaces, nsGrants[ns])
+			}
+			nsGrants[ns].Roles = append(nsGrants[ns].Roles, grant)
+		}
+	}
+
+	sort.Slice(set.Apis, func(i, j int) bool {
+		a, b := set.Apis[i].Api, set.Apis[j].Api
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return strings.ToUpper(a.Action) < strings.ToUpper(b.Action)
+	})
+	sort.Slice(set.Namespaces, func(i, j int) bool { return set.Namespaces[i].Namespace < set.Namespaces[j].Namespace })
+	return set
+}
+
+// ExplainApi the roles granting the api of the method and the path, the path is either the route of the
+// api, e.g. /api/v1/user/:id, or a request path, e.g. /api/v1/user/12. nil if the api is not granted
+func (s *Set) ExplainApi(method string, path string) []*RoleGrant {
+	var roles []*RoleGrant
+	for _, grant := range s.Apis {
+		if strings.EqualFold(grant.Api.Action, method) && MatchPath(grant.Api.Path, path) {
+			roles = append(roles, grant.Roles...)
+		}
+	}
+	return roles
+}
+
+// ExplainNamespace the roles permitting the kubernetes namespace, nil if the namespace is not permitted
+func (s *Set) ExplainNamespace(namespace string) []*RoleGrant {
+	roles := append([]*RoleGrant{}, s.AllNamespacesRoles...)
+	for _, grant := range s.Namespaces {
+		if grant.Namespace == namespace {
+			roles = append(roles, grant.Roles...)
+		}
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	return roles
+}
+
+// MatchPath whether the path is the route or matches its parameters, :name matches a segment and
+// *name matches the rest of the path
+func MatchPath(route string, path string) bool {
+	if route == path {
+		return true
+	}
+	routeParts := strings.Split(strings.Trim(route, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range routeParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(routeParts) == len(pathParts)
+}
@@ -0,0 +1,106 @@
+package permission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-admin/internal/model"
+)
+
+func newRole(id uint64, key string, dataScope string) *model.Role {
+	role := &model.Role{RoleKey: key, DataScope: dataScope}
+	role.ID = id
+	return role
+}
+
+func newApi(id uint64, action string, path string) *model.Api {
+	api := &model.Api{Action: action, Path: path}
+	api.ID = id
+	return api
+}
+
+func roleKeys(grants []*RoleGrant) []string {
+	keys := []string{}
+	for _, grant := range grants {
+		chain := ""
+		for i, role := range grant.Via {
+			if i > 0 {
+				chain += ">"
+			}
+			chain += role.RoleKey
+		}
+		keys = append(keys, chain)
+	}
+	return keys
+}
+
+// viewer (1) < operator (2) < admin (3), auditor (4) is also a parent of admin, and the deleted role 5
+func testRoles() (map[uint64]*model.Role, map[uint64][]uint64) {
+	roles := map[uint64]*model.Role{
+		1: newRole(1, "viewer", "dev"),
+		2: newRole(2, "operator", "staging, dev"),
+		3: newRole(3, "admin", ""),
+		4: newRole(4, "auditor", "*"),
+	}
+	parents := map[uint64][]uint64{3: {2, 4}, 2: {1, 5}, 5: {1}}
+	return roles, parents
+}
+
+func TestInherit(t *testing.T) {
+	roles, parents := testRoles()
+	grants := Inherit([]*model.Role{roles[3]}, roles, parents)
+	assert.Equal(t, []string{"admin", "admin>operator", "admin>auditor", "admin>operator>viewer"}, roleKeys(grants))
+	assert.False(t, grants[0].Inherited())
+	assert.True(t, grants[3].Inherited())
+
+	// a role bound to the user directly is not inherited
+	grants = Inherit([]*model.Role{roles[1], roles[2]}, roles, parents)
+	assert.Equal(t, []string{"viewer", "operator"}, roleKeys(grants))
+	assert.Empty(t, Inherit(nil, roles, parents))
+
+	assert.Equal(t, map[uint64]bool{3: true, 2: true, 4: true, 1: true, 5: true}, Ancestors(3, parents))
+	assert.Equal(t, map[uint64]bool{4: true}, Ancestors(4, parents))
+}
+
+func TestSet(t *testing.T) {
+	roles, parents := testRoles()
+	apis := map[uint64][]*model.Api{
+		1: {newApi(1, "GET", "/api/v1/user/:id")},
+		2: {newApi(2, "post", "/api/v1/user/list"), newApi(1, "GET", "/api/v1/user/:id")},
+	}
+
+	set := New(Inherit([]*model.Role{roles[2]}, roles, parents), apis)
+	assert.Len(t, set.Apis, 2)
+	assert.Equal(t, "/api/v1/user/:id", set.Apis[0].Api.Path)
+	assert.Equal(t, []string{"operator", "operator>viewer"}, roleKeys(set.Apis[0].Roles))
+	assert.Equal(t, []string{"operator", "operator>viewer"}, roleKeys(set.ExplainApi("get", "/api/v1/user/12")))
+	assert.Equal(t, []string{"operator"}, roleKeys(set.ExplainApi("POST", "/api/v1/user/list")))
+	assert.Nil(t, set.ExplainApi("DELETE", "/api/v1/user/12"))
+	assert.Equal(t, []string{"operator", "operator>viewer"}, roleKeys(set.ExplainNamespace("dev")))
+	assert.Nil(t, set.ExplainNamespace("prod"))
+	assert.Equal(t, "dev", set.Namespaces[0].Namespace)
+	assert.Equal(t, "staging", set.Namespaces[1].Namespace)
+
+	set = New(Inherit([]*model.Role{roles[3]}, roles, parents), apis)
+	assert.Equal(t, []string{"admin>auditor"}, roleKeys(set.ExplainNamespace("prod")))
+	assert.Equal(t, []string{"admin>auditor", "admin>operator", "admin>operator>viewer"}, roleKeys(set.ExplainNamespace("dev")))
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		route string
+		path  string
+		want  bool
+	}{
+		{"/api/v1/user/:id", "/api/v1/user/:id", true},
+		{"/api/v1/user/:id", "/api/v1/user/12", true},
+		{"/api/v1/user/:id", "/api/v1/user/12/roles", false},
+		{"/api/v1/user/:id", "/api/v1/user", false},
+		{"/api/v1/user/list", "/api/v1/user/12", false},
+		{"/api/v1/proxy/*path", "/api/v1/proxy/a/b", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPath(tt.route, tt.path), tt.route+" "+tt.path)
+	}
+}
@@ -0,0 +1,85 @@
+package routers
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/gin/response"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/bootstrap"
+	"go-admin/internal/config"
+	"go-admin/internal/dao"
+	"go-admin/internal/ecode"
+	"go-admin/internal/model"
+)
+
+var (
+	userRoleDao     dao.UserRoleDao
+	userRoleDaoOnce sync.Once
+)
+
+// admin allow only the users holding an admin role, bound, inherited or elevated, it follows auth() on the route
+func admin() gin.HandlerFunc {
+	return requireAdmin(getUserRoleDao(), adminRoles(), false)
+}
+
+// selfOrAdmin allow the user whose id is the :id of the route, and the users holding an admin role
+func selfOrAdmin() gin.HandlerFunc {
+	return requireAdmin(getUserRoleDao(), adminRoles(), true)
+}
+
+func getUserRoleDao() dao.UserRoleDao {
+	userRoleDaoOnce.Do(func() {
+		userRoleDao = dao.NewUserRoleDao(model.GetDB())
+	})
+	return userRoleDao
+}
+
+// adminRoles the role keys of the admins, the bootstrap admin role if none is configured
+func adminRoles() []string {
+	if roles := config.Get().Auth.AdminRoles; len(roles) > 0 {
+		return roles
+	}
+	return []string{bootstrap.AdminRoleKey}
+}
+
+// requireAdmin the requests without a user are unauthorized, the users without one of the role keys are forbidden,
+// unless self is true and the :id of the route is the user
+func requireAdmin(urDao dao.UserRoleDao, roleKeys []string, self bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid, err := utils.StrToUint64E(c.GetString("uid"))
+		if err != nil || uid == 0 {
+			response.Error(c, ecode.Unauthorized)
+			c.Abort()
+			return
+		}
+		if self && c.Param("id") == utils.Uint64ToStr(uid) {
+			c.Next()
+			return
+		}
+
+		roles, err := urDao.GetInheritedRolesByUserID(middleware.WrapCtx(c), uid)
+		if err != nil {
+			logger.Error("GetInheritedRolesByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			c.Abort()
+			return
+		}
+		for _, role := range roles {
+			for _, key := range roleKeys {
+				if role.RoleKey == key {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		logger.Warn("admin role required", logger.Any("id", uid), logger.String("path", c.FullPath()), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.Forbidden)
+		c.Abort()
+	}
+}
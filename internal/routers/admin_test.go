@@ -0,0 +1,66 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	_, err = migration.Up(db, ggorm.DBDriverSqlite)
+	require.NoError(t, err)
+	return db
+}
+
+func Test_requireAdmin(t *testing.T) {
+	db := newSQLiteDB(t)
+	require.NoError(t, db.Create(&model.Role{RoleName: "Administrator", RoleKey: "admin"}).Error)
+	require.NoError(t, db.Create(&model.UserRole{UserID: 1, RoleID: 1}).Error)
+	urDao := dao.NewUserRoleDao(db)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if uid := c.GetHeader("X-Uid"); uid != "" {
+			c.Set("uid", uid)
+		}
+	})
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	r.PUT("/user/:id/roles", requireAdmin(urDao, []string{"admin"}, false), ok)
+	r.GET("/user/:id/roles", requireAdmin(urDao, []string{"admin"}, true), ok)
+
+	for _, tt := range []struct {
+		method string
+		path   string
+		uid    string
+		want   string
+	}{
+		{http.MethodPut, "/user/2/roles", "", "Unauthorized"},
+		{http.MethodPut, "/user/2/roles", "2", "Forbidden"},
+		{http.MethodPut, "/user/2/roles", "1", "ok"},
+		{http.MethodGet, "/user/2/roles", "2", "ok"},
+		{http.MethodGet, "/user/3/roles", "2", "Forbidden"},
+		{http.MethodGet, "/user/3/roles", "1", "ok"},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("X-Uid", tt.uid)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Contains(t, w.Body.String(), tt.want, tt.method+" "+tt.path+" "+tt.uid)
+	}
+}
@@ -9,6 +9,7 @@ import (
 func init() {
 	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
 		apiRouter(group, handler.NewApiHandler())
+		apiSyncRouter(group, handler.NewApiSyncHandler(registeredRoutes))
 	})
 }
 
@@ -17,12 +18,22 @@ func apiRouter(group *gin.RouterGroup, h handler.ApiHandler) {
 	// or group.Use(middleware.Auth(middleware.WithVerify(verify))) // token authentication
 
 	group.POST("/api", h.Create)
-	group.DELETE("/api/:id", h.DeleteByID)
-	group.POST("/api/delete/ids", h.DeleteByIDs)
+	group.DELETE("/api/:id", auth(), admin(), h.DeleteByID)
+	group.POST("/api/delete/ids", auth(), admin(), h.DeleteByIDs)
 	group.PUT("/api/:id", h.UpdateByID)
+	group.PATCH("/api/:id", auth(), admin(), h.PatchByID)
 	group.GET("/api/:id", h.GetByID)
 	group.POST("/api/condition", h.GetByCondition)
 	group.POST("/api/list/ids", h.ListByIDs)
 	group.GET("/api/list", h.ListByLastID)
 	group.POST("/api/list", h.List)
+	group.POST("/api/trash/list", auth(), admin(), h.ListTrash)
+	group.POST("/api/trash/:id/restore", auth(), admin(), h.Restore)
+	group.DELETE("/api/trash/:id", auth(), admin(), h.Purge)
+	group.POST("/api/import", auth(), admin(), h.Import)
+	group.POST("/api/export", auth(), admin(), h.Export)
+}
+
+func apiSyncRouter(group *gin.RouterGroup, h handler.ApiSyncHandler) {
+	group.POST("/api/sync", auth(), admin(), h.Sync)
 }
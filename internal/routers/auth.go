@@ -0,0 +1,43 @@
+package routers
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/jwt"
+
+	"go-admin/internal/apitoken"
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/model"
+	"go-admin/internal/session"
+)
+
+var (
+	authHandler gin.HandlerFunc
+	authOnce    sync.Once
+)
+
+// auth authenticate the requests with a jwt or a personal api token, it is used instead of middleware.Auth
+// so that the automations can call the routes with a token, and the jwt of the revoked sessions are rejected.
+// The active temporary roles of the user are resolved once the user is set, before the handler.
+func auth() gin.HandlerFunc {
+	authOnce.Do(func() {
+		verify := session.Verify(cache.NewSessionCache(model.GetCacheType()))
+		authHandler = apitoken.Auth(
+			dao.NewApiTokenDao(model.GetDB()),
+			dao.NewUserDao(model.GetDB(), cache.NewUserCache(model.GetCacheType())),
+			middleware.Auth(middleware.WithVerify(func(claims *jwt.Claims, tokenTail10 string, c *gin.Context) error {
+				if err := verify(claims, tokenTail10, c); err != nil {
+					return err
+				}
+				resolveElevations(c)
+				return nil
+			})),
+			resolveElevations,
+		)
+	})
+	return authHandler
+}
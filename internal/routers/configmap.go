@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		configMapRouter(group, handler.NewConfigMapHandler())
+	})
+}
+
+func configMapRouter(group *gin.RouterGroup, h handler.ConfigMapHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	group.GET("/namespaces/:namespace/configmaps", h.List)
+	group.GET("/namespaces/:namespace/configmaps/:name", h.GetByName)
+	group.PUT("/namespaces/:namespace/configmaps/:name/keys/:key", h.SetKey)
+	group.DELETE("/namespaces/:namespace/configmaps/:name/keys/:key", h.DeleteKey)
+}
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		customResourceRouter(group, handler.NewCustomResourceHandler())
+	})
+}
+
+func customResourceRouter(group *gin.RouterGroup, h handler.CustomResourceHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	group.GET("/apis", h.ListAPIResources)
+	group.GET("/crds", h.ListCRDs)
+	group.GET("/apis/:group/:version/:resource", h.List)
+	group.GET("/apis/:group/:version/:resource/:name", h.GetByName)
+	group.PUT("/apis/:group/:version/:resource/:name", h.UpdateByName)
+	group.DELETE("/apis/:group/:version/:resource/:name", h.DeleteByName)
+}
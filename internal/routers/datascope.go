@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/dao"
+	"go-admin/internal/datascope"
+	"go-admin/internal/model"
+)
+
+var (
+	deptDao     dao.DepartmentDao
+	deptDaoOnce sync.Once
+)
+
+// dataScope put the data scope of the authenticated user in the context of the request, it is computed the first
+// time a list needs it, after the authentication of the route, the requests without a user see nothing
+func dataScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := datascope.WithResolver(c.Request.Context(), func(ctx context.Context) (*datascope.Scope, error) {
+			uid, err := utils.StrToUint64E(c.GetString("uid"))
+			if err != nil || uid == 0 {
+				return &datascope.Scope{}, nil // fail closed, a nil scope is not restricted
+			}
+			deptDaoOnce.Do(func() {
+				deptDao = dao.NewDepartmentDao(model.GetDB())
+			})
+			return deptDao.GetDataScope(ctx, uid)
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		departmentRouter(group, handler.NewDepartmentHandler())
+	})
+}
+
+func departmentRouter(group *gin.RouterGroup, h handler.DepartmentHandler) {
+	group.POST("/department", auth(), admin(), h.Create)
+	group.DELETE("/department/:id", auth(), admin(), h.DeleteByID)
+	group.PUT("/department/:id", auth(), admin(), h.UpdateByID)
+	group.GET("/department/:id", auth(), admin(), h.GetByID)
+	group.GET("/department/tree", auth(), admin(), h.GetTree)
+	group.GET("/user/:id/departments", auth(), selfOrAdmin(), h.GetUserDepartments)
+	group.PUT("/user/:id/departments", auth(), admin(), h.SetUserDepartments)
+	group.GET("/role/:id/departments", auth(), admin(), h.GetRoleDepartments)
+	group.PUT("/role/:id/departments", auth(), admin(), h.SetRoleDepartments)
+}
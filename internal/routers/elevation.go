@@ -0,0 +1,125 @@
+package routers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/model"
+)
+
+const (
+	// elevationsKey the key of the active grants of the authenticated user in the gin context
+	elevationsKey = "elevations"
+
+	// elevationCacheTTL the longest time the grants of a user are cached, the approvals and the revocations
+	// forget them at once
+	elevationCacheTTL = time.Minute
+)
+
+var (
+	elevationDao   dao.RoleElevationDao
+	elevationCache cache.RoleElevationCache
+	elevationOnce  sync.Once
+)
+
+func getElevationStore() (dao.RoleElevationDao, cache.RoleElevationCache) {
+	elevationOnce.Do(func() {
+		elevationDao = dao.NewRoleElevationDao(model.GetDB())
+		elevationCache = cache.NewRoleElevationCache(model.GetCacheType())
+	})
+	return elevationDao, elevationCache
+}
+
+// elevation record the requests made with a temporary role in the events of the grants, the active grants are
+// resolved by auth() before the handler, the requests of the users without grant are not recorded
+func elevation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, ok := c.Get(elevationsKey)
+		if !ok {
+			return
+		}
+		iDao, _ := getElevationStore()
+		recordElevatedRequest(c, iDao, value.([]*model.RoleElevation))
+	}
+}
+
+// resolveElevations put the active grants of the authenticated user in the context, it is called by auth()
+// once the user is set
+func resolveElevations(c *gin.Context) {
+	iDao, iCache := getElevationStore()
+	setActiveElevations(c, iDao, iCache)
+}
+
+// setActiveElevations the grants are read from the cache, the database is only queried when they are not cached,
+// the users without grant are cached too, nothing is put in the context if no grant is active
+func setActiveElevations(c *gin.Context, iDao dao.RoleElevationDao, iCache cache.RoleElevationCache) {
+	uid, err := utils.StrToUint64E(c.GetString("uid"))
+	if err != nil || uid == 0 {
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+	now := time.Now()
+	elevations, err := iCache.Get(ctx, uid)
+	if err != nil {
+		if !errors.Is(err, cache.ErrRoleElevationNotFound) {
+			logger.Warn("Get role elevations error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		}
+		elevations, err = iDao.GetActiveByUserID(ctx, uid, now.Unix())
+		if err != nil {
+			logger.Warn("GetActiveByUserID error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			return
+		}
+		if err = iCache.Set(ctx, uid, elevations, cacheDuration(elevations, now)); err != nil {
+			logger.Warn("Set role elevations error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+		}
+	}
+
+	active := []*model.RoleElevation{}
+	for _, record := range elevations {
+		if record.Active(now.Unix()) {
+			active = append(active, record)
+		}
+	}
+	if len(active) > 0 {
+		c.Set(elevationsKey, active)
+	}
+}
+
+// cacheDuration the grants are cached until the first of them expires, at most elevationCacheTTL
+func cacheDuration(elevations []*model.RoleElevation, now time.Time) time.Duration {
+	duration := elevationCacheTTL
+	for _, record := range elevations {
+		if left := time.Unix(record.ExpiresAt, 0).Sub(now); left < duration {
+			duration = left
+		}
+	}
+	if duration < time.Second {
+		duration = time.Second
+	}
+	return duration
+}
+
+// recordElevatedRequest record the request in the events of the active grants of the user
+func recordElevatedRequest(c *gin.Context, iDao dao.RoleElevationDao, elevations []*model.RoleElevation) {
+	uid := elevations[0].UserID
+	detail := fmt.Sprintf("%s %s %d", c.Request.Method, c.Request.URL.Path, c.Writer.Status())
+	if len(detail) > 500 {
+		detail = detail[:500]
+	}
+	err := iDao.RecordUse(middleware.WrapCtx(c), elevations, uid, detail)
+	if err != nil {
+		logger.Warn("RecordUse error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+	}
+}
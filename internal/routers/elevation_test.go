@@ -0,0 +1,97 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/ggorm"
+
+	"go-admin/internal/cache"
+	"go-admin/internal/dao"
+	"go-admin/internal/migration"
+	"go-admin/internal/model"
+)
+
+func Test_elevation(t *testing.T) {
+	db, err := ggorm.InitSqlite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ggorm.CloseSQLDB(db) })
+	_, err = migration.Up(db, ggorm.DBDriverSqlite)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	iDao := dao.NewRoleElevationDao(db)
+	iCache := cache.NewRoleElevationCache(&model.CacheType{CType: "memory"})
+	for _, uid := range []uint64{1, 2} {
+		require.NoError(t, iCache.Del(ctx, uid))
+	}
+	now := time.Now().Unix()
+	require.NoError(t, iDao.Create(ctx, &model.RoleElevation{UserID: 1, RoleID: 1, Reason: "incident", Duration: 600}, now))
+	_, err = iDao.Approve(ctx, 1, 2, "", now)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // replaces elevation() and auth()
+		c.Set("uid", c.GetHeader("X-Uid"))
+		setActiveElevations(c, iDao, iCache)
+		c.Next()
+		if value, ok := c.Get(elevationsKey); ok {
+			recordElevatedRequest(c, iDao, value.([]*model.RoleElevation))
+		}
+	})
+	r.DELETE("/api/v1/user/:id", func(c *gin.Context) {
+		value, _ := c.Get(elevationsKey)
+		elevations, _ := value.([]*model.RoleElevation)
+		c.String(http.StatusAccepted, "%d", len(elevations))
+	})
+	do := func(uid string) string {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/user/7", nil)
+		req.Header.Set("X-Uid", uid)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// the grants are resolved before the handler
+	assert.Equal(t, "1", do("1"))
+	assert.Equal(t, "0", do("2"))
+	assert.Equal(t, "0", do(""))
+
+	// only the request of the user holding the grant is recorded
+	events, err := iDao.GetEvents(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, model.ElevationActionUse, events[2].Action)
+	assert.Equal(t, uint64(1), events[2].ActorID)
+	assert.Equal(t, "DELETE /api/v1/user/7 202", events[2].Detail)
+
+	// the grants are cached, an empty list for the user without grant
+	elevations, err := iCache.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, elevations, 1)
+	elevations, err = iCache.Get(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, elevations)
+
+	// the revocation is seen once the cached grants are forgotten
+	_, err = iDao.Revoke(ctx, 1, 1, "", now)
+	require.NoError(t, err)
+	require.NoError(t, iCache.Del(ctx, 1))
+	assert.Equal(t, "0", do("1"))
+}
+
+func Test_cacheDuration(t *testing.T) {
+	now := time.Unix(1000, 0)
+	assert.Equal(t, elevationCacheTTL, cacheDuration(nil, now))
+	assert.Equal(t, 30*time.Second, cacheDuration([]*model.RoleElevation{{ExpiresAt: 1030}, {ExpiresAt: 2000}}, now))
+	assert.Equal(t, time.Second, cacheDuration([]*model.RoleElevation{{ExpiresAt: 1000}}, now))
+}
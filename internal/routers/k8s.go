@@ -14,7 +14,7 @@ func init() {
 func proxyRouter(group *gin.RouterGroup, h handler.ProxyHandler) {
 	//group.Use(middleware.Auth()) // all of the following routes use jwt authentication
 	// or group.Use(middleware.Auth(middleware.WithVerify(verify))) // token authentication
-	group = group.Group("/proxy")
+	group = group.Group("/proxy", auth(), admin())
 	group.Any("/*path", h.Proxy)
 
 }
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		kubeconfigRouter(group, handler.NewKubeconfigHandler())
+	})
+}
+
+func kubeconfigRouter(group *gin.RouterGroup, h handler.KubeconfigHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	group.POST("/kubeconfig", h.Generate)
+	group.DELETE("/kubeconfig", h.Revoke)
+}
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		menuRouter(group, handler.NewMenuHandler())
+	})
+}
+
+func menuRouter(group *gin.RouterGroup, h handler.MenuHandler) {
+	group.GET("/menu/me/tree", auth(), h.GetMyTree)
+	group.POST("/menu", auth(), admin(), h.Create)
+	group.DELETE("/menu/:id", auth(), admin(), h.DeleteByID)
+	group.PUT("/menu/:id", auth(), admin(), h.UpdateByID)
+	group.GET("/menu/:id", auth(), admin(), h.GetByID)
+	group.GET("/menu/tree", auth(), admin(), h.GetTree)
+	group.GET("/role/:id/menus", auth(), admin(), h.GetRoleMenus)
+	group.PUT("/role/:id/menus", auth(), admin(), h.SetRoleMenus)
+}
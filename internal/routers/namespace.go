@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		namespaceRouter(group, handler.NewNamespaceHandler())
+	})
+}
+
+func namespaceRouter(group *gin.RouterGroup, h handler.NamespaceHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	group.GET("/namespaces", h.List)
+}
@@ -0,0 +1,44 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		portForwardRouter(group, handler.NewPortForwardHandler())
+	})
+}
+
+func portForwardRouter(group *gin.RouterGroup, h handler.PortForwardHandler) {
+	k8sGroup := group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+	k8sGroup.POST("/namespaces/:namespace/portforward", h.Create)
+	k8sGroup.GET("/portforward/sessions", h.List)
+	k8sGroup.DELETE("/portforward/sessions/:id", h.Close)
+
+	// browsers can not set the Authorization header for websockets and page navigations, the jwt may be passed in the query
+	tokenGroup := group.Group("", queryTokenToHeader(), auth())
+	tokenGroup.GET("/k8s/portforward/sessions/:id/tunnel", h.Tunnel)
+	tokenGroup.Any("/portforward/:session/*path", h.Proxy)
+}
+
+// queryTokenToHeader move the token query parameter to the Authorization header if the header is not set,
+// the parameter is removed so that it is not passed on
+func queryTokenToHeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		token := query.Get("token")
+		if token == "" {
+			return
+		}
+		if c.GetHeader(middleware.HeaderAuthorizationKey) == "" {
+			c.Request.Header.Set(middleware.HeaderAuthorizationKey, "Bearer "+token)
+		}
+		query.Del("token")
+		c.Request.URL.RawQuery = query.Encode()
+	}
+}
@@ -16,13 +16,19 @@ func roleRouter(group *gin.RouterGroup, h handler.RoleHandler) {
 	//group.Use(middleware.Auth()) // all of the following routes use jwt authentication
 	// or group.Use(middleware.Auth(middleware.WithVerify(verify))) // token authentication
 
-	group.POST("/role", h.Create)
-	group.DELETE("/role/:id", h.DeleteByID)
-	group.POST("/role/delete/ids", h.DeleteByIDs)
-	group.PUT("/role/:id", h.UpdateByID)
+	group.POST("/role", auth(), admin(), h.Create)
+	group.DELETE("/role/:id", auth(), admin(), h.DeleteByID)
+	group.POST("/role/delete/ids", auth(), admin(), h.DeleteByIDs)
+	group.PUT("/role/:id", auth(), admin(), h.UpdateByID)
+	group.PATCH("/role/:id", auth(), admin(), h.PatchByID)
 	group.GET("/role/:id", h.GetByID)
 	group.POST("/role/condition", h.GetByCondition)
 	group.POST("/role/list/ids", h.ListByIDs)
 	group.GET("/role/list", h.ListByLastID)
 	group.POST("/role/list", h.List)
+	group.POST("/role/trash/list", auth(), admin(), h.ListTrash)
+	group.POST("/role/trash/:id/restore", auth(), admin(), h.Restore)
+	group.DELETE("/role/trash/:id", auth(), admin(), h.Purge)
+	group.POST("/role/import", auth(), admin(), h.Import)
+	group.POST("/role/export", auth(), admin(), h.Export)
 }
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		roleElevationRouter(group, handler.NewRoleElevationHandler())
+	})
+}
+
+func roleElevationRouter(group *gin.RouterGroup, h handler.RoleElevationHandler) {
+	group.POST("/elevation", auth(), h.Create)
+	group.GET("/elevation", auth(), h.List)
+	group.GET("/elevation/me", auth(), h.ListMine)
+	group.GET("/elevation/:id", auth(), h.GetByID)
+	group.POST("/elevation/:id/approve", auth(), h.Approve)
+	group.POST("/elevation/:id/reject", auth(), h.Reject)
+	group.POST("/elevation/:id/cancel", auth(), h.Cancel)
+	group.POST("/elevation/:id/revoke", auth(), h.Revoke)
+}
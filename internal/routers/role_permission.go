@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		rolePermissionRouter(group, handler.NewRolePermissionHandler())
+	})
+}
+
+func rolePermissionRouter(group *gin.RouterGroup, h handler.RolePermissionHandler) {
+	group.GET("/user/me/permissions", auth(), h.GetMyPermissions)
+	group.GET("/role/:id/parents", auth(), admin(), h.GetParents)
+	group.PUT("/role/:id/parents", auth(), admin(), h.SetParents)
+	group.GET("/role/:id/apis", auth(), admin(), h.GetApis)
+	group.PUT("/role/:id/apis", auth(), admin(), h.SetApis)
+	group.GET("/user/:id/permissions", auth(), admin(), h.GetPermissions)
+	group.GET("/user/:id/permissions/explain", auth(), admin(), h.ExplainPermission)
+}
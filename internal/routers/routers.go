@@ -21,6 +21,7 @@ import (
 	"github.com/zhufuyi/sponge/pkg/logger"
 
 	"go-admin/docs"
+	"go-admin/internal/avatar"
 	"go-admin/internal/config"
 )
 
@@ -29,12 +30,19 @@ var (
 	// if you have other group routes you can define them here
 	// example:
 	//     apiV2RouterFns []func(r *gin.RouterGroup)
+
+	engine *gin.Engine // the latest router created by NewRouter, its routes are synced to the api rows
 )
 
 // NewRouter create a new router
 func NewRouter() *gin.Engine {
 	r := gin.New()
 
+	// the client ip of the login limits is read from X-Forwarded-For only if the request comes from a trusted proxy
+	if err := r.SetTrustedProxies(config.Get().HTTP.TrustedProxies); err != nil {
+		panic("trustedProxies error: " + err.Error())
+	}
+
 	r.Use(gin.Recovery())
 	r.Use(middleware.Cors())
 
@@ -101,15 +109,36 @@ func NewRouter() *gin.Engine {
 	// access path /swagger/index.html
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// serve the avatars stored on the local disk
+	if cfg := config.Get().Avatar; cfg.Storage != "s3" {
+		dir, urlPrefix := cfg.Local.Dir, cfg.Local.URLPrefix
+		if dir == "" {
+			dir = avatar.DefaultDir
+		}
+		if urlPrefix == "" {
+			urlPrefix = avatar.DefaultURLPrefix
+		}
+		r.Static(urlPrefix, dir)
+	}
+
 	// register routers, middleware support
-	registerRouters(r, "/api/v1", apiV1RouterFns)
+	registerRouters(r, "/api/v1", apiV1RouterFns, dataScope(), elevation())
 	// if you have other group routes you can add them here
 	// example:
 	//    registerRouters(r, "/api/v2", apiV2RouteFns, middleware.Auth())
 
+	engine = r
 	return r
 }
 
+// registeredRoutes the routes of the router created by NewRouter
+func registeredRoutes() gin.RoutesInfo {
+	if engine == nil {
+		return nil
+	}
+	return engine.Routes()
+}
+
 func registerRouters(r *gin.Engine, groupPath string, routerFns []func(*gin.RouterGroup), handlers ...gin.HandlerFunc) {
 	rg := r.Group(groupPath, handlers...)
 	for _, fn := range routerFns {
@@ -56,11 +56,17 @@ func (u mock) Create(c *gin.Context)         { return }
 func (u mock) DeleteByID(c *gin.Context)     { return }
 func (u mock) DeleteByIDs(c *gin.Context)    { return }
 func (u mock) UpdateByID(c *gin.Context)     { return }
+func (u mock) PatchByID(c *gin.Context)      { return }
 func (u mock) GetByID(c *gin.Context)        { return }
 func (u mock) GetByCondition(c *gin.Context) { return }
 func (u mock) ListByIDs(c *gin.Context)      { return }
 func (u mock) ListByLastID(c *gin.Context)   { return }
 func (u mock) List(c *gin.Context)           { return }
+func (u mock) ListTrash(c *gin.Context)      { return }
+func (u mock) Restore(c *gin.Context)        { return }
+func (u mock) Purge(c *gin.Context)          { return }
+func (u mock) Import(c *gin.Context)         { return }
+func (u mock) Export(c *gin.Context)         { return }
 
 func Test_apiRouter(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
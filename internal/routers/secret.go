@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		secretRouter(group, handler.NewSecretHandler())
+	})
+}
+
+func secretRouter(group *gin.RouterGroup, h handler.SecretHandler) {
+	// secret values are sensitive, all of the following routes use jwt authentication,
+	// the caller is recorded in the audit log
+	group = group.Group("/k8s", auth())
+
+	group.GET("/namespaces/:namespace/secrets", h.List)
+	group.GET("/namespaces/:namespace/secrets/:name", h.GetByName)
+	group.POST("/namespaces/:namespace/secrets/:name/reveal", h.Reveal)
+	group.PUT("/namespaces/:namespace/secrets/:name/keys/:key", h.SetKey)
+	group.DELETE("/namespaces/:namespace/secrets/:name/keys/:key", h.DeleteKey)
+}
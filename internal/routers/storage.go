@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		storageRouter(group, handler.NewStorageHandler())
+	})
+}
+
+func storageRouter(group *gin.RouterGroup, h handler.StorageHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	group.GET("/storage/pvcs", h.ListPVCs)
+	group.GET("/storage/pvs", h.ListPVs)
+	group.GET("/storage/classes", h.ListStorageClasses)
+	group.POST("/namespaces/:namespace/pvcs/:name/expand", h.ExpandPVC)
+}
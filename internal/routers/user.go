@@ -17,14 +17,50 @@ func userRouter(group *gin.RouterGroup, h handler.UserHandler) {
 	// or group.Use(middleware.Auth(middleware.WithVerify(verify))) // token authentication
 
 	group.POST("/user/login", h.Login)
+	group.POST("/user/login/2fa", h.LoginTwoFactor)
+	group.GET("/user/login/2fa/qrcode", h.LoginTwoFactorQRCode)
 	group.POST("/user/reg", h.Register)
-	group.DELETE("/user/:id", h.DeleteByID)
-	group.POST("/user/delete/ids", h.DeleteByIDs)
+	group.GET("/user/oidc/login", h.OIDCLogin)
+	group.GET("/user/oidc/callback", h.OIDCCallback)
+	group.GET("/user/me", auth(), h.GetMe)
+	group.PUT("/user/me", auth(), h.UpdateMe)
+	group.PUT("/user/me/password", auth(), h.ChangePassword)
+	group.POST("/user/me/avatar", auth(), h.UploadAvatar)
+	group.GET("/user/me/totp", auth(), h.GetTwoFactor)
+	group.POST("/user/me/totp", auth(), h.EnrollTwoFactor)
+	group.GET("/user/me/totp/qrcode", auth(), h.GetTwoFactorQRCode)
+	group.POST("/user/me/totp/enable", auth(), h.EnableTwoFactor)
+	group.POST("/user/me/totp/disable", auth(), h.DisableTwoFactor)
+	group.POST("/user/me/totp/recovery-codes", auth(), h.RegenerateRecoveryCodes)
+	group.POST("/user/me/tokens", auth(), h.CreateApiToken)
+	group.GET("/user/me/tokens", auth(), h.ListApiTokens)
+	group.DELETE("/user/me/tokens/:tokenID", auth(), h.RevokeApiToken)
+	group.POST("/user/logout", auth(), h.Logout)
+	group.GET("/user/me/sessions", auth(), h.ListSessions)
+	group.DELETE("/user/me/sessions/:sessionID", auth(), h.RevokeSession)
+	group.DELETE("/user/:id", auth(), admin(), h.DeleteByID)
+	group.POST("/user/delete/ids", auth(), admin(), h.DeleteByIDs)
 	group.PUT("/user/:id", h.UpdateByID)
-	group.GET("/user/:id", h.GetByID)
-	group.POST("/user/condition", h.GetByCondition)
-	group.POST("/user/list/ids", h.ListByIDs)
-	group.GET("/user/list", h.ListByLastID)
-	group.POST("/user/list", h.List)
+	group.PATCH("/user/:id", auth(), admin(), h.PatchByID)
+	group.GET("/user/:id", auth(), h.GetByID)
+	group.GET("/user/:id/roles", auth(), selfOrAdmin(), h.GetRoles)
+	group.PUT("/user/:id/roles", auth(), admin(), h.SetRoles)
+	group.POST("/user/:id/unlock", auth(), admin(), h.Unlock)
+	group.DELETE("/user/:id/totp", auth(), selfOrAdmin(), h.ResetTwoFactor)
+	group.GET("/user/:id/tokens", auth(), selfOrAdmin(), h.ListUserApiTokens)
+	group.DELETE("/user/:id/tokens/:tokenID", auth(), selfOrAdmin(), h.RevokeUserApiToken)
+	group.GET("/user/:id/sessions", auth(), selfOrAdmin(), h.ListUserSessions)
+	group.DELETE("/user/:id/sessions", auth(), selfOrAdmin(), h.ForceLogout)
+	group.POST("/user/login/history", auth(), admin(), h.ListLoginHistory)
+	group.POST("/user/:id/login/history", auth(), selfOrAdmin(), h.ListUserLoginHistory)
+	group.POST("/user/condition", auth(), h.GetByCondition)
+	group.POST("/user/list/ids", auth(), h.ListByIDs)
+	group.GET("/user/list", auth(), h.ListByLastID)
+	group.POST("/user/list", auth(), h.List) // filtered by the data scope of the user
+	group.POST("/user/trash/list", auth(), admin(), h.ListTrash)
+	group.POST("/user/trash/:id/restore", auth(), admin(), h.Restore)
+	group.DELETE("/user/trash/:id", auth(), admin(), h.Purge)
+	group.POST("/user/import", auth(), admin(), h.Import)
+	group.POST("/user/export", auth(), admin(), h.Export)
 
 }
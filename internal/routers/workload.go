@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-admin/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		workloadRouter(group, handler.NewWorkloadHandler())
+	})
+}
+
+func workloadRouter(group *gin.RouterGroup, h handler.WorkloadHandler) {
+	group = group.Group("/k8s", auth()) // all of the following routes use jwt or api token authentication
+
+	// kind is deployments, statefulsets or daemonsets
+	group.GET("/namespaces/:namespace/workloads/:kind/:name/revisions", h.ListRevisions)
+	group.GET("/namespaces/:namespace/workloads/:kind/:name/revisions/diff", h.DiffRevisions)
+	group.POST("/namespaces/:namespace/workloads/:kind/:name/rollback", h.Rollback)
+}
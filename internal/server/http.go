@@ -71,6 +71,9 @@ func NewHTTPServer(addr string, opts ...HTTPOption) app.IServer {
 	}
 
 	router := routers.NewRouter()
+	for _, fn := range o.routesFns {
+		fn(router.Routes())
+	}
 	server := &http.Server{
 		Addr:    addr,
 		Handler: router,
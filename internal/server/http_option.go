@@ -1,6 +1,8 @@
 package server
 
 import (
+	"github.com/gin-gonic/gin"
+
 	"github.com/zhufuyi/sponge/pkg/servicerd/registry"
 )
 
@@ -11,6 +13,7 @@ type httpOptions struct {
 	isProd    bool
 	instance  *registry.ServiceInstance
 	iRegistry registry.Registry
+	routesFns []func(routes gin.RoutesInfo)
 }
 
 func defaultHTTPOptions() *httpOptions {
@@ -41,3 +44,10 @@ func WithHTTPRegistry(iRegistry registry.Registry, instance *registry.ServiceIns
 		o.instance = instance
 	}
 }
+
+// WithHTTPRoutesHook called with the registered routes after the router is created, before the server starts
+func WithHTTPRoutesHook(fn func(routes gin.RoutesInfo)) HTTPOption {
+	return func(o *httpOptions) {
+		o.routesFns = append(o.routesFns, fn)
+	}
+}
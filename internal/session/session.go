@@ -0,0 +1,84 @@
+// Package session records the jwt issued by the logins, a jwt is only accepted while its session exists,
+// so that the users can log out their devices and the admins can force the logout of a user.
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhufuyi/sponge/pkg/gin/middleware"
+	"github.com/zhufuyi/sponge/pkg/jwt"
+	"github.com/zhufuyi/sponge/pkg/logger"
+	"github.com/zhufuyi/sponge/pkg/utils"
+
+	"go-admin/internal/cache"
+)
+
+const (
+	// ContextKey the key of the id of the session of the request in the gin context
+	ContextKey = "sessionID"
+
+	// touchInterval the minimum seconds between two updates of the last seen time of a session
+	touchInterval = 60
+)
+
+// ID the id of the session of a jwt, derived from the jwt so that the jwt itself is not stored
+func ID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// New the session of a jwt issued to the user, it expires with the jwt
+func New(token string, userID uint64, ip string, userAgent string) (*cache.Session, error) {
+	claims, err := jwt.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("the token has no expiry")
+	}
+	now := time.Now().Unix()
+	return &cache.Session{
+		ID:         ID(token),
+		UserID:     userID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  claims.ExpiresAt.Unix(),
+	}, nil
+}
+
+// Verify the verification of middleware.Auth, the jwt of a revoked or unknown session is unauthorized,
+// it sets the uid and the name of the jwt and the id of the session.
+func Verify(store cache.SessionCache) middleware.VerifyFn {
+	return func(claims *jwt.Claims, _ string, c *gin.Context) error {
+		uid, err := utils.StrToUint64E(claims.UID)
+		if err != nil {
+			return err
+		}
+		token := c.GetHeader(middleware.HeaderAuthorizationKey)[7:] // remove Bearer prefix like middleware.Auth
+		ctx := middleware.WrapCtx(c)
+		s, err := store.Get(ctx, uid, ID(token))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().Unix()
+		if now-s.LastSeenAt >= touchInterval {
+			s.LastSeenAt = now
+			if err = store.Set(ctx, s); err != nil {
+				logger.Warn("Set session error", logger.Err(err), logger.Any("id", uid), middleware.GCtxRequestIDField(c))
+			}
+		}
+
+		c.Set("uid", claims.UID)
+		c.Set("name", claims.Name)
+		c.Set(ContextKey, s.ID)
+		return nil
+	}
+}
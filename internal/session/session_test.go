@@ -0,0 +1,32 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhufuyi/sponge/pkg/jwt"
+)
+
+func TestNew(t *testing.T) {
+	jwt.Init(jwt.WithExpire(time.Hour))
+	token, err := jwt.GenerateToken("1", "foo")
+	require.NoError(t, err)
+
+	s, err := New(token, 1, "127.0.0.1", "curl")
+	require.NoError(t, err)
+	assert.Equal(t, ID(token), s.ID)
+	assert.Len(t, s.ID, 32)
+	assert.NotContains(t, s.ID, token)
+	assert.Equal(t, uint64(1), s.UserID)
+	assert.Equal(t, "127.0.0.1", s.IP)
+	assert.Equal(t, "curl", s.UserAgent)
+	assert.Equal(t, s.CreatedAt, s.LastSeenAt)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), s.ExpiresAt, 2)
+
+	assert.NotEqual(t, ID(token), ID(token+"x"))
+	_, err = New("invalid", 1, "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// Package sso logs the users in with an OpenID Connect provider,
+// by the authorization code flow with PKCE.
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// DefaultGroupsClaim the claim of the id token holding the groups of the user
+const DefaultGroupsClaim = "groups"
+
+// DefaultScopes the scopes requested if none are configured
+var DefaultScopes = []string{oidc.ScopeOpenID, "profile", "email"}
+
+// Options the settings of the client registered at the provider
+type Options struct {
+	Issuer       string   // url of the provider, the discovery document is at <issuer>/.well-known/openid-configuration
+	ClientID     string   // id of the client
+	ClientSecret string   // secret of the client, empty for a public client
+	RedirectURL  string   // callback url registered at the provider
+	Scopes       []string // scopes requested, default is DefaultScopes
+	GroupsClaim  string   // claim holding the groups, default is DefaultGroupsClaim
+}
+
+// Claims the user information of the verified id token
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string // preferred_username
+	Name          string
+	Groups        []string
+}
+
+// AuthRequest the secrets of an authorization request, they must be kept until the callback
+type AuthRequest struct {
+	URL      string // url of the provider the browser is redirected to
+	State    string
+	Nonce    string
+	Verifier string // PKCE code verifier
+}
+
+// Provider an OpenID Connect provider
+type Provider struct {
+	issuer      string
+	config      oauth2.Config
+	verifier    *oidc.IDTokenVerifier
+	groupsClaim string
+}
+
+// NewProvider fetch the discovery document of the issuer and create the provider
+func NewProvider(ctx context.Context, opts *Options) (*Provider, error) {
+	p, err := oidc.NewProvider(ctx, opts.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	groupsClaim := opts.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = DefaultGroupsClaim
+	}
+
+	return &Provider{
+		issuer: opts.Issuer,
+		config: oauth2.Config{
+			ClientID:     opts.ClientID,
+			ClientSecret: opts.ClientSecret,
+			RedirectURL:  opts.RedirectURL,
+			Endpoint:     p.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier:    p.Verifier(&oidc.Config{ClientID: opts.ClientID}),
+		groupsClaim: groupsClaim,
+	}, nil
+}
+
+// Issuer the url of the provider, it identifies the accounts with the subject
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// NewAuthRequest create an authorization request with a random state, nonce and PKCE verifier
+func (p *Provider) NewAuthRequest() (*AuthRequest, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	return &AuthRequest{
+		URL:      p.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	}, nil
+}
+
+// Exchange the authorization code for the tokens and return the claims of the verified id token
+func (p *Provider) Exchange(ctx context.Context, code string, verifier string, nonce string) (*Claims, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("no id_token in the token response")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("the nonce of the id_token does not match")
+	}
+
+	claims := map[string]interface{}{}
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	c := &Claims{Subject: idToken.Subject}
+	c.Email, _ = claims["email"].(string)
+	c.EmailVerified, _ = claims["email_verified"].(bool)
+	c.Username, _ = claims["preferred_username"].(string)
+	c.Name, _ = claims["name"].(string)
+	switch groups := claims[p.groupsClaim].(type) {
+	case string: // some providers send a single group as a string
+		c.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				c.Groups = append(c.Groups, s)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
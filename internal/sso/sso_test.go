@@ -0,0 +1,81 @@
+package sso
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-admin/internal/sso/ssotest"
+)
+
+// authorize follow the authorization url and return the query of the redirect to the callback
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query()
+}
+
+func TestProvider(t *testing.T) {
+	iss := ssotest.NewIssuer()
+	defer iss.Close()
+	iss.Claims = map[string]interface{}{
+		"sub":                "u-1",
+		"email":              "foo@example.com",
+		"email_verified":     true,
+		"preferred_username": "foo",
+		"groups":             []string{"admins", "devs"},
+	}
+
+	ctx := context.Background()
+	p, err := NewProvider(ctx, &Options{Issuer: iss.URL, ClientID: ssotest.ClientID, RedirectURL: "http://localhost/callback"})
+	require.NoError(t, err)
+	assert.Equal(t, iss.URL, p.Issuer())
+
+	req, err := p.NewAuthRequest()
+	require.NoError(t, err)
+	q := authorize(t, req.URL)
+	assert.Equal(t, req.State, q.Get("state"))
+
+	// a wrong verifier is rejected by the issuer
+	_, err = p.Exchange(ctx, q.Get("code"), "wrong-verifier-wrong-verifier-wrong-verifier", req.Nonce)
+	assert.Error(t, err)
+
+	q = authorize(t, req.URL)
+	claims, err := p.Exchange(ctx, q.Get("code"), req.Verifier, req.Nonce)
+	require.NoError(t, err)
+	assert.Equal(t, &Claims{
+		Subject:       "u-1",
+		Email:         "foo@example.com",
+		EmailVerified: true,
+		Username:      "foo",
+		Groups:        []string{"admins", "devs"},
+	}, claims)
+
+	// the nonce of another request is rejected
+	q = authorize(t, req.URL)
+	_, err = p.Exchange(ctx, q.Get("code"), req.Verifier, "other")
+	assert.Error(t, err)
+
+	// a single group in a custom claim
+	p, err = NewProvider(ctx, &Options{Issuer: iss.URL, ClientID: ssotest.ClientID, GroupsClaim: "roles"})
+	require.NoError(t, err)
+	iss.Claims["roles"] = "admins"
+	req, err = p.NewAuthRequest()
+	require.NoError(t, err)
+	q = authorize(t, req.URL)
+	claims, err = p.Exchange(ctx, q.Get("code"), req.Verifier, req.Nonce)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins"}, claims.Groups)
+
+	_, err = NewProvider(ctx, &Options{Issuer: "http://127.0.0.1:1", ClientID: ssotest.ClientID})
+	assert.Error(t, err)
+}
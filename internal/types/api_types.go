@@ -27,26 +27,31 @@ type UpdateApiByIDRequest struct {
 
 	Handle   string `json:"handle" binding:""`
 	Title    string `json:"title" binding:""`
-	Path     string `json:"path" binding:""`
+	Path     string `json:"path" binding:"omitempty,startswith=/"`
 	Type     string `json:"type" binding:""`
-	Action   string `json:"action" binding:""`
+	Action   string `json:"action" binding:"omitempty,oneof=GET POST PUT PATCH DELETE HEAD OPTIONS ANY"`
 	CreateBy int    `json:"createBy" binding:""`
 	UpdateBy int    `json:"updateBy" binding:""`
+	Version  uint64 `json:"version" binding:""` // version of the record read by get, required unless the If-Match header is set
 }
 
 // ApiObjDetail detail
 type ApiObjDetail struct {
 	ID string `json:"id"` // convert to string id
 
-	Handle    string    `json:"handle"`
-	Title     string    `json:"title"`
-	Path      string    `json:"path"`
-	Type      string    `json:"type"`
-	Action    string    `json:"action"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	CreateBy  int       `json:"createBy"`
-	UpdateBy  int       `json:"updateBy"`
+	Handle    string     `json:"handle"`
+	Title     string     `json:"title"`
+	Path      string     `json:"path"`
+	Type      string     `json:"type"`
+	Action    string     `json:"action"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	CreateBy  int        `json:"createBy"`
+	UpdateBy  int        `json:"updateBy"`
+	Stale     bool       `json:"stale"`               // true if no registered route matches the path and action any more
+	DeletedAt *time.Time `json:"deletedAt,omitempty"` // only set for the records in the trash
+	DeletedBy uint64     `json:"deletedBy,omitempty"` // id of the user who deleted the record
+	Version   uint64     `json:"version"`             // version of the record, send it back to update the record
 }
 
 // CreateApiRespond only for api docs
@@ -128,3 +133,64 @@ type ListApisRespond struct {
 		Apis []ApiObjDetail `json:"apis"`
 	} `json:"data"` // return data
 }
+
+// ApiSyncChange a change made by syncing the api rows with the registered routes
+type ApiSyncChange struct {
+	ID       uint64 `json:"id"`     // id of the api row, 0 for the rows to be created in a dry run
+	Change   string `json:"change"` // created, updated, stale or restored
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Handle   string `json:"handle"`
+	Title    string `json:"title"`
+	OldTitle string `json:"oldTitle,omitempty"` // title before the update
+}
+
+// SyncApisRespond only for api docs
+type SyncApisRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		DryRun  bool            `json:"dryRun"`
+		Changes []ApiSyncChange `json:"changes"`
+	} `json:"data"` // return data
+}
+
+// ListTrashApisRequest request params
+type ListTrashApisRequest struct {
+	query.Params
+}
+
+// ListTrashApisRespond only for api docs
+type ListTrashApisRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Apis  []ApiObjDetail `json:"apis"`
+		Total int64          `json:"total"`
+	} `json:"data"` // return data
+}
+
+// RestoreApiRespond only for api docs
+type RestoreApiRespond struct {
+	Result
+}
+
+// PurgeApiRespond only for api docs
+type PurgeApiRespond struct {
+	Result
+}
+
+// ImportApiRow a row of the apis import and export, the apis are matched by action and path
+type ImportApiRow struct {
+	Action string `json:"action" binding:"required,oneof=GET POST PUT PATCH DELETE HEAD OPTIONS ANY"`
+	Path   string `json:"path" binding:"required,startswith=/"`
+	Handle string `json:"handle" binding:""`
+	Title  string `json:"title" binding:""`
+	Type   string `json:"type" binding:""`
+}
+
+// ExportApisRequest request params, all the apis matching the columns are exported
+type ExportApisRequest struct {
+	Sort    string         `json:"sort" binding:""`    // sorted fields, multi-column sorting separated by commas
+	Columns []query.Column `json:"columns" binding:""` // query conditions, the same as list
+}
@@ -0,0 +1,25 @@
+package types
+
+// ImportRowResult the result of a row of an import
+type ImportRowResult struct {
+	Row    int    `json:"row"`             // row number in the file, starting from 1
+	Key    string `json:"key"`             // the unique key the row is matched by
+	Action string `json:"action"`          // created, updated or invalid
+	Error  string `json:"error,omitempty"` // why the row is invalid
+}
+
+// ImportResult the report of an import
+type ImportResult struct {
+	Imported bool               `json:"imported"` // false if a row is invalid or it is a dry run, then nothing is saved
+	Created  int                `json:"created"`  // number of rows created
+	Updated  int                `json:"updated"`  // number of rows updated
+	Invalid  int                `json:"invalid"`  // number of invalid rows
+	Rows     []*ImportRowResult `json:"rows"`     // result of every row
+}
+
+// ImportRespond only for api docs
+type ImportRespond struct {
+	Code int          `json:"code"` // return code
+	Msg  string       `json:"msg"`  // return information description
+	Data ImportResult `json:"data"` // return data
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"time"
+)
+
+// ConfigMapItem a key of configMap
+type ConfigMapItem struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`  // value, base64 encoded if the key is in binaryData
+	Size   int    `json:"size"`   // size of the decoded value in bytes
+	Base64 bool   `json:"base64"` // true if the key is in binaryData
+}
+
+// ConfigMapObjDetail detail
+type ConfigMapObjDetail struct {
+	Namespace       string          `json:"namespace"`
+	Name            string          `json:"name"`
+	ResourceVersion string          `json:"resourceVersion"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	Items           []ConfigMapItem `json:"items"`
+}
+
+// SetConfigMapKeyRequest request params
+type SetConfigMapKeyRequest struct {
+	Value  string `json:"value" binding:""`  // plain text value
+	Base64 bool   `json:"base64" binding:""` // true if value is base64 encoded, the key is stored in binaryData
+}
+
+// ListConfigMapsRespond only for api docs
+type ListConfigMapsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ConfigMaps []ConfigMapObjDetail `json:"configMaps"`
+	} `json:"data"` // return data
+}
+
+// GetConfigMapRespond only for api docs
+type GetConfigMapRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ConfigMap ConfigMapObjDetail `json:"configMap"`
+	} `json:"data"` // return data
+}
+
+// SetConfigMapKeyRespond only for api docs
+type SetConfigMapKeyRespond struct {
+	Result
+}
+
+// DeleteConfigMapKeyRespond only for api docs
+type DeleteConfigMapKeyRespond struct {
+	Result
+}
@@ -0,0 +1,87 @@
+package types
+
+// APIResource a resource served by the cluster
+type APIResource struct {
+	Group          string   `json:"group"` // api group, core for the core group
+	Version        string   `json:"version"`
+	Resource       string   `json:"resource"` // plural name, e.g. deployments
+	Kind           string   `json:"kind"`
+	Namespaced     bool     `json:"namespaced"`
+	Verbs          []string `json:"verbs"`          // e.g. get, list, update
+	CustomResource bool     `json:"customResource"` // true if the resource is defined by a customResourceDefinition
+}
+
+// PrinterColumn column of the resource table, same as additionalPrinterColumns of crd
+type PrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`     // integer, number, string, boolean or date
+	JSONPath    string `json:"jsonPath"` // e.g. .spec.replicas
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority,omitempty"` // 0 is shown in the standard view, greater than 0 only in the wide view
+}
+
+// CRDObjDetail detail of customResourceDefinition
+type CRDObjDetail struct {
+	Name           string          `json:"name"` // e.g. crontabs.stable.example.com
+	Group          string          `json:"group"`
+	Kind           string          `json:"kind"`
+	Resource       string          `json:"resource"` // plural name
+	Scope          string          `json:"scope"`    // Namespaced or Cluster
+	Versions       []string        `json:"versions"` // served versions
+	StorageVersion string          `json:"storageVersion"`
+	PrinterColumns []PrinterColumn `json:"printerColumns"` // additionalPrinterColumns of the storage version
+}
+
+// CustomResourceRow a row of the resource table
+type CustomResourceRow struct {
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Cells     []interface{} `json:"cells"` // values in the order of columns, null if the field is missing
+}
+
+// CustomResourceObject the full object of a resource
+type CustomResourceObject map[string]interface{}
+
+// ListAPIResourcesRespond only for api docs
+type ListAPIResourcesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Resources    []APIResource `json:"resources"`
+		FailedGroups []string      `json:"failedGroups"` // group versions that could not be discovered
+	} `json:"data"` // return data
+}
+
+// ListCRDsRespond only for api docs
+type ListCRDsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		CRDs []CRDObjDetail `json:"crds"`
+	} `json:"data"` // return data
+}
+
+// ListCustomResourcesRespond only for api docs
+type ListCustomResourcesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Columns  []PrinterColumn     `json:"columns"`
+		Rows     []CustomResourceRow `json:"rows"`
+		Continue string              `json:"continue"` // token of the next page, empty if there are no more pages
+	} `json:"data"` // return data
+}
+
+// GetCustomResourceRespond only for api docs
+type GetCustomResourceRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Object CustomResourceObject `json:"object"`
+	} `json:"data"` // return data
+}
+
+// DeleteCustomResourceRespond only for api docs
+type DeleteCustomResourceRespond struct {
+	Result
+}
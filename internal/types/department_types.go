@@ -0,0 +1,93 @@
+package types
+
+import (
+	"time"
+)
+
+// CreateDepartmentRequest request params
+type CreateDepartmentRequest struct {
+	ParentID   uint64 `json:"parentId" binding:""`             // parent department id, 0 for a top level department
+	Name       string `json:"name" binding:"required,max=100"` // department name
+	Sort       int    `json:"sort" binding:""`                 // the siblings are sorted in ascending order
+	Namespaces string `json:"namespaces" binding:""`           // kubernetes namespaces of the department, comma separated
+	Remark     string `json:"remark" binding:"max=255"`
+}
+
+// UpdateDepartmentByIDRequest request params, all the fields are replaced
+type UpdateDepartmentByIDRequest struct {
+	ID         uint64 `json:"id" binding:""`
+	ParentID   uint64 `json:"parentId" binding:""`
+	Name       string `json:"name" binding:"required,max=100"`
+	Sort       int    `json:"sort" binding:""`
+	Namespaces string `json:"namespaces" binding:""`
+	Remark     string `json:"remark" binding:"max=255"`
+}
+
+// DepartmentObjDetail detail, a node of the department tree
+type DepartmentObjDetail struct {
+	ID         string                 `json:"id"`       // convert to string id
+	ParentID   string                 `json:"parentId"` // "0" for a top level department
+	Name       string                 `json:"name"`
+	Sort       int                    `json:"sort"`
+	Namespaces string                 `json:"namespaces"`
+	Remark     string                 `json:"remark"`
+	CreatedAt  time.Time              `json:"createdAt"`
+	UpdatedAt  time.Time              `json:"updatedAt"`
+	Children   []*DepartmentObjDetail `json:"children,omitempty"` // only set in the tree
+}
+
+// CreateDepartmentRespond only for api docs
+type CreateDepartmentRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// UpdateDepartmentByIDRespond only for api docs
+type UpdateDepartmentByIDRespond struct {
+	Result
+}
+
+// DeleteDepartmentByIDRespond only for api docs
+type DeleteDepartmentByIDRespond struct {
+	Result
+}
+
+// GetDepartmentByIDRespond only for api docs
+type GetDepartmentByIDRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Department DepartmentObjDetail `json:"department"`
+	} `json:"data"` // return data
+}
+
+// GetDepartmentTreeRespond only for api docs
+type GetDepartmentTreeRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Departments []DepartmentObjDetail `json:"departments"` // the top level departments with their sub departments
+	} `json:"data"` // return data
+}
+
+// SetDepartmentsRequest request params
+type SetDepartmentsRequest struct {
+	DeptIDs []uint64 `json:"deptIds" binding:""` // department id list, an empty list removes all departments
+}
+
+// SetDepartmentsRespond only for api docs
+type SetDepartmentsRespond struct {
+	Result
+}
+
+// GetDepartmentsRespond only for api docs
+type GetDepartmentsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		DeptIDs []uint64 `json:"deptIds"`
+	} `json:"data"` // return data
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"time"
+)
+
+// CreateElevationRequest request params
+type CreateElevationRequest struct {
+	RoleID   uint64 `json:"roleId" binding:"gt=0"`             // id of the requested role
+	Reason   string `json:"reason" binding:"required,max=500"` // justification, e.g. the incident
+	Duration int    `json:"duration" binding:"gt=0"`           // seconds the role is held from the approval
+}
+
+// ReviewElevationRequest request params
+type ReviewElevationRequest struct {
+	Comment string `json:"comment" binding:"max=500"` // comment of the approver, recorded with the step
+}
+
+// ElevationEventDetail a step of a role elevation
+type ElevationEventDetail struct {
+	CreatedAt time.Time `json:"createdAt"`
+	ActorID   uint64    `json:"actorId"` // id of the user who did the step, 0 for the expiry
+	Action    string    `json:"action"`  // request, approve, reject, cancel, revoke, expire or use
+	Detail    string    `json:"detail"`  // the reason, the comment, or the method, the path and the status of a request
+}
+
+// ElevationObjDetail detail
+type ElevationObjDetail struct {
+	ID string `json:"id"` // convert to string id
+
+	CreatedAt     time.Time               `json:"createdAt"`
+	UserID        uint64                  `json:"userId"` // id of the requester
+	RoleID        uint64                  `json:"roleId"`
+	Reason        string                  `json:"reason"`
+	Duration      int                     `json:"duration"` // seconds the role is held from the approval
+	Status        string                  `json:"status"`   // pending, approved, rejected, cancelled, revoked or expired
+	ReviewerID    uint64                  `json:"reviewerId"`
+	ReviewComment string                  `json:"reviewComment"`
+	ReviewedAt    int64                   `json:"reviewedAt"` // unix time, 0 until the review
+	ExpiresAt     int64                   `json:"expiresAt"`  // unix time, 0 until the approval
+	EndedAt       int64                   `json:"endedAt"`    // unix time of the revocation or the expiry, 0 otherwise
+	EndedBy       uint64                  `json:"endedBy"`    // id of the user who revoked the grant
+	Active        bool                    `json:"active"`     // the role is held now
+	Events        []*ElevationEventDetail `json:"events,omitempty"`
+}
+
+// CreateElevationRespond only for api docs
+type CreateElevationRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Elevation ElevationObjDetail `json:"elevation"`
+	} `json:"data"` // return data
+}
+
+// GetElevationRespond only for api docs
+type GetElevationRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Elevation ElevationObjDetail `json:"elevation"` // with its events
+	} `json:"data"` // return data
+}
+
+// ListElevationsRespond only for api docs
+type ListElevationsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Elevations []ElevationObjDetail `json:"elevations"` // the latest first
+	} `json:"data"` // return data
+}
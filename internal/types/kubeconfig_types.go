@@ -0,0 +1,11 @@
+package types
+
+// GenerateKubeconfigRequest request params
+type GenerateKubeconfigRequest struct {
+	ExpiresIn int `form:"expiresIn" binding:"min=0"` // lifetime of the token in seconds, 0 is the default lifetime
+}
+
+// RevokeKubeconfigRespond only for api docs
+type RevokeKubeconfigRespond struct {
+	Result
+}
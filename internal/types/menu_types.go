@@ -0,0 +1,118 @@
+package types
+
+import (
+	"time"
+)
+
+// CreateMenuRequest request params
+type CreateMenuRequest struct {
+	ParentID   uint64 `json:"parentId" binding:""`                                 // parent menu id, 0 for a top level menu
+	Type       string `json:"type" binding:"required,oneof=directory menu button"` // the buttons only grant a permission key
+	Title      string `json:"title" binding:"required,max=100"`                    // shown in the navigation
+	Name       string `json:"name" binding:"max=100"`                              // route name of the frontend
+	Path       string `json:"path" binding:"max=255"`                              // route path of the frontend
+	Component  string `json:"component" binding:"max=255"`                         // view component of the frontend
+	Icon       string `json:"icon" binding:"max=100"`
+	Permission string `json:"permission" binding:"max=100"` // permission key checked by the frontend, e.g. user:create
+	Sort       int    `json:"sort" binding:""`              // the siblings are sorted in ascending order
+	Hidden     bool   `json:"hidden" binding:""`            // routed but not shown in the navigation
+}
+
+// UpdateMenuByIDRequest request params, all the fields are replaced
+type UpdateMenuByIDRequest struct {
+	ID         uint64 `json:"id" binding:""`
+	ParentID   uint64 `json:"parentId" binding:""`
+	Type       string `json:"type" binding:"required,oneof=directory menu button"`
+	Title      string `json:"title" binding:"required,max=100"`
+	Name       string `json:"name" binding:"max=100"`
+	Path       string `json:"path" binding:"max=255"`
+	Component  string `json:"component" binding:"max=255"`
+	Icon       string `json:"icon" binding:"max=100"`
+	Permission string `json:"permission" binding:"max=100"`
+	Sort       int    `json:"sort" binding:""`
+	Hidden     bool   `json:"hidden" binding:""`
+}
+
+// MenuObjDetail detail, a node of the menu tree
+type MenuObjDetail struct {
+	ID         string           `json:"id"`       // convert to string id
+	ParentID   string           `json:"parentId"` // "0" for a top level menu
+	Type       string           `json:"type"`
+	Title      string           `json:"title"`
+	Name       string           `json:"name"`
+	Path       string           `json:"path"`
+	Component  string           `json:"component"`
+	Icon       string           `json:"icon"`
+	Permission string           `json:"permission"`
+	Sort       int              `json:"sort"`
+	Hidden     bool             `json:"hidden"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+	Children   []*MenuObjDetail `json:"children,omitempty"` // only set in the trees
+}
+
+// CreateMenuRespond only for api docs
+type CreateMenuRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// UpdateMenuByIDRespond only for api docs
+type UpdateMenuByIDRespond struct {
+	Result
+}
+
+// DeleteMenuByIDRespond only for api docs
+type DeleteMenuByIDRespond struct {
+	Result
+}
+
+// GetMenuByIDRespond only for api docs
+type GetMenuByIDRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Menu MenuObjDetail `json:"menu"`
+	} `json:"data"` // return data
+}
+
+// GetMenuTreeRespond only for api docs
+type GetMenuTreeRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Menus []MenuObjDetail `json:"menus"` // the top level menus with their children
+	} `json:"data"` // return data
+}
+
+// GetMyMenuTreeRespond only for api docs
+type GetMyMenuTreeRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Menus       []MenuObjDetail `json:"menus"`       // the directories and menus granted to the roles, with their parents
+		Permissions []string        `json:"permissions"` // the permission keys of the menus and buttons granted to the roles
+	} `json:"data"` // return data
+}
+
+// SetRoleMenusRequest request params
+type SetRoleMenusRequest struct {
+	MenuIDs []uint64 `json:"menuIds" binding:""` // menu id list, an empty list removes all menus
+}
+
+// SetRoleMenusRespond only for api docs
+type SetRoleMenusRespond struct {
+	Result
+}
+
+// GetRoleMenusRespond only for api docs
+type GetRoleMenusRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		MenuIDs []uint64 `json:"menuIds"`
+	} `json:"data"` // return data
+}
@@ -0,0 +1,10 @@
+package types
+
+// ListNamespacesRespond only for api docs
+type ListNamespacesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Namespaces []string `json:"namespaces"` // the namespaces of the cluster in the data scope of the user, sorted
+	} `json:"data"` // return data
+}
@@ -0,0 +1,92 @@
+package types
+
+// SetRoleParentsRequest request params
+type SetRoleParentsRequest struct {
+	ParentIDs []uint64 `json:"parentIds" binding:""` // ids of the roles the role inherits, an empty list removes all parents
+}
+
+// SetRoleParentsRespond only for api docs
+type SetRoleParentsRespond struct {
+	Result
+}
+
+// GetRoleParentsRespond only for api docs
+type GetRoleParentsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ParentIDs []uint64 `json:"parentIds"`
+	} `json:"data"` // return data
+}
+
+// SetRoleApisRequest request params
+type SetRoleApisRequest struct {
+	ApiIDs []uint64 `json:"apiIds" binding:""` // ids of the apis granted to the role, an empty list revokes all apis
+}
+
+// SetRoleApisRespond only for api docs
+type SetRoleApisRespond struct {
+	Result
+}
+
+// GetRoleApisRespond only for api docs
+type GetRoleApisRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ApiIDs []uint64 `json:"apiIds"`
+	} `json:"data"` // return data
+}
+
+// GrantingRole a role held by the user and the chain it is held through
+type GrantingRole struct {
+	RoleID    string   `json:"roleId"`
+	RoleKey   string   `json:"roleKey"`
+	RoleName  string   `json:"roleName"`
+	Inherited bool     `json:"inherited"` // true if the role is held through the parents of a role bound to the user
+	Via       []string `json:"via"`       // keys of the roles from the role bound to the user to the role
+	Elevated  bool     `json:"elevated"`  // true if the first role of via is held through a temporary elevation
+}
+
+// PermissionApiDetail an api granted to the user
+type PermissionApiDetail struct {
+	ID     string          `json:"id"`
+	Title  string          `json:"title"`
+	Path   string          `json:"path"`
+	Action string          `json:"action"`
+	Roles  []*GrantingRole `json:"roles"` // the roles granting the api
+}
+
+// PermissionNamespaceDetail a kubernetes namespace permitted to the user
+type PermissionNamespaceDetail struct {
+	Namespace string          `json:"namespace"`
+	Roles     []*GrantingRole `json:"roles"` // the roles permitting the namespace
+}
+
+// PermissionsDetail the effective permissions of a user
+type PermissionsDetail struct {
+	Roles              []*GrantingRole              `json:"roles"`
+	Apis               []*PermissionApiDetail       `json:"apis"`
+	Namespaces         []*PermissionNamespaceDetail `json:"namespaces"`
+	AllNamespaces      bool                         `json:"allNamespaces"`      // true if a role permits all the namespaces
+	AllNamespacesRoles []*GrantingRole              `json:"allNamespacesRoles"` // the roles permitting all the namespaces
+}
+
+// GetPermissionsRespond only for api docs
+type GetPermissionsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Permissions PermissionsDetail `json:"permissions"`
+	} `json:"data"` // return data
+}
+
+// ExplainPermissionRespond only for api docs
+type ExplainPermissionRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Granted bool            `json:"granted"`
+		Roles   []*GrantingRole `json:"roles"` // the roles granting the permission, empty if it is not granted
+	} `json:"data"` // return data
+}
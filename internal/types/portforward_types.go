@@ -0,0 +1,48 @@
+package types
+
+import (
+	"time"
+)
+
+// CreatePortForwardRequest request params
+type CreatePortForwardRequest struct {
+	Kind string `json:"kind" binding:"oneof=pod service"` // pod or service, a service is forwarded to one of its ready pods
+	Name string `json:"name" binding:"required"`          // name of pod or service
+	Port string `json:"port" binding:"required"`          // port number or name, for a service it is a port of the service
+}
+
+// PortForwardSession a running port-forward
+type PortForwardSession struct {
+	ID         string    `json:"id"`
+	Namespace  string    `json:"namespace"`
+	Service    string    `json:"service"` // empty if the session was opened to a pod
+	Pod        string    `json:"pod"`
+	Port       int       `json:"port"`       // port of pod
+	ProxyPath  string    `json:"proxyPath"`  // http reverse-proxy path, e.g. /api/v1/portforward/{id}/
+	TunnelPath string    `json:"tunnelPath"` // websocket tunnel path, raw tcp bytes are carried in binary messages
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"` // the session is closed automatically at this time
+}
+
+// CreatePortForwardRespond only for api docs
+type CreatePortForwardRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Session PortForwardSession `json:"session"`
+	} `json:"data"` // return data
+}
+
+// ListPortForwardsRespond only for api docs
+type ListPortForwardsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Sessions []PortForwardSession `json:"sessions"`
+	} `json:"data"` // return data
+}
+
+// ClosePortForwardRespond only for api docs
+type ClosePortForwardRespond struct {
+	Result
+}
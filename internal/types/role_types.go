@@ -12,49 +12,59 @@ var _ time.Time
 
 // CreateRoleRequest request params
 type CreateRoleRequest struct {
-	RoleID    int    `json:"roleId" binding:""`
-	RoleName  string `json:"roleName" binding:""`
-	Status    string `json:"status" binding:""`
-	RoleKey   string `json:"roleKey" binding:""`
-	RoleSort  int    `json:"roleSort" binding:""`
-	Flag      string `json:"flag" binding:""`
-	Remark    string `json:"remark" binding:""`
-	Admin     string `json:"admin" binding:""`
-	DataScope string `json:"dataScope" binding:""`
-	CreateBy  int    `json:"createBy" binding:""`
-	UpdateBy  int    `json:"updateBy" binding:""`
+	RoleID           int    `json:"roleId" binding:""`
+	RoleName         string `json:"roleName" binding:""`
+	Status           string `json:"status" binding:""`
+	RoleKey          string `json:"roleKey" binding:""`
+	RoleSort         int    `json:"roleSort" binding:""`
+	Flag             string `json:"flag" binding:""`
+	Remark           string `json:"remark" binding:""`
+	Admin            string `json:"admin" binding:""`
+	DataScope        string `json:"dataScope" binding:""`
+	DataScopeMode    string `json:"dataScopeMode" binding:"omitempty,oneof=all dept dept_and_children self custom"` // the users visible to the members, all by default
+	RequireTwoFactor bool   `json:"requireTwoFactor" binding:""`                                                    // the members must log in with a TOTP code
+	CreateBy         int    `json:"createBy" binding:""`
+	UpdateBy         int    `json:"updateBy" binding:""`
 }
 
 // UpdateRoleByIDRequest request params
 type UpdateRoleByIDRequest struct {
-	ID        uint64 `json:"roleId" binding:""`
-	RoleName  string `json:"roleName" binding:""`
-	Status    string `json:"status" binding:""`
-	RoleKey   string `json:"roleKey" binding:""`
-	RoleSort  int    `json:"roleSort" binding:""`
-	Flag      string `json:"flag" binding:""`
-	Remark    string `json:"remark" binding:""`
-	Admin     string `json:"admin" binding:""`
-	DataScope string `json:"dataScope" binding:""`
-	CreateBy  int    `json:"createBy" binding:""`
-	UpdateBy  int    `json:"updateBy" binding:""`
+	ID               uint64 `json:"roleId" binding:""`
+	RoleName         string `json:"roleName" binding:"max=128"`
+	Status           string `json:"status" binding:""`
+	RoleKey          string `json:"roleKey" binding:"max=128"`
+	RoleSort         int    `json:"roleSort" binding:"gte=0"`
+	Flag             string `json:"flag" binding:""`
+	Remark           string `json:"remark" binding:""`
+	Admin            string `json:"admin" binding:""`
+	DataScope        string `json:"dataScope" binding:""`
+	DataScopeMode    string `json:"dataScopeMode" binding:"omitempty,oneof=all dept dept_and_children self custom"` // the users visible to the members, all by default
+	RequireTwoFactor bool   `json:"requireTwoFactor" binding:""`                                                    // the members must log in with a TOTP code
+	CreateBy         int    `json:"createBy" binding:""`
+	UpdateBy         int    `json:"updateBy" binding:""`
+	Version          uint64 `json:"version" binding:""` // version of the record read by get, required unless the If-Match header is set
 }
 
 // RoleObjDetail detail
 type RoleObjDetail struct {
-	ID        string    `json:"roleId"`
-	RoleName  string    `json:"roleName"`
-	Status    string    `json:"status"`
-	RoleKey   string    `json:"roleKey"`
-	RoleSort  int       `json:"roleSort"`
-	Flag      string    `json:"flag"`
-	Remark    string    `json:"remark"`
-	Admin     string    `json:"admin"`
-	DataScope string    `json:"dataScope"`
-	CreateBy  int       `json:"createBy"`
-	UpdateBy  int       `json:"updateBy"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               string     `json:"roleId"`
+	RoleName         string     `json:"roleName"`
+	Status           string     `json:"status"`
+	RoleKey          string     `json:"roleKey"`
+	RoleSort         int        `json:"roleSort"`
+	Flag             string     `json:"flag"`
+	Remark           string     `json:"remark"`
+	Admin            string     `json:"admin"`
+	DataScope        string     `json:"dataScope"`
+	DataScopeMode    string     `json:"dataScopeMode"`
+	RequireTwoFactor bool       `json:"requireTwoFactor"`
+	CreateBy         int        `json:"createBy"`
+	UpdateBy         int        `json:"updateBy"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+	DeletedAt        *time.Time `json:"deletedAt,omitempty"` // only set for the records in the trash
+	DeletedBy        uint64     `json:"deletedBy,omitempty"` // id of the user who deleted the record
+	Version          uint64     `json:"version"`             // version of the record, send it back to update the record
 }
 
 // CreateRoleRespond only for api docs
@@ -136,3 +146,48 @@ type ListRolesRespond struct {
 		Roles []RoleObjDetail `json:"roles"`
 	} `json:"data"` // return data
 }
+
+// ListTrashRolesRequest request params
+type ListTrashRolesRequest struct {
+	query.Params
+}
+
+// ListTrashRolesRespond only for api docs
+type ListTrashRolesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Roles []RoleObjDetail `json:"roles"`
+		Total int64           `json:"total"`
+	} `json:"data"` // return data
+}
+
+// RestoreRoleRespond only for api docs
+type RestoreRoleRespond struct {
+	Result
+}
+
+// PurgeRoleRespond only for api docs
+type PurgeRoleRespond struct {
+	Result
+}
+
+// ImportRoleRow a row of the roles import and export, the roles are matched by role key
+type ImportRoleRow struct {
+	RoleKey          string `json:"roleKey" binding:"required,max=128"`
+	RoleName         string `json:"roleName" binding:"required,max=128"`
+	Status           string `json:"status" binding:""`
+	RoleSort         int    `json:"roleSort" binding:"gte=0"`
+	Flag             string `json:"flag" binding:""`
+	Remark           string `json:"remark" binding:""`
+	Admin            string `json:"admin" binding:""`
+	DataScope        string `json:"dataScope" binding:""`
+	DataScopeMode    string `json:"dataScopeMode" binding:"omitempty,oneof=all dept dept_and_children self custom"` // the users visible to the members, all by default
+	RequireTwoFactor bool   `json:"requireTwoFactor" binding:""`
+}
+
+// ExportRolesRequest request params, all the roles matching the columns are exported
+type ExportRolesRequest struct {
+	Sort    string         `json:"sort" binding:""`    // sorted fields, multi-column sorting separated by commas
+	Columns []query.Column `json:"columns" binding:""` // query conditions, the same as list
+}
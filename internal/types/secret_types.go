@@ -0,0 +1,63 @@
+package types
+
+import (
+	"time"
+)
+
+// SecretItem a key of secret, the value is masked unless it is revealed
+type SecretItem struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`  // masked value, or the decoded value when revealed
+	Size   int    `json:"size"`   // size of the decoded value in bytes
+	Base64 bool   `json:"base64"` // true if the revealed value is base64 encoded because it is not utf-8 text
+}
+
+// SecretObjDetail detail
+type SecretObjDetail struct {
+	Namespace       string       `json:"namespace"`
+	Name            string       `json:"name"`
+	Type            string       `json:"type"`
+	ResourceVersion string       `json:"resourceVersion"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	Items           []SecretItem `json:"items"`
+}
+
+// RevealSecretRequest request params
+type RevealSecretRequest struct {
+	Keys   []string `json:"keys" binding:""`   // keys to reveal, if empty, reveal all keys
+	Reason string   `json:"reason" binding:""` // why the values are needed, written to the audit log
+}
+
+// SetSecretKeyRequest request params
+type SetSecretKeyRequest struct {
+	Value  string `json:"value" binding:""`  // plain text value, it is base64 encoded when stored
+	Base64 bool   `json:"base64" binding:""` // true if value is already base64 encoded, use it for binary values
+}
+
+// ListSecretsRespond only for api docs
+type ListSecretsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Secrets []SecretObjDetail `json:"secrets"`
+	} `json:"data"` // return data
+}
+
+// GetSecretRespond only for api docs
+type GetSecretRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Secret SecretObjDetail `json:"secret"`
+	} `json:"data"` // return data
+}
+
+// SetSecretKeyRespond only for api docs
+type SetSecretKeyRespond struct {
+	Result
+}
+
+// DeleteSecretKeyRespond only for api docs
+type DeleteSecretKeyRespond struct {
+	Result
+}
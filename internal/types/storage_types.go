@@ -0,0 +1,82 @@
+package types
+
+import (
+	"time"
+)
+
+// PVCObjDetail detail of persistentVolumeClaim
+type PVCObjDetail struct {
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`       // Pending, Bound or Lost
+	Problem      string    `json:"problem"`      // Pending or Lost if the claim needs attention, empty if it is healthy
+	VolumeName   string    `json:"volumeName"`   // bound persistentVolume
+	StorageClass string    `json:"storageClass"` // storageClass name
+	Requested    string    `json:"requested"`    // requested size, e.g. 10Gi
+	Capacity     string    `json:"capacity"`     // actual size of the bound volume
+	AccessModes  []string  `json:"accessModes"`  // e.g. ReadWriteOnce
+	VolumeMode   string    `json:"volumeMode"`   // Filesystem or Block
+	Pods         []string  `json:"pods"`         // names of the pods that mount the claim
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// PVObjDetail detail of persistentVolume
+type PVObjDetail struct {
+	Name          string    `json:"name"`
+	Status        string    `json:"status"`   // Available, Bound, Released or Failed
+	Orphaned      bool      `json:"orphaned"` // true if the volume is Released, its claim has been deleted
+	Claim         string    `json:"claim"`    // namespace/name of the claim
+	StorageClass  string    `json:"storageClass"`
+	Capacity      string    `json:"capacity"`
+	AccessModes   []string  `json:"accessModes"`
+	ReclaimPolicy string    `json:"reclaimPolicy"` // Retain, Delete or Recycle
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// StorageClassObjDetail detail of storageClass
+type StorageClassObjDetail struct {
+	Name                 string    `json:"name"`
+	Provisioner          string    `json:"provisioner"`
+	ReclaimPolicy        string    `json:"reclaimPolicy"`
+	VolumeBindingMode    string    `json:"volumeBindingMode"`
+	AllowVolumeExpansion bool      `json:"allowVolumeExpansion"`
+	IsDefault            bool      `json:"isDefault"`
+	CreatedAt            time.Time `json:"createdAt"`
+}
+
+// ExpandPVCRequest request params
+type ExpandPVCRequest struct {
+	Size string `json:"size" binding:"required"` // new size, must be larger than the current request, e.g. 20Gi
+}
+
+// ListPVCsRespond only for api docs
+type ListPVCsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		PVCs []PVCObjDetail `json:"pvcs"`
+	} `json:"data"` // return data
+}
+
+// ListPVsRespond only for api docs
+type ListPVsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		PVs []PVObjDetail `json:"pvs"`
+	} `json:"data"` // return data
+}
+
+// ListStorageClassesRespond only for api docs
+type ListStorageClassesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		StorageClasses []StorageClassObjDetail `json:"storageClasses"`
+	} `json:"data"` // return data
+}
+
+// ExpandPVCRespond only for api docs
+type ExpandPVCRespond struct {
+	Result
+}
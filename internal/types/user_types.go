@@ -38,32 +38,36 @@ type CreateUserRequest struct {
 type UpdateUserByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
 
-	Name     string `json:"name" binding:""`     // username
-	Password string `json:"password" binding:""` // password
-	Email    string `json:"email" binding:""`    // email
-	Phone    string `json:"phone" binding:""`    // phone number
-	Avatar   string `json:"avatar" binding:""`   // avatar
-	Age      int    `json:"age" binding:""`      // age
-	Gender   int    `json:"gender" binding:""`   // gender, 1:Male, 2:Female, other values:unknown
-	Status   int    `json:"status" binding:""`   // account status, 1:inactive, 2:activated, 3:blocked
-	LoginAt  uint64 `json:"loginAt" binding:""`  // login timestamp
+	Name     string `json:"name" binding:"max=50"`                  // username
+	Password string `json:"password" binding:"max=100"`             // password
+	Email    string `json:"email" binding:"omitempty,email,max=50"` // email
+	Phone    string `json:"phone" binding:"max=30"`                 // phone number
+	Avatar   string `json:"avatar" binding:"max=200"`               // avatar
+	Age      int    `json:"age" binding:"gte=0,lte=200"`            // age
+	Gender   int    `json:"gender" binding:"gte=0"`                 // gender, 1:Male, 2:Female, other values:unknown
+	Status   int    `json:"status" binding:"omitempty,oneof=1 2 3"` // account status, 1:inactive, 2:activated, 3:blocked
+	LoginAt  uint64 `json:"loginAt" binding:""`                     // login timestamp
+	Version  uint64 `json:"version" binding:""`                     // version of the record read by get, required unless the If-Match header is set
 }
 
 // UserObjDetail detail
 type UserObjDetail struct {
 	ID string `json:"id"` // convert to string id
 
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	Name      string    `json:"name"`     // username
-	Password  string    `json:"password"` // password
-	Email     string    `json:"email"`    // email
-	Phone     string    `json:"phone"`    // phone number
-	Avatar    string    `json:"avatar"`   // avatar
-	Age       int       `json:"age"`      // age
-	Gender    int       `json:"gender"`   // gender, 1:Male, 2:Female, other values:unknown
-	Status    int       `json:"status"`   // account status, 1:inactive, 2:activated, 3:blocked
-	LoginAt   uint64    `json:"loginAt"`  // login timestamp
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Name      string     `json:"name"`                // username
+	Password  string     `json:"password"`            // password
+	Email     string     `json:"email"`               // email
+	Phone     string     `json:"phone"`               // phone number
+	Avatar    string     `json:"avatar"`              // avatar
+	Age       int        `json:"age"`                 // age
+	Gender    int        `json:"gender"`              // gender, 1:Male, 2:Female, other values:unknown
+	Status    int        `json:"status"`              // account status, 1:inactive, 2:activated, 3:blocked
+	LoginAt   uint64     `json:"loginAt"`             // login timestamp
+	DeletedAt *time.Time `json:"deletedAt,omitempty"` // only set for the records in the trash
+	DeletedBy uint64     `json:"deletedBy,omitempty"` // id of the user who deleted the record
+	Version   uint64     `json:"version"`             // version of the record, send it back to update the record
 }
 
 // CreateUserRespond only for api docs
@@ -145,3 +149,327 @@ type ListUsersRespond struct {
 		Users []UserObjDetail `json:"users"`
 	} `json:"data"` // return data
 }
+
+// LoginRespond only for api docs
+type LoginRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Token     string              `json:"token"` // jwt token, set it to the Authorization header as "Bearer <token>"
+		User      UserObjDetail       `json:"user"`
+		Challenge *TwoFactorChallenge `json:"challenge"` // set instead of the token and the user if a TOTP code is required
+	} `json:"data"` // return data
+}
+
+// OIDCCallbackRequest request params, the query of the redirect from the identity provider
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`              // authorization code
+	State            string `form:"state"`             // state of the login request
+	Error            string `form:"error"`             // error code of the provider if the login failed
+	ErrorDescription string `form:"error_description"` // error description of the provider
+}
+
+// SetUserRolesRequest request params
+type SetUserRolesRequest struct {
+	RoleIDs []uint64 `json:"roleIds" binding:""` // role id list, an empty list removes all roles
+}
+
+// SetUserRolesRespond only for api docs
+type SetUserRolesRespond struct {
+	Result
+}
+
+// GetUserRolesRespond only for api docs
+type GetUserRolesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Roles []RoleObjDetail `json:"roles"`
+	} `json:"data"` // return data
+}
+
+// ListTrashUsersRequest request params
+type ListTrashUsersRequest struct {
+	query.Params
+}
+
+// ListTrashUsersRespond only for api docs
+type ListTrashUsersRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Users []UserObjDetail `json:"users"`
+		Total int64           `json:"total"`
+	} `json:"data"` // return data
+}
+
+// RestoreUserRespond only for api docs
+type RestoreUserRespond struct {
+	Result
+}
+
+// PurgeUserRespond only for api docs
+type PurgeUserRespond struct {
+	Result
+}
+
+// ImportUserRow a row of the users import and export, the users are matched by name,
+// the password is required to create a user and is never exported
+type ImportUserRow struct {
+	Name     string `json:"name" binding:"required,max=50"`         // username
+	Password string `json:"password,omitempty" binding:"max=100"`   // password, only changes the password of an existing user if resetPasswords is set
+	Email    string `json:"email" binding:"omitempty,email,max=50"` // email
+	Phone    string `json:"phone" binding:"max=30"`                 // phone number
+	Avatar   string `json:"avatar" binding:"max=200"`               // avatar
+	Age      int    `json:"age" binding:"gte=0,lte=200"`            // age
+	Gender   int    `json:"gender" binding:"gte=0"`                 // gender, 1:Male, 2:Female, other values:unknown
+	Status   int    `json:"status" binding:"omitempty,oneof=1 2 3"` // account status, 1:inactive, 2:activated, 3:blocked
+}
+
+// ExportUsersRequest request params, all the users matching the columns are exported
+type ExportUsersRequest struct {
+	Sort    string         `json:"sort" binding:""`    // sorted fields, multi-column sorting separated by commas
+	Columns []query.Column `json:"columns" binding:""` // query conditions, the same as list
+}
+
+// UpdateMeRequest request params, the profile fields users can change themselves,
+// the name, status and roles are changed by an admin
+type UpdateMeRequest struct {
+	Email   string `json:"email" binding:"omitempty,email,max=50"` // email
+	Phone   string `json:"phone" binding:"max=30"`                 // phone number
+	Age     int    `json:"age" binding:"gte=0,lte=200"`            // age
+	Gender  int    `json:"gender" binding:"gte=0"`                 // gender, 1:Male, 2:Female, other values:unknown
+	Version uint64 `json:"version" binding:""`                     // version of the profile read by get, not checked if it and the If-Match header are not set
+}
+
+// GetMeRespond only for api docs
+type GetMeRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		User UserObjDetail `json:"user"` // the password is always empty
+	} `json:"data"` // return data
+}
+
+// UpdateMeRespond only for api docs
+type UpdateMeRespond struct {
+	Result
+}
+
+// ChangePasswordRequest request params
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`                                   // the password in use
+	NewPassword     string `json:"newPassword" binding:"required,min=6,max=100,nefield=CurrentPassword"` // the new password
+}
+
+// ChangePasswordRespond only for api docs
+type ChangePasswordRespond struct {
+	Result
+}
+
+// UploadAvatarRespond only for api docs
+type UploadAvatarRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Avatar string `json:"avatar"` // url of the uploaded avatar
+	} `json:"data"` // return data
+}
+
+// ListLoginHistoryRequest request params
+type ListLoginHistoryRequest struct {
+	query.Params
+}
+
+// LoginHistoryObjDetail detail
+type LoginHistoryObjDetail struct {
+	ID string `json:"id"` // convert to string id
+
+	CreatedAt time.Time `json:"createdAt"` // time of the attempt
+	UserID    uint64    `json:"userId"`    // user id, 0 if no user has the name
+	Name      string    `json:"name"`      // username of the attempt
+	IP        string    `json:"ip"`        // client ip
+	UserAgent string    `json:"userAgent"` // user agent of the client
+	Result    string    `json:"result"`    // success, failed, locked, throttled, blocked or challenge
+}
+
+// ListLoginHistoryRespond only for api docs
+type ListLoginHistoryRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		LoginHistory []LoginHistoryObjDetail `json:"loginHistory"`
+		Total        int64                   `json:"total"`
+	} `json:"data"` // return data
+}
+
+// UnlockUserRespond only for api docs
+type UnlockUserRespond struct {
+	Result
+}
+
+// TwoFactorChallenge the second step of a login, the code is sent with the challenge to /api/v1/user/login/2fa
+type TwoFactorChallenge struct {
+	Challenge string `json:"challenge"`        // token of the login, valid for expiresIn seconds
+	ExpiresIn int    `json:"expiresIn"`        // seconds
+	Enroll    bool   `json:"enroll"`           // the roles of the user require a TOTP code but none is enrolled, the code of the new secret enables it
+	Secret    string `json:"secret,omitempty"` // base32 secret to enroll, the manual entry in the authenticator app
+	URI       string `json:"uri,omitempty"`    // otpauth:// uri of the secret to enroll, GET /api/v1/user/login/2fa/qrcode?challenge= returns its QR code
+}
+
+// LoginTwoFactorRequest request params
+type LoginTwoFactorRequest struct {
+	Challenge string `json:"challenge" binding:"required"` // challenge returned by the login
+	Code      string `json:"code" binding:"required"`      // TOTP code or recovery code
+}
+
+// LoginTwoFactorRespond only for api docs
+type LoginTwoFactorRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Token         string        `json:"token"` // jwt token, set it to the Authorization header as "Bearer <token>"
+		User          UserObjDetail `json:"user"`
+		RecoveryCodes []string      `json:"recoveryCodes"` // only set if the TOTP was enrolled at the login, they are not shown again
+	} `json:"data"` // return data
+}
+
+// TwoFactorQRCodeRequest request params
+type TwoFactorQRCodeRequest struct {
+	Challenge string `form:"challenge" binding:"required"` // challenge returned by the login
+}
+
+// GetTwoFactorRespond only for api docs
+type GetTwoFactorRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Enabled           bool  `json:"enabled"`           // the login requires a TOTP code
+		Pending           bool  `json:"pending"`           // a secret is enrolled but not enabled yet
+		Required          bool  `json:"required"`          // a role of the user requires a TOTP code
+		RecoveryCodesLeft int64 `json:"recoveryCodesLeft"` // number of unused recovery codes
+	} `json:"data"` // return data
+}
+
+// EnrollTwoFactorRespond only for api docs
+type EnrollTwoFactorRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Secret string `json:"secret"` // base32 secret, the manual entry in the authenticator app
+		URI    string `json:"uri"`    // otpauth:// uri, GET /api/v1/user/me/totp/qrcode returns its QR code
+	} `json:"data"` // return data
+}
+
+// TwoFactorCodeRequest request params
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP code, or a recovery code if the TOTP is enabled
+}
+
+// RecoveryCodesRespond only for api docs
+type RecoveryCodesRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		RecoveryCodes []string `json:"recoveryCodes"` // one-time codes replacing the TOTP code, they are not shown again
+	} `json:"data"` // return data
+}
+
+// DisableTwoFactorRespond only for api docs
+type DisableTwoFactorRespond struct {
+	Result
+}
+
+// ResetTwoFactorRespond only for api docs
+type ResetTwoFactorRespond struct {
+	Result
+}
+
+// CreateApiTokenRequest request params
+type CreateApiTokenRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"` // e.g. the pipeline using the token
+	ApiIDs    []uint64 `json:"apiIds" binding:"required,min=1"` // ids of the apis the token may call
+	ExpiresIn int      `json:"expiresIn" binding:"min=-1"`      // lifetime in days, 0 is the default lifetime, -1 never expires if the tokens have no maximum lifetime
+}
+
+// ApiTokenScope an api a token may call
+type ApiTokenScope struct {
+	ID     string `json:"id"` // api id, convert to string id
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+}
+
+// ApiTokenObjDetail detail, the token itself is only returned by the creation
+type ApiTokenObjDetail struct {
+	ID string `json:"id"` // convert to string id
+
+	CreatedAt  time.Time       `json:"createdAt"`
+	Name       string          `json:"name"`
+	Hint       string          `json:"hint"`       // start of the token, to recognize it
+	ExpiresAt  int64           `json:"expiresAt"`  // unix time, 0 if it never expires
+	LastUsedAt int64           `json:"lastUsedAt"` // unix time, 0 if it was never used
+	LastUsedIP string          `json:"lastUsedIp"`
+	RevokedAt  int64           `json:"revokedAt"` // unix time, 0 if it is not revoked
+	Active     bool            `json:"active"`    // neither revoked nor expired
+	Scopes     []ApiTokenScope `json:"scopes"`
+}
+
+// CreateApiTokenRespond only for api docs
+type CreateApiTokenRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Token    string            `json:"token"` // set it to the Authorization header as "Bearer <token>", it is not shown again
+		ApiToken ApiTokenObjDetail `json:"apiToken"`
+	} `json:"data"` // return data
+}
+
+// ListApiTokensRespond only for api docs
+type ListApiTokensRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ApiTokens []ApiTokenObjDetail `json:"apiTokens"`
+	} `json:"data"` // return data
+}
+
+// RevokeApiTokenRespond only for api docs
+type RevokeApiTokenRespond struct {
+	Result
+}
+
+// SessionObjDetail detail of a login session, the jwt itself is not shown
+type SessionObjDetail struct {
+	ID         string `json:"id"`
+	IP         string `json:"ip"`         // client ip of the login
+	UserAgent  string `json:"userAgent"`  // user agent of the login, it tells the device
+	CreatedAt  int64  `json:"createdAt"`  // unix time of the login
+	LastSeenAt int64  `json:"lastSeenAt"` // unix time, updated at most once a minute
+	ExpiresAt  int64  `json:"expiresAt"`  // unix time the jwt expires
+	Current    bool   `json:"current"`    // the session of the request
+}
+
+// ListSessionsRespond only for api docs
+type ListSessionsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Sessions []SessionObjDetail `json:"sessions"`
+	} `json:"data"` // return data
+}
+
+// RevokeSessionRespond only for api docs
+type RevokeSessionRespond struct {
+	Result
+}
+
+// LogoutRespond only for api docs
+type LogoutRespond struct {
+	Result
+}
+
+// ForceLogoutRespond only for api docs
+type ForceLogoutRespond struct {
+	Result
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+)
+
+// WorkloadRevision revision of deployment, statefulSet or daemonSet
+type WorkloadRevision struct {
+	Revision    int64     `json:"revision"`
+	Name        string    `json:"name"`        // name of replicaSet or controllerRevision
+	ChangeCause string    `json:"changeCause"` // value of the kubernetes.io/change-cause annotation
+	Images      []string  `json:"images"`      // images of init containers and containers
+	CreatedAt   time.Time `json:"createdAt"`
+	Current     bool      `json:"current"` // true if it is the revision in use
+}
+
+// RevisionChange a changed field of pod template between two revisions
+type RevisionChange struct {
+	Path string      `json:"path"` // json path of field, e.g. spec.containers[0].image
+	From interface{} `json:"from"` // null if the field is added
+	To   interface{} `json:"to"`   // null if the field is removed
+}
+
+// RollbackWorkloadRequest request params
+type RollbackWorkloadRequest struct {
+	Revision int64 `json:"revision" binding:"min=0"` // revision to roll back to, 0 means the previous revision
+}
+
+// ListWorkloadRevisionsRespond only for api docs
+type ListWorkloadRevisionsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Revisions []WorkloadRevision `json:"revisions"`
+	} `json:"data"` // return data
+}
+
+// DiffWorkloadRevisionsRespond only for api docs
+type DiffWorkloadRevisionsRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		From    WorkloadRevision `json:"from"`
+		To      WorkloadRevision `json:"to"`
+		Changes []RevisionChange `json:"changes"`
+	} `json:"data"` // return data
+}
+
+// RollbackWorkloadRespond only for api docs
+type RollbackWorkloadRespond struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Revision int64 `json:"revision"` // revision rolled back to
+		Skipped  bool  `json:"skipped"`  // true if the revision is already in use
+	} `json:"data"` // return data
+}
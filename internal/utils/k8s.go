@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -68,3 +69,22 @@ func getClientOutOfCluster() *kubernetes.Clientset {
 	}
 	return clientset
 }
+
+// NewKubeClient returns a k8s clientset built from GetKubeConfig, unlike GetKubeClientSet
+// it reports errors to the caller instead of exiting the process
+func NewKubeClient() (kubernetes.Interface, error) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// NewDynamicClient returns a k8s dynamic client built from GetKubeConfig, it works with any resource including custom resources
+func NewDynamicClient() (dynamic.Interface, error) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
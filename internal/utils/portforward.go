@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// ForwardPodPort opens a SPDY port-forward from a random port on 127.0.0.1 to the port of pod,
+// it returns the local address once the forward is ready, the forward runs until stopCh is closed
+// or the connection to the pod is lost, the result is sent on the returned channel.
+// If an error is returned, the forward has been stopped and stopCh must not be closed again.
+func ForwardPodPort(namespace string, pod string, port int, stopCh chan struct{}) (string, <-chan error, error) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return "", nil, err
+	}
+
+	url := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	readyCh := make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", port)}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return "", nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err = <-errCh: // stopCh is still open, the forward exited by itself
+		if err == nil {
+			err = errors.New("port-forward stopped before it was ready")
+		}
+		return "", nil, err
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil {
+		close(stopCh)
+		return "", nil, err
+	}
+	if len(ports) == 0 {
+		close(stopCh)
+		return "", nil, errors.New("no local port is listening")
+	}
+	return fmt.Sprintf("127.0.0.1:%d", ports[0].Local), errCh, nil
+}